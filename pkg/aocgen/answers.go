@@ -0,0 +1,126 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// loadDataset reads the dataset cached by `aocgen setup`.
+func loadDataset() ([]Challenge, error) {
+	return readDataset(io.Discard)
+}
+
+// syncAnswers copies known answers from dataset into challenges of year (all
+// years if zero) that have none. Answers depend on the puzzle input, so an
+// answer is only copied from a dataset record of the same challenge with the
+// same input. It returns how many answers were copied and how many challenges
+// were left without one because their input differs from the dataset's.
+func syncAnswers(challenges []Challenge, dataset []Challenge, year int) (synced, mismatched int) {
+	known := make(map[string][]Challenge)
+	for _, c := range dataset {
+		if c.Answer != "" {
+			known[c.Name] = append(known[c.Name], c)
+		}
+	}
+
+	for i := range challenges {
+		if challenges[i].Answer != "" || len(known[challenges[i].Name]) == 0 {
+			continue
+		}
+		if _, _, y, ok := parseChallengeName(challenges[i].Name); year != 0 && (!ok || y != year) {
+			continue
+		}
+		input := strings.TrimSpace(challenges[i].Input)
+		found := false
+		for _, c := range known[challenges[i].Name] {
+			if strings.TrimSpace(c.Input) == input {
+				challenges[i].Answer = c.Answer
+				found = true
+				break
+			}
+		}
+		if found {
+			synced++
+		} else {
+			mismatched++
+		}
+	}
+	return synced, mismatched
+}
+
+// syncAnswersFromDataset fills in answers from the cached dataset, if there is
+// one. Downloads call it so freshly downloaded puzzles can be evaluated.
+func syncAnswersFromDataset(challenges []Challenge) int {
+	dataset, err := loadDataset()
+	if err != nil {
+		return 0
+	}
+	synced, _ := syncAnswers(challenges, dataset, 0)
+	return synced
+}
+
+// runAnswersSyncCommand copies known answers from the dataset into stored
+// challenges without one, limited to --year if it is set.
+func runAnswersSyncCommand(flags Flags, w io.Writer) error {
+	dataset, err := loadDataset()
+	if os.IsNotExist(err) {
+		return fmt.Errorf("dataset not found, run 'aocgen setup' first")
+	}
+	if err != nil {
+		return fmt.Errorf("error loading dataset: %v", err)
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	synced, mismatched := syncAnswers(challenges, dataset, flags.Year)
+
+	if synced > 0 {
+		if err := saveChallenges(challenges); err != nil {
+			return fmt.Errorf("error saving challenges: %v", err)
+		}
+	}
+
+	fmt.Fprintf(w, "Synced %d answers from the dataset.\n", synced)
+	if mismatched > 0 {
+		fmt.Fprintf(w, "%d challenges have a different input than the dataset, so their answers are unknown.\n", mismatched)
+	}
+	return nil
+}
+
+// recordAnswer stores answer in every record of the challenge name with
+// input, so solutions in any language can be checked against it. Records
+// with another input have another answer and are left alone. An answer
+// Advent of Code confirmed replaces the stored one; otherwise only records
+// without an answer get it. It returns how many records changed.
+func recordAnswer(name, input, answer string, confirmed bool) (int, error) {
+	answer = strings.TrimSpace(answer)
+	input = strings.TrimSpace(input)
+	if answer == "" || input == "" {
+		return 0, nil
+	}
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for i := range challenges {
+		if challenges[i].Name != name || strings.TrimSpace(challenges[i].Input) != input || challenges[i].Answer == answer || (challenges[i].Answer != "" && !confirmed) {
+			continue
+		}
+		challenges[i].Answer = answer
+		changed++
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, saveChallenges(challenges)
+}
@@ -0,0 +1,164 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestDataset writes challenges to a parquet file with the columns of
+// the HuggingFace dataset.
+func writeTestDataset(t *testing.T, path string, challenges []Challenge) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create dataset: %v", err)
+	}
+	defer f.Close()
+	if err := writeParquet(f, challenges); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+}
+
+func TestSyncAnswers(t *testing.T) {
+	dataset := []Challenge{
+		{Name: "day1_part1_2015", Input: "(()\n", Answer: "1", SolutionLang: "go"},
+		{Name: "day1_part1_2015", Input: "(()\n", Answer: "1", SolutionLang: "python"},
+		{Name: "day2_part1_2015", Input: "2x3x4", Answer: "58"},
+		{Name: "day1_part1_2016", Input: "R2", Answer: "2"},
+	}
+	challenges := []Challenge{
+		{Name: "day1_part1_2015", Input: "(()"},
+		{Name: "day2_part1_2015", Input: "1x1x10"},
+		{Name: "day3_part1_2015", Input: ">"},
+		{Name: "day1_part1_2016", Input: "R2"},
+		{Name: "day1_part2_2015", Input: "(()", Answer: "5"},
+	}
+
+	synced, mismatched := syncAnswers(challenges, dataset, 2015)
+	if synced != 1 || mismatched != 1 {
+		t.Errorf("Expected 1 synced and 1 mismatched, got %d and %d", synced, mismatched)
+	}
+	for i, expected := range []string{"1", "", "", "", "5"} {
+		if challenges[i].Answer != expected {
+			t.Errorf("Expected answer %q for %s, got %q", expected, challenges[i].Name, challenges[i].Answer)
+		}
+	}
+
+	if synced, _ := syncAnswers(challenges, dataset, 0); synced != 1 || challenges[3].Answer != "2" {
+		t.Errorf("Expected the 2016 answer to be synced without a year filter, got %d synced: %+v", synced, challenges[3])
+	}
+}
+
+func TestRunAnswersSyncCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	if err := runAnswersSyncCommand(Flags{}, &out); err == nil || !strings.Contains(err.Error(), "aocgen setup") {
+		t.Errorf("Expected an error pointing to setup without a dataset, got %v", err)
+	}
+
+	writeTestDataset(t, filepath.Join(tempDir, datasetParquet), []Challenge{
+		{Name: "day1_part1_2015", Input: "(()", Task: "Find the floor.", Solution: "print(1)", SolutionLang: "python", Year: 2015, Answer: "1"},
+		{Name: "day2_part1_2015", Input: "2x3x4", Task: "Wrap presents.", Solution: "print(58)", SolutionLang: "python", Year: 2015, Answer: "58"},
+	})
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", Input: "(()", Year: 2015},
+		{Name: "day2_part1_2015", Input: "1x1x10", Year: 2015},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	out.Reset()
+	if err := runAnswersSyncCommand(Flags{}, &out); err != nil {
+		t.Fatalf("Failed to sync answers: %v", err)
+	}
+	if !strings.Contains(out.String(), "Synced 1 answers") || !strings.Contains(out.String(), "1 challenges have a different input") {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+
+	challenges, err := loadChallenges(tempDir, challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	if challenges[0].Answer != "1" || challenges[1].Answer != "" {
+		t.Errorf("Expected only the matching input to get its answer, got %+v", challenges)
+	}
+}
+
+func TestDownloadCopiesKnownAnswer(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2015/day/1":
+			w.Write([]byte(`<article class="day-desc"><h2>--- Day 1: Not Quite Lisp ---</h2><p>Find the floor.</p></article>`))
+		case "/2015/day/1/input":
+			w.Write([]byte("(()\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	writeTestDataset(t, filepath.Join(tempDir, datasetParquet), []Challenge{
+		{Name: "day1_part1_2015", Input: "(()", Task: "Find the floor.", Solution: "print(1)", SolutionLang: "python", Year: 2015, Answer: "1"},
+	})
+
+	if err := downloadChallenge(context.Background(), Flags{Day: 1, Part: 1, Year: 2015, Session: "test_session"}); err != nil {
+		t.Fatalf("Failed to download challenge: %v", err)
+	}
+
+	challenges, err := loadChallenges(tempDir, challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	if len(challenges) != 1 || challenges[0].Answer != "1" {
+		t.Errorf("Expected the downloaded challenge to get the dataset answer, got %+v", challenges)
+	}
+}
+
+func TestRecordAnswer(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2023", SolutionLang: "go", Input: "mine\n"},
+		{Name: "day1_part1_2023", SolutionLang: "python", Input: "mine", Answer: "old"},
+		{Name: "day1_part1_2023", SolutionLang: "rust", Input: "theirs", Answer: "55"},
+		{Name: "day2_part1_2023", Input: "mine"},
+	})
+
+	if n, err := recordAnswer("day1_part1_2023", "mine", " 142\n", false); err != nil || n != 1 {
+		t.Errorf("Expected only the record without an answer to change, got %d, %v", n, err)
+	}
+	if n, err := recordAnswer("day1_part1_2023", "mine", "142", true); err != nil || n != 1 {
+		t.Errorf("Expected a confirmed answer to replace the old one, got %d, %v", n, err)
+	}
+	challenges, _ := loadChallenges(getCacheDir(), challengesFile)
+	for _, c := range challenges {
+		want := "142"
+		switch {
+		case c.Name != "day1_part1_2023":
+			want = ""
+		case c.Input == "theirs":
+			want = "55"
+		}
+		if c.Answer != want {
+			t.Errorf("Expected %s in %s to have answer %q, got %q", c.SolutionLang, c.Name, want, c.Answer)
+		}
+	}
+}
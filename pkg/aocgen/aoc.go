@@ -0,0 +1,145 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// aocUserAgent identifies aocgen to adventofcode.com, as the site's
+// automation guidelines ask, with a link where the maintainers can be reached.
+const aocUserAgent = "aocgen (+https://github.com/isavita/aocgen)"
+
+// defaultAoCRequestsPerMinute spaces requests to adventofcode.com two seconds
+// apart unless the config file says otherwise.
+const defaultAoCRequestsPerMinute = 30
+
+// aocMaxRetries is how often a GET request that failed with a server error is
+// retried, doubling the delay each time.
+const aocMaxRetries = 3
+
+// aocRetryDelay is the delay before the first retry.
+var aocRetryDelay = time.Second
+
+// aocSleep is replaced in tests to avoid real waiting.
+var aocSleep = time.Sleep
+
+// aocThrottle spaces requests to adventofcode.com across the whole process.
+type aocThrottle struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+var aocRequests aocThrottle
+
+// wait blocks until the next request may be sent and reserves the slot after
+// it.
+func (t *aocThrottle) wait(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if wait := time.Until(t.next); wait > 0 {
+		aocSleep(wait)
+	}
+	t.next = time.Now().Add(interval)
+}
+
+// aocRequestInterval returns the minimum time between two requests to
+// adventofcode.com.
+func aocRequestInterval() time.Duration {
+	perMinute := defaultAoCRequestsPerMinute
+	if cfg, err := loadConfig(); err == nil && cfg.AoCRequestsPerMinute > 0 {
+		perMinute = cfg.AoCRequestsPerMinute
+	}
+	return time.Minute / time.Duration(perMinute)
+}
+
+// newAoCRequest creates a request to adventofcode.com carrying the session
+// cookie and aocgen's User-Agent. The request is cancelled with ctx.
+func newAoCRequest(ctx context.Context, method, url, session string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", aocUserAgent)
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+	}
+	return req, nil
+}
+
+// doAoCRequest sends req once the throttle allows it. GET requests that fail
+// with a server error are retried with exponential backoff; other requests,
+// such as answer submissions, are sent only once.
+func doAoCRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	delay := aocRetryDelay
+	for attempt := 0; ; attempt++ {
+		aocRequests.wait(aocRequestInterval())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 500 || req.Method != http.MethodGet || attempt == aocMaxRetries {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		logger.Info(fmt.Sprintf("Advent of Code returned %s, retrying in %v...", resp.Status, delay))
+		aocSleep(delay)
+		delay *= 2
+	}
+}
+
+// errLoginRequired is returned when Advent of Code asks to log in instead of
+// serving a puzzle or its input.
+var errLoginRequired = errors.New("Advent of Code asks to log in, the session token is missing or expired: " + sessionHelp)
+
+// lockedPage matches what Advent of Code says about puzzles that have not
+// unlocked yet, such as "Please don't repeatedly request this endpoint
+// before it unlocks!".
+var lockedPage = regexp.MustCompile(`(?i)please don't repeatedly request this endpoint|before it unlocks|not (yet )?available`)
+
+// loginPage matches the login page and the "Please log in to get your
+// puzzle input" notice.
+var loginPage = regexp.MustCompile(`(?i)please log in|to play, please identify yourself`)
+
+// checkPuzzlePage returns why the body of a puzzle page cannot be stored as
+// a task: the puzzle is locked, Advent of Code wants a login, or the page
+// has no puzzle at all.
+func checkPuzzlePage(body string) error {
+	if strings.Contains(body, "<article") {
+		return nil
+	}
+	switch {
+	case lockedPage.MatchString(body):
+		return errPuzzleLocked
+	case loginPage.MatchString(body):
+		return errLoginRequired
+	}
+	return errors.New("the puzzle page has no puzzle in it")
+}
+
+// checkPuzzleInput returns why the body of an input download cannot be
+// stored as puzzle input: a notice that the puzzle is locked or a login is
+// needed, a web page, or nothing at all.
+func checkPuzzleInput(body string) error {
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case lockedPage.MatchString(trimmed):
+		return errPuzzleLocked
+	case loginPage.MatchString(trimmed):
+		return errLoginRequired
+	case strings.HasPrefix(trimmed, "<"):
+		return errors.New("Advent of Code served a web page instead of the puzzle input")
+	case trimmed == "":
+		return errors.New("the puzzle input is empty")
+	}
+	return nil
+}
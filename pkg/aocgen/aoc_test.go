@@ -0,0 +1,157 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// withoutAoCThrottle makes requests to the fake Advent of Code server in a
+// test skip the throttle and retry delays.
+func withoutAoCThrottle(t *testing.T) {
+	t.Helper()
+	original := aocSleep
+	aocSleep = func(time.Duration) {}
+	t.Cleanup(func() { aocSleep = original })
+}
+
+func TestAoCThrottleSpacesRequests(t *testing.T) {
+	var slept []time.Duration
+	original := aocSleep
+	aocSleep = func(d time.Duration) { slept = append(slept, d) }
+	defer func() { aocSleep = original }()
+
+	var throttle aocThrottle
+	throttle.wait(time.Minute)
+	throttle.wait(time.Minute)
+
+	if len(slept) != 1 || slept[0] <= 59*time.Second || slept[0] > time.Minute {
+		t.Errorf("Expected one wait of about a minute before the second request, got %v", slept)
+	}
+}
+
+func TestDoAoCRequestRetriesServerErrors(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	// Keep the throttle's own waits well below the retry delays
+	if err := saveConfig(Config{AoCRequestsPerMinute: 600000}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	// Earlier tests may have reserved the next slot of the shared throttle
+	aocRequests.next = time.Time{}
+
+	var delays []time.Duration
+	aocSleep = func(d time.Duration) { delays = append(delays, d) }
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != aocUserAgent {
+			t.Errorf("Unexpected User-Agent: %q", ua)
+		}
+		requests++
+		if r.Method == http.MethodGet && requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req, _ := newAoCRequest(context.Background(), "GET", server.URL, "test_session", nil)
+	resp, err := doAoCRequest(http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if requests != 4 || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the request to be retried %d times, got %d requests ending in %s", aocMaxRetries, requests, resp.Status)
+	}
+
+	var backoff []time.Duration
+	for _, d := range delays {
+		if d >= aocRetryDelay {
+			backoff = append(backoff, d)
+		}
+	}
+	if len(backoff) != 3 || backoff[0] != aocRetryDelay || backoff[1] != 2*aocRetryDelay || backoff[2] != 4*aocRetryDelay {
+		t.Errorf("Expected exponential backoff, got %v", backoff)
+	}
+
+	requests = 10
+	req, _ = newAoCRequest(context.Background(), "POST", server.URL, "test_session", nil)
+	resp, err = doAoCRequest(http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if requests != 11 {
+		t.Errorf("Expected a POST request not to be retried, got %d requests", requests-10)
+	}
+}
+
+func TestCheckPuzzleInput(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"input", "1000\n2000\n", nil},
+		{"locked", "Please don't repeatedly request this endpoint before it unlocks! The calendar countdown is synchronized with the server time.", errPuzzleLocked},
+		{"login", "Puzzle inputs differ by user.  Please log in to get your puzzle input.\n", errLoginRequired},
+	}
+	for _, tt := range tests {
+		if err := checkPuzzleInput(tt.body); err != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+	if err := checkPuzzleInput("<!DOCTYPE html><html><body>[Log In]</body></html>"); err == nil {
+		t.Errorf("Expected a web page to be rejected")
+	}
+	if err := checkPuzzleInput("\n"); err == nil {
+		t.Errorf("Expected an empty input to be rejected")
+	}
+	if err := checkPuzzlePage("<html><p>To play, please identify yourself via one of these services:</p></html>"); err != errLoginRequired {
+		t.Errorf("Expected the login page to be rejected, got %v", err)
+	}
+}
+
+func TestDownloadChallengeRejectsNotices(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	inputStatus, inputBody := http.StatusBadRequest, "Puzzle inputs differ by user.  Please log in to get your puzzle input.\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/settings":
+		case "/2023/day/1/input":
+			w.WriteHeader(inputStatus)
+			w.Write([]byte(inputBody))
+		default:
+			w.Write([]byte(`<article class="day-desc"><h2>--- Day 1: Trebuchet?! ---</h2></article>`))
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	flags := Flags{Day: 1, Part: 1, Year: 2023, Session: "test_session"}
+	if err := downloadChallenge(context.Background(), flags); !errors.Is(err, errLoginRequired) {
+		t.Errorf("Expected a login error, got %v", err)
+	}
+
+	inputStatus, inputBody = http.StatusNotFound, "Please don't repeatedly request this endpoint before it unlocks!"
+	if err := downloadChallenge(context.Background(), flags); !errors.Is(err, errPuzzleLocked) {
+		t.Errorf("Expected the puzzle to be locked, got %v", err)
+	}
+
+	if challenges, _ := loadChallenges(tempDir, "challenges.json"); len(challenges) != 0 {
+		t.Errorf("Expected nothing to be stored, got %+v", challenges)
+	}
+}
@@ -0,0 +1,2338 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet/file"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+type Flags struct {
+	Day              int
+	Part             int
+	AllParts         bool
+	Year             int
+	Lang             string
+	Model            string
+	ModelAPI         string
+	Session          string
+	Timeout          int64
+	Stream           bool
+	Lenient          bool
+	Force            bool
+	Examples         int
+	Workers          int
+	Answer           string
+	Retry            bool
+	Template         string
+	Memory           int64
+	Resume           string
+	Format           string
+	Attempt          int
+	JSON             bool
+	DryRun           bool
+	Calendar         bool
+	Solved           bool
+	Unsolved         bool
+	NoFormat         bool
+	All              bool
+	Wait             bool
+	Workspace        bool
+	Workdir          string
+	InputArg         bool
+	Sampling         Sampling
+	SystemPrompt     string
+	HTTPTimeout      time.Duration
+	LeaderboardID    int
+	Update           bool
+	Manifest         string
+	CacheDir         string
+	Addr             string
+	Token            string
+	WithPart1        bool
+	Similar          bool
+	SkipExamples     bool
+	Input            string
+	Expect           string
+	Normalize        string
+	Rounds           int
+	Verbose          bool
+	CacheResponses   bool
+	Structured       bool
+	Open             bool
+	Head             int
+	Stats            bool
+	Save             string
+	InputLines       int
+	Profile          string
+	RetryPolicy      RetryPolicy
+	IgnoreFormatting bool
+	Quiet            bool
+	LogFile          string
+	Events           string
+	MetricsAddr      string
+	Out              string
+	Header           bool
+	HFDataset        string
+	// Args are the arguments left after the flags
+	Args []string
+}
+
+type Challenge struct {
+	Name string `json:"name"`
+	// Title is the puzzle's title, e.g. "Care Package"
+	Title        string `json:"title,omitempty"`
+	Solution     string `json:"solution"`
+	Input        string `json:"input"`
+	Task         string `json:"task"`
+	SolutionLang string `json:"solution_lang"`
+	Year         int64  `json:"year"`
+	Answer       string `json:"answer"`
+	// Examples are the worked examples of the task, used to check a
+	// solution before it runs on the real input
+	Examples []Example `json:"examples,omitempty"`
+	// Tests are test cases added with `aocgen test add`
+	Tests []Example `json:"tests,omitempty"`
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+var getCacheDirFunc = defaultGetCacheDir
+var saveChallenges = defaultSaveChallenges
+
+func getCacheDir() string {
+	return getCacheDirFunc()
+}
+
+// cacheDirEnv names the environment variable that moves the cache directory
+// away from ~/.aocgen, like --cache-dir.
+const cacheDirEnv = "AOCGEN_HOME"
+
+func defaultGetCacheDir() string {
+	if dir := os.Getenv(cacheDirEnv); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Error("cannot find the cache directory, set "+cacheDirEnv, "err", err)
+		os.Exit(1)
+	}
+	return filepath.Join(homeDir, ".aocgen")
+}
+
+// useCacheDir makes dir the cache directory for the rest of the process, for
+// --cache-dir.
+func useCacheDir(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid cache directory: %v", err)
+	}
+	setGetCacheDir(func() string { return abs })
+	return nil
+}
+
+// Add this function to allow overriding getCacheDir in tests
+func setGetCacheDir(f func() string) func() {
+	old := getCacheDirFunc
+	getCacheDirFunc = f
+	return func() { getCacheDirFunc = old }
+}
+
+const challengesFile = "challenges.json"
+const datasetParquet = "dataset.parquet"
+
+var aocBaseURL = "https://adventofcode.com"
+
+func parseFlags(args []string) (Flags, error) {
+	flags := Flags{}
+	flagSet := newFlagSet(&flags)
+
+	if len(args) == 0 {
+		return flags, nil
+	}
+
+	err := flagSet.Parse(args)
+	if err != nil {
+		return flags, err
+	}
+	flags.Args = flagSet.Args()
+
+	return flags, nil
+}
+
+// newFlagSet defines the flags shared by all subcommands on flags.
+func newFlagSet(flags *Flags) *flag.FlagSet {
+	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
+	flagSet.IntVar(&flags.Day, "day", 0, "Day of the challenge")
+	flagSet.Func("part", "Part of the challenge: 1, 2, or all to download both parts at once", func(value string) error {
+		if value == "all" {
+			flags.Part, flags.AllParts = 0, true
+			return nil
+		}
+		part, err := strconv.Atoi(value)
+		flags.Part, flags.AllParts = part, false
+		return err
+	})
+	flagSet.IntVar(&flags.Year, "year", 0, "Year of the challenge")
+	flagSet.Func("lang", "Programming language for the solution; generate takes several, comma separated or with --lang repeated", func(value string) error {
+		if flags.Lang != "" {
+			value = flags.Lang + "," + value
+		}
+		flags.Lang = value
+		return nil
+	})
+	flagSet.StringVar(&flags.Profile, "profile", "", "Use the model, endpoint, sampling and retry settings of this profile of the config file")
+	flagSet.StringVar(&flags.Model, "model", "", "AI model to use")
+	flagSet.StringVar(&flags.ModelAPI, "model_api", "", "API endpoint for the AI model (default: the endpoint of the model's provider)")
+	flagSet.StringVar(&flags.Session, "session", "", "Session token for Advent of Code")
+	flagSet.Int64Var(&flags.Timeout, "timeout", 0, "Timeout in milliseconds")
+	flagSet.BoolVar(&flags.Stream, "stream", false, "Stream model output to stderr as it is generated")
+	flagSet.BoolVar(&flags.Lenient, "lenient", false, "Accept a solution if the answer appears anywhere in its output")
+	flagSet.IntVar(&flags.Rounds, "rounds", defaultRefineRounds, "Number of times refine asks the model to fix the solution")
+	flagSet.StringVar(&flags.Normalize, "normalize", "", "Comma-separated normalizations applied to both answers before comparing: casefold, commas, spaces")
+	flagSet.BoolVar(&flags.Force, "force", false, "Overwrite existing data, including solution files that were edited by hand")
+	flagSet.IntVar(&flags.Examples, "examples", 0, "Number of solved challenges to include in the prompt as few-shot examples")
+	flagSet.BoolVar(&flags.Similar, "similar", false, "Pick the --examples solved challenges whose tasks are the most similar by embedding instead of at random (default 3 examples)")
+	flagSet.BoolVar(&flags.WithPart1, "with-part1", false, "Include the stored part 1 solution in the prompt when generating part 2")
+	flagSet.IntVar(&flags.Workers, "workers", 1, "Number of solutions to run, or of model requests a benchmark sends, concurrently")
+	flagSet.StringVar(&flags.Answer, "answer", "", "Answer to submit")
+	flagSet.StringVar(&flags.Input, "input", "", "Input of a test case, or @file to read it from")
+	flagSet.StringVar(&flags.Expect, "expect", "", "Expected answer of a test case")
+	flagSet.BoolVar(&flags.Retry, "retry", false, "Wait out the submission cooldown and retry automatically")
+	flagSet.Int64Var(&flags.Memory, "memory", 0, "Memory limit in megabytes for evaluated solutions")
+	flagSet.StringVar(&flags.Resume, "resume", "", "Resume an interrupted perf or benchmark run by its ID")
+	flagSet.StringVar(&flags.Format, "format", "table", "Output format: table or json (stats, leaderboard), markdown (benchmark compare, benchmark report), html (benchmark report), parquet, jsonl or csv (export)")
+	flagSet.IntVar(&flags.Attempt, "attempt", 0, "Print the code of this attempt number")
+	flagSet.BoolVar(&flags.IgnoreFormatting, "ignore-formatting", false, "Format both sides of a diff and ignore whitespace, so only changes to the code show")
+	flagSet.BoolVar(&flags.DryRun, "dry-run", false, "Estimate the tokens and cost of a benchmark without sending any request, or list what clean would remove")
+	flagSet.BoolVar(&flags.JSON, "json", false, "Write machine-readable JSON to stdout")
+	flagSet.BoolVar(&flags.Verbose, "verbose", false, "Log debug messages, including model API requests and responses with secrets redacted")
+	flagSet.BoolVar(&flags.Quiet, "quiet", false, "Only log warnings and errors")
+	flagSet.StringVar(&flags.LogFile, "log-file", "", "Append every log message, including debug ones, to this file")
+	flagSet.StringVar(&flags.Events, "events", "", "Append a JSON line for every step (downloaded, prompt_sent, code_received, eval_started, verdict) to this file, or - for stdout")
+	flagSet.StringVar(&flags.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics at /metrics on this address while a benchmark runs")
+	flagSet.BoolVar(&flags.Calendar, "calendar", false, "Show a year's progress as a 25-day calendar")
+	flagSet.BoolVar(&flags.Solved, "solved", false, "Only list challenges that have a solution")
+	flagSet.BoolVar(&flags.Unsolved, "unsolved", false, "Only list challenges that have no solution")
+	flagSet.BoolVar(&flags.All, "all", false, "Download every day of --year")
+	flagSet.BoolVar(&flags.Workspace, "workspace", false, "Keep each challenge in its own <year>/dayNN/partN project directory")
+	flagSet.IntVar(&flags.Head, "head", defaultInputHead, "Number of input lines input prints")
+	flagSet.BoolVar(&flags.Stats, "stats", false, "Print the line count, longest line and characters of the input")
+	flagSet.StringVar(&flags.Save, "save", "", "Write the input to this file")
+	flagSet.BoolVar(&flags.Open, "open", false, "Open the generated solution and its task in $VISUAL or $EDITOR")
+	flagSet.BoolVar(&flags.Header, "header", false, "Start generated solutions with a comment noting the model, time, prompt hash and aocgen version")
+	flagSet.StringVar(&flags.HFDataset, "hf-dataset", "", "Export locally verified solutions to this parquet file in the schema of the HuggingFace dataset")
+	flagSet.StringVar(&flags.Out, "out", "", "Directory to write solutions and input.txt to, and to read them from, instead of a directory per challenge under --workdir")
+	flagSet.StringVar(&flags.Workdir, "workdir", "", "Write each challenge's solution and input.txt to its own directory under this one and run it there (default: ~/.aocgen/work; for benchmark, a directory per run under it)")
+	registerSamplingFlags(flagSet, &flags.Sampling)
+	flagSet.StringVar(&flags.SystemPrompt, "system-prompt", "", "System prompt for the model, or @file to read it from")
+	flagSet.BoolVar(&flags.SkipExamples, "skip-examples", false, "Do not check solutions against the task's examples before the real input")
+	flagSet.IntVar(&flags.InputLines, "input-lines", defaultInputLines, "Show the model the first N lines of the real input in the prompt, as far as the context window allows (0 for none)")
+	flagSet.BoolVar(&flags.InputArg, "input-arg", false, "Pass the input file path to solutions as their first argument instead of using input.txt")
+	flagSet.DurationVar(&flags.HTTPTimeout, "http-timeout", defaultHTTPTimeout, "Time limit for each puzzle download, model request and dataset download (0 for none)")
+	flagSet.IntVar(&flags.LeaderboardID, "id", 0, "Private leaderboard ID")
+	flagSet.BoolVar(&flags.Update, "update", false, "Only download the dataset if it changed and merge it into the stored challenges")
+	flagSet.StringVar(&flags.CacheDir, "cache-dir", "", "Directory for the cache, configuration and stored challenges (default $AOCGEN_HOME or ~/.aocgen)")
+	flagSet.StringVar(&flags.Manifest, "manifest", "", "File or URL listing the dataset's parquet shards (default: ask HuggingFace)")
+	flagSet.StringVar(&flags.Addr, "addr", defaultServeAddr, "Address for serve to listen on")
+	flagSet.StringVar(&flags.Token, "token", "", "API token serve requires from clients (default $AOCGEN_TOKEN or a random one)")
+	flagSet.BoolVar(&flags.Wait, "wait", false, "Wait for the puzzle to unlock before downloading it")
+	flagSet.BoolVar(&flags.Structured, "structured", false, "Ask the model for JSON with the language and code of the solution instead of a markdown code block (OpenAI, Ollama, Groq and Mistral)")
+	flagSet.BoolVar(&flags.CacheResponses, "cache-responses", false, "Reuse the stored response to an identical request to the model instead of sending it again")
+	flagSet.BoolVar(&flags.NoFormat, "no-format", false, "Do not run the language's formatter on generated code")
+	flagSet.StringVar(&flags.Template, "template", "", "Prompt template file (default ~/.aocgen/templates/prompt.tmpl if present)")
+	return flagSet
+}
+
+// parseCommandFlags parses subcommand flags and fills the gaps from the
+// environment and the config file
+func parseCommandFlags(args []string) (Flags, error) {
+	flags, err := parseFlags(args)
+	if err != nil {
+		return flags, err
+	}
+	// The config file lives in the cache directory
+	if flags.CacheDir != "" {
+		if err := useCacheDir(flags.CacheDir); err != nil {
+			return flags, err
+		}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return flags, fmt.Errorf("error loading config: %v", err)
+	}
+	if flags.Session == "" {
+		flags.Session = resolveSession(cfg)
+	} else if keychainEnabled(cfg) && keychainSession() != flags.Session && storeSession(flags.Session) {
+		logger.Info("Stored the session token in the keychain, --session is no longer needed")
+	}
+	if err := registerConfigLanguages(cfg); err != nil {
+		return flags, err
+	}
+	if flags.Profile == "" {
+		flags.Profile = cfg.Profile
+	}
+	if cfg, err = cfg.withProfile(flags.Profile); err != nil {
+		return flags, err
+	}
+	return applyConfig(flags, cfg), nil
+}
+
+func loadChallenges(cacheDir, filename string) ([]Challenge, error) {
+	backend := storageBackend()
+	if backend == "json" {
+		return jsonStore{dir: cacheDir, file: filename}.Load()
+	}
+
+	store, err := openStore(cacheDir, backend)
+	if err != nil {
+		return nil, err
+	}
+	return store.Load()
+}
+
+// languageExtensions maps supported languages to their file extensions
+var languageExtensions = map[string]string{
+	"go":           "go",
+	"python":       "py",
+	"javascript":   "js",
+	"java":         "java",
+	"scala":        "scala",
+	"kotlin":       "kt",
+	"groovy":       "groovy",
+	"clojure":      "clj",
+	"csharp":       "cs",
+	"fsharp":       "fs",
+	"swift":        "swift",
+	"objectivec":   "m",
+	"r":            "r",
+	"haskell":      "hs",
+	"ocaml":        "ml",
+	"racket":       "rkt",
+	"scheme":       "scm",
+	"ruby":         "rb",
+	"erlang":       "erl",
+	"elixir":       "ex",
+	"rust":         "rs",
+	"c":            "c",
+	"cpp":          "cpp",
+	"zig":          "zig",
+	"fortran90":    "f90",
+	"perl":         "pl",
+	"pascal":       "pas",
+	"crystal":      "cr",
+	"julia":        "jl",
+	"lua":          "lua",
+	"php":          "php",
+	"dart":         "dart",
+	"bash":         "sh",
+	"awk":          "awk",
+	"nim":          "nim",
+	"d":            "d",
+	"v":            "v",
+	"prolog":       "pl",
+	"tcl":          "tcl",
+	"coffeescript": "coffee",
+	"typescript":   "ts",
+}
+
+// function to map languages to file extensions
+func getFileExtension(lang string) (string, error) {
+	ext, ok := languageExtensions[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported language: %s", lang)
+	}
+	return ext, nil
+}
+
+func generateSolutionFile(ctx context.Context, challenge Challenge, flags Flags) error {
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return err
+	}
+
+	filename := solutionFileName(challenge.Name, ext)
+	if err := checkOverwrite(filename, challenge, flags); err != nil {
+		return err
+	}
+
+	transcript, err := generateCode(ctx, challenge, flags)
+	if err != nil {
+		return fmt.Errorf("error generating code with AI: %v", err)
+	}
+	return saveSolutionCode(filename, challenge, flags, transcript)
+}
+
+// saveSolutionCode formats the code of a generation, writes it to filename
+// and records it as an attempt along with its transcript.
+func saveSolutionCode(filename string, challenge Challenge, flags Flags, transcript Transcript) error {
+	code := transcript.Code
+	if !flags.NoFormat {
+		formatted, err := formatCode(flags.Lang, code)
+		if err != nil {
+			logger.Warn("keeping unformatted code", "err", err)
+		}
+		code = formatted
+	}
+	if flags.Header {
+		code = addProvenanceHeader(code, flags.Lang, transcript)
+	}
+
+	err := os.WriteFile(filename, []byte(code), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write solution file: %v", err)
+	}
+
+	attempt, err := recordAttempt(challenge.Name, flags.Lang, flags.Model, code, transcript.Usage)
+	if err != nil {
+		logger.Warn("failed to record attempt", "err", err)
+		return nil
+	}
+	logger.Info(fmt.Sprintf("Saved as attempt #%d for %s in %s", attempt.Number, challenge.Name, flags.Lang))
+
+	transcript.Challenge, transcript.Lang, transcript.Attempt = challenge.Name, flags.Lang, attempt.Number
+	if err := saveTranscript(transcript); err != nil {
+		logger.Warn("failed to record transcript", "err", err)
+	}
+
+	return nil
+}
+
+func callOllamaAPI(ctx context.Context, apiURL, model, prompt string) (string, error) {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"model":  model,
+		"prompt": prompt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var result map[string]interface{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return "", err
+	}
+
+	response, ok := result["response"].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected response format")
+	}
+
+	return response, nil
+}
+
+func callOpenAIAPI(ctx context.Context, apiURL, model, system, prompt string, stream, structured bool, sampling Sampling) (string, Usage, error) {
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": chatMessages(system, prompt),
+		"stream":   stream,
+	}
+	if stream {
+		// Ask for a final chunk carrying token usage
+		payload["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+	setSamplingParams(payload, sampling, "seed")
+	if structured {
+		setResponseFormat(payload, true)
+	}
+	requestBody, err := json.Marshal(payload)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("OPENAI_API_KEY"))
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	if stream && resp.StatusCode == http.StatusOK {
+		return readStreamedCompletion(resp.Body, streamOutput)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", Usage{}, chatCompletionError(resp.Status, body)
+	}
+
+	return parseChatCompletion(body)
+}
+
+func generateCodeWithAI(ctx context.Context, challenge Challenge, flags Flags) (string, error) {
+	transcript, err := generateCode(ctx, challenge, flags)
+	return transcript.Code, err
+}
+
+// generateCode asks the model for a solution and returns the transcript of
+// the generation, which holds the code and the tokens the request used. The
+// request is cancelled with ctx or after --http-timeout.
+func generateCode(ctx context.Context, challenge Challenge, flags Flags) (Transcript, error) {
+	if flags.Model == "test" {
+		code := fmt.Sprintf(`# Test model response for %s
+def solve():
+    with open('input.txt', 'r') as file:
+        input_data = file.read()
+    # TODO: Implement solution
+    print('Hello, World!')
+
+if __name__ == '__main__':
+    solve()`, flags.Lang)
+		return Transcript{Model: flags.Model, Provider: modelProvider(flags.Model), Time: time.Now(), Response: code, Code: code}, nil
+	}
+
+	prompt, err := buildPrompt(challenge, flags)
+	if err != nil {
+		return Transcript{}, err
+	}
+	emitEvent(Event{Event: eventPromptSent, Challenge: challenge.Name, Lang: flags.Lang, Model: flags.Model, PromptTokens: countTokens(prompt)})
+	transcript, err := completeCode(ctx, prompt, flags)
+	var failure string
+	if err != nil {
+		failure = generationFailure(transcript)
+	}
+	emitEvent(Event{
+		Event:            eventCodeReceived,
+		Challenge:        challenge.Name,
+		Lang:             flags.Lang,
+		Model:            flags.Model,
+		DurationMs:       transcript.Latency.Milliseconds(),
+		PromptTokens:     transcript.Usage.PromptTokens,
+		CompletionTokens: transcript.Usage.CompletionTokens,
+		Cached:           transcript.Cached,
+		Error:            errorString(err),
+		Failure:          failure,
+	})
+	return transcript, err
+}
+
+// completeCode sends prompt to the model in flags and returns the transcript
+// of the request, including the code in its response.
+func completeCode(ctx context.Context, prompt string, flags Flags) (Transcript, error) {
+	system, err := loadSystemPrompt(flags.SystemPrompt)
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	if flags.ModelAPI == "" {
+		flags.ModelAPI = modelAPIFor(flags.Model)
+	}
+	if flags.Structured {
+		if strings.HasPrefix(flags.Model, "bedrock/") {
+			return Transcript{}, fmt.Errorf("--structured is not supported for Bedrock models")
+		}
+		system = structuredSystemPrompt(system)
+	}
+
+	transcript := Transcript{
+		Model:      flags.Model,
+		Provider:   modelProvider(flags.Model),
+		Time:       time.Now(),
+		System:     system,
+		Prompt:     prompt,
+		Sampling:   flags.Sampling,
+		Stream:     flags.Stream,
+		Structured: flags.Structured,
+	}
+
+	var cacheKey string
+	if flags.CacheResponses {
+		cacheKey = responseCacheKey(flags, system, prompt)
+		if cached, ok := loadCachedResponse(cacheKey); ok {
+			logger.Info(fmt.Sprintf("Using the cached response of %s from %s", flags.Model, cached.Time.Local().Format("2006-01-02 15:04")))
+			transcript.Cached = true
+			transcript.Response, transcript.Usage = cached.Response, cached.Usage
+			transcript.Code, err = extractSolution(cached.Response, flags)
+			return transcript, err
+		}
+	}
+
+	ctx, cancel := withHTTPTimeout(ctx, flags.HTTPTimeout)
+	defer cancel()
+
+	recorder := &exchangeRecorder{}
+	ctx = context.WithValue(ctx, exchangeRecorderKey{}, recorder)
+	if flags.RetryPolicy != (RetryPolicy{}) {
+		ctx = context.WithValue(ctx, retryPolicyKey{}, flags.RetryPolicy)
+	}
+
+	// The completion is not known yet, so only the prompt is reserved
+	limiter := limiterFor(transcript.Provider)
+	reserved := countTokens(system) + countTokens(prompt)
+	if limiter != nil {
+		if err := limiter.reserve(ctx, transcript.Provider, reserved); err != nil {
+			return Transcript{}, httpError(ctx, flags.HTTPTimeout, err)
+		}
+	}
+
+	var result string
+	var usage Usage
+
+	switch {
+	case strings.HasPrefix(flags.Model, "gpt-"):
+		result, usage, err = callOpenAIAPI(ctx, flags.ModelAPI, flags.Model, system, prompt, flags.Stream, flags.Structured, flags.Sampling)
+	case strings.HasPrefix(flags.Model, "ollama/"):
+		result, usage, err = callOllamaChatAPI(ctx, flags.ModelAPI, strings.TrimPrefix(flags.Model, "ollama/"), system, prompt, flags.Stream, flags.Structured, flags.Sampling)
+	case strings.HasPrefix(flags.Model, "groq/"):
+		result, usage, err = callGroqAPI(ctx, flags.ModelAPI, strings.TrimPrefix(flags.Model, "groq/"), system, prompt, flags.Stream, flags.Structured, flags.Sampling)
+	case isMistralModel(flags.Model):
+		result, usage, err = callMistralAPI(ctx, flags.ModelAPI, flags.Model, system, prompt, flags.Stream, flags.Structured, flags.Sampling)
+	case strings.HasPrefix(flags.Model, "bedrock/"):
+		result, usage, err = callBedrockAPI(ctx, flags.ModelAPI, strings.TrimPrefix(flags.Model, "bedrock/"), system, prompt, flags.Sampling)
+	default:
+		return Transcript{}, fmt.Errorf("unsupported model provider: %s", flags.Model)
+	}
+
+	if limiter != nil {
+		limiter.settle(reserved, usage)
+	}
+	if err != nil {
+		return Transcript{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	transcript.Latency = time.Since(transcript.Time)
+	transcript.Exchanges, transcript.Retries = recorder.all(), recorder.retryCount()
+	transcript.Response, transcript.Usage = result, usage
+
+	if err := recordUsage(flags.Model, usage); err != nil {
+		logger.Warn("failed to record token usage", "err", err)
+	}
+	if flags.CacheResponses {
+		if err := saveCachedResponse(cacheKey, cachedResponse{Model: flags.Model, Time: transcript.Time, Response: result, Usage: usage}); err != nil {
+			logger.Warn("failed to cache response", "err", err)
+		}
+	}
+
+	transcript.Code, err = extractSolution(result, flags)
+	return transcript, err
+}
+
+// ollamaSystemPrompt is sent to Ollama models unless --system-prompt is set.
+const ollamaSystemPrompt = "You are a helpful AI assistant that generates code solutions."
+
+func callOllamaChatAPI(ctx context.Context, apiURL, model, system, prompt string, stream, structured bool, sampling Sampling) (string, Usage, error) {
+	if system == "" {
+		system = ollamaSystemPrompt
+	}
+
+	requestBody := map[string]interface{}{
+		"model":    model,
+		"messages": chatMessages(system, prompt),
+		"stream":   stream,
+	}
+	// The OpenAI-compatible endpoint takes the parameters at the top level,
+	// Ollama's native chat API takes them as options
+	setSamplingParams(requestBody, sampling, "seed")
+	options := map[string]interface{}{}
+	setSamplingParams(options, sampling, "seed")
+	if maxTokens, ok := options["max_tokens"]; ok {
+		delete(options, "max_tokens")
+		options["num_predict"] = maxTokens
+	}
+	if len(options) > 0 {
+		requestBody["options"] = options
+	}
+	// Likewise for the response format, which is a schema of its own in the
+	// native API
+	if structured {
+		setResponseFormat(requestBody, true)
+		requestBody["format"] = solutionSchema
+	}
+
+	requestBodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	if stream && resp.StatusCode == http.StatusOK {
+		return readStreamedCompletion(resp.Body, streamOutput)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("error unmarshaling response: %v", err)
+	}
+
+	var content string
+
+	// Check for the simple response format
+	if simpleResponse, ok := response["response"].(string); ok {
+		content = simpleResponse
+	} else {
+		// Check for the complex response format
+		choices, ok := response["choices"].([]interface{})
+		if !ok || len(choices) == 0 {
+			return "", Usage{}, fmt.Errorf("unexpected response format: 'choices' field not found or empty")
+		}
+
+		firstChoice, ok := choices[0].(map[string]interface{})
+		if !ok {
+			return "", Usage{}, fmt.Errorf("unexpected response format: first choice is not a map")
+		}
+
+		message, ok := firstChoice["message"].(map[string]interface{})
+		if !ok {
+			return "", Usage{}, fmt.Errorf("unexpected response format: 'message' field not found in first choice")
+		}
+
+		content, ok = message["content"].(string)
+		if !ok {
+			return "", Usage{}, fmt.Errorf("unexpected response format: 'content' field not found or not a string")
+		}
+	}
+
+	return content, parseUsage(response), nil
+}
+
+// parseChatCompletion extracts the message content and token usage from an
+// OpenAI-compatible chat completions response.
+func parseChatCompletion(body []byte) (string, Usage, error) {
+	var result map[string]interface{}
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	choices, ok := result["choices"].([]interface{})
+	if !ok || len(choices) == 0 {
+		return "", Usage{}, fmt.Errorf("unexpected response format")
+	}
+
+	firstChoice, ok := choices[0].(map[string]interface{})
+	if !ok {
+		return "", Usage{}, fmt.Errorf("unexpected response format")
+	}
+
+	message, ok := firstChoice["message"].(map[string]interface{})
+	if !ok {
+		return "", Usage{}, fmt.Errorf("unexpected response format")
+	}
+
+	content, ok := message["content"].(string)
+	if !ok {
+		return "", Usage{}, fmt.Errorf("unexpected response format")
+	}
+
+	return content, parseUsage(result), nil
+}
+
+// chatCompletionError builds an error from an OpenAI-style error body,
+// falling back to the HTTP status.
+func chatCompletionError(status string, body []byte) error {
+	var errorResponse struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorResponse); err != nil || errorResponse.Error.Message == "" {
+		return fmt.Errorf("API error: %s", status)
+	}
+	return fmt.Errorf("API error: %s (%s)", errorResponse.Error.Message, errorResponse.Error.Type)
+}
+
+// writeInputFile writes the challenge input to input.txt in dir.
+func writeInputFile(dir string, challenge Challenge) error {
+	file, err := os.Create(filepath.Join(dir, "input.txt"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(challenge.Input)
+	return err
+}
+
+// writeTempInputFile writes the challenge input to a new temporary file for
+// solutions that take the input path as their first argument, so concurrent
+// evaluations never share an input.txt. The returned function removes it.
+func writeTempInputFile(challenge Challenge) (string, func(), error) {
+	file, err := os.CreateTemp("", "aocgen_input_*.txt")
+	if err != nil {
+		return "", func() {}, err
+	}
+	defer file.Close()
+
+	cleanup := func() { os.Remove(file.Name()) }
+	if _, err := file.WriteString(challenge.Input); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return file.Name(), cleanup, nil
+}
+
+func findChallenge(challenges []Challenge, flags Flags) (Challenge, error) {
+	name := fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+	for _, c := range challenges {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Challenge{}, fmt.Errorf("challenge not found: %s", name)
+}
+
+// findStoredChallenge looks up a single challenge in the configured store
+// without loading every challenge when the backend supports indexed lookup.
+func findStoredChallenge(flags Flags) (Challenge, error) {
+	if storageBackend() == "sqlite" {
+		challenge, found, err := lookupChallengeSQLite(getCacheDir(), flags.Year, flags.Day, flags.Part, "")
+		if err != nil {
+			return Challenge{}, fmt.Errorf("error loading challenges: %v", err)
+		}
+		if !found {
+			return Challenge{}, fmt.Errorf("error finding challenge: challenge not found: day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+		}
+		return challenge, nil
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), "challenges.json")
+	if err != nil {
+		return Challenge{}, fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	challenge, err := findChallenge(challenges, flags)
+	if err != nil {
+		return Challenge{}, fmt.Errorf("error finding challenge: %v", err)
+	}
+	return challenge, nil
+}
+
+// Main runs the aocgen command line interface with os.Args and exits with a
+// non-zero status on failure.
+func Main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usageMessage)
+		os.Exit(1)
+	}
+
+	ctx, stop := notifyInterrupt(context.Background())
+	defer stop()
+	commandContext = ctx
+
+	switch os.Args[1] {
+	case "list":
+		// list filters by --lang only when it is given explicitly, so the
+		// default language from the config file must not apply
+		runCommandWithParser(os.Args[2:], parseFlags, runListCommand)
+	case "generate":
+		runCommand(os.Args[2:], runGenerateCommand)
+	case "download":
+		runCommand(os.Args[2:], runDownloadCommand)
+	case "eval":
+		runCommand(os.Args[2:], runEvaluationCommand)
+	case "run":
+		runCommand(os.Args[2:], runRunCommand)
+	case "init":
+		// init reads the config file itself to offer its values as defaults
+		runCommandWithParser(os.Args[2:], parseFlags, func(flags Flags) error { return runInitCommand(flags, os.Stdin, os.Stdout) })
+	case "setup":
+		runCommand(os.Args[2:], func(flags Flags) error { return setupDataset(commandContext, flags) })
+	case "perf":
+		runCommand(os.Args[2:], runPerformanceBenchmark)
+	case "benchmark":
+		switch {
+		case len(os.Args) >= 5 && os.Args[2] == "compare":
+			runCommand(os.Args[5:], func(flags Flags) error { return runBenchmarkCompareCommand(os.Args[3], os.Args[4], flags, os.Stdout) })
+		case len(os.Args) >= 4 && os.Args[2] == "report":
+			runCommand(os.Args[4:], func(flags Flags) error { return runBenchmarkReportCommand(os.Args[3], flags, os.Stdout) })
+		case len(os.Args) == 2 || strings.HasPrefix(os.Args[2], "-"):
+			runCommand(os.Args[2:], func(flags Flags) error { return runBenchmarkCommand(flags, os.Stdout) })
+		default:
+			fmt.Println("Expected 'benchmark [flags]', 'benchmark compare <run_id> <run_id>' or 'benchmark report <run_id>'")
+			os.Exit(1)
+		}
+	case "prompt":
+		runCommand(os.Args[2:], func(flags Flags) error { return runPromptCommand(flags, os.Stdout) })
+	case "attempts":
+		runCommand(os.Args[2:], func(flags Flags) error { return runAttemptsCommand(flags, os.Stdout) })
+	case "diff":
+		if len(os.Args) < 4 {
+			fmt.Println("Expected 'diff <attempt|model|solution> <attempt|model|solution>'")
+			os.Exit(1)
+		}
+		runCommand(os.Args[4:], func(flags Flags) error { return runDiffCommand(os.Args[2], os.Args[3], flags, os.Stdout) })
+	case "stats":
+		runCommand(os.Args[2:], func(flags Flags) error { return runStatsCommand(flags, os.Stdout) })
+	case "submit":
+		runCommand(os.Args[2:], runSubmitCommand)
+	case "doctor":
+		runCommand(os.Args[2:], func(flags Flags) error { return runDoctorCommand(flags, os.Stdout) })
+	case "answers":
+		if len(os.Args) < 3 || os.Args[2] != "sync" {
+			fmt.Println("Expected 'answers sync'")
+			os.Exit(1)
+		}
+		runCommand(os.Args[3:], func(flags Flags) error { return runAnswersSyncCommand(flags, os.Stdout) })
+	case "export":
+		// like list, export filters by --lang only when it is given explicitly
+		runCommandWithParser(os.Args[2:], parseFlags, func(flags Flags) error { return runExportCommand(flags, os.Stdout) })
+	case "import":
+		if len(os.Args) < 3 {
+			fmt.Println("Expected 'import <file>'")
+			os.Exit(1)
+		}
+		runCommand(os.Args[3:], func(flags Flags) error { return runImportCommand(os.Args[2], os.Stdout) })
+	case "restore":
+		switch {
+		case len(os.Args) >= 3 && os.Args[2] == "list":
+			runCommand(os.Args[3:], func(flags Flags) error { return runRestoreListCommand(flags, os.Stdout) })
+		case len(os.Args) >= 3 && !strings.HasPrefix(os.Args[2], "-"):
+			runCommand(os.Args[3:], func(flags Flags) error { return runRestoreCommand(os.Args[2], os.Stdout) })
+		default:
+			runCommand(os.Args[2:], func(flags Flags) error { return runRestoreCommand("", os.Stdout) })
+		}
+	case "leaderboard":
+		runCommand(os.Args[2:], func(flags Flags) error { return runLeaderboardCommand(flags, os.Stdout) })
+	case "times":
+		runCommand(os.Args[2:], func(flags Flags) error { return runTimesCommand(flags, os.Stdout) })
+	case "cache":
+		if len(os.Args) < 3 {
+			fmt.Println("Expected 'cache info', 'cache path' or 'cache clean <target>...'")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "info":
+			runCommand(os.Args[3:], func(flags Flags) error { return runCacheInfoCommand(flags, os.Stdout) })
+		case "path":
+			runCommandWithParser(os.Args[3:], parseFlags, func(flags Flags) error {
+				fmt.Println(getCacheDir())
+				return nil
+			})
+		case "clean":
+			targets, args := splitCacheTargets(os.Args[3:])
+			runCommand(args, func(flags Flags) error {
+				return runCacheCleanCommand(append(targets, flags.Args...), os.Stdout)
+			})
+		default:
+			fmt.Println("Expected 'cache info', 'cache path' or 'cache clean <target>...'")
+			os.Exit(1)
+		}
+	case "completion":
+		if len(os.Args) < 3 {
+			fmt.Println("Expected 'completion bash', 'completion zsh' or 'completion fish'")
+			os.Exit(1)
+		}
+		if os.Args[2] == "values" {
+			// Called by the completion scripts on every key press; failures
+			// just mean nothing to offer
+			writeCompletionValues(os.Args[3:], os.Stdout)
+			return
+		}
+		runCommandWithParser(os.Args[3:], parseFlags, func(flags Flags) error { return writeCompletion(os.Args[2], os.Stdout) })
+	case "serve":
+		runCommand(os.Args[2:], func(flags Flags) error { return runServeCommand(commandContext, flags, os.Stdout) })
+	case "refine":
+		runCommand(os.Args[2:], func(flags Flags) error { return runRefineCommand(flags, os.Stdout) })
+	case "test":
+		if len(os.Args) < 3 {
+			fmt.Println("Expected 'test add' or 'test list'")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "add":
+			runCommand(os.Args[3:], func(flags Flags) error { return runTestAddCommand(flags, os.Stdout) })
+		case "list":
+			runCommand(os.Args[3:], func(flags Flags) error { return runTestListCommand(flags, os.Stdout) })
+		default:
+			fmt.Println("Expected 'test add' or 'test list'")
+			os.Exit(1)
+		}
+	case "transcript":
+		if len(os.Args) < 3 || os.Args[2] != "show" {
+			fmt.Println("Expected 'transcript show'")
+			os.Exit(1)
+		}
+		runCommand(os.Args[3:], func(flags Flags) error { return runTranscriptShowCommand(flags, os.Stdout) })
+	case "clean":
+		runCommand(os.Args[2:], func(flags Flags) error { return runCleanCommand(flags, os.Stdout) })
+	case "open":
+		runCommand(os.Args[2:], runOpenCommand)
+	case "show":
+		runCommand(os.Args[2:], func(flags Flags) error { return runShowCommand(flags, os.Stdout) })
+	case "input":
+		runCommand(os.Args[2:], func(flags Flags) error { return runInputCommand(flags, os.Stdout) })
+	case "models":
+		if len(os.Args) < 3 {
+			fmt.Println("Expected 'models list' or 'models pull <name>'")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "list":
+			runCommand(os.Args[3:], func(flags Flags) error { return runModelsListCommand(flags, os.Stdout) })
+		case "pull":
+			if len(os.Args) < 4 {
+				fmt.Println("Expected 'models pull <name>'")
+				os.Exit(1)
+			}
+			runCommand(os.Args[4:], func(flags Flags) error { return runModelsPullCommand(os.Args[3], flags, os.Stdout) })
+		default:
+			fmt.Println("Expected 'models list' or 'models pull <name>'")
+			os.Exit(1)
+		}
+	case "usage":
+		runCommandWithParser(os.Args[2:], parseFlags, func(flags Flags) error { return runUsageCommand(os.Stdout) })
+	default:
+		fmt.Println(usageMessage)
+		os.Exit(1)
+	}
+}
+
+const usageMessage = "Expected 'init', 'generate', 'download', 'eval', 'run', 'list', 'setup', 'perf', 'benchmark', 'prompt', 'attempts', 'diff', 'stats', 'submit', 'doctor', 'answers', 'export', 'import', 'restore', 'leaderboard', 'times', 'cache', 'completion', 'serve', 'test', 'refine', 'transcript', 'models', 'clean', 'open', 'show', 'input', or 'usage' subcommands"
+
+// runCommand parses the flags of a subcommand and runs it, exiting on error.
+// With --json the error is reported as a JSON document on stdout.
+func runCommand(args []string, run func(Flags) error) {
+	runCommandWithParser(args, parseCommandFlags, run)
+}
+
+// runCommandWithParser is runCommand with a custom flag parser.
+func runCommandWithParser(args []string, parse func([]string) (Flags, error), run func(Flags) error) {
+	flags, err := parse(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+	if flags.CacheDir != "" {
+		if err := useCacheDir(flags.CacheDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	// The log file is closed when the process exits
+	if _, err := setupLogging(flags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Like the log file, the events file is closed when the process exits
+	if _, err := setupEvents(flags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if flags.JSON {
+		enableJSONOutput()
+	}
+	if err := run(flags); err != nil {
+		var exit *exitError
+		if errors.As(err, &exit) && exit.err == nil {
+			os.Exit(exit.code)
+		}
+		interrupted := commandContext.Err() != nil
+		if interrupted {
+			err = errInterrupted
+		}
+		if flags.JSON {
+			emitJSON(map[string]string{"error": err.Error()})
+		} else {
+			logger.Error(err.Error())
+		}
+		if interrupted {
+			// The conventional status of a program stopped by SIGINT
+			os.Exit(130)
+		}
+		if exit != nil {
+			os.Exit(exit.code)
+		}
+		os.Exit(1)
+	}
+}
+
+// exitError makes a command exit with a specific status. A nil err means the
+// command has already reported the outcome, so nothing more is printed.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func runDownloadCommand(flags Flags) error {
+	if flags.All {
+		reports, err := downloadYear(commandContext, flags)
+		if err != nil {
+			return err
+		}
+		if flags.JSON {
+			if reports == nil {
+				reports = []DownloadReport{}
+			}
+			return emitJSON(reports)
+		}
+		return nil
+	}
+
+	if flags.AllParts {
+		reports, err := downloadDay(commandContext, flags)
+		if err != nil {
+			return err
+		}
+		if flags.JSON {
+			return emitJSON(reports)
+		}
+		return nil
+	}
+
+	downloaded, err := downloadChallengeIfMissing(commandContext, flags)
+	if err != nil {
+		return err
+	}
+	if flags.JSON {
+		if flags.Part == 0 {
+			flags.Part = 1
+		}
+		return emitJSON(DownloadReport{
+			Challenge:  fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year),
+			Downloaded: downloaded,
+		})
+	}
+	return nil
+}
+
+func downloadChallenge(ctx context.Context, flags Flags) error {
+	_, err := downloadChallengeIfMissing(ctx, flags)
+	return err
+}
+
+// downloadChallengeIfMissing downloads and stores a challenge unless it is
+// already stored and --force is not set. It reports whether it downloaded.
+func downloadChallengeIfMissing(ctx context.Context, flags Flags) (bool, error) {
+	if flags.Session == "" {
+		return false, fmt.Errorf("session token is required")
+	}
+
+	// Set default part to 1 if not specified
+	if flags.Part == 0 {
+		flags.Part = 1
+	}
+
+	name := fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+
+	// Ensure the cache directory exists
+	cacheDir := getCacheDir()
+	err := os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		return false, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	challenges, err := loadChallenges(cacheDir, "challenges.json")
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	if !flags.Force {
+		for _, c := range challenges {
+			if c.Name == name {
+				fmt.Printf("Challenge %s is already downloaded, use --force to download it again.\n", name)
+				return false, nil
+			}
+		}
+	}
+
+	if flags.Wait {
+		waitForUnlock(os.Stderr, flags.Year, flags.Day)
+	} else if err := checkUnlocked(flags.Year, flags.Day); err != nil {
+		return false, err
+	}
+
+	if err := verifyCommandSession(ctx, flags); err != nil {
+		return false, err
+	}
+	challenge, err := fetchChallenge(ctx, flags)
+	// The site may take a moment to serve a puzzle that has just unlocked
+	for retry := 0; flags.Wait && err == errPuzzleLocked && retry < unlockRetries; retry++ {
+		unlockSleep(time.Second)
+		challenge, err = fetchChallenge(ctx, flags)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	challenges = upsertChallenge(challenges, challenge)
+	if lookupChallenge(challenges, name).Answer == "" && syncAnswersFromDataset(challenges) > 0 {
+		fmt.Println("Copied the known answer from the dataset.")
+	}
+	err = saveChallenges(challenges)
+	if err != nil {
+		return false, fmt.Errorf("error saving challenge: %v", err)
+	}
+	emitEvent(Event{Event: eventDownloaded, Challenge: name})
+
+	fmt.Println("Challenge downloaded and saved successfully!")
+	return true, nil
+}
+
+// fetchChallenge downloads the task and input of a challenge from Advent of
+// Code without storing it.
+func fetchChallenge(ctx context.Context, flags Flags) (Challenge, error) {
+	page, err := fetchDay(ctx, flags)
+	if err != nil {
+		return Challenge{}, err
+	}
+
+	// Combine Part 1 and Part 2 for the task field
+	task := page.PartOne
+	examples := page.PartOneExamples
+	if flags.Part == 2 {
+		task = page.PartOne + "\n\n" + page.PartTwo
+		examples = page.PartTwoExamples
+	}
+
+	return Challenge{
+		Name:         fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year),
+		Title:        puzzleTitle(page.PartOne),
+		Solution:     "",
+		Input:        page.Input,
+		Task:         task,
+		SolutionLang: "",
+		Year:         int64(flags.Year),
+		Answer:       "",
+		Examples:     examples,
+	}, nil
+}
+
+// puzzleDay is what fetchDay downloads for a day.
+type puzzleDay struct {
+	PartOne string
+	// PartTwo is empty while part two is still locked
+	PartTwo         string
+	Input           string
+	PartOneExamples []Example
+	PartTwoExamples []Example
+}
+
+// errPuzzleLocked is returned for puzzles Advent of Code does not serve yet.
+var errPuzzleLocked = errors.New("puzzle is not unlocked yet")
+
+// fetchDay downloads the puzzle page and input of a day and extracts the tasks
+// and examples of both parts from the page. The downloads are cancelled with
+// ctx or after --http-timeout.
+func fetchDay(ctx context.Context, flags Flags) (puzzleDay, error) {
+	ctx, cancel := withHTTPTimeout(ctx, flags.HTTPTimeout)
+	defer cancel()
+	client := &http.Client{}
+
+	// Download challenge description
+	descURL := fmt.Sprintf("%s/%d/day/%d", aocBaseURL, flags.Year, flags.Day)
+	descReq, err := newAoCRequest(ctx, "GET", descURL, flags.Session, nil)
+	if err != nil {
+		return puzzleDay{}, err
+	}
+
+	descResp, err := doAoCRequest(client, descReq)
+	if err != nil {
+		return puzzleDay{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	defer descResp.Body.Close()
+
+	if descResp.StatusCode == http.StatusNotFound {
+		return puzzleDay{}, errPuzzleLocked
+	}
+	if descResp.StatusCode != http.StatusOK {
+		return puzzleDay{}, fmt.Errorf("failed to download challenge description: %s", descResp.Status)
+	}
+
+	descBody, err := io.ReadAll(descResp.Body)
+	if err != nil {
+		return puzzleDay{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	if err := checkPuzzlePage(string(descBody)); err != nil {
+		return puzzleDay{}, err
+	}
+
+	// Process the challenge description
+	var page puzzleDay
+	page.PartOne, page.PartTwo = cleanTaskDescription(ctx, string(descBody), flags, client)
+	page.PartOneExamples = extractExamples(string(descBody), 1)
+	page.PartTwoExamples = extractExamples(string(descBody), 2)
+
+	page.Input, err = fetchPuzzleInput(ctx, client, flags)
+	if err != nil {
+		return puzzleDay{}, err
+	}
+	return page, nil
+}
+
+// fetchPuzzleInput downloads your input of the day of flags.
+func fetchPuzzleInput(ctx context.Context, client *http.Client, flags Flags) (string, error) {
+	inputURL := fmt.Sprintf("%s/%d/day/%d/input", aocBaseURL, flags.Year, flags.Day)
+	inputReq, err := newAoCRequest(ctx, "GET", inputURL, flags.Session, nil)
+	if err != nil {
+		return "", err
+	}
+
+	inputResp, err := doAoCRequest(client, inputReq)
+	if err != nil {
+		return "", httpError(ctx, flags.HTTPTimeout, err)
+	}
+	defer inputResp.Body.Close()
+
+	inputBody, err := io.ReadAll(inputResp.Body)
+	if err != nil {
+		return "", httpError(ctx, flags.HTTPTimeout, err)
+	}
+	// Locked puzzles and missing logins come with a 404 or 400 and a
+	// notice saying so
+	if err := checkPuzzleInput(string(inputBody)); err != nil {
+		return "", err
+	}
+	if inputResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download challenge input: %s", inputResp.Status)
+	}
+	return string(inputBody), nil
+}
+
+// upsertChallenge stores a freshly downloaded challenge. Existing records with
+// the same name get the new input and task but keep their solutions and
+// answers; otherwise the challenge is appended.
+func upsertChallenge(challenges []Challenge, challenge Challenge) []Challenge {
+	updated := false
+	for i := range challenges {
+		if challenges[i].Name == challenge.Name {
+			challenges[i].Input = challenge.Input
+			challenges[i].Task = challenge.Task
+			challenges[i].Title = challenge.Title
+			challenges[i].Year = challenge.Year
+			challenges[i].Examples = challenge.Examples
+			updated = true
+		}
+	}
+	if !updated {
+		challenges = append(challenges, challenge)
+	}
+	return challenges
+}
+
+func cleanTaskDescription(ctx context.Context, htmlContent string, flags Flags, client *http.Client) (string, string) {
+	articles := extractArticles(htmlContent)
+
+	var partOne, partTwo string
+
+	if len(articles) > 0 {
+		// Remove "Your puzzle answer was" and everything after it from Part 1
+		parts := strings.Split(articles[0], "--- Part Two ---")
+		partOne = removePuzzleAnswers(parts[0])
+
+		if len(parts) > 1 {
+			partTwo = "--- Part Two ---\n\n" + removePuzzleAnswers(parts[1])
+		} else if len(articles) > 1 {
+			partTwo = formatPartTwo(articles[1])
+		} else if flags.Part == 2 {
+			// If Part Two is not found in the initial HTML, fetch it separately
+			partTwo = fetchPartTwo(ctx, flags, client)
+		}
+	}
+
+	return partOne, partTwo
+}
+
+var puzzleAnswerRe = regexp.MustCompile(`Your puzzle answer was.*`)
+
+// removePuzzleAnswers drops the "Your puzzle answer was" lines shown for
+// solved puzzles so answers never leak into the task.
+func removePuzzleAnswers(markdown string) string {
+	markdown = puzzleAnswerRe.ReplaceAllString(markdown, "")
+	return strings.TrimSpace(markdownNewlineRe.ReplaceAllString(markdown, "\n\n"))
+}
+
+func fetchPartTwo(ctx context.Context, flags Flags, client *http.Client) string {
+	descURL := fmt.Sprintf("%s/%d/day/%d", aocBaseURL, flags.Year, flags.Day)
+	descReq, err := newAoCRequest(ctx, "GET", descURL, flags.Session, nil)
+	if err != nil {
+		logger.Warn("cannot fetch part two", "err", err)
+		return ""
+	}
+
+	descResp, err := doAoCRequest(client, descReq)
+	if err != nil {
+		logger.Warn("cannot fetch part two", "err", err)
+		return ""
+	}
+	defer descResp.Body.Close()
+
+	if descResp.StatusCode != http.StatusOK {
+		logger.Warn("cannot fetch part two", "status", descResp.Status)
+		return ""
+	}
+
+	descBody, err := io.ReadAll(descResp.Body)
+	if err != nil {
+		logger.Warn("cannot read part two", "err", err)
+		return ""
+	}
+
+	articles := extractArticles(string(descBody))
+	if len(articles) > 1 {
+		return formatPartTwo(articles[1])
+	}
+
+	return ""
+}
+
+// formatPartTwo cleans up the Part Two article of a puzzle page.
+func formatPartTwo(article string) string {
+	partTwo := removePuzzleAnswers(article)
+	if strings.HasPrefix(partTwo, "--- Part Two ---") {
+		partTwo = "--- Part Two ---\n\n" + strings.TrimSpace(strings.TrimPrefix(partTwo, "--- Part Two ---"))
+	}
+	return partTwo
+}
+
+func stripTags(htmlContent string) string {
+	re := regexp.MustCompile(`<[^>]*>`)
+	return re.ReplaceAllString(htmlContent, "")
+}
+
+func defaultSaveChallenges(challenges []Challenge) error {
+	return Store{}.Save(challenges)
+}
+
+// lookupChallenge returns a pointer into challenges for the named challenge,
+// or nil if it is not there.
+func lookupChallenge(challenges []Challenge, name string) *Challenge {
+	for i := range challenges {
+		if challenges[i].Name == name {
+			return &challenges[i]
+		}
+	}
+	return nil
+}
+
+func runGenerateCommand(flags Flags) error {
+	if langs := splitLanguages(flags.Lang); len(langs) > 1 {
+		return generateLanguages(flags, langs, os.Stdout)
+	}
+	return generateSolution(flags)
+}
+
+func generateSolution(flags Flags) error {
+	report, err := generateChallengeSolution(commandContext, flags)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Challenge files created successfully in %s\n", filepath.Dir(report.File))
+	fmt.Printf("Solution: %s\n", report.File)
+
+	if flags.JSON {
+		if err := emitJSON(report); err != nil {
+			return err
+		}
+	}
+	if flags.Open {
+		return openChallenge(flags)
+	}
+	return nil
+}
+
+// generateChallengeSolution writes a model's solution of the challenge in
+// flags to its solution file, downloading the challenge first if it is not
+// stored and a session is available, and reports what it wrote.
+func generateChallengeSolution(ctx context.Context, flags Flags) (GenerateReport, error) {
+	challengeName := fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+	challenges, err := loadChallenges(getCacheDir(), "challenges.json")
+	if err != nil && !(os.IsNotExist(err) && flags.Session != "") {
+		return GenerateReport{}, fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	challenge := lookupChallenge(challenges, challengeName)
+
+	// Fetch missing challenges on the fly when we can authenticate
+	if challenge == nil && flags.Session != "" {
+		logger.Info(fmt.Sprintf("Challenge %s not found locally, downloading it first...", challengeName))
+		if err := downloadChallenge(ctx, flags); err != nil {
+			return GenerateReport{}, fmt.Errorf("error downloading challenge: %v", err)
+		}
+		challenges, err = loadChallenges(getCacheDir(), "challenges.json")
+		if err != nil {
+			return GenerateReport{}, fmt.Errorf("error loading challenges: %v", err)
+		}
+		challenge = lookupChallenge(challenges, challengeName)
+	}
+
+	if challenge == nil {
+		return GenerateReport{}, fmt.Errorf("challenge not found: %s", challengeName)
+	}
+
+	if err := ensureOllamaModel(ctx, flags); err != nil {
+		return GenerateReport{}, err
+	}
+	return writeChallengeSolution(challenges, challenge, flags, func() (Transcript, error) {
+		return generateCode(ctx, *challenge, flags)
+	})
+}
+
+// writeChallengeSolution writes the solution generate returns, along with the
+// input, to the working directory or workspace of challenge and marks
+// challenge as solved in flags.Lang. challenge must point into challenges,
+// which are saved.
+func writeChallengeSolution(challenges []Challenge, challenge *Challenge, flags Flags, generate func() (Transcript, error)) (GenerateReport, error) {
+	report, err := writeSolutionFiles(*challenge, flags, generate)
+	if err != nil {
+		return GenerateReport{}, err
+	}
+
+	// Set the SolutionLang field
+	challenge.SolutionLang = flags.Lang
+
+	// Save the updated challenges
+	err = saveChallenges(challenges)
+	if err != nil {
+		return GenerateReport{}, fmt.Errorf("error saving updated challenges: %v", err)
+	}
+	return report, nil
+}
+
+// writeSolutionFiles writes the solution generate returns, along with the
+// input, to the working directory or workspace of challenge, without
+// touching the stored challenges.
+func writeSolutionFiles(challenge Challenge, flags Flags, generate func() (Transcript, error)) (GenerateReport, error) {
+	dir, err := openChallengeDir(flags, true)
+	if err != nil {
+		return GenerateReport{}, err
+	}
+	if flags.Workspace {
+		if err := scaffoldWorkspace(dir, challenge, flags.Lang); err != nil {
+			return GenerateReport{}, err
+		}
+	}
+
+	err = writeInputFile(dir, challenge)
+	if err != nil {
+		return GenerateReport{}, fmt.Errorf("error creating input file: %v", err)
+	}
+
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return GenerateReport{}, err
+	}
+	filename := filepath.Join(dir, solutionFileName(challenge.Name, ext))
+	if err := checkOverwrite(filename, challenge, flags); err != nil {
+		return GenerateReport{}, err
+	}
+	transcript, err := generate()
+	if err != nil {
+		return GenerateReport{}, fmt.Errorf("error generating code with AI: %v", err)
+	}
+	err = saveSolutionCode(filename, challenge, flags, transcript)
+	if err != nil {
+		return GenerateReport{}, fmt.Errorf("error generating solution file: %v", err)
+	}
+
+	report := GenerateReport{
+		Challenge: challenge.Name,
+		Lang:      flags.Lang,
+		Model:     flags.Model,
+		File:      filename,
+	}
+	if attempts, err := loadAttempts(); err == nil {
+		if latest := filterAttempts(attempts, flags); len(latest) > 0 {
+			report.Attempt = latest[len(latest)-1].Number
+		}
+	}
+	return report, nil
+}
+
+func runPerformanceBenchmark(flags Flags) error {
+	var run *BenchmarkRun
+	if flags.Resume != "" {
+		var err error
+		run, err = loadBenchmarkRun(flags.Resume)
+		if err != nil {
+			return err
+		}
+		if run.Model != "" {
+			return fmt.Errorf("run %s is a benchmark run, resume it with 'aocgen benchmark --resume %s'", run.ID, run.ID)
+		}
+		if flags.Lang == "" {
+			flags.Lang = run.Lang
+		}
+		if !strings.EqualFold(flags.Lang, run.Lang) {
+			return fmt.Errorf("run %s benchmarks %s, not %s", run.ID, run.Lang, flags.Lang)
+		}
+		if flags.Timeout == 0 {
+			flags.Timeout = run.TimeoutMs
+		}
+	}
+
+	if flags.Lang == "" {
+		return fmt.Errorf("language is required for performance benchmark")
+	}
+
+	if run == nil {
+		if flags.Out != "" && flags.Workdir != "" {
+			return fmt.Errorf("--out and --workdir cannot be used together")
+		}
+		run = newBenchmarkRun(flags.Lang, flags.Timeout)
+		// The solutions are read from where generate wrote them, and the
+		// report reads them from there again
+		run.Workdir, run.Out, run.Workspace = absPath(flags.Workdir), absPath(flags.Out), flags.Workspace
+	}
+	done := run.completed()
+	fmt.Printf("Run ID: %s (continue an interrupted run with --resume %s)\n", run.ID, run.ID)
+
+	challenges, err := loadChallenges(getCacheDir(), "challenges.json")
+	if err != nil {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	fmt.Printf("Total challenges loaded: %d\n", len(challenges))
+
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return fmt.Errorf("error getting file extension: %v", err)
+	}
+
+	type benchmarkJob struct {
+		challenge Challenge
+		filename  string
+	}
+
+	var jobs []benchmarkJob
+	matchingChallenges := 0
+
+	for _, challenge := range challenges {
+		if strings.EqualFold(challenge.SolutionLang, flags.Lang) {
+			matchingChallenges++
+			filename := run.solutionPath(challenge.Name, ext)
+
+			// Check if the file exists
+			if _, err := os.Stat(filename); os.IsNotExist(err) {
+				logger.Warn(fmt.Sprintf("solution file not found for %s, skipping", challenge.Name))
+				continue
+			}
+
+			jobs = append(jobs, benchmarkJob{challenge: challenge, filename: filename})
+		}
+	}
+
+	timeout := time.Duration(flags.Timeout) * time.Millisecond
+	durations := make([]time.Duration, len(jobs))
+	outputs := make([]string, len(jobs))
+	errs := make([]error, len(jobs))
+
+	var pending []int
+	for i, job := range jobs {
+		if result, ok := done[job.challenge.Name]; ok {
+			durations[i] = result.Duration
+			if result.Error != "" {
+				errs[i] = errors.New(result.Error)
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+	var benchmarked atomic.Int64
+	if skipped := len(jobs) - len(pending); skipped > 0 {
+		fmt.Printf("Resuming run %s: %d challenges already benchmarked\n", run.ID, skipped)
+	}
+
+	err = runWorkers(commandContext, len(pending), flags.Workers, func(p int, dir string) {
+		i := pending[p]
+		job := jobs[i]
+		if err := writeInputFile(dir, job.challenge); err != nil {
+			errs[i] = fmt.Errorf("error creating input file: %v", err)
+		} else {
+			logger.Info(fmt.Sprintf("Benchmarking %s...", job.challenge.Name))
+			var args []string
+			if flags.InputArg {
+				args = append(args, filepath.Join(dir, "input.txt"))
+			}
+			durations[i], outputs[i], errs[i] = benchmarkSolution(commandContext, job.challenge, job.filename, flags.Lang, timeout, dir, args...)
+		}
+
+		// An interrupted challenge is left out of the checkpoint so a
+		// resumed run benchmarks it again
+		if errs[i] == errInterrupted {
+			return
+		}
+		benchmarked.Add(1)
+
+		result := RunResult{Challenge: job.challenge.Name, Duration: durations[i], Output: outputs[i]}
+		if errs[i] != nil {
+			result.Error = errs[i].Error()
+		}
+		if err := run.record(result); err != nil {
+			logger.Warn("failed to save checkpoint", "run", run.ID, "err", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if commandContext.Err() != nil {
+		fmt.Printf("\nInterrupted: benchmarked %d of %d challenges, %d are saved in run %s.\n", benchmarked.Load(), len(pending), len(jobs)-len(pending)+int(benchmarked.Load()), run.ID)
+		fmt.Printf("Continue with: aocgen perf --resume %s\n", run.ID)
+		return errInterrupted
+	}
+
+	if flags.JSON {
+		report := BenchmarkReport{RunID: run.ID, Lang: flags.Lang, Results: []BenchmarkReportResult{}}
+		for i, job := range jobs {
+			result := BenchmarkReportResult{
+				Challenge:  job.challenge.Name,
+				DurationMs: durations[i].Milliseconds(),
+				TimedOut:   timeout > 0 && durations[i] >= timeout,
+			}
+			if errs[i] != nil {
+				result.Error = errs[i].Error()
+			}
+			report.Results = append(report.Results, result)
+		}
+		return emitJSON(report)
+	}
+
+	results := make([]BenchmarkResult, 0, len(jobs))
+	for i, job := range jobs {
+		if errs[i] != nil {
+			logger.Error(fmt.Sprintf("benchmarking %s failed", job.challenge.Name), "err", errs[i])
+			continue
+		}
+		results = append(results, BenchmarkResult{
+			ChallengeName: job.challenge.Name,
+			Duration:      durations[i],
+		})
+	}
+
+	if matchingChallenges == 0 {
+		fmt.Printf("No challenges found for language: %s\n", flags.Lang)
+		return nil
+	}
+
+	fmt.Printf("Matching challenges: %d\n", matchingChallenges)
+	fmt.Printf("Successfully benchmarked challenges: %d\n", len(results))
+
+	// Sort results by duration in descending order
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Duration > results[j].Duration
+	})
+
+	// Print results
+	fmt.Printf("\nPerformance Benchmark Results for %s:\n", flags.Lang)
+	fmt.Println("----------------------------------------")
+	for _, result := range results {
+		if result.Duration >= time.Duration(flags.Timeout)*time.Millisecond {
+			fmt.Printf("%s: Timeout (>%dms)\n", result.ChallengeName, flags.Timeout)
+		} else {
+			fmt.Printf("%s: %v\n", result.ChallengeName, result.Duration)
+		}
+	}
+
+	return nil
+}
+
+type BenchmarkResult struct {
+	ChallengeName string
+	Duration      time.Duration
+}
+
+// benchmarkSolution times a solution run with args inside dir, which must
+// already contain the challenge's input.txt, and returns the end of its
+// output. A timed-out run reports the timeout; a run killed because ctx was
+// cancelled returns errInterrupted.
+func benchmarkSolution(ctx context.Context, challenge Challenge, filename string, lang string, timeout time.Duration, dir string, args ...string) (time.Duration, string, error) {
+	cmd, cleanup, err := getCommand(lang, filename)
+	defer cleanup()
+	if err != nil {
+		return 0, "", err
+	}
+
+	start := time.Now()
+
+	parent := ctx
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd = exec.CommandContext(ctx, cmd.Path, append(cmd.Args[1:], args...)...)
+	cmd.Dir = dir
+	killGroupOnCancel(cmd)
+	out, err := combinedOutputTree(cmd)
+	duration := time.Since(start)
+	output := tailLines(string(out), runOutputLines)
+
+	if err != nil {
+		if parent.Err() != nil {
+			return 0, "", errInterrupted
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return timeout, output, nil // Timeout occurred
+		}
+		return 0, output, fmt.Errorf("error running command: %v", err)
+	}
+
+	return duration, output, nil
+}
+
+func runEvaluationCommand(flags Flags) error {
+	challenge, result, err := evaluateChallengeSolution(commandContext, flags, nil)
+	var missing *MissingToolchainError
+	if errors.As(err, &missing) {
+		if flags.JSON {
+			emitJSON(EvalReport{Challenge: challenge.Name, Lang: flags.Lang, Verdict: VerdictMissingToolchain, ExitCode: VerdictMissingToolchain.ExitCode(), Error: err.Error()})
+			return &exitError{code: VerdictMissingToolchain.ExitCode()}
+		}
+		fmt.Println(verdictLine(challenge.Name, flags.Lang, VerdictMissingToolchain, 0))
+		return &exitError{code: VerdictMissingToolchain.ExitCode(), err: err}
+	}
+	if err != nil {
+		return err
+	}
+
+	if flags.JSON {
+		if err := emitJSON(newEvalReport(challenge.Name, flags.Lang, result)); err != nil {
+			return err
+		}
+		return verdictStatus(result.Verdict)
+	}
+
+	fmt.Println(verdictLine(challenge.Name, flags.Lang, result.Verdict, result.Duration))
+	switch result.Verdict {
+	case VerdictCorrect:
+		fmt.Printf("Solution is correct!\nOutput: %s\n", result.Output)
+	case VerdictWrongAnswer:
+		match, _ := newAnswerMatch(flags)
+		writeWrongAnswer(os.Stdout, result, match)
+	case VerdictUnknownAnswer:
+		fmt.Printf("No answer is stored for %s, so the solution could not be checked; once Advent of Code accepts yours with 'aocgen submit', it is stored for eval.\nOutput: %s\n", challenge.Name, result.Output)
+	case VerdictCompileError:
+		fmt.Printf("Solution failed to compile.\nCompiler output: %s\n", result.Output)
+	case VerdictRuntimeError:
+		fmt.Printf("Solution failed at runtime: %v\nOutput: %s\n", result.Err, result.Output)
+	case VerdictTimeout:
+		fmt.Printf("Solution timed out after %v.\nOutput: %s\n", result.Duration.Round(time.Millisecond), result.Output)
+	}
+
+	return verdictStatus(result.Verdict)
+}
+
+// evaluateChallengeSolution judges the solution file of the challenge in
+// flags, on the examples of the task and the test cases first, and records
+// the result in the eval log and the attempts. A correct solution and its
+// answer are stored with the challenge. The output of the solution is copied
+// to live, if not nil, as it runs. A missing toolchain is returned as a
+// *MissingToolchainError along with the challenge.
+func evaluateChallengeSolution(ctx context.Context, flags Flags, live io.Writer) (Challenge, EvalResult, error) {
+	challenge, result, code, err := judgeChallengeSolution(ctx, flags, live)
+	if err != nil {
+		return challenge, result, err
+	}
+
+	// Without the answer the solution was not checked, so nothing about it
+	// is kept
+	if result.Verdict == VerdictUnknownAnswer {
+		return challenge, result, nil
+	}
+
+	if err := recordEval(challenge, flags.Lang, code, result); err != nil {
+		logger.Warn("failed to record eval result", "err", err)
+	}
+	if code != "" {
+		if err := recordAttemptVerdict(challenge.Name, flags.Lang, code, result); err != nil {
+			logger.Warn("failed to record attempt verdict", "err", err)
+		}
+		if result.Verdict == VerdictCorrect {
+			if err := recordSolution(challenge, flags.Lang, code); err != nil {
+				logger.Warn("failed to record solution", "err", err)
+			}
+		}
+	}
+	// Records of the challenge in other languages may lack the answer
+	if result.Verdict == VerdictCorrect {
+		if _, err := recordAnswer(challenge.Name, challenge.Input, challenge.Answer, false); err != nil {
+			logger.Warn("failed to record answer", "err", err)
+		}
+	}
+	return challenge, result, nil
+}
+
+// judgeChallengeSolution evaluates the solution of the challenge of flags
+// like evaluateChallengeSolution without recording anything. It also
+// returns the code that was judged.
+func judgeChallengeSolution(ctx context.Context, flags Flags, live io.Writer) (Challenge, EvalResult, string, error) {
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return Challenge{}, EvalResult{}, "", err
+	}
+
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return challenge, EvalResult{}, "", fmt.Errorf("error getting file extension: %v", err)
+	}
+
+	dir, err := openChallengeDir(flags, false)
+	if err != nil {
+		return challenge, EvalResult{}, "", err
+	}
+	solutionPath := filepath.Join(dir, solutionFileName(challenge.Name, ext))
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return challenge, EvalResult{}, "", fmt.Errorf("error loading config: %v", err)
+	}
+
+	var args []string
+	if flags.InputArg {
+		inputPath, cleanup, err := writeTempInputFile(challenge)
+		if err != nil {
+			return challenge, EvalResult{}, "", fmt.Errorf("error creating input file: %v", err)
+		}
+		defer cleanup()
+		args = append(args, inputPath)
+	}
+
+	match, err := newAnswerMatch(flags)
+	if err != nil {
+		return challenge, EvalResult{}, "", err
+	}
+
+	emitEvent(Event{Event: eventEvalStarted, Challenge: challenge.Name, Lang: flags.Lang})
+	// A solution that gets the examples wrong is not run on the real input.
+	// The examples are guessed from the task, though, and some use other
+	// parameters than the puzzle, so with a known answer the real input
+	// decides and a failed example is only a warning
+	limits := resolveLimits(flags.Lang, flags, cfg)
+	var result EvalResult
+	passed := true
+	if len(challenge.Examples) > 0 && !flags.SkipExamples {
+		result, passed, err = checkExamples(ctx, challenge, challenge.Examples, "Example", solutionPath, flags.Lang, limits, match, flags)
+		if err == nil && !passed && strings.TrimSpace(challenge.Answer) != "" {
+			logger.Warn(fmt.Sprintf("%s: %s, expected %s, got %s; checking the real input anyway", result.Case, result.Verdict, result.Expected, result.Answer))
+			passed = true
+		}
+	}
+	if err == nil && passed && len(challenge.Tests) > 0 {
+		result, passed, err = checkExamples(ctx, challenge, challenge.Tests, "Test", solutionPath, flags.Lang, limits, match, flags)
+	}
+	if err == nil && passed {
+		result, err = judgeSolutionLive(ctx, challenge, solutionPath, flags.Lang, limits, match, live, args...)
+	}
+	var missing *MissingToolchainError
+	if errors.As(err, &missing) {
+		emitEvent(Event{Event: eventVerdict, Challenge: challenge.Name, Lang: flags.Lang, Verdict: VerdictMissingToolchain, Error: err.Error()})
+		return challenge, EvalResult{}, "", err
+	}
+	if err != nil {
+		err = fmt.Errorf("error evaluating solution: %v", err)
+		emitEvent(Event{Event: eventVerdict, Challenge: challenge.Name, Lang: flags.Lang, Error: err.Error()})
+		return challenge, EvalResult{}, "", err
+	}
+	emitEvent(Event{Event: eventVerdict, Challenge: challenge.Name, Lang: flags.Lang, Verdict: result.Verdict, DurationMs: result.Duration.Milliseconds()})
+
+	code, _ := os.ReadFile(solutionPath)
+	return challenge, result, string(code), nil
+}
+
+// verdictStatus makes eval exit with the verdict's exit code. The report has
+// already been printed, so there is no further message.
+func verdictStatus(verdict Verdict) error {
+	if code := verdict.ExitCode(); code != 0 {
+		return &exitError{code: code}
+	}
+	return nil
+}
+
+// evaluateSolution runs a solution and checks its answer. The answer is the
+// last non-empty line of stdout and must match challenge.Answer exactly; in
+// lenient mode it is enough for the answer to appear anywhere in the output.
+// Anything other than a correct or wrong answer is reported as an error.
+func evaluateSolution(challenge Challenge, filename string, lang string, timeout time.Duration, lenient bool) (bool, string, error) {
+	result, err := judgeSolution(context.Background(), challenge, filename, lang, Limits{Timeout: timeout}, lenient)
+	if err != nil {
+		return false, "", err
+	}
+
+	switch result.Verdict {
+	case VerdictCorrect, VerdictWrongAnswer, VerdictUnknownAnswer:
+		return result.Verdict == VerdictCorrect, result.Output, nil
+	case VerdictCompileError:
+		return false, result.Output, fmt.Errorf("compile error: %v", result.Err)
+	case VerdictTimeout:
+		return false, "", fmt.Errorf("process killed as timeout reached")
+	default:
+		return false, result.Output, fmt.Errorf("process finished with error: %v", result.Err)
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that is safe to share between the stdout and
+// stderr copying goroutines of an exec.Cmd.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// extractAnswer returns the last non-empty line of a program's stdout, trimmed.
+func extractAnswer(stdout string) string {
+	lines := strings.Split(stdout, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func ListChallenges() error {
+	return runListCommand(Flags{})
+}
+
+func runListCommand(flags Flags) error {
+	if flags.Solved && flags.Unsolved {
+		return fmt.Errorf("--solved and --unsolved are mutually exclusive")
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), "challenges.json")
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	if flags.Calendar {
+		return runCalendarCommand(flags, challenges, os.Stdout)
+	}
+
+	entries := filterListEntries(listEntries(challenges), flags)
+
+	if flags.JSON {
+		if entries == nil {
+			entries = []ListEntry{}
+		}
+		return emitJSON(entries)
+	}
+
+	if len(challenges) == 0 {
+		fmt.Println("No challenges found. Use the 'download' command to get some challenges.")
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Println("No challenges match the filters.")
+		return nil
+	}
+
+	// Print sorted challenges with their languages
+	for _, entry := range entries {
+		for _, lang := range entry.Languages {
+			if entry.Title == "" {
+				fmt.Printf("%s %s\n", entry.Name, lang)
+			} else {
+				fmt.Printf("%s %s  %s\n", entry.Name, lang, entry.Title)
+			}
+		}
+	}
+
+	return nil
+}
+
+// listEntries groups challenges by name, sorted by name, with the languages
+// each one is solved in ("unsolved" for records without a solution).
+func listEntries(challenges []Challenge) []ListEntry {
+	// Create a map to store challenges with their languages
+	challengeMap := make(map[string][]string)
+	titles := make(map[string]string)
+
+	for _, challenge := range challenges {
+		key := challenge.Name
+		if titles[key] == "" {
+			titles[key] = challengeTitle(challenge)
+		}
+		lang := challenge.SolutionLang
+		if lang == "" {
+			lang = "unsolved"
+		}
+		challengeMap[key] = append(challengeMap[key], lang)
+	}
+
+	// Create a sorted list of challenge names
+	var sortedChallenges []string
+	for challenge := range challengeMap {
+		sortedChallenges = append(sortedChallenges, challenge)
+	}
+	sort.Strings(sortedChallenges)
+
+	var entries []ListEntry
+	for _, challenge := range sortedChallenges {
+		languages := challengeMap[challenge]
+		sort.Strings(languages) // Sort languages for consistent output
+		entries = append(entries, ListEntry{Name: challenge, Title: titles[challenge], Languages: languages})
+	}
+	return entries
+}
+
+// filterListEntries applies the list filters. With --lang each entry's
+// languages are narrowed to that language, or "unsolved" if the challenge has
+// no solution in it, before --solved and --unsolved are applied.
+func filterListEntries(entries []ListEntry, flags Flags) []ListEntry {
+	var filtered []ListEntry
+	for _, entry := range entries {
+		day, _, year, ok := parseChallengeName(entry.Name)
+		if (flags.Year != 0 || flags.Day != 0) && !ok {
+			continue
+		}
+		if (flags.Year != 0 && year != flags.Year) || (flags.Day != 0 && day != flags.Day) {
+			continue
+		}
+
+		var languages []string
+		for _, lang := range entry.Languages {
+			if lang == "unsolved" {
+				continue
+			}
+			if flags.Lang == "" || strings.EqualFold(lang, flags.Lang) {
+				languages = append(languages, lang)
+			}
+		}
+		solved := len(languages) > 0
+		if !solved {
+			languages = []string{"unsolved"}
+		} else if flags.Lang == "" {
+			languages = entry.Languages
+		}
+
+		if (flags.Solved && !solved) || (flags.Unsolved && solved) {
+			continue
+		}
+		if flags.Solved {
+			languages = removeString(languages, "unsolved")
+		}
+		filtered = append(filtered, ListEntry{Name: entry.Name, Title: entry.Title, Languages: languages})
+	}
+	return filtered
+}
+
+func removeString(values []string, s string) []string {
+	var result []string
+	for _, v := range values {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// parquetBatchSize is how many rows readParquetFile decodes at a time.
+const parquetBatchSize = 1024
+
+// readParquetFile reads the challenges of a dataset parquet file, reporting
+// progress to w. The file is streamed one row group at a time in batches of
+// parquetBatchSize rows, so only the challenges themselves are kept in
+// memory. Columns are matched to Challenge fields by name, see
+// resolveDatasetColumns; other columns are not read.
+func readParquetFile(filepath string, w io.Writer) ([]Challenge, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %v", err)
+	}
+	defer f.Close()
+
+	reader, err := file.NewParquetReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("error creating parquet reader: %v", err)
+	}
+	defer reader.Close()
+
+	arrowReader, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{BatchSize: parquetBatchSize}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, fmt.Errorf("error creating arrow reader: %v", err)
+	}
+
+	schema, err := arrowReader.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema: %v", err)
+	}
+	columns, err := resolveDatasetColumns(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	numRows := reader.NumRows()
+	fmt.Fprintf(w, "Total rows in parquet file: %d\n", numRows)
+
+	challenges := make([]Challenge, 0, numRows)
+	for rg := 0; rg < reader.NumRowGroups(); rg++ {
+		records, err := arrowReader.GetRecordReader(context.Background(), columns, []int{rg})
+		if err != nil {
+			return nil, fmt.Errorf("error reading row group %d: %v", rg, err)
+		}
+		for records.Next() {
+			challenges = appendRecordChallenges(challenges, records.Record())
+		}
+		err = records.Err()
+		records.Release()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading row group %d: %v", rg, err)
+		}
+		fmt.Fprintf(w, "Processed row group %d of %d\n", rg+1, reader.NumRowGroups())
+	}
+
+	fmt.Fprintf(w, "Total challenges processed: %d\n", len(challenges))
+	return challenges, nil
+}
+
+// optionalDatasetColumns may be missing from a dataset file. Files from
+// before answers were added to the dataset have no answer column.
+var optionalDatasetColumns = map[string]bool{"answer": true}
+
+// resolveDatasetColumns finds the datasetColumns in schema by name and
+// returns their indices. Missing required columns and columns of the wrong
+// type are errors, so a changed upstream schema fails instead of yielding
+// empty challenges.
+func resolveDatasetColumns(schema *arrow.Schema) ([]int, error) {
+	var columns []int
+	var missing []string
+	for _, name := range datasetColumns {
+		indices := schema.FieldIndices(name)
+		if len(indices) == 0 {
+			if !optionalDatasetColumns[name] {
+				missing = append(missing, name)
+			}
+			continue
+		}
+
+		field := schema.Field(indices[0])
+		var expected arrow.DataType = arrow.BinaryTypes.String
+		if name == "year" {
+			expected = arrow.PrimitiveTypes.Int64
+		}
+		if !arrow.TypeEqual(field.Type, expected) {
+			return nil, fmt.Errorf("dataset column %q has type %s, expected %s", name, field.Type, expected)
+		}
+		columns = append(columns, indices[0])
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("dataset is missing required columns: %s", strings.Join(missing, ", "))
+	}
+	sort.Ints(columns)
+	return columns, nil
+}
+
+// challengeStringFields maps the string columns of the dataset to the
+// Challenge fields they fill.
+var challengeStringFields = map[string]func(c *Challenge) *string{
+	"name":          func(c *Challenge) *string { return &c.Name },
+	"solution":      func(c *Challenge) *string { return &c.Solution },
+	"input":         func(c *Challenge) *string { return &c.Input },
+	"task":          func(c *Challenge) *string { return &c.Task },
+	"solution_lang": func(c *Challenge) *string { return &c.SolutionLang },
+	"answer":        func(c *Challenge) *string { return &c.Answer },
+}
+
+// appendRecordChallenges appends a challenge for each row of record. The
+// strings are copied out of the record so its buffers can be freed.
+func appendRecordChallenges(challenges []Challenge, record arrow.Record) []Challenge {
+	start := len(challenges)
+	for i := 0; i < int(record.NumRows()); i++ {
+		challenges = append(challenges, Challenge{})
+	}
+	rows := challenges[start:]
+
+	for i, field := range record.Schema().Fields() {
+		switch col := record.Column(i).(type) {
+		case *array.String:
+			dst, ok := challengeStringFields[field.Name]
+			if !ok {
+				continue
+			}
+			for j := range rows {
+				if !col.IsNull(j) {
+					*dst(&rows[j]) = strings.Clone(col.Value(j))
+				}
+			}
+		case *array.Int64:
+			if field.Name != "year" {
+				continue
+			}
+			for j := range rows {
+				if !col.IsNull(j) {
+					rows[j].Year = col.Value(j)
+				}
+			}
+		}
+	}
+	return challenges
+}
@@ -1,7 +1,8 @@
-package main
+package aocgen
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,6 +17,9 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// testEnvFile is the .env file in the repository root.
+var testEnvFile = filepath.Join("..", "..", ".env")
+
 func setupTestEnvironment(t *testing.T) (string, func()) {
 	t.Helper()
 
@@ -26,10 +30,17 @@ func setupTestEnvironment(t *testing.T) (string, func()) {
 
 	originalGetCacheDir := getCacheDirFunc
 	originalSaveChallenges := saveChallenges
-
-	getCacheDirFunc = func() string {
-		return tempDir
-	}
+	originalAocSleep := aocSleep
+	aocSleep = func(time.Duration) {}
+	originalRetrySleep := retrySleep
+	retrySleep = func(context.Context, time.Duration) error { return nil }
+	// Tests never touch the keychain of the machine
+	originalKeychain := osKeychain
+	osKeychain = unavailableKeychain{}
+
+	// Commands find the cache the same way as outside tests
+	t.Setenv(cacheDirEnv, tempDir)
+	getCacheDirFunc = defaultGetCacheDir
 
 	saveChallenges = func(challenges []Challenge) error {
 		data, err := json.Marshal(challenges)
@@ -42,12 +53,27 @@ func setupTestEnvironment(t *testing.T) (string, func()) {
 	cleanup := func() {
 		getCacheDirFunc = originalGetCacheDir
 		saveChallenges = originalSaveChallenges
+		aocSleep = originalAocSleep
+		retrySleep = originalRetrySleep
+		osKeychain = originalKeychain
 		os.RemoveAll(tempDir)
 	}
 
 	return tempDir, cleanup
 }
 
+// testSolutionPath returns the path generate writes the solution of the
+// challenge name with extension ext to by default, creating its directory.
+func testSolutionPath(t *testing.T, name, ext string) string {
+	t.Helper()
+
+	dir := filepath.Join(defaultWorkdir(), name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create the working directory: %v", err)
+	}
+	return filepath.Join(dir, name+"."+ext)
+}
+
 // TestParseFlags tests the parsing of command-line flags
 func TestParseFlags(t *testing.T) {
 	// Save original os.Args
@@ -111,7 +137,7 @@ func TestGenerateSolutionFile(t *testing.T) {
 		ModelAPI: "http://example.com", // This is not used for "test" model, but included for completeness
 	}
 
-	err := generateSolutionFile(challenge, flags)
+	err := generateSolutionFile(context.Background(), challenge, flags)
 	if err != nil {
 		t.Fatalf("Failed to generate solution file: %v", err)
 	}
@@ -144,7 +170,7 @@ func TestGenerateSolutionFileUnsupportedLang(t *testing.T) {
 		Model: "test-model",
 	}
 
-	err := generateSolutionFile(challenge, flags)
+	err := generateSolutionFile(context.Background(), challenge, flags)
 	if err == nil {
 		t.Errorf("Expected error for unsupported language, but got none")
 	}
@@ -161,7 +187,7 @@ func TestGenerateSolutionFileUnsupportedLang(t *testing.T) {
 
 // TestCreateInputFile tests the creation of an input file
 func TestCreateInputFile(t *testing.T) {
-	_, cleanup := setupTestEnvironment(t)
+	tempDir, cleanup := setupTestEnvironment(t)
 	defer cleanup()
 
 	challenge := Challenge{
@@ -169,13 +195,13 @@ func TestCreateInputFile(t *testing.T) {
 		Input: "test input",
 	}
 
-	err := createInputFile(challenge)
+	err := writeInputFile(tempDir, challenge)
 	if err != nil {
 		t.Fatalf("Failed to create input file: %v", err)
 	}
 
 	// Check if file was created and contains correct content
-	content, err := os.ReadFile("input.txt")
+	content, err := os.ReadFile(filepath.Join(tempDir, "input.txt"))
 	if err != nil {
 		t.Fatalf("Failed to read input file: %v", err)
 	}
@@ -236,7 +262,7 @@ func TestEvaluateSolution(t *testing.T) {
 		Answer: "42",
 	}
 
-	correct, output, err := evaluateSolution(challenge, tmpfile.Name(), "python", 5*time.Second)
+	correct, output, err := evaluateSolution(challenge, tmpfile.Name(), "python", 5*time.Second, false)
 	if err != nil {
 		t.Fatalf("Failed to evaluate solution: %v", err)
 	}
@@ -247,7 +273,7 @@ func TestEvaluateSolution(t *testing.T) {
 
 	// Test incorrect solution
 	challenge.Answer = "24"
-	correct, output, err = evaluateSolution(challenge, tmpfile.Name(), "python", 5*time.Second)
+	correct, output, err = evaluateSolution(challenge, tmpfile.Name(), "python", 5*time.Second, false)
 	if err != nil {
 		t.Fatalf("Failed to evaluate solution: %v", err)
 	}
@@ -267,7 +293,7 @@ func TestGenerateCodeWithAI(t *testing.T) {
 		Model: "test",
 	}
 
-	code, err := generateCodeWithAI(challenge, flags)
+	code, err := generateCodeWithAI(context.Background(), challenge, flags)
 	if err != nil {
 		t.Fatalf("Failed to generate code with AI: %v", err)
 	}
@@ -324,7 +350,7 @@ func TestGenerateCodeWithAIOllama(t *testing.T) {
 		ModelAPI: server.URL + "/v1/chat/completions",
 	}
 
-	code, err := generateCodeWithAI(challenge, flags)
+	code, err := generateCodeWithAI(context.Background(), challenge, flags)
 	if err != nil {
 		t.Fatalf("Failed to generate code with AI: %v", err)
 	}
@@ -343,7 +369,7 @@ func TestGenerateCodeWithAIOpenAI(t *testing.T) {
 	defer cleanup()
 
 	// Load the .env file
-	err := godotenv.Load()
+	err := godotenv.Load(testEnvFile)
 	if err != nil {
 		t.Fatalf("Error loading .env file: %v", err)
 	}
@@ -369,7 +395,7 @@ func TestGenerateCodeWithAIOpenAI(t *testing.T) {
 		ModelAPI: "https://api.openai.com/v1/chat/completions",
 	}
 
-	code, err := generateCodeWithAI(challenge, flags)
+	code, err := generateCodeWithAI(context.Background(), challenge, flags)
 	if err != nil {
 		if strings.Contains(err.Error(), "insufficient_quota") {
 			t.Skip("Skipping OpenAI test: Insufficient quota")
@@ -391,7 +417,7 @@ func TestGenerateCodeWithAIGroq(t *testing.T) {
 	defer cleanup()
 
 	// Load the .env file
-	err := godotenv.Load()
+	err := godotenv.Load(testEnvFile)
 	if err != nil {
 		t.Fatalf("Error loading .env file: %v", err)
 	}
@@ -417,7 +443,7 @@ func TestGenerateCodeWithAIGroq(t *testing.T) {
 		ModelAPI: "https://api.groq.com/openai/v1/chat/completions",
 	}
 
-	code, err := generateCodeWithAI(challenge, flags)
+	code, err := generateCodeWithAI(context.Background(), challenge, flags)
 	if err != nil {
 		t.Fatalf("Failed to generate code with AI: %v", err)
 	}
@@ -516,7 +542,7 @@ func TestDownloadChallenge(t *testing.T) {
 				Session: "test_session",
 			}
 
-			err := downloadChallenge(flags)
+			err := downloadChallenge(context.Background(), flags)
 			if err != nil {
 				t.Fatalf("Failed to download challenge: %v", err)
 			}
@@ -605,6 +631,10 @@ func TestDownloadChallengeWithAnswers(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/input") {
+					w.Write([]byte("1000\n2000\n"))
+					return
+				}
 				w.Write([]byte(tc.responseBody))
 			}))
 			defer server.Close()
@@ -620,7 +650,7 @@ func TestDownloadChallengeWithAnswers(t *testing.T) {
 				Session: "test_session",
 			}
 
-			err := downloadChallenge(flags)
+			err := downloadChallenge(context.Background(), flags)
 			if err != nil {
 				t.Fatalf("Failed to download challenge: %v", err)
 			}
@@ -665,7 +695,7 @@ func TestRealDownloadChallenge(t *testing.T) {
 		t.Skip("Skipping real download test. Set RUN_REAL_DOWNLOAD_TEST=true to run this test.")
 	}
 
-	err := godotenv.Load()
+	err := godotenv.Load(testEnvFile)
 	if err != nil {
 		t.Fatalf("Error loading .env file: %v", err)
 	}
@@ -701,7 +731,7 @@ func TestRealDownloadChallenge(t *testing.T) {
 				Session: session,
 			}
 
-			err := downloadChallenge(flags)
+			err := downloadChallenge(context.Background(), flags)
 			if err != nil {
 				t.Fatalf("Failed to download challenge: %v", err)
 			}
@@ -792,6 +822,51 @@ day3_part1_2022 unsolved
 	}
 }
 
+func TestFilterListEntries(t *testing.T) {
+	entries := listEntries([]Challenge{
+		{Name: "day1_part1_2019", SolutionLang: "python"},
+		{Name: "day1_part1_2019", SolutionLang: "clojure"},
+		{Name: "day2_part1_2019", SolutionLang: "python"},
+		{Name: "day3_part1_2019"},
+		{Name: "day1_part1_2020", SolutionLang: "clojure"},
+	})
+
+	format := func(entries []ListEntry) string {
+		var lines []string
+		for _, e := range entries {
+			lines = append(lines, e.Name+" "+strings.Join(e.Languages, ","))
+		}
+		return strings.Join(lines, "; ")
+	}
+
+	tests := []struct {
+		name     string
+		flags    Flags
+		expected string
+	}{
+		{"no filters", Flags{}, "day1_part1_2019 clojure,python; day1_part1_2020 clojure; day2_part1_2019 python; day3_part1_2019 unsolved"},
+		{"year", Flags{Year: 2019}, "day1_part1_2019 clojure,python; day2_part1_2019 python; day3_part1_2019 unsolved"},
+		{"day", Flags{Day: 1}, "day1_part1_2019 clojure,python; day1_part1_2020 clojure"},
+		{"lang", Flags{Year: 2019, Lang: "Clojure"}, "day1_part1_2019 clojure; day2_part1_2019 unsolved; day3_part1_2019 unsolved"},
+		{"lang unsolved", Flags{Year: 2019, Lang: "clojure", Unsolved: true}, "day2_part1_2019 unsolved; day3_part1_2019 unsolved"},
+		{"lang solved", Flags{Lang: "clojure", Solved: true}, "day1_part1_2019 clojure; day1_part1_2020 clojure"},
+		{"unsolved", Flags{Unsolved: true}, "day3_part1_2019 unsolved"},
+		{"solved", Flags{Year: 2019, Solved: true}, "day1_part1_2019 clojure,python; day2_part1_2019 python"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := format(filterListEntries(entries, tt.flags)); got != tt.expected {
+				t.Errorf("Unexpected entries.\nExpected: %s\nGot:      %s", tt.expected, got)
+			}
+		})
+	}
+
+	if err := runListCommand(Flags{Solved: true, Unsolved: true}); err == nil {
+		t.Errorf("Expected an error for --solved with --unsolved")
+	}
+}
+
 func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 	_, cleanup := setupTestEnvironment(t)
 	defer cleanup()
@@ -801,6 +876,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 		lang           string
 		code           string
 		expectedAnswer string
+		lenient        bool
 		expectedResult bool
 		expectedOutput string
 	}{
@@ -809,6 +885,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 			lang:           "python",
 			code:           "print('The answer is:', 40+2)",
 			expectedAnswer: "42",
+			lenient:        true,
 			expectedResult: true,
 			expectedOutput: "The answer is: 42",
 		},
@@ -817,6 +894,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 			lang:           "ruby",
 			code:           "puts 'Result: ' + (40+2).to_s",
 			expectedAnswer: "42",
+			lenient:        true,
 			expectedResult: true,
 			expectedOutput: "Result: 42",
 		},
@@ -825,6 +903,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 			lang:           "javascript",
 			code:           "console.log('The sum is:', 40+2)",
 			expectedAnswer: "42",
+			lenient:        true,
 			expectedResult: true,
 			expectedOutput: "The sum is: 42",
 		},
@@ -833,6 +912,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 			lang:           "go",
 			code:           "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"Answer:\", 40+2)\n}",
 			expectedAnswer: "42",
+			lenient:        true,
 			expectedResult: true,
 			expectedOutput: "Answer: 42",
 		},
@@ -841,9 +921,35 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 			lang:           "python",
 			code:           "print('The answer is:', 40+3)",
 			expectedAnswer: "42",
+			lenient:        true,
 			expectedResult: false,
 			expectedOutput: "The answer is: 43",
 		},
+		{
+			name:           "Python exact answer on last line",
+			lang:           "python",
+			code:           "print('processed 42 lines')\nprint()\nprint(' 1337 ')",
+			expectedAnswer: "1337",
+			expectedResult: true,
+			expectedOutput: "processed 42 lines",
+		},
+		{
+			name:           "Python debug output containing the answer is not accepted",
+			lang:           "python",
+			code:           "print('processed 42 lines')\nprint(7)",
+			expectedAnswer: "42",
+			expectedResult: false,
+			expectedOutput: "processed 42 lines",
+		},
+		{
+			name:           "Python lenient mode accepts answer anywhere in output",
+			lang:           "python",
+			code:           "print('processed 42 lines')\nprint(7)",
+			expectedAnswer: "42",
+			lenient:        true,
+			expectedResult: true,
+			expectedOutput: "processed 42 lines",
+		},
 	}
 
 	for _, tt := range tests {
@@ -884,7 +990,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 			}
 
 			// Evaluate the solution
-			result, output, err := evaluateSolution(challenge, filename, tt.lang, 5*time.Second)
+			result, output, err := evaluateSolution(challenge, filename, tt.lang, 5*time.Second, tt.lenient)
 			if err != nil {
 				t.Fatalf("Evaluation failed: %v", err)
 			}
@@ -906,7 +1012,7 @@ func TestEvaluateSolutionMultiLanguage(t *testing.T) {
 
 func TestGenerateSolutionFileOpenAI(t *testing.T) {
 	// Load the .env file
-	err := godotenv.Load()
+	err := godotenv.Load(testEnvFile)
 	if err != nil {
 		t.Fatalf("Error loading .env file: %v", err)
 	}
@@ -939,7 +1045,7 @@ func TestGenerateSolutionFileOpenAI(t *testing.T) {
 		ModelAPI: "https://api.openai.com/v1/chat/completions",
 	}
 
-	err = generateSolutionFile(challenge, flags)
+	err = generateSolutionFile(context.Background(), challenge, flags)
 	if err != nil {
 		if strings.Contains(err.Error(), "insufficient_quota") {
 			t.Skip("Skipping OpenAI test: Insufficient quota")
@@ -977,7 +1083,7 @@ func TestDownloadChallengePart2(t *testing.T) {
 	defer cleanup()
 
 	// Load environment variables
-	err := godotenv.Load()
+	err := godotenv.Load(testEnvFile)
 	if err != nil {
 		t.Fatalf("Error loading .env file: %v", err)
 	}
@@ -1050,7 +1156,7 @@ func TestDownloadChallengePart2(t *testing.T) {
 	}
 
 	// Run the download function
-	err = downloadChallenge(flags)
+	err = downloadChallenge(context.Background(), flags)
 	if err != nil {
 		t.Fatalf("Failed to download challenge: %v", err)
 	}
@@ -1095,3 +1201,122 @@ func TestDownloadChallengePart2(t *testing.T) {
 		t.Errorf("Incorrect challenge year. Got: %d, Want: 2015", challenge.Year)
 	}
 }
+
+func TestDownloadChallengeUpsert(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	input := "first input"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/2022/day/1":
+			w.Write([]byte(`<article class="day-desc"><h2>--- Day 1: Calorie Counting ---</h2><p>Count calories.</p></article>`))
+		case "/2022/day/1/input":
+			w.Write([]byte(input))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	// A solved copy of the same challenge from the dataset must keep its solution
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2022", Input: "old input", Solution: "print(1)", SolutionLang: "python", Answer: "24000"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	flags := Flags{Day: 1, Part: 1, Year: 2022, Session: "test_session"}
+
+	// Without --force nothing is fetched or changed
+	if err := downloadChallenge(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to download challenge: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests for an already downloaded challenge, got %d", requests)
+	}
+
+	flags.Force = true
+	if err := downloadChallenge(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to download challenge: %v", err)
+	}
+	input = "second input"
+	if err := downloadChallenge(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to download challenge: %v", err)
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), "challenges.json")
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+
+	if len(challenges) != 1 {
+		t.Fatalf("Expected a single challenge after repeated downloads, got %d", len(challenges))
+	}
+
+	c := challenges[0]
+	if c.Input != "second input" {
+		t.Errorf("Expected --force to overwrite the input, got %q", c.Input)
+	}
+	if !strings.Contains(c.Task, "Count calories.") {
+		t.Errorf("Expected --force to overwrite the task, got %q", c.Task)
+	}
+	if c.Solution != "print(1)" || c.SolutionLang != "python" || c.Answer != "24000" {
+		t.Errorf("Expected solution and answer to be preserved, got %+v", c)
+	}
+}
+
+func TestGenerateSolutionDownloadsMissingChallenge(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2021/day/2":
+			w.Write([]byte(`<article class="day-desc"><h2>--- Day 2: Dive! ---</h2><p>Steer the submarine.</p></article>`))
+		case "/2021/day/2/input":
+			w.Write([]byte("forward 5\ndown 5"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	flags := Flags{Day: 2, Part: 1, Year: 2021, Lang: "python", Model: "test"}
+	if err := generateSolution(flags); err == nil {
+		t.Fatalf("Expected an error for a missing challenge without a session")
+	}
+
+	flags.Session = "test_session"
+	if err := generateSolution(flags); err != nil {
+		t.Fatalf("Failed to generate solution: %v", err)
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), "challenges.json")
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	if len(challenges) != 1 || challenges[0].Input != "forward 5\ndown 5" || challenges[0].SolutionLang != "python" {
+		t.Errorf("Expected the downloaded challenge to be stored, got %+v", challenges)
+	}
+	if _, err := os.Stat(testSolutionPath(t, "day2_part1_2021", "py")); err != nil {
+		t.Errorf("Expected the solution file to be written: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const attemptsFile = "attempts.json"
+
+// Attempt is one generated solution. Every generate run adds an attempt, so
+// solutions from different models for the same challenge can be compared.
+type Attempt struct {
+	Challenge string    `json:"challenge"`
+	Lang      string    `json:"lang"`
+	Number    int       `json:"number"`
+	Model     string    `json:"model"`
+	Time      time.Time `json:"time"`
+	Code      string    `json:"code"`
+	// Usage is the token count of the request that generated the code.
+	Usage Usage `json:"usage"`
+	// Verdict is the result of the latest eval of this attempt, if any, and
+	// Duration the wall-clock time of that eval.
+	Verdict  Verdict       `json:"verdict,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+func loadAttempts() ([]Attempt, error) {
+	var attempts []Attempt
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), attemptsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return attempts, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		return nil, fmt.Errorf("error parsing attempts: %v", err)
+	}
+	return attempts, nil
+}
+
+func saveAttempts(attempts []Attempt) error {
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(attempts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getCacheDir(), attemptsFile), data, 0644)
+}
+
+// recordAttempt stores generated code as the next attempt for the challenge
+// in lang and returns it.
+func recordAttempt(challenge, lang, model, code string, usage Usage) (Attempt, error) {
+	attempts, err := loadAttempts()
+	if err != nil {
+		return Attempt{}, err
+	}
+
+	attempt := Attempt{Challenge: challenge, Lang: lang, Number: 1, Model: model, Time: time.Now(), Code: code, Usage: usage}
+	for _, a := range attempts {
+		if a.Challenge == challenge && strings.EqualFold(a.Lang, lang) && a.Number >= attempt.Number {
+			attempt.Number = a.Number + 1
+		}
+	}
+
+	return attempt, saveAttempts(append(attempts, attempt))
+}
+
+// recordAttemptVerdict attaches an eval result to the most recent attempt
+// whose code matches the evaluated solution. Solutions that were not
+// generated by aocgen have no attempt and are ignored.
+func recordAttemptVerdict(challenge, lang, code string, result EvalResult) error {
+	attempts, err := loadAttempts()
+	if err != nil {
+		return err
+	}
+	for i := len(attempts) - 1; i >= 0; i-- {
+		a := attempts[i]
+		if a.Challenge == challenge && strings.EqualFold(a.Lang, lang) && a.Code == code {
+			attempts[i].Verdict = result.Verdict
+			attempts[i].Duration = result.Duration
+			return saveAttempts(attempts)
+		}
+	}
+	return nil
+}
+
+// filterAttempts returns the attempts for the day and year in flags,
+// narrowed down by part and language when those are given.
+func filterAttempts(attempts []Attempt, flags Flags) []Attempt {
+	var matching []Attempt
+	for _, a := range attempts {
+		day, part, year, ok := parseChallengeName(a.Challenge)
+		if !ok || day != flags.Day || year != flags.Year {
+			continue
+		}
+		if flags.Part != 0 && part != flags.Part {
+			continue
+		}
+		if flags.Lang != "" && !strings.EqualFold(a.Lang, flags.Lang) {
+			continue
+		}
+		matching = append(matching, a)
+	}
+	return matching
+}
+
+func runAttemptsCommand(flags Flags, w io.Writer) error {
+	if flags.Day == 0 || flags.Year == 0 {
+		return fmt.Errorf("day and year are required")
+	}
+
+	attempts, err := loadAttempts()
+	if err != nil {
+		return err
+	}
+	attempts = filterAttempts(attempts, flags)
+
+	if flags.Attempt > 0 {
+		for _, a := range attempts {
+			if a.Number == flags.Attempt {
+				fmt.Fprintln(w, a.Code)
+				return nil
+			}
+		}
+		return fmt.Errorf("attempt %d not found", flags.Attempt)
+	}
+
+	if len(attempts) == 0 {
+		fmt.Fprintf(w, "No attempts found for day %d of %d.\n", flags.Day, flags.Year)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-18s  %-12s  %3s  %-30s  %-16s  %6s  %s\n", "Challenge", "Language", "#", "Model", "Generated", "Lines", "Verdict")
+	for _, a := range attempts {
+		verdict := string(a.Verdict)
+		if verdict == "" {
+			verdict = "-"
+		}
+		lines := strings.Count(strings.TrimRight(a.Code, "\n"), "\n") + 1
+		fmt.Fprintf(w, "%-18s  %-12s  %3d  %-30s  %-16s  %6d  %s\n", a.Challenge, a.Lang, a.Number, a.Model, a.Time.Format("2006-01-02 15:04"), lines, verdict)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAttempts(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	challenge := Challenge{Name: "day3_part1_2023", Task: "test task"}
+	for i := 0; i < 2; i++ {
+		if err := generateSolutionFile(context.Background(), challenge, Flags{Lang: "python", Model: "test"}); err != nil {
+			t.Fatalf("Failed to generate solution file: %v", err)
+		}
+	}
+	if _, err := recordAttempt("day3_part2_2023", "go", "gpt-4o", "package main", Usage{}); err != nil {
+		t.Fatalf("Failed to record attempt: %v", err)
+	}
+	if _, err := recordAttempt("day4_part1_2023", "go", "gpt-4o", "package main", Usage{}); err != nil {
+		t.Fatalf("Failed to record attempt: %v", err)
+	}
+
+	attempts, err := loadAttempts()
+	if err != nil {
+		t.Fatalf("Failed to load attempts: %v", err)
+	}
+	if len(attempts) != 4 || attempts[0].Number != 1 || attempts[1].Number != 2 || attempts[2].Number != 1 {
+		t.Fatalf("Unexpected attempt numbering: %+v", attempts)
+	}
+	if attempts[1].Model != "test" || attempts[1].Code == "" {
+		t.Errorf("Expected the attempt to store model and code, got %+v", attempts[1])
+	}
+
+	code, _ := os.ReadFile("day3_part1_2023.py")
+	if err := recordAttemptVerdict("day3_part1_2023", "python", string(code), EvalResult{Verdict: VerdictCorrect}); err != nil {
+		t.Fatalf("Failed to record verdict: %v", err)
+	}
+	attempts, _ = loadAttempts()
+	if attempts[0].Verdict != "" || attempts[1].Verdict != VerdictCorrect {
+		t.Errorf("Expected the verdict on the latest matching attempt, got %q and %q", attempts[0].Verdict, attempts[1].Verdict)
+	}
+
+	var out bytes.Buffer
+	if err := runAttemptsCommand(Flags{Day: 3, Year: 2023}, &out); err != nil {
+		t.Fatalf("Failed to list attempts: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a header and 3 attempts for day 3, got:\n%s", out.String())
+	}
+	if !strings.Contains(lines[2], "correct") || !strings.Contains(lines[3], "gpt-4o") {
+		t.Errorf("Unexpected listing:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runAttemptsCommand(Flags{Day: 3, Part: 2, Year: 2023, Attempt: 1}, &out); err != nil {
+		t.Fatalf("Failed to show attempt: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "package main" {
+		t.Errorf("Expected the attempt's code, got %q", out.String())
+	}
+}
@@ -0,0 +1,211 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupsDir = "backups"
+	// defaultBackups is the number of backups of challenges.json kept unless
+	// "backups" is set in the config file
+	defaultBackups = 5
+	// backupTimeFormat names backups so they sort by age
+	backupTimeFormat = "20060102-150405.000000000"
+)
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory that is renamed over path, so a crash leaves either the old or
+// the new contents, never a truncated file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// backupCount returns how many backups to keep.
+func backupCount() int {
+	cfg, err := loadConfig()
+	if err != nil || cfg.Backups <= 0 {
+		return defaultBackups
+	}
+	return cfg.Backups
+}
+
+// backupFile keeps the current contents of path, if it exists, under
+// dir/backups and deletes all but the newest keep backups of it. The backup
+// is a hard link where possible, which costs no space until path is
+// replaced.
+func backupFile(dir, path string, keep int) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	backups := filepath.Join(dir, backupsDir)
+	if err := os.MkdirAll(backups, 0755); err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	backup := filepath.Join(backups, fmt.Sprintf("%s-%s%s", base, time.Now().Format(backupTimeFormat), filepath.Ext(path)))
+	if err := os.Link(path, backup); err != nil {
+		if err := copyFile(path, backup); err != nil {
+			return err
+		}
+	}
+
+	names, err := listBackups(dir, path)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(names); i++ {
+		if err := os.Remove(filepath.Join(backups, names[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// listBackups returns the names of the backups of path in dir/backups,
+// newest first.
+func listBackups(dir, path string) ([]string, error) {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	matches, err := filepath.Glob(filepath.Join(dir, backupsDir, base+"-*"+filepath.Ext(path)))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(matches))
+	for i, match := range matches {
+		names[i] = filepath.Base(match)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// BackupEntry is the --json form of a backup in `aocgen restore list`.
+type BackupEntry struct {
+	Name       string    `json:"name"`
+	Time       time.Time `json:"time"`
+	Challenges int       `json:"challenges"`
+	Bytes      int64     `json:"bytes"`
+}
+
+func readBackup(dir, name string) ([]Challenge, error) {
+	data, err := os.ReadFile(filepath.Join(dir, backupsDir, filepath.Base(name)))
+	if err != nil {
+		return nil, err
+	}
+	var challenges []Challenge
+	if err := json.Unmarshal(data, &challenges); err != nil {
+		return nil, fmt.Errorf("backup %s is damaged: %v", name, err)
+	}
+	return challenges, nil
+}
+
+// runRestoreListCommand lists the backups of challenges.json, newest first.
+func runRestoreListCommand(flags Flags, w io.Writer) error {
+	dir := getCacheDir()
+	names, err := listBackups(dir, filepath.Join(dir, challengesFile))
+	if err != nil {
+		return err
+	}
+
+	entries := []BackupEntry{}
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(dir, backupsDir, name))
+		if err != nil {
+			return err
+		}
+		entry := BackupEntry{Name: name, Time: info.ModTime(), Bytes: info.Size(), Challenges: -1}
+		if challenges, err := readBackup(dir, name); err == nil {
+			entry.Challenges = len(challenges)
+		}
+		entries = append(entries, entry)
+	}
+
+	if flags.JSON {
+		return emitJSON(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No backups yet. challenges.json is backed up every time it is saved.")
+		return nil
+	}
+	for _, e := range entries {
+		count := "damaged"
+		if e.Challenges >= 0 {
+			count = fmt.Sprintf("%d challenges", e.Challenges)
+		}
+		fmt.Fprintf(w, "%s  %s  %s\n", e.Name, e.Time.Format("2006-01-02 15:04:05"), count)
+	}
+	return nil
+}
+
+// runRestoreCommand replaces challenges.json with a backup, by default the
+// newest one. The current challenges.json is backed up first, so a restore
+// can be undone.
+func runRestoreCommand(name string, w io.Writer) error {
+	if backend := storageBackend(); backend != "json" {
+		return fmt.Errorf("restore only supports the json storage backend, not %s", backend)
+	}
+	dir := getCacheDir()
+	if name == "" {
+		names, err := listBackups(dir, filepath.Join(dir, challengesFile))
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no backups in %s", filepath.Join(dir, backupsDir))
+		}
+		name = names[0]
+	}
+
+	challenges, err := readBackup(dir, name)
+	if err != nil {
+		return fmt.Errorf("error reading backup: %v", err)
+	}
+	if err := (jsonStore{dir: dir, file: challengesFile}).Save(challenges); err != nil {
+		return fmt.Errorf("error saving challenges: %v", err)
+	}
+	fmt.Fprintf(w, "Restored %d challenges from %s.\n", len(challenges), filepath.Base(name))
+	return nil
+}
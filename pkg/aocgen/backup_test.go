@@ -0,0 +1,107 @@
+package aocgen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Errorf("Expected the new contents, got %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %v", entries)
+	}
+}
+
+func TestJSONStoreBackups(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := saveConfig(Config{Backups: 3}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	store := jsonStore{dir: tempDir, file: challengesFile}
+	for i := 1; i <= 5; i++ {
+		challenges := make([]Challenge, i)
+		for j := range challenges {
+			challenges[j] = Challenge{Name: "day1_part1_2015", Answer: strings.Repeat("1", j+1)}
+		}
+		if err := store.Save(challenges); err != nil {
+			t.Fatalf("Failed to save challenges: %v", err)
+		}
+	}
+
+	names, err := listBackups(tempDir, filepath.Join(tempDir, challengesFile))
+	if err != nil {
+		t.Fatalf("Failed to list backups: %v", err)
+	}
+	if len(names) != 3 {
+		t.Fatalf("Expected 3 backups to be kept, got %v", names)
+	}
+	newest, err := readBackup(tempDir, names[0])
+	if err != nil || len(newest) != 4 {
+		t.Errorf("Expected the newest backup to hold the previous 4 challenges, got %d, %v", len(newest), err)
+	}
+	oldest, err := readBackup(tempDir, names[2])
+	if err != nil || len(oldest) != 2 {
+		t.Errorf("Expected the oldest backup to hold 2 challenges, got %d, %v", len(oldest), err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	if err := runRestoreCommand("", &out); err == nil {
+		t.Error("Expected an error without backups")
+	}
+
+	store := jsonStore{dir: tempDir, file: challengesFile}
+	store.Save([]Challenge{{Name: "day1_part1_2015", Answer: "42"}})
+	store.Save([]Challenge{})
+
+	out.Reset()
+	if err := runRestoreListCommand(Flags{}, &out); err != nil {
+		t.Fatalf("restore list failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "challenges-") || !strings.Contains(out.String(), "1 challenges") {
+		t.Errorf("Unexpected backup list:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runRestoreCommand("", &out); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	challenges, err := store.Load()
+	if err != nil || len(challenges) != 1 || challenges[0].Answer != "42" {
+		t.Errorf("Expected the backed up challenge to be restored, got %+v, %v", challenges, err)
+	}
+
+	// The state before the restore is backed up too, so it can be undone
+	if err := runRestoreCommand("", &out); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if challenges, _ := store.Load(); len(challenges) != 0 {
+		t.Errorf("Expected the restore to be undone, got %+v", challenges)
+	}
+
+	if err := runRestoreCommand("challenges-missing.json", &out); err == nil {
+		t.Error("Expected an error for an unknown backup")
+	}
+}
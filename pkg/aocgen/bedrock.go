@@ -0,0 +1,207 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// bedrockMaxTokens bounds the length of a completion requested from Bedrock,
+// which, unlike the chat completion APIs, requires an explicit limit.
+const bedrockMaxTokens = 4096
+
+// bedrockFamily returns the model family of a Bedrock model ID, ignoring any
+// cross-region inference profile prefix such as "us." or "eu.".
+func bedrockFamily(modelID string) string {
+	for _, family := range []string{"anthropic", "meta"} {
+		if strings.HasPrefix(modelID, family+".") || strings.Contains(modelID, "."+family+".") {
+			return family
+		}
+	}
+	return ""
+}
+
+// bedrockRequestBody builds the InvokeModel request body for modelID. Every
+// model family on Bedrock has its own native request format. Neither family
+// takes a seed.
+func bedrockRequestBody(modelID, system, prompt string, sampling Sampling) ([]byte, error) {
+	var body map[string]interface{}
+	maxTokensKey := "max_tokens"
+
+	switch bedrockFamily(modelID) {
+	case "anthropic":
+		body = map[string]interface{}{
+			"anthropic_version": "bedrock-2023-05-31",
+			"max_tokens":        bedrockMaxTokens,
+			"messages": []map[string]string{
+				{"role": "user", "content": prompt},
+			},
+		}
+		if system != "" {
+			body["system"] = system
+		}
+	case "meta":
+		body = map[string]interface{}{
+			"prompt":      llamaPrompt(modelID, system, prompt),
+			"max_gen_len": 2048,
+		}
+		maxTokensKey = "max_gen_len"
+	default:
+		return nil, fmt.Errorf("unsupported Bedrock model: %s", modelID)
+	}
+
+	if sampling.Temperature != nil {
+		body["temperature"] = *sampling.Temperature
+	}
+	if sampling.TopP != nil {
+		body["top_p"] = *sampling.TopP
+	}
+	if sampling.MaxTokens > 0 {
+		body[maxTokensKey] = sampling.MaxTokens
+	}
+	return json.Marshal(body)
+}
+
+// llamaPrompt wraps the system message, if any, and prompt in the chat
+// template the Llama model expects, since Bedrock passes Meta prompts to the
+// model verbatim.
+func llamaPrompt(modelID, system, prompt string) string {
+	if strings.Contains(modelID, "llama2") {
+		if system != "" {
+			prompt = "<<SYS>>\n" + system + "\n<</SYS>>\n\n" + prompt
+		}
+		return "<s>[INST] " + prompt + " [/INST]"
+	}
+	text := "<|begin_of_text|>"
+	if system != "" {
+		text += "<|start_header_id|>system<|end_header_id|>\n\n" + system + "<|eot_id|>"
+	}
+	return text + "<|start_header_id|>user<|end_header_id|>\n\n" + prompt +
+		"<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n"
+}
+
+// parseBedrockResponse extracts the completion and token usage from an
+// InvokeModel response body for modelID.
+func parseBedrockResponse(modelID string, body []byte) (string, Usage, error) {
+	switch bedrockFamily(modelID) {
+	case "anthropic":
+		var response struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+			Usage struct {
+				InputTokens  int `json:"input_tokens"`
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", Usage{}, fmt.Errorf("error unmarshaling response: %v", err)
+		}
+		var text strings.Builder
+		for _, block := range response.Content {
+			if block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		if text.Len() == 0 {
+			return "", Usage{}, fmt.Errorf("no content in response")
+		}
+		usage := Usage{PromptTokens: response.Usage.InputTokens, CompletionTokens: response.Usage.OutputTokens}
+		return text.String(), usage, nil
+	case "meta":
+		var response struct {
+			Generation           string `json:"generation"`
+			PromptTokenCount     int    `json:"prompt_token_count"`
+			GenerationTokenCount int    `json:"generation_token_count"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return "", Usage{}, fmt.Errorf("error unmarshaling response: %v", err)
+		}
+		if response.Generation == "" {
+			return "", Usage{}, fmt.Errorf("no content in response")
+		}
+		usage := Usage{PromptTokens: response.PromptTokenCount, CompletionTokens: response.GenerationTokenCount}
+		return response.Generation, usage, nil
+	default:
+		return "", Usage{}, fmt.Errorf("unsupported Bedrock model: %s", modelID)
+	}
+}
+
+// callBedrockAPI invokes modelID through the Bedrock InvokeModel API. The
+// request is signed with SigV4 using the default AWS credential chain
+// (environment, shared config files, SSO, instance roles), which also supplies
+// the region. apiURL overrides the regional bedrock-runtime endpoint when set.
+func callBedrockAPI(ctx context.Context, apiURL, modelID, system, prompt string, sampling Sampling) (string, Usage, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("error loading AWS config: %v", err)
+	}
+	if cfg.Region == "" {
+		return "", Usage{}, fmt.Errorf("AWS region is not configured (set AWS_REGION)")
+	}
+
+	if sampling.Seed != nil {
+		logger.Warn("Bedrock models do not support --seed, ignoring it")
+	}
+	requestBody, err := bedrockRequestBody(modelID, system, prompt, sampling)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	if apiURL == "" {
+		apiURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", cfg.Region)
+	}
+	endpoint := strings.TrimRight(apiURL, "/") + "/model/" + url.PathEscape(modelID) + "/invoke"
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("error retrieving AWS credentials: %v", err)
+	}
+	payloadHash := sha256.Sum256(requestBody)
+	err = v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), "bedrock", cfg.Region, time.Now())
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("error signing request: %v", err)
+	}
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err != nil || errorResponse.Message == "" {
+			return "", Usage{}, fmt.Errorf("API error: %s", resp.Status)
+		}
+		return "", Usage{}, fmt.Errorf("API error: %s (%s)", errorResponse.Message, resp.Status)
+	}
+
+	return parseBedrockResponse(modelID, body)
+}
@@ -0,0 +1,118 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupBedrockCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "us-west-2")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+}
+
+func TestGenerateCodeWithAIBedrock(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	setupBedrockCredentials(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") {
+			t.Errorf("Expected a SigV4 Authorization header, got: %q", auth)
+		}
+
+		var requestBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+
+		switch r.URL.Path {
+		case "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke":
+			if requestBody["anthropic_version"] != "bedrock-2023-05-31" {
+				t.Errorf("Unexpected anthropic_version: %v", requestBody["anthropic_version"])
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"content": []map[string]string{{"type": "text", "text": "```go\npackage main\n```"}},
+				"usage":   map[string]int{"input_tokens": 10, "output_tokens": 5},
+			})
+		case "/model/meta.llama3-8b-instruct-v1:0/invoke":
+			prompt, _ := requestBody["prompt"].(string)
+			if !strings.Contains(prompt, "<|start_header_id|>user<|end_header_id|>") || !strings.Contains(prompt, "test task") {
+				t.Errorf("Expected a Llama 3 chat prompt, got: %q", prompt)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"generation":             "```go\npackage main\n```",
+				"prompt_token_count":     10,
+				"generation_token_count": 5,
+			})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"The provided model identifier is invalid."}`))
+		}
+	}))
+	defer server.Close()
+
+	for _, model := range []string{"bedrock/anthropic.claude-3-haiku-20240307-v1:0", "bedrock/meta.llama3-8b-instruct-v1:0"} {
+		t.Run(model, func(t *testing.T) {
+			code, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: model, ModelAPI: server.URL})
+			if err != nil {
+				t.Fatalf("Failed to generate code with AI: %v", err)
+			}
+			if code != "package main" {
+				t.Errorf("Unexpected code: %q", code)
+			}
+		})
+	}
+
+	_, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "bedrock/anthropic.claude-unknown", ModelAPI: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "model identifier is invalid") {
+		t.Errorf("Expected the API error message, got: %v", err)
+	}
+
+	_, err = generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "bedrock/cohere.command-r-v1:0", ModelAPI: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "unsupported Bedrock model") {
+		t.Errorf("Expected an unsupported model error, got: %v", err)
+	}
+}
+
+func TestBedrockFamily(t *testing.T) {
+	tests := map[string]string{
+		"anthropic.claude-3-5-sonnet-20240620-v1:0":    "anthropic",
+		"us.anthropic.claude-3-5-sonnet-20240620-v1:0": "anthropic",
+		"meta.llama3-70b-instruct-v1:0":                "meta",
+		"eu.meta.llama3-2-3b-instruct-v1:0":            "meta",
+		"amazon.titan-text-express-v1":                 "",
+	}
+	for modelID, expected := range tests {
+		if family := bedrockFamily(modelID); family != expected {
+			t.Errorf("bedrockFamily(%q) = %q, expected %q", modelID, family, expected)
+		}
+	}
+}
+
+func TestLlamaPromptSystem(t *testing.T) {
+	prompt := llamaPrompt("meta.llama3-8b-instruct-v1:0", "Be brief.", "Solve it.")
+	if !strings.HasPrefix(prompt, "<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|><|start_header_id|>user") {
+		t.Errorf("Expected a Llama 3 system header, got %q", prompt)
+	}
+
+	prompt = llamaPrompt("meta.llama2-13b-chat-v1", "Be brief.", "Solve it.")
+	if prompt != "<s>[INST] <<SYS>>\nBe brief.\n<</SYS>>\n\nSolve it. [/INST]" {
+		t.Errorf("Expected a Llama 2 system block, got %q", prompt)
+	}
+
+	body, _ := bedrockRequestBody("anthropic.claude-3-haiku-20240307-v1:0", "Be brief.", "Solve it.", Sampling{})
+	var request map[string]interface{}
+	json.Unmarshal(body, &request)
+	if request["system"] != "Be brief." {
+		t.Errorf("Expected the system prompt in the Anthropic request, got %s", body)
+	}
+}
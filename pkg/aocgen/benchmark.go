@@ -0,0 +1,297 @@
+package aocgen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultCompletionTokens is the expected length of a solution in tokens
+// when there are no earlier attempts to estimate it from.
+const defaultCompletionTokens = 600
+
+// CostEstimate is the --json form of `aocgen benchmark --dry-run`.
+type CostEstimate struct {
+	Model            string `json:"model"`
+	Lang             string `json:"lang"`
+	Challenges       int    `json:"challenges"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	// CompletionBasis tells where the completion tokens per challenge come
+	// from: the model's earlier attempts, any model's or a default
+	CompletionBasis string `json:"completion_basis"`
+	// Price and Cost are omitted when the price of the model is unknown
+	Price *ModelPrice `json:"price,omitempty"`
+	Cost  *float64    `json:"cost_usd,omitempty"`
+}
+
+// selectBenchmarkChallenges returns the stored challenges a benchmark
+// generates solutions for: those with a known answer, limited to --year,
+// --day and --part when they are given, in year, day and part order.
+func selectBenchmarkChallenges(flags Flags) ([]Challenge, error) {
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading challenges: %v", err)
+	}
+	var selected []Challenge
+	for _, c := range challenges {
+		day, part, year, ok := parseChallengeName(c.Name)
+		if !ok || strings.TrimSpace(c.Answer) == "" {
+			continue
+		}
+		if (flags.Year != 0 && year != flags.Year) || (flags.Day != 0 && day != flags.Day) || (flags.Part != 0 && part != flags.Part) {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return challengeLess(selected[i].Name, selected[j].Name) })
+	return selected, nil
+}
+
+// expectedCompletionTokens estimates the length of a solution from the
+// earlier attempts in flags.Lang, preferring those of flags.Model.
+func expectedCompletionTokens(flags Flags) (int, string) {
+	attempts, err := loadAttempts()
+	if err != nil {
+		return defaultCompletionTokens, "default"
+	}
+	var own, all, ownCount, allCount int
+	for _, a := range attempts {
+		if !strings.EqualFold(a.Lang, flags.Lang) || a.Usage.CompletionTokens == 0 {
+			continue
+		}
+		all += a.Usage.CompletionTokens
+		allCount++
+		if a.Model == flags.Model {
+			own += a.Usage.CompletionTokens
+			ownCount++
+		}
+	}
+	switch {
+	case ownCount > 0:
+		return own / ownCount, fmt.Sprintf("average of %d earlier attempts by %s", ownCount, flags.Model)
+	case allCount > 0:
+		return all / allCount, fmt.Sprintf("average of %d earlier attempts in %s", allCount, flags.Lang)
+	}
+	return defaultCompletionTokens, "default"
+}
+
+// estimateBenchmarkCost approximates the tokens of the prompts a benchmark of
+// challenges would send and prices them with the model's price.
+func estimateBenchmarkCost(challenges []Challenge, flags Flags, prices map[string]ModelPrice) (CostEstimate, error) {
+	estimate := CostEstimate{Model: flags.Model, Lang: flags.Lang, Challenges: len(challenges)}
+	system, err := loadSystemPrompt(flags.SystemPrompt)
+	if err != nil {
+		return estimate, err
+	}
+	for _, c := range challenges {
+		prompt, err := buildPrompt(c, flags)
+		if err != nil {
+			return estimate, err
+		}
+		estimate.PromptTokens += countTokens(system) + countTokens(prompt)
+	}
+	perChallenge, basis := expectedCompletionTokens(flags)
+	estimate.CompletionTokens = perChallenge * len(challenges)
+	estimate.CompletionBasis = basis
+
+	if price, ok := priceForModel(flags.Model, prices); ok {
+		cost := estimateCost(price, estimate.PromptTokens, estimate.CompletionTokens)
+		estimate.Price, estimate.Cost = &price, &cost
+	}
+	return estimate, nil
+}
+
+func printCostEstimate(w io.Writer, e CostEstimate) {
+	fmt.Fprintf(w, "Benchmark of %d challenges with %s in %s (dry run, nothing was sent)\n", e.Challenges, e.Model, e.Lang)
+	if e.Challenges == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Prompt tokens:     %10d (approximate, about %d per challenge)\n", e.PromptTokens, e.PromptTokens/e.Challenges)
+	fmt.Fprintf(w, "Completion tokens: %10d (%d per challenge, %s)\n", e.CompletionTokens, e.CompletionTokens/e.Challenges, e.CompletionBasis)
+	if e.Cost == nil {
+		fmt.Fprintf(w, "Estimated cost:    unknown, add the price of %s under \"prices\" in the config file\n", e.Model)
+		return
+	}
+	fmt.Fprintf(w, "Estimated cost:    $%.2f at $%.2f/$%.2f per million prompt/completion tokens\n", *e.Cost, e.Price.Prompt, e.Price.Completion)
+}
+
+// runBenchmarkCommand generates a solution of every selected challenge with
+// --model in --lang and evaluates it, recording the verdicts in a run that
+// `benchmark compare` and `benchmark report` read. With --dry-run it only
+// estimates what the run would cost.
+func runBenchmarkCommand(flags Flags, w io.Writer) error {
+	var run *BenchmarkRun
+	if flags.Resume != "" {
+		var err error
+		if run, err = loadBenchmarkRun(flags.Resume); err != nil {
+			return err
+		}
+		if run.Model == "" {
+			return fmt.Errorf("run %s is a perf run, resume it with 'aocgen perf --resume %s'", run.ID, run.ID)
+		}
+		flags.Lang, flags.Model = run.Lang, run.Model
+		if flags.Timeout == 0 {
+			flags.Timeout = run.TimeoutMs
+		}
+	}
+	if flags.Lang == "" || flags.Model == "" {
+		return fmt.Errorf("--lang and --model are required")
+	}
+
+	challenges, err := selectBenchmarkChallenges(flags)
+	if err != nil {
+		return err
+	}
+
+	if flags.DryRun {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("error loading config: %v", err)
+		}
+		estimate, err := estimateBenchmarkCost(challenges, flags, cfg.Prices)
+		if err != nil {
+			return err
+		}
+		if flags.JSON {
+			return emitJSON(estimate)
+		}
+		printCostEstimate(w, estimate)
+		return nil
+	}
+
+	if run == nil {
+		run = newBenchmarkRun(flags.Lang, flags.Timeout)
+		run.Model = flags.Model
+		run.Workdir, run.Workspace = absPath(flags.Workdir), flags.Workspace
+		if run.Workdir == "" {
+			run.Workdir = filepath.Join(defaultWorkdir(), run.ID)
+		}
+	}
+	// Every challenge gets its own directory, so no solution or input.txt of
+	// the user is overwritten
+	flags.Workdir, flags.Workspace = run.Workdir, run.Workspace
+	if err := ensureOllamaModel(commandContext, flags); err != nil {
+		return err
+	}
+	if flags.MetricsAddr != "" {
+		stop, err := serveMetrics(flags.MetricsAddr, w)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+	done := run.completed()
+	fmt.Fprintf(w, "Run ID: %s (continue an interrupted run with --resume %s)\n", run.ID, run.ID)
+
+	var pending []Challenge
+	for _, c := range challenges {
+		if _, ok := done[c.Name]; !ok {
+			pending = append(pending, c)
+		}
+	}
+	// Streams of parallel requests would interleave on stderr
+	if flags.Workers > 1 {
+		flags.Stream = false
+	}
+
+	// The model requests and the evaluations run in parallel, each challenge
+	// in its own directory, while the attempts log is written one at a time
+	var mu sync.Mutex
+	var started atomic.Int32
+	err = runWorkers(commandContext, len(pending), flags.Workers, func(i int, _ string) {
+		c := pending[i]
+		day, part, year, _ := parseChallengeName(c.Name)
+		inner := flags
+		inner.Day, inner.Part, inner.Year = day, part, year
+
+		logger.Info(fmt.Sprintf("[%d/%d] %s", int(started.Add(1))+len(challenges)-len(pending), len(challenges), c.Name))
+		transcript, genErr := generateCode(commandContext, c, inner)
+		// An interrupted challenge is left out of the run so a resumed run
+		// generates it again
+		if commandContext.Err() != nil {
+			return
+		}
+
+		result := RunResult{Challenge: c.Name}
+		mu.Lock()
+		err := writeBenchmarkSolution(c.Name, inner, transcript, genErr)
+		mu.Unlock()
+		if err != nil {
+			result.Error = err.Error()
+			if genErr != nil {
+				result.Failure = generationFailure(transcript)
+			}
+		} else {
+			// The verdict is kept in the run and the attempts log, not in the
+			// stored challenges
+			_, eval, code, err := judgeChallengeSolution(commandContext, inner, nil)
+			var missing *MissingToolchainError
+			switch {
+			case errors.As(err, &missing):
+				result.Verdict, result.Error = VerdictMissingToolchain, err.Error()
+			case err != nil:
+				result.Error = err.Error()
+			default:
+				result.Verdict, result.Duration, result.Output = eval.Verdict, eval.Duration, tailLines(eval.Output, runOutputLines)
+				// Without the answer the solution was not checked, and
+				// without its code there is no attempt to record it for
+				if eval.Verdict == VerdictUnknownAnswer || code == "" {
+					break
+				}
+				mu.Lock()
+				err := recordAttemptVerdict(c.Name, inner.Lang, code, eval)
+				mu.Unlock()
+				if err != nil {
+					logger.Warn("failed to record attempt verdict", "err", err)
+				}
+			}
+		}
+		if commandContext.Err() != nil {
+			return
+		}
+		if err := run.record(result); err != nil {
+			logger.Warn("failed to save checkpoint", "run", run.ID, "err", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if commandContext.Err() != nil {
+		fmt.Fprintf(w, "\nInterrupted: %d of %d challenges are saved in run %s.\n", len(run.completed()), len(challenges), run.ID)
+		fmt.Fprintf(w, "Continue with: aocgen benchmark --resume %s\n", run.ID)
+		return errInterrupted
+	}
+
+	summary := summarizeRun(run)
+	if flags.JSON {
+		return emitJSON(summary)
+	}
+	fmt.Fprintf(w, "%s in %s: %s\n", flags.Model, flags.Lang, describeRun(summary))
+	printFailures(w, summary.Failures)
+	fmt.Fprintf(w, "See the details with: aocgen benchmark report %s > report.html\n", run.ID)
+	return nil
+}
+
+// writeBenchmarkSolution writes the solution a benchmark generated to the
+// workdir of the run, or reports why it could not be generated, the way
+// `aocgen generate` would. The stored challenges are left as they are.
+func writeBenchmarkSolution(name string, flags Flags, transcript Transcript, genErr error) error {
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+	challenge := lookupChallenge(challenges, name)
+	if challenge == nil {
+		return fmt.Errorf("challenge not found: %s", name)
+	}
+	_, err = writeSolutionFiles(*challenge, flags, func() (Transcript, error) {
+		return transcript, genErr
+	})
+	return err
+}
@@ -0,0 +1,135 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunBenchmarkCommandDryRun(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", Task: "Sum the numbers.", Input: "1\n2\n", Answer: "3"},
+		{Name: "day2_part1_2015", Task: "Multiply the numbers.", Input: "2\n3\n", Answer: "6"},
+		{Name: "day1_part1_2016", Task: "Count the lines.", Input: "a\nb\n", Answer: "2"},
+		{Name: "day2_part1_2016", Task: "Not answered yet.", Input: "x\n"},
+	})
+	saveConfig(Config{Prices: map[string]ModelPrice{"my-model": {Prompt: 1000, Completion: 2000}}})
+	recordAttempt("day1_part1_2015", "python", "my-model", "print(3)\n", Usage{PromptTokens: 100, CompletionTokens: 300})
+	recordAttempt("day1_part1_2016", "python", "my-model", "print(2)\n", Usage{PromptTokens: 100, CompletionTokens: 100})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("A dry run must not send requests")
+	}))
+	defer server.Close()
+
+	flags := Flags{Year: 2015, Lang: "python", Model: "my-model", ModelAPI: server.URL, DryRun: true}
+	var estimate CostEstimate
+	out := captureJSON(t, func() error {
+		flags.JSON = true
+		return runBenchmarkCommand(flags, &bytes.Buffer{})
+	})
+	if err := json.Unmarshal(out, &estimate); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if estimate.Challenges != 2 || estimate.CompletionTokens != 400 || !strings.Contains(estimate.CompletionBasis, "2 earlier attempts by my-model") {
+		t.Errorf("Unexpected estimate: %+v", estimate)
+	}
+	prompt, _ := buildPrompt(Challenge{Name: "day1_part1_2015", Task: "Sum the numbers.", Input: "1\n2\n"}, flags)
+	if estimate.PromptTokens <= countTokens(prompt) {
+		t.Errorf("Expected the prompt tokens of both challenges, got %d", estimate.PromptTokens)
+	}
+	if want := estimateCost(ModelPrice{Prompt: 1000, Completion: 2000}, estimate.PromptTokens, 400); estimate.Cost == nil || *estimate.Cost != want {
+		t.Errorf("Expected a cost of %v, got %v", want, estimate.Cost)
+	}
+
+	var text bytes.Buffer
+	flags.JSON = false
+	flags.Model = "unpriced-model"
+	if err := runBenchmarkCommand(flags, &text); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	for _, want := range []string{"Benchmark of 2 challenges with unpriced-model in python", "400 (200 per challenge, average of 2 earlier attempts in python)", "Estimated cost:    unknown"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, text.String())
+		}
+	}
+}
+
+func TestRunBenchmarkCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	stored := []Challenge{
+		{Name: "day1_part1_2015", Task: "Sum the numbers.", Input: "1\n2\n", Answer: "3", Solution: "package main", SolutionLang: "go"},
+		{Name: "day2_part1_2015", Task: "Multiply the numbers.", Input: "2\n3\n", Answer: "6"},
+	}
+	saveChallenges(stored)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(sum(int(l) for l in open('input.txt')))\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	flags := Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL, NoFormat: true, Timeout: 5000, Workers: 2}
+	if err := runBenchmarkCommand(flags, &out); err != nil {
+		t.Fatalf("benchmark failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "gpt-4o-mini in python: 1/2 passed (50.0%)\nFailures: 1 wrong answer\n") {
+		t.Errorf("Unexpected summary:\n%s", out.String())
+	}
+
+	runs, _ := os.ReadDir(tempDir + "/" + runsDir)
+	if len(runs) != 1 {
+		t.Fatalf("Expected one run, got %d", len(runs))
+	}
+	run, err := loadBenchmarkRun(strings.TrimSuffix(runs[0].Name(), ".json"))
+	if err != nil {
+		t.Fatalf("Failed to load run: %v", err)
+	}
+	// The workers may finish in any order
+	done := run.completed()
+	if run.Model != "gpt-4o-mini" || len(run.Results) != 2 || done["day1_part1_2015"].Verdict != VerdictCorrect || done["day2_part1_2015"].Verdict != VerdictWrongAnswer {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, workDir, run.ID, "day2_part1_2015", "day2_part1_2015.py")); err != nil {
+		t.Errorf("Expected the solutions in the run's working directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "day2_part1_2015.py")); !os.IsNotExist(err) {
+		t.Errorf("Expected no solution in the current directory")
+	}
+	if challenges, _ := loadChallenges(tempDir, challengesFile); !reflect.DeepEqual(challenges, stored) {
+		t.Errorf("Expected the benchmark to leave the stored challenges alone, got %+v", challenges)
+	}
+	if attempts, _ := loadAttempts(); len(attempts) != 2 || attempts[0].Verdict == "" || attempts[1].Verdict == "" {
+		t.Errorf("Expected the verdicts in the attempts log, got %+v", attempts)
+	}
+	if outcome := runOutcome(run, done["day2_part1_2015"]); outcome != "wrong answer" {
+		t.Errorf("Expected the verdict as the outcome, got %q", outcome)
+	}
+
+	flags.Resume = run.ID
+	if err := runPerformanceBenchmark(flags); err == nil || !strings.Contains(err.Error(), "is a benchmark run") {
+		t.Errorf("Expected perf to refuse a benchmark run, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cacheTarget is a group of files aocgen keeps in the cache directory.
+type cacheTarget struct {
+	Name        string
+	Description string
+	// Paths are glob patterns relative to the cache directory
+	Paths []string
+	// Cleanable targets can be recreated and may be deleted with `cache
+	// clean`; the others hold your own data.
+	Cleanable bool
+}
+
+// cacheTargets lists everything aocgen stores in the cache directory.
+var cacheTargets = []cacheTarget{
+	{Name: "challenges", Description: "Puzzles, inputs and solutions", Paths: []string{challengesFile, challengesDB}},
+	{Name: "config", Description: "Configuration and prompt templates", Paths: []string{configFile, "templates"}},
+	{Name: "attempts", Description: "Generated solution attempts", Paths: []string{attemptsFile}},
+	{Name: "transcripts", Description: "Model request transcripts", Paths: []string{transcriptsDir}},
+	{Name: "evals", Description: "Evaluation history", Paths: []string{evalLogFile}},
+	{Name: "usage", Description: "Model token usage", Paths: []string{usageFile}},
+	{Name: "times", Description: "Personal solve times", Paths: []string{personalTimesFile}},
+	{Name: "dataset", Description: "Downloaded dataset shards", Paths: []string{datasetParquet, datasetParquet + ".part", datasetShardsDir, datasetMetaFile}, Cleanable: true},
+	{Name: "runs", Description: "Performance benchmark runs", Paths: []string{runsDir}, Cleanable: true},
+	{Name: "work", Description: "Working directories of generate, eval and benchmark runs", Paths: []string{workDir}, Cleanable: true},
+	{Name: "leaderboards", Description: "Cached private leaderboards", Paths: []string{leaderboardsDir}, Cleanable: true},
+	{Name: "responses", Description: "Cached model responses", Paths: []string{responsesDir}, Cleanable: true},
+	{Name: "embeddings", Description: "Embeddings of tasks for --similar", Paths: []string{embeddingsFile}, Cleanable: true},
+	{Name: "backups", Description: "Backups of challenges.json", Paths: []string{backupsDir}, Cleanable: true},
+}
+
+// CacheEntry is the --json form of a target in `aocgen cache info`.
+type CacheEntry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Paths       []string `json:"paths"`
+	Files       int      `json:"files"`
+	Bytes       int64    `json:"bytes"`
+	Cleanable   bool     `json:"cleanable"`
+}
+
+// CacheInfo is the --json form of `aocgen cache info`.
+type CacheInfo struct {
+	Dir     string       `json:"dir"`
+	Entries []CacheEntry `json:"entries"`
+	Bytes   int64        `json:"bytes"`
+}
+
+// existingPaths returns the paths of target that exist in dir.
+func (target cacheTarget) existingPaths(dir string) ([]string, error) {
+	var paths []string
+	for _, pattern := range target.Paths {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// diskUsage returns how many files there are under path and their size.
+func diskUsage(path string) (files int, bytes int64, err error) {
+	err = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		bytes += info.Size()
+		return nil
+	})
+	return files, bytes, err
+}
+
+// cacheInfo measures every cache target.
+func cacheInfo() (CacheInfo, error) {
+	info := CacheInfo{Dir: getCacheDir(), Entries: []CacheEntry{}}
+	for _, target := range cacheTargets {
+		entry := CacheEntry{Name: target.Name, Description: target.Description, Paths: []string{}, Cleanable: target.Cleanable}
+		paths, err := target.existingPaths(info.Dir)
+		if err != nil {
+			return CacheInfo{}, err
+		}
+		for _, path := range paths {
+			files, bytes, err := diskUsage(path)
+			if err != nil {
+				return CacheInfo{}, fmt.Errorf("error reading %s: %v", path, err)
+			}
+			entry.Paths = append(entry.Paths, path)
+			entry.Files += files
+			entry.Bytes += bytes
+		}
+		info.Entries = append(info.Entries, entry)
+		info.Bytes += entry.Bytes
+	}
+	return info, nil
+}
+
+// runCacheInfoCommand shows the size of everything in the cache directory.
+func runCacheInfoCommand(flags Flags, w io.Writer) error {
+	info, err := cacheInfo()
+	if err != nil {
+		return err
+	}
+	if flags.JSON {
+		return emitJSON(info)
+	}
+
+	fmt.Fprintf(w, "Cache directory: %s\n\n", info.Dir)
+	fmt.Fprintf(w, "%-12s  %10s  %6s  %s\n", "Name", "Size", "Files", "Description")
+	for _, entry := range info.Entries {
+		size := "-"
+		if entry.Files > 0 {
+			size = formatBytes(entry.Bytes)
+		}
+		description := entry.Description
+		if entry.Cleanable {
+			description += " (cleanable)"
+		}
+		fmt.Fprintf(w, "%-12s  %10s  %6d  %s\n", entry.Name, size, entry.Files, description)
+	}
+	fmt.Fprintf(w, "%-12s  %10s\n", "Total", formatBytes(info.Bytes))
+	return nil
+}
+
+// runCacheCleanCommand deletes the named cache targets. Only cleanable
+// targets can be deleted, so your challenges, attempts and configuration
+// are never removed.
+// splitCacheTargets splits the arguments of cache clean into the targets
+// named before the first flag and the rest, which may name more targets after
+// the flags.
+func splitCacheTargets(args []string) (targets, rest []string) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return args[:i:i], args[i:]
+		}
+	}
+	return args, nil
+}
+
+func runCacheCleanCommand(names []string, w io.Writer) error {
+	if len(names) == 0 {
+		return fmt.Errorf("expected one or more of: %s", strings.Join(cleanableTargets(), ", "))
+	}
+
+	var targets []cacheTarget
+	for _, name := range names {
+		target, ok := findCacheTarget(name)
+		if !ok {
+			return fmt.Errorf("unknown cache target %q, expected one of: %s", name, strings.Join(cleanableTargets(), ", "))
+		}
+		if !target.Cleanable {
+			return fmt.Errorf("%s holds your own data and cannot be cleaned", name)
+		}
+		targets = append(targets, target)
+	}
+
+	for _, target := range targets {
+		paths, err := target.existingPaths(getCacheDir())
+		if err != nil {
+			return err
+		}
+		var freed int64
+		for _, path := range paths {
+			_, bytes, err := diskUsage(path)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %v", path, err)
+			}
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("error removing %s: %v", path, err)
+			}
+			freed += bytes
+		}
+		fmt.Fprintf(w, "Removed %s (%s).\n", target.Name, formatBytes(freed))
+	}
+	return nil
+}
+
+func findCacheTarget(name string) (cacheTarget, bool) {
+	for _, target := range cacheTargets {
+		if target.Name == name {
+			return target, true
+		}
+	}
+	return cacheTarget{}, false
+}
+
+func cleanableTargets() []string {
+	var names []string
+	for _, target := range cacheTargets {
+		if target.Cleanable {
+			names = append(names, target.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,107 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCacheInfoAndClean(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	os.MkdirAll(filepath.Join(tempDir, datasetShardsDir), 0755)
+	os.WriteFile(filepath.Join(tempDir, datasetShardsDir, "0000.parquet"), make([]byte, 2048), 0644)
+	os.WriteFile(filepath.Join(tempDir, datasetShardsDir, "0001.parquet.part"), make([]byte, 100), 0644)
+	os.WriteFile(filepath.Join(tempDir, datasetMetaFile), []byte("{}"), 0644)
+	os.MkdirAll(filepath.Join(tempDir, runsDir), 0755)
+	os.WriteFile(filepath.Join(tempDir, runsDir, "run.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tempDir, attemptsFile), []byte("[]"), 0644)
+
+	var info CacheInfo
+	if err := json.Unmarshal(captureJSON(t, func() error { return runCacheInfoCommand(Flags{JSON: true}, nil) }), &info); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	entries := make(map[string]CacheEntry)
+	for _, entry := range info.Entries {
+		entries[entry.Name] = entry
+	}
+	if dataset := entries["dataset"]; dataset.Files != 3 || dataset.Bytes != 2150 || !dataset.Cleanable {
+		t.Errorf("Unexpected dataset entry: %+v", dataset)
+	}
+	if entries["attempts"].Files != 1 || entries["attempts"].Cleanable {
+		t.Errorf("Unexpected attempts entry: %+v", entries["attempts"])
+	}
+	if info.Dir != tempDir || info.Bytes < 2150 {
+		t.Errorf("Unexpected cache info: %+v", info)
+	}
+
+	var out bytes.Buffer
+	if err := runCacheInfoCommand(Flags{}, &out); err != nil {
+		t.Fatalf("Failed to show cache info: %v", err)
+	}
+	if !strings.Contains(out.String(), "Cache directory: "+tempDir) || !strings.Contains(out.String(), "2.1 KiB") {
+		t.Errorf("Unexpected cache info:\n%s", out.String())
+	}
+
+	if err := runCacheCleanCommand([]string{"attempts"}, &out); err == nil {
+		t.Errorf("Expected attempts not to be cleanable")
+	}
+	if err := runCacheCleanCommand([]string{"bogus"}, &out); err == nil || !strings.Contains(err.Error(), "dataset, runs, work, leaderboards") {
+		t.Errorf("Expected the cleanable targets to be listed, got %v", err)
+	}
+
+	out.Reset()
+	if err := runCacheCleanCommand([]string{"dataset", "runs"}, &out); err != nil {
+		t.Fatalf("Failed to clean cache: %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed dataset (2.1 KiB).") {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+	for _, path := range []string{datasetShardsDir, datasetMetaFile, runsDir} {
+		if _, err := os.Stat(filepath.Join(tempDir, path)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, attemptsFile)); err != nil {
+		t.Errorf("Expected attempts to be kept, got %v", err)
+	}
+}
+
+func TestCacheDirOverride(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if getCacheDir() != tempDir {
+		t.Errorf("Expected %s from %s to be the cache directory, got %s", tempDir, cacheDirEnv, getCacheDir())
+	}
+
+	t.Setenv("ADVENT_OF_CODE_SESSION", "")
+	other := t.TempDir()
+	os.WriteFile(filepath.Join(other, configFile), []byte(`{"session": "from-other"}`), 0644)
+	flags, err := parseCommandFlags([]string{"--cache-dir", other})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if getCacheDir() != other {
+		t.Errorf("Expected --cache-dir to take precedence, got %s", getCacheDir())
+	}
+	if flags.Session != "from-other" {
+		t.Errorf("Expected the config of --cache-dir to be loaded, got session %q", flags.Session)
+	}
+}
+
+func TestSplitCacheTargets(t *testing.T) {
+	targets, rest := splitCacheTargets([]string{"work", "--cache-dir", "/x", "logs"})
+	flags, err := parseFlags(rest)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	targets = append(targets, flags.Args...)
+	if strings.Join(targets, " ") != "work logs" || flags.CacheDir != "/x" {
+		t.Errorf("Expected targets work and logs with --cache-dir /x, got %q and %q", targets, flags.CacheDir)
+	}
+}
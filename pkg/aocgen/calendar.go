@@ -0,0 +1,148 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PartStatus is the progress on one part of a puzzle.
+type PartStatus struct {
+	Downloaded bool `json:"downloaded"`
+	Generated  bool `json:"generated"`
+	Passing    bool `json:"passing"`
+}
+
+// symbol renders the status as a single character, like the stars on the
+// Advent of Code calendar.
+func (s PartStatus) symbol() string {
+	switch {
+	case s.Passing:
+		return "*"
+	case s.Generated:
+		return "+"
+	case s.Downloaded:
+		return "d"
+	default:
+		return "."
+	}
+}
+
+// CalendarDay is the progress on both parts of one day.
+type CalendarDay struct {
+	Day   int        `json:"day"`
+	Part1 PartStatus `json:"part1"`
+	Part2 PartStatus `json:"part2"`
+}
+
+// buildCalendar computes the status of every part of every day of year. A
+// part is generated if it has a stored solution or a generate attempt, and
+// passing if its latest eval was correct. When lang is set only solutions,
+// attempts and evals in that language count.
+func buildCalendar(year int, lang string, challenges []Challenge, attempts []Attempt, evals []EvalRecord) []CalendarDay {
+	matchesLang := func(l string) bool {
+		return lang == "" || strings.EqualFold(l, lang)
+	}
+
+	status := make(map[string]*PartStatus)
+	get := func(name string) *PartStatus {
+		if status[name] == nil {
+			status[name] = &PartStatus{}
+		}
+		return status[name]
+	}
+
+	for _, c := range challenges {
+		if _, _, y, ok := parseChallengeName(c.Name); !ok || y != year {
+			continue
+		}
+		s := get(c.Name)
+		s.Downloaded = true
+		if strings.TrimSpace(c.Solution) != "" && matchesLang(c.SolutionLang) {
+			s.Generated = true
+		}
+	}
+
+	for _, a := range attempts {
+		if matchesLang(a.Lang) {
+			get(a.Challenge).Generated = true
+		}
+	}
+
+	latest := make(map[string]EvalRecord)
+	for _, e := range evals {
+		if !matchesLang(e.Lang) {
+			continue
+		}
+		key := e.Challenge + "/" + strings.ToLower(e.Lang)
+		if prev, ok := latest[key]; !ok || !e.Time.Before(prev.Time) {
+			latest[key] = e
+		}
+	}
+	for _, e := range latest {
+		if e.Verdict == VerdictCorrect {
+			get(e.Challenge).Passing = true
+		}
+	}
+
+	days := make([]CalendarDay, 25)
+	for i := range days {
+		day := i + 1
+		days[i].Day = day
+		if s := status[fmt.Sprintf("day%d_part1_%d", day, year)]; s != nil {
+			days[i].Part1 = *s
+		}
+		if s := status[fmt.Sprintf("day%d_part2_%d", day, year)]; s != nil {
+			days[i].Part2 = *s
+		}
+	}
+	return days
+}
+
+// printCalendar renders days as a five-by-five grid with one symbol per part.
+func printCalendar(w io.Writer, year int, lang string, days []CalendarDay) {
+	title := fmt.Sprintf("Advent of Code %d", year)
+	if lang != "" {
+		title += " (" + lang + ")"
+	}
+	fmt.Fprintln(w, title)
+	fmt.Fprintln(w)
+
+	for i, d := range days {
+		fmt.Fprintf(w, "%4d %s%s", d.Day, d.Part1.symbol(), d.Part2.symbol())
+		if (i+1)%5 == 0 {
+			fmt.Fprintln(w)
+		} else {
+			fmt.Fprint(w, "  ")
+		}
+	}
+	if len(days)%5 != 0 {
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Parts 1 and 2: * passing  + generated  d downloaded  . missing")
+}
+
+// runCalendarCommand prints the calendar view of `aocgen list --calendar`.
+func runCalendarCommand(flags Flags, challenges []Challenge, w io.Writer) error {
+	if flags.Year == 0 {
+		return fmt.Errorf("--calendar requires --year")
+	}
+
+	attempts, err := loadAttempts()
+	if err != nil {
+		return fmt.Errorf("error loading attempts: %v", err)
+	}
+	evals, err := loadEvalLog()
+	if err != nil {
+		return fmt.Errorf("error loading eval history: %v", err)
+	}
+
+	days := buildCalendar(flags.Year, flags.Lang, challenges, attempts, evals)
+	if flags.JSON {
+		return emitJSON(days)
+	}
+	printCalendar(w, flags.Year, flags.Lang, days)
+	return nil
+}
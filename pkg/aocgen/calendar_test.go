@@ -0,0 +1,74 @@
+package aocgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	now := time.Now()
+	challenges := []Challenge{
+		{Name: "day1_part1_2023", Task: "task", Solution: "print(1)", SolutionLang: "python"},
+		{Name: "day1_part2_2023", Task: "task"},
+		{Name: "day2_part1_2023", Task: "task"},
+		{Name: "day2_part1_2022", Task: "other year", Solution: "print(2)", SolutionLang: "python"},
+	}
+	attempts := []Attempt{
+		{Challenge: "day1_part2_2023", Lang: "go", Number: 1},
+	}
+	evals := []EvalRecord{
+		{Challenge: "day1_part1_2023", Lang: "python", Verdict: VerdictCorrect, Time: now.Add(-time.Hour)},
+		{Challenge: "day1_part2_2023", Lang: "go", Verdict: VerdictCorrect, Time: now.Add(-time.Hour)},
+		{Challenge: "day1_part2_2023", Lang: "go", Verdict: VerdictWrongAnswer, Time: now},
+	}
+
+	days := buildCalendar(2023, "", challenges, attempts, evals)
+	if len(days) != 25 {
+		t.Fatalf("Expected 25 days, got %d", len(days))
+	}
+	if got := days[0].Part1; got != (PartStatus{Downloaded: true, Generated: true, Passing: true}) {
+		t.Errorf("Unexpected status for day 1 part 1: %+v", got)
+	}
+	if got := days[0].Part2; got != (PartStatus{Downloaded: true, Generated: true}) {
+		t.Errorf("Expected the latest wrong answer to count for day 1 part 2, got: %+v", got)
+	}
+	if got := days[1].Part1; got != (PartStatus{Downloaded: true}) {
+		t.Errorf("Unexpected status for day 2 part 1: %+v", got)
+	}
+	if got := days[24].Part1; got != (PartStatus{}) {
+		t.Errorf("Expected day 25 to be missing, got: %+v", got)
+	}
+
+	days = buildCalendar(2023, "go", challenges, attempts, evals)
+	if got := days[0].Part1; got != (PartStatus{Downloaded: true}) {
+		t.Errorf("Expected python solutions to be ignored with --lang go, got: %+v", got)
+	}
+}
+
+func TestRunCalendarCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	challenges := []Challenge{
+		{Name: "day1_part1_2023", Task: "task", Solution: "print(1)", SolutionLang: "python"},
+		{Name: "day1_part2_2023", Task: "task"},
+	}
+
+	var out bytes.Buffer
+	if err := runCalendarCommand(Flags{Year: 2023}, challenges, &out); err != nil {
+		t.Fatalf("Failed to print calendar: %v", err)
+	}
+
+	output := out.String()
+	for _, expected := range []string{"Advent of Code 2023", "   1 +d", "  25 ..", "* passing"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if err := runCalendarCommand(Flags{}, challenges, &out); err == nil {
+		t.Errorf("Expected an error without --year")
+	}
+}
@@ -0,0 +1,174 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// challengeFile matches files named after a challenge: solutions, and
+// binaries compiled from them by hand.
+var challengeFile = regexp.MustCompile(`^day\d+_part\d+_\d{4}(\.[^.]+)?$`)
+
+// workspacePath matches the directories of a --workspace tree.
+var workspacePath = []*regexp.Regexp{
+	regexp.MustCompile(`^\d{4}$`),
+	regexp.MustCompile(`^day\d{2}$`),
+	regexp.MustCompile(`^part\d$`),
+}
+
+// buildArtifacts are the extensions of compiler output left next to sources.
+var buildArtifacts = map[string]bool{
+	".class": true, ".pyc": true, ".o": true, ".obj": true, ".hi": true,
+	".beam": true, ".cmi": true, ".cmx": true, ".cmo": true, ".exe": true,
+}
+
+// CleanReport is the --json form of `aocgen clean`.
+type CleanReport struct {
+	DryRun bool     `json:"dry_run"`
+	Paths  []string `json:"paths"`
+	Bytes  int64    `json:"bytes"`
+}
+
+// isGeneratedFile reports whether name, in the current directory or a
+// workspace, is a file aocgen or a toolchain wrote there.
+func isGeneratedFile(name string, isDir, inWorkspace bool) bool {
+	if isDir {
+		return name == "__pycache__"
+	}
+	if name == "input.txt" || buildArtifacts[filepath.Ext(name)] {
+		return true
+	}
+	if m := challengeFile.FindStringSubmatch(name); m != nil {
+		if m[1] == "" {
+			return true
+		}
+		for _, ext := range languageExtensions {
+			if "."+ext == m[1] {
+				return true
+			}
+		}
+	}
+	// A workspace also holds the task and the project files of the scaffold
+	return inWorkspace && (name == "README.md" || name == "go.mod" || name == "package.json")
+}
+
+// generatedFiles returns the generated files and directories in dir.
+func generatedFiles(dir string, inWorkspace bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if isGeneratedFile(e.Name(), e.IsDir(), inWorkspace) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	return paths, nil
+}
+
+// workspaceDirs returns the challenge directories of the --workspace tree
+// under dir, such as 2023/day03/part1.
+func workspaceDirs(dir string, depth int) ([]string, error) {
+	if depth == len(workspacePath) {
+		return []string{dir}, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if !e.IsDir() || !workspacePath[depth].MatchString(e.Name()) {
+			continue
+		}
+		sub, err := workspaceDirs(filepath.Join(dir, e.Name()), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, sub...)
+	}
+	return dirs, nil
+}
+
+// removeEmptyParents removes dir and its parents up to the current
+// directory while they are empty.
+func removeEmptyParents(dir string) {
+	for dir != "." && dir != "" {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// runCleanCommand removes the solutions, input files and build artifacts
+// that generate, eval and the toolchains leave in the current directory and
+// its workspace tree. Generated solutions stay in the history of attempts.
+// With --dry-run it only lists what would be removed.
+func runCleanCommand(flags Flags, w io.Writer) error {
+	paths, err := generatedFiles(".", false)
+	if err != nil {
+		return fmt.Errorf("error reading the current directory: %v", err)
+	}
+	workspaces, err := workspaceDirs(".", 0)
+	if err != nil {
+		return fmt.Errorf("error reading the workspaces: %v", err)
+	}
+	for _, dir := range workspaces {
+		files, err := generatedFiles(dir, true)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", dir, err)
+		}
+		paths = append(paths, files...)
+	}
+	sort.Strings(paths)
+
+	report := CleanReport{DryRun: flags.DryRun, Paths: []string{}}
+	for _, path := range paths {
+		_, bytes, err := diskUsage(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		if !flags.DryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("error removing %s: %v", path, err)
+			}
+		}
+		report.Paths = append(report.Paths, path)
+		report.Bytes += bytes
+	}
+	if !flags.DryRun {
+		for _, dir := range workspaces {
+			removeEmptyParents(dir)
+		}
+	}
+
+	if flags.JSON {
+		return emitJSON(report)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(w, "Nothing to clean.")
+		return nil
+	}
+	verb := "Removed"
+	if flags.DryRun {
+		verb = "Would remove"
+	}
+	for _, path := range paths {
+		fmt.Fprintf(w, "%s %s\n", verb, path)
+	}
+	noun := "files"
+	if len(paths) == 1 {
+		noun = "file"
+	}
+	fmt.Fprintf(w, "%s %d %s (%s).\n", verb, len(paths), noun, formatBytes(report.Bytes))
+	if flags.DryRun {
+		fmt.Fprintln(w, "Run without --dry-run to remove them.")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunCleanCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	workspace := filepath.Join("2023", "day03", "part1")
+	os.MkdirAll(workspace, 0755)
+	os.MkdirAll("__pycache__", 0755)
+	os.MkdirAll("notes", 0755)
+	for _, name := range []string{
+		"day1_part1_2015.py", "day1_part1_2015", "input.txt", "Main.class", "__pycache__/helper.cpython-312.pyc",
+		"notes.md", "day1_part1_2015.txt", "notes/input.txt", "README.md",
+		filepath.Join(workspace, "day3_part1_2023.go"), filepath.Join(workspace, "go.mod"),
+		filepath.Join(workspace, "input.txt"), filepath.Join(workspace, "README.md"),
+	} {
+		os.WriteFile(name, []byte("x"), 0644)
+	}
+
+	var out bytes.Buffer
+	if err := runCleanCommand(Flags{DryRun: true}, &out); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Would remove 9 files") {
+		t.Errorf("Unexpected dry run output:\n%s", out.String())
+	}
+	if _, err := os.Stat("day1_part1_2015.py"); err != nil {
+		t.Errorf("A dry run must not remove files")
+	}
+
+	var report CleanReport
+	data := captureJSON(t, func() error { return runCleanCommand(Flags{JSON: true}, &out) })
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	want := []string{
+		"2023/day03/part1/README.md", "2023/day03/part1/day3_part1_2023.go", "2023/day03/part1/go.mod", "2023/day03/part1/input.txt",
+		"Main.class", "__pycache__", "day1_part1_2015", "day1_part1_2015.py", "input.txt",
+	}
+	for i := range want {
+		want[i] = filepath.FromSlash(want[i])
+	}
+	if !reflect.DeepEqual(report.Paths, want) {
+		t.Errorf("Unexpected paths:\n%v\nwant:\n%v", report.Paths, want)
+	}
+
+	// Files that are not generated stay, and emptied workspaces go
+	for _, name := range []string{"notes.md", "day1_part1_2015.txt", "notes/input.txt", "README.md"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("Expected %s to be kept", name)
+		}
+	}
+	if _, err := os.Stat("2023"); !os.IsNotExist(err) {
+		t.Errorf("Expected the empty workspace tree to be removed")
+	}
+
+	out.Reset()
+	if err := runCleanCommand(Flags{}, &out); err != nil || out.String() != "Nothing to clean.\n" {
+		t.Errorf("Expected nothing left to clean, got %q, %v", out.String(), err)
+	}
+}
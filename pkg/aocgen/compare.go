@@ -0,0 +1,277 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Outcomes of a challenge in a run. A perf run does not check answers, so a
+// challenge passes when its solution finished in time without an error; in a
+// benchmark run it passes when its verdict is correct.
+const (
+	outcomePass    = "pass"
+	outcomeTimeout = "timeout"
+	outcomeError   = "error"
+	// outcomeUnchecked is a solution of a challenge without a known answer.
+	// It neither passes nor fails, so it is left out of the pass rate.
+	outcomeUnchecked = "unchecked"
+)
+
+// Changes of a challenge between the two runs of `aocgen benchmark compare`.
+const (
+	changeRegression  = "regression"
+	changeImprovement = "improvement"
+	changeUnchanged   = "unchanged"
+	changeAdded       = "added"
+	changeRemoved     = "removed"
+)
+
+// CompareRun summarizes one of the runs in a CompareReport.
+type CompareRun struct {
+	ID         string  `json:"id"`
+	Lang       string  `json:"lang"`
+	Challenges int     `json:"challenges"`
+	Passed     int     `json:"passed"`
+	PassRate   float64 `json:"pass_rate"`
+	// Unchecked counts the challenges without a known answer, which are not
+	// in Challenges
+	Unchecked int `json:"unchecked,omitempty"`
+	// Failures counts the challenges that did not pass by how they failed
+	Failures []FailureCount `json:"failures"`
+}
+
+// CompareResult is a challenge in a CompareReport. The outcome of a run is
+// empty when the challenge is not in it.
+type CompareResult struct {
+	Challenge      string `json:"challenge"`
+	Change         string `json:"change"`
+	FromOutcome    string `json:"from_outcome,omitempty"`
+	ToOutcome      string `json:"to_outcome,omitempty"`
+	FromDurationMs int64  `json:"from_duration_ms,omitempty"`
+	ToDurationMs   int64  `json:"to_duration_ms,omitempty"`
+}
+
+// CompareReport is the JSON form of `aocgen benchmark compare`.
+type CompareReport struct {
+	From         CompareRun `json:"from"`
+	To           CompareRun `json:"to"`
+	Regressions  int        `json:"regressions"`
+	Improvements int        `json:"improvements"`
+	// PassRateChange is the change of the pass rate from the first run to
+	// the second, in percentage points
+	PassRateChange float64         `json:"pass_rate_change"`
+	Results        []CompareResult `json:"results"`
+}
+
+// runOutcome classifies a result of run. Failed evaluations are reported by
+// their verdict, failed generations by their failure.
+func runOutcome(run *BenchmarkRun, result RunResult) string {
+	switch {
+	case result.Verdict == VerdictCorrect:
+		return outcomePass
+	case result.Verdict == VerdictTimeout:
+		return outcomeTimeout
+	case result.Verdict == VerdictUnknownAnswer:
+		return outcomeUnchecked
+	case result.Verdict != "":
+		return string(result.Verdict)
+	case result.Failure != "":
+		return result.Failure
+	case result.Error != "":
+		return outcomeError
+	case run.TimeoutMs > 0 && result.Duration >= time.Duration(run.TimeoutMs)*time.Millisecond:
+		return outcomeTimeout
+	}
+	return outcomePass
+}
+
+func summarizeRun(run *BenchmarkRun) CompareRun {
+	summary := CompareRun{ID: run.ID, Lang: run.Lang, Failures: countFailures(run)}
+	for _, result := range run.Results {
+		switch runOutcome(run, result) {
+		case outcomeUnchecked:
+			summary.Unchecked++
+			continue
+		case outcomePass:
+			summary.Passed++
+		}
+		summary.Challenges++
+	}
+	if summary.Challenges > 0 {
+		summary.PassRate = float64(summary.Passed) / float64(summary.Challenges)
+	}
+	return summary
+}
+
+// compareRuns lines up the challenges of two runs.
+func compareRuns(from, to *BenchmarkRun) CompareReport {
+	report := CompareReport{From: summarizeRun(from), To: summarizeRun(to), Results: []CompareResult{}}
+	report.PassRateChange = (report.To.PassRate - report.From.PassRate) * 100
+
+	results := make(map[string]*CompareResult)
+	for _, result := range from.Results {
+		results[result.Challenge] = &CompareResult{
+			Challenge:      result.Challenge,
+			FromOutcome:    runOutcome(from, result),
+			FromDurationMs: result.Duration.Milliseconds(),
+		}
+	}
+	for _, result := range to.Results {
+		r, ok := results[result.Challenge]
+		if !ok {
+			r = &CompareResult{Challenge: result.Challenge}
+			results[result.Challenge] = r
+		}
+		r.ToOutcome = runOutcome(to, result)
+		r.ToDurationMs = result.Duration.Milliseconds()
+	}
+
+	for _, r := range results {
+		switch {
+		case r.FromOutcome == "":
+			r.Change = changeAdded
+		case r.ToOutcome == "":
+			r.Change = changeRemoved
+		case r.FromOutcome == outcomeUnchecked || r.ToOutcome == outcomeUnchecked:
+			r.Change = changeUnchanged
+		case r.FromOutcome == outcomePass && r.ToOutcome != outcomePass:
+			r.Change = changeRegression
+			report.Regressions++
+		case r.FromOutcome != outcomePass && r.ToOutcome == outcomePass:
+			r.Change = changeImprovement
+			report.Improvements++
+		default:
+			r.Change = changeUnchanged
+		}
+		report.Results = append(report.Results, *r)
+	}
+	sort.Slice(report.Results, func(i, j int) bool {
+		return challengeLess(report.Results[i].Challenge, report.Results[j].Challenge)
+	})
+	return report
+}
+
+// challengeLess orders challenges by year, day and part; names that do not
+// parse sort first.
+func challengeLess(a, b string) bool {
+	ad, ap, ay, _ := parseChallengeName(a)
+	bd, bp, by, _ := parseChallengeName(b)
+	if ay != by {
+		return ay < by
+	}
+	if ad != bd {
+		return ad < bd
+	}
+	if ap != bp {
+		return ap < bp
+	}
+	return a < b
+}
+
+// runBenchmarkCompareCommand compares the outcomes of two perf runs as a
+// table, JSON or Markdown.
+func runBenchmarkCompareCommand(fromID, toID string, flags Flags, w io.Writer) error {
+	from, err := loadBenchmarkRun(fromID)
+	if err != nil {
+		return err
+	}
+	to, err := loadBenchmarkRun(toID)
+	if err != nil {
+		return err
+	}
+	report := compareRuns(from, to)
+
+	switch {
+	case flags.JSON:
+		return emitJSON(report)
+	case flags.Format == "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	case flags.Format == "markdown":
+		printCompareMarkdown(w, report)
+		return nil
+	case flags.Format == "" || flags.Format == "table":
+		printCompareTable(w, report)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", flags.Format)
+	}
+}
+
+// changed returns the results whose outcome changed between the runs.
+func (r CompareReport) changed() []CompareResult {
+	var changed []CompareResult
+	for _, result := range r.Results {
+		if result.Change == changeRegression || result.Change == changeImprovement {
+			changed = append(changed, result)
+		}
+	}
+	return changed
+}
+
+func (r CompareReport) count(change string) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Change == change {
+			n++
+		}
+	}
+	return n
+}
+
+func describeRun(run CompareRun) string {
+	description := fmt.Sprintf("%d/%d passed (%.1f%%)", run.Passed, run.Challenges, run.PassRate*100)
+	if run.Unchecked > 0 {
+		description += fmt.Sprintf(", %d unchecked without a known answer", run.Unchecked)
+	}
+	return description
+}
+
+func printCompareTable(w io.Writer, r CompareReport) {
+	fmt.Fprintf(w, "From: %s (%s)  %s\n", r.From.ID, r.From.Lang, describeRun(r.From))
+	fmt.Fprintf(w, "To:   %s (%s)  %s\n", r.To.ID, r.To.Lang, describeRun(r.To))
+	fmt.Fprintf(w, "Pass rate: %+.1f points, %d regressions, %d improvements\n", r.PassRateChange, r.Regressions, r.Improvements)
+	if added, removed := r.count(changeAdded), r.count(changeRemoved); added > 0 || removed > 0 {
+		fmt.Fprintf(w, "Only in %s: %d, only in %s: %d\n", r.From.ID, removed, r.To.ID, added)
+	}
+
+	changed := r.changed()
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "\nNo challenge changed outcome.")
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-18s  %-12s  %-8s  %-8s  %10s  %10s\n", "Challenge", "Change", "From", "To", "From ms", "To ms")
+	for _, c := range changed {
+		fmt.Fprintf(w, "%-18s  %-12s  %-8s  %-8s  %10d  %10d\n", c.Challenge, c.Change, c.FromOutcome, c.ToOutcome, c.FromDurationMs, c.ToDurationMs)
+	}
+}
+
+func printCompareMarkdown(w io.Writer, r CompareReport) {
+	fmt.Fprintf(w, "### Benchmark comparison: `%s` → `%s`\n\n", r.From.ID, r.To.ID)
+	fmt.Fprintln(w, "| Run | Language | Passed | Pass rate |")
+	fmt.Fprintln(w, "| --- | --- | ---: | ---: |")
+	for _, run := range []CompareRun{r.From, r.To} {
+		fmt.Fprintf(w, "| `%s` | %s | %d/%d | %.1f%% |\n", run.ID, run.Lang, run.Passed, run.Challenges, run.PassRate*100)
+	}
+	fmt.Fprintf(w, "\n**Pass rate change:** %+.1f points (%d regressions, %d improvements)\n", r.PassRateChange, r.Regressions, r.Improvements)
+
+	changed := r.changed()
+	if len(changed) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Challenge | Change | From | To |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	for _, c := range changed {
+		fmt.Fprintf(w, "| %s | %s | %s | %s |\n", c.Challenge, strings.ToUpper(c.Change[:1])+c.Change[1:], c.FromOutcome, c.ToOutcome)
+	}
+}
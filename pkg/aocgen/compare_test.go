@@ -0,0 +1,85 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func saveTestRun(t *testing.T, id string, timeoutMs int64, results ...RunResult) {
+	t.Helper()
+	run := &BenchmarkRun{ID: id, Lang: "python", TimeoutMs: timeoutMs, Results: results}
+	if err := run.save(); err != nil {
+		t.Fatalf("Failed to save run %s: %v", id, err)
+	}
+}
+
+func TestRunBenchmarkCompareCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveTestRun(t, "before", 1000,
+		RunResult{Challenge: "day1_part1_2015", Duration: 10 * time.Millisecond},
+		RunResult{Challenge: "day2_part1_2015", Duration: 20 * time.Millisecond},
+		RunResult{Challenge: "day10_part1_2015", Duration: time.Second},
+		RunResult{Challenge: "day3_part1_2015", Error: "error running command: exit status 1"},
+	)
+	saveTestRun(t, "after", 1000,
+		RunResult{Challenge: "day1_part1_2015", Duration: 12 * time.Millisecond},
+		RunResult{Challenge: "day2_part1_2015", Error: "error running command: exit status 1"},
+		RunResult{Challenge: "day10_part1_2015", Duration: 300 * time.Millisecond},
+		RunResult{Challenge: "day4_part1_2015", Duration: 5 * time.Millisecond},
+	)
+
+	var out bytes.Buffer
+	if err := runBenchmarkCompareCommand("before", "after", Flags{Format: "json"}, &out); err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	var report CompareReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out.String())
+	}
+	if report.From.Passed != 2 || report.To.Passed != 3 || report.Regressions != 1 || report.Improvements != 1 {
+		t.Errorf("Unexpected summary: %+v", report)
+	}
+	if report.PassRateChange != 25 {
+		t.Errorf("Expected a pass rate change of 25 points, got %v", report.PassRateChange)
+	}
+	var order, changes []string
+	for _, r := range report.Results {
+		order = append(order, r.Challenge)
+		changes = append(changes, r.Change)
+	}
+	if got := strings.Join(order, " "); got != "day1_part1_2015 day2_part1_2015 day3_part1_2015 day4_part1_2015 day10_part1_2015" {
+		t.Errorf("Unexpected order: %s", got)
+	}
+	if got := strings.Join(changes, " "); got != "unchanged regression removed added improvement" {
+		t.Errorf("Unexpected changes: %s", got)
+	}
+
+	out.Reset()
+	if err := runBenchmarkCompareCommand("before", "after", Flags{}, &out); err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	for _, want := range []string{"+25.0 points, 1 regressions, 1 improvements", "day2_part1_2015     regression    pass      error", "day10_part1_2015    improvement   timeout   pass"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Table missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runBenchmarkCompareCommand("before", "after", Flags{Format: "markdown"}, &out); err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	for _, want := range []string{"| `before` | python | 2/4 | 50.0% |", "| day2_part1_2015 | Regression | pass | error |"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Markdown missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := runBenchmarkCompareCommand("before", "missing", Flags{}, &out); err == nil || !strings.Contains(err.Error(), "run missing not found") {
+		t.Errorf("Expected a missing run error, got %v", err)
+	}
+}
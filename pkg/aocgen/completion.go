@@ -0,0 +1,307 @@
+package aocgen
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// subcommands are the subcommands offered by shell completion, with the
+// actions or arguments they take first.
+var subcommands = []struct {
+	Name    string
+	Actions []string
+}{
+	{Name: "init"},
+	{Name: "generate"},
+	{Name: "download"},
+	{Name: "eval"},
+	{Name: "refine"},
+	{Name: "run"},
+	{Name: "list"},
+	{Name: "setup"},
+	{Name: "perf"},
+	{Name: "benchmark", Actions: []string{"compare", "report"}},
+	{Name: "prompt"},
+	{Name: "attempts"},
+	{Name: "diff"},
+	{Name: "stats"},
+	{Name: "submit"},
+	{Name: "doctor"},
+	{Name: "answers", Actions: []string{"sync"}},
+	{Name: "export"},
+	{Name: "import"},
+	{Name: "restore", Actions: []string{"list"}},
+	{Name: "leaderboard"},
+	{Name: "times"},
+	{Name: "cache", Actions: []string{"info", "path", "clean"}},
+	{Name: "completion", Actions: []string{"bash", "zsh", "fish"}},
+	{Name: "serve"},
+	{Name: "test", Actions: []string{"add", "list"}},
+	{Name: "transcript", Actions: []string{"show"}},
+	{Name: "models", Actions: []string{"list", "pull"}},
+	{Name: "clean"},
+	{Name: "open"},
+	{Name: "show"},
+	{Name: "input"},
+	{Name: "usage"},
+}
+
+// completionFlag is a flag as the completion scripts describe it.
+type completionFlag struct {
+	Name  string
+	Usage string
+	// Bool flags take no value
+	Bool bool
+}
+
+// completionData is what the completion script templates are filled with.
+type completionData struct {
+	Subcommands  []string
+	Actions      map[string]string
+	Flags        []completionFlag
+	Languages    string
+	Formats      string
+	CacheTargets string
+}
+
+func newCompletionData() completionData {
+	data := completionData{
+		Actions:      make(map[string]string),
+		Languages:    strings.Join(supportedLanguages(), " "),
+		Formats:      "table json markdown html parquet jsonl csv",
+		CacheTargets: strings.Join(cleanableTargets(), " "),
+	}
+	for _, sub := range subcommands {
+		data.Subcommands = append(data.Subcommands, sub.Name)
+		if len(sub.Actions) > 0 {
+			data.Actions[sub.Name] = strings.Join(sub.Actions, " ")
+		}
+	}
+	newFlagSet(&Flags{}).VisitAll(func(f *flag.Flag) {
+		isBool := false
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			isBool = b.IsBoolFlag()
+		}
+		data.Flags = append(data.Flags, completionFlag{Name: f.Name, Usage: f.Usage, Bool: isBool})
+	})
+	return data
+}
+
+var completionFuncs = template.FuncMap{
+	"join": strings.Join,
+	"flagNames": func(flags []completionFlag) string {
+		names := make([]string, len(flags))
+		for i, f := range flags {
+			names[i] = "--" + f.Name
+		}
+		return strings.Join(names, " ")
+	},
+	// fishQuote quotes s for a single-quoted fish string
+	"fishQuote": func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	},
+}
+
+// completionScripts are the templates of `aocgen completion <shell>`. Stored
+// years and days are not part of the script; it asks `aocgen completion
+// values` for them, so they follow the store.
+var completionScripts = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Funcs(completionFuncs).Parse(`# bash completion for aocgen
+# Load with: source <(aocgen completion bash)
+
+_aocgen() {
+    local cur prev year i
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=($(compgen -W "{{join .Subcommands " "}}" -- "$cur"))
+        return
+    fi
+
+    case "$prev" in
+        --lang)
+            COMPREPLY=($(compgen -W "{{.Languages}}" -- "$cur"))
+            return ;;
+        --year)
+            COMPREPLY=($(compgen -W "$(aocgen completion values years 2>/dev/null)" -- "$cur"))
+            return ;;
+        --day)
+            for ((i = 1; i < COMP_CWORD - 1; i++)); do
+                if [ "${COMP_WORDS[i]}" = "--year" ]; then
+                    year="${COMP_WORDS[i+1]}"
+                fi
+            done
+            COMPREPLY=($(compgen -W "$(aocgen completion values days $year 2>/dev/null)" -- "$cur"))
+            return ;;
+        --part)
+            COMPREPLY=($(compgen -W "1 2 all" -- "$cur"))
+            return ;;
+        --format)
+            COMPREPLY=($(compgen -W "{{.Formats}}" -- "$cur"))
+            return ;;
+    esac
+
+    if [ "$COMP_CWORD" -eq 2 ]; then
+        case "${COMP_WORDS[1]}" in
+{{- range $sub, $actions := .Actions}}
+            {{$sub}})
+                COMPREPLY=($(compgen -W "{{$actions}}" -- "$cur"))
+                return ;;
+{{- end}}
+            import)
+                COMPREPLY=($(compgen -f -- "$cur"))
+                return ;;
+        esac
+    fi
+    if [ "${COMP_WORDS[1]}" = "cache" ] && [ "${COMP_WORDS[2]}" = "clean" ] && [[ "$cur" != -* ]]; then
+        COMPREPLY=($(compgen -W "{{.CacheTargets}}" -- "$cur"))
+        return
+    fi
+
+    COMPREPLY=($(compgen -W "{{flagNames .Flags}}" -- "$cur"))
+}
+
+complete -o default -F _aocgen aocgen
+`)),
+	"zsh": template.Must(template.New("zsh").Funcs(completionFuncs).Parse(`#compdef aocgen
+# zsh completion for aocgen
+# Load with: source <(aocgen completion zsh)
+
+_aocgen() {
+    local prev=${words[CURRENT-1]} year i
+
+    if (( CURRENT == 2 )); then
+        compadd -- {{join .Subcommands " "}}
+        return
+    fi
+
+    case $prev in
+        --lang)
+            compadd -- {{.Languages}}
+            return ;;
+        --year)
+            compadd -- ${(f)"$(aocgen completion values years 2>/dev/null)"}
+            return ;;
+        --day)
+            i=${words[(I)--year]}
+            (( i > 0 )) && year=${words[i+1]}
+            compadd -- ${(f)"$(aocgen completion values days $year 2>/dev/null)"}
+            return ;;
+        --part)
+            compadd -- 1 2 all
+            return ;;
+        --format)
+            compadd -- {{.Formats}}
+            return ;;
+    esac
+
+    if (( CURRENT == 3 )); then
+        case ${words[2]} in
+{{- range $sub, $actions := .Actions}}
+            {{$sub}})
+                compadd -- {{$actions}}
+                return ;;
+{{- end}}
+            import)
+                _files
+                return ;;
+        esac
+    fi
+    if [[ ${words[2]} == cache && ${words[3]} == clean && $PREFIX != -* ]]; then
+        compadd -- {{.CacheTargets}}
+        return
+    fi
+
+    compadd -- {{flagNames .Flags}}
+}
+
+compdef _aocgen aocgen
+`)),
+	"fish": template.Must(template.New("fish").Funcs(completionFuncs).Parse(`# fish completion for aocgen
+# Load with: aocgen completion fish | source
+
+function __aocgen_year
+    set -l tokens (commandline -opc)
+    set -l i (contains -i -- --year $tokens)
+    and echo $tokens[(math $i + 1)]
+end
+
+complete -c aocgen -f
+complete -c aocgen -n __fish_use_subcommand -a {{fishQuote (join .Subcommands " ")}}
+{{- range $sub, $actions := .Actions}}
+complete -c aocgen -n '__fish_seen_subcommand_from {{$sub}}; and test (count (commandline -opc)) -eq 2' -a {{fishQuote $actions}}
+{{- end}}
+complete -c aocgen -n '__fish_seen_subcommand_from import' -F
+complete -c aocgen -n '__fish_seen_subcommand_from clean' -a {{fishQuote .CacheTargets}}
+{{- range .Flags}}
+{{- if eq .Name "lang"}}
+complete -c aocgen -l lang -x -a {{fishQuote $.Languages}} -d {{fishQuote .Usage}}
+{{- else if eq .Name "year"}}
+complete -c aocgen -l year -x -a '(aocgen completion values years 2>/dev/null)' -d {{fishQuote .Usage}}
+{{- else if eq .Name "day"}}
+complete -c aocgen -l day -x -a '(aocgen completion values days (__aocgen_year) 2>/dev/null)' -d {{fishQuote .Usage}}
+{{- else if eq .Name "part"}}
+complete -c aocgen -l part -x -a '1 2 all' -d {{fishQuote .Usage}}
+{{- else if eq .Name "format"}}
+complete -c aocgen -l format -x -a {{fishQuote $.Formats}} -d {{fishQuote .Usage}}
+{{- else if .Bool}}
+complete -c aocgen -l {{.Name}} -d {{fishQuote .Usage}}
+{{- else}}
+complete -c aocgen -l {{.Name}} -r -d {{fishQuote .Usage}}
+{{- end}}
+{{- end}}
+`)),
+}
+
+// writeCompletion writes the completion script for shell.
+func writeCompletion(shell string, w io.Writer) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", shell)
+	}
+	return script.Execute(w, newCompletionData())
+}
+
+// writeCompletionValues writes the stored years, or the stored days of the
+// year in args (all years if none), one per line, for the completion scripts.
+func writeCompletionValues(args []string, w io.Writer) error {
+	if len(args) == 0 || (args[0] != "years" && args[0] != "days") {
+		return fmt.Errorf("expected 'years' or 'days [year]'")
+	}
+	year := 0
+	if args[0] == "days" && len(args) > 1 {
+		year, _ = strconv.Atoi(args[1])
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int]bool)
+	for _, c := range challenges {
+		d, _, y, ok := parseChallengeName(c.Name)
+		switch {
+		case !ok:
+		case args[0] == "years":
+			seen[y] = true
+		case year == 0 || y == year:
+			seen[d] = true
+		}
+	}
+
+	values := make([]int, 0, len(seen))
+	for v := range seen {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	for _, v := range values {
+		fmt.Fprintln(w, v)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package aocgen
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var out bytes.Buffer
+		if err := writeCompletion(shell, &out); err != nil {
+			t.Fatalf("Failed to write %s completion: %v", shell, err)
+		}
+		script := out.String()
+		flagName := "--http-timeout"
+		if shell == "fish" {
+			flagName = "-l http-timeout"
+		}
+		for _, expected := range []string{"leaderboard", flagName, "haskell", "aocgen completion values years", "info path clean"} {
+			if !strings.Contains(script, expected) {
+				t.Errorf("Expected the %s script to contain %q", shell, expected)
+			}
+		}
+
+		if shell == "bash" {
+			if _, err := exec.LookPath("bash"); err == nil {
+				path := filepath.Join(t.TempDir(), "aocgen.bash")
+				os.WriteFile(path, out.Bytes(), 0644)
+				if output, err := exec.Command("bash", "-n", path).CombinedOutput(); err != nil {
+					t.Errorf("Invalid bash script: %v\n%s", err, output)
+				}
+			}
+		}
+	}
+
+	if err := writeCompletion("powershell", &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected an error for an unsupported shell")
+	}
+}
+
+func TestWriteCompletionValues(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	err := saveChallenges([]Challenge{
+		{Name: "day3_part1_2023"},
+		{Name: "day12_part2_2023"},
+		{Name: "day1_part1_2015"},
+		{Name: "day3_part2_2023"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	tests := map[string]string{
+		"years":     "2015\n2023\n",
+		"days":      "1\n3\n12\n",
+		"days 2023": "3\n12\n",
+		"days 2016": "",
+	}
+	for args, expected := range tests {
+		var out bytes.Buffer
+		if err := writeCompletionValues(strings.Fields(args), &out); err != nil {
+			t.Fatalf("Failed to write %s: %v", args, err)
+		}
+		if out.String() != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, args, out.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const configFile = "config.json"
+
+// Config holds user defaults written by `aocgen init`. Command-line flags
+// always take precedence over values from the config file.
+type Config struct {
+	Session  string `json:"session,omitempty"`
+	Lang     string `json:"lang,omitempty"`
+	Model    string `json:"model,omitempty"`
+	ModelAPI string `json:"model_api,omitempty"`
+	// Storage selects the challenge store backend: "json" (default) or "sqlite"
+	Storage string `json:"storage,omitempty"`
+	// Prices overrides the built-in cost table used by `aocgen usage`
+	Prices map[string]ModelPrice `json:"prices,omitempty"`
+	// Limits sets per-language evaluation timeout and memory defaults
+	Limits map[string]LanguageLimits `json:"limits,omitempty"`
+	// AoCRequestsPerMinute throttles requests to adventofcode.com
+	AoCRequestsPerMinute int `json:"aoc_requests_per_minute,omitempty"`
+	// Sampling sets default sampling parameters for generation
+	Sampling Sampling `json:"sampling,omitempty"`
+	// SystemPrompt is the default system prompt, text or @file
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	// Normalize is the default of --normalize, e.g. "casefold,commas"
+	Normalize string `json:"normalize,omitempty"`
+	// CacheResponses turns on --cache-responses for every command
+	CacheResponses bool `json:"cache_responses,omitempty"`
+	// Header turns on --header for every command
+	Header bool `json:"header,omitempty"`
+	// Languages adds languages or overrides the commands of built-in ones
+	Languages map[string]LanguageConfig `json:"languages,omitempty"`
+	// Backups is the number of backups of challenges.json kept (default 5)
+	Backups int `json:"backups,omitempty"`
+	// Retry sets how failed model API requests are retried
+	Retry RetryPolicy `json:"retry,omitempty"`
+	// RateLimits caps the requests and tokens per minute of each provider
+	RateLimits map[string]ProviderLimits `json:"rate_limits,omitempty"`
+	// Workdir is the default of --workdir
+	Workdir string `json:"workdir,omitempty"`
+	// ModelAPIs overrides the default endpoint of each provider
+	ModelAPIs map[string]string `json:"model_apis,omitempty"`
+	// InputLines is the default of --input-lines instead of 10
+	InputLines int `json:"input_lines,omitempty"`
+	// ContextWindows overrides the context window, in tokens, of models by
+	// name prefix
+	ContextWindows map[string]int `json:"context_windows,omitempty"`
+	// ContextStrategies sets what happens to prompts that do not fit in the
+	// context window of models by name prefix: "truncate" or "warn"
+	ContextStrategies map[string]string `json:"context_strategies,omitempty"`
+	// Profiles are named model settings selected with --profile
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// Profile is the profile used when --profile is not given
+	Profile string `json:"profile,omitempty"`
+	// DisableKeychain keeps the session token out of the keychain
+	DisableKeychain bool `json:"disable_keychain,omitempty"`
+	// Editor opens solutions when neither $VISUAL nor $EDITOR is set
+	Editor string `json:"editor,omitempty"`
+	// Embeddings selects the embeddings model of --similar
+	Embeddings EmbeddingsConfig `json:"embeddings,omitempty"`
+}
+
+// loadConfig reads the config file from the cache directory. A missing
+// config file is not an error and yields an empty Config.
+func loadConfig() (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), configFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	err = json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+func saveConfig(cfg Config) error {
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The config may contain the session token, so keep it private
+	return os.WriteFile(filepath.Join(getCacheDir(), configFile), data, 0600)
+}
+
+// applyConfig fills flags that were not set on the command line from cfg.
+func applyConfig(flags Flags, cfg Config) Flags {
+	if flags.Session == "" {
+		flags.Session = cfg.Session
+	}
+	if flags.Lang == "" {
+		flags.Lang = cfg.Lang
+	}
+	if flags.Model == "" {
+		flags.Model = cfg.Model
+	}
+	// The endpoint of the default model does not serve models of other
+	// providers, which get their provider's endpoint instead
+	if flags.ModelAPI == "" && modelProvider(flags.Model) == modelProvider(cfg.Model) {
+		flags.ModelAPI = cfg.ModelAPI
+	}
+	if flags.SystemPrompt == "" {
+		flags.SystemPrompt = cfg.SystemPrompt
+	}
+	if flags.Normalize == "" {
+		flags.Normalize = cfg.Normalize
+	}
+	// --out replaces the configured working directory
+	if flags.Workdir == "" && flags.Out == "" {
+		flags.Workdir = cfg.Workdir
+	}
+	if flags.InputLines == defaultInputLines && cfg.InputLines != 0 {
+		flags.InputLines = cfg.InputLines
+	}
+	if cfg.CacheResponses {
+		flags.CacheResponses = true
+	}
+	if cfg.Header {
+		flags.Header = true
+	}
+	flags.Sampling = flags.Sampling.withDefaults(cfg.Sampling)
+	flags.RetryPolicy = cfg.Retry
+	return flags
+}
@@ -0,0 +1,138 @@
+package aocgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultContextWindow is the context window, in tokens, assumed for models
+// without an entry in defaultContextWindows.
+const defaultContextWindow = 8192
+
+// defaultCompletionReserve is the part of the context window kept free for
+// the completion when --max-tokens is not set, or a quarter of smaller
+// windows.
+const defaultCompletionReserve = 4096
+
+// defaultContextWindows maps model name prefixes to the number of tokens
+// their context window holds. The longest matching prefix wins.
+var defaultContextWindows = map[string]int{
+	"gpt-3.5-turbo":      16385,
+	"gpt-4":              8192,
+	"gpt-4-turbo":        128000,
+	"gpt-4o":             128000,
+	"groq/llama3-":       8192,
+	"groq/llama-3.1-":    131072,
+	"groq/mixtral-8x7b":  32768,
+	"groq/gemma2-9b":     8192,
+	"mistral-":           32000,
+	"mistral-large":      128000,
+	"codestral-":         32000,
+	"ollama/":            4096,
+	"bedrock/anthropic.": 200000,
+	"bedrock/meta.":      8192,
+}
+
+// Context strategies say what buildPrompt does with a prompt that does not
+// fit in the context window.
+const (
+	// contextTruncate drops few-shot examples, then the flavor text of the
+	// task, until the prompt fits
+	contextTruncate = "truncate"
+	// contextWarn sends the prompt as it is
+	contextWarn = "warn"
+)
+
+// essentialText matches paragraphs of a task that tell what to compute:
+// those with code, emphasis, or talk of examples, the input or the answer.
+var essentialText = regexp.MustCompile("(?i)[`*]|example|input|output|answer|what is|how many|determine|calculate|find")
+
+// modelSetting returns the value of the longest prefix of model in the
+// first table that has one.
+func modelSetting[T any](model string, tables ...map[string]T) (T, bool) {
+	for _, table := range tables {
+		best := ""
+		for prefix := range table {
+			if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+				best = prefix
+			}
+		}
+		if best != "" {
+			return table[best], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// contextWindow returns the context window of model, from overrides or the
+// built-in table.
+func contextWindow(model string, overrides map[string]int) int {
+	if window, ok := modelSetting(model, overrides, defaultContextWindows); ok {
+		return window
+	}
+	return defaultContextWindow
+}
+
+// contextStrategy returns the context strategy of model, from overrides or
+// else contextTruncate.
+func contextStrategy(model string, overrides map[string]string) (string, error) {
+	strategy, ok := modelSetting(model, overrides)
+	if !ok {
+		return contextTruncate, nil
+	}
+	if strategy != contextTruncate && strategy != contextWarn {
+		return "", fmt.Errorf("unknown context strategy %q for %s, expected %s or %s", strategy, model, contextTruncate, contextWarn)
+	}
+	return strategy, nil
+}
+
+// promptBudget returns how many tokens a prompt to the model in flags can
+// take: its context window less the room kept for the completion.
+func promptBudget(flags Flags, cfg Config) int {
+	window := contextWindow(flags.Model, cfg.ContextWindows)
+	reserve := flags.Sampling.MaxTokens
+	if reserve == 0 {
+		reserve = min(defaultCompletionReserve, window/4)
+	}
+	return window - reserve
+}
+
+// taskParagraphs splits a task in Markdown into its paragraphs, keeping
+// code blocks whole.
+func taskParagraphs(task string) []string {
+	var paragraphs, lines []string
+	inFence := false
+	for _, line := range strings.Split(strings.TrimSpace(task), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if strings.TrimSpace(line) == "" && !inFence {
+			if len(lines) > 0 {
+				paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+			}
+			lines = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	return paragraphs
+}
+
+// stripFlavorText removes the story around a task: paragraphs that are
+// neither a heading, a list or a code block nor match essentialText. The
+// last paragraph, which asks the question, is always kept.
+func stripFlavorText(task string) string {
+	paragraphs := taskParagraphs(task)
+	var kept []string
+	for i, p := range paragraphs {
+		if i == len(paragraphs)-1 || strings.HasPrefix(p, "#") || strings.HasPrefix(p, "--- ") || listItem.MatchString(p) || essentialText.MatchString(p) {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
@@ -0,0 +1,68 @@
+package aocgen
+
+import "testing"
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model     string
+		overrides map[string]int
+		want      int
+	}{
+		{"gpt-4o-mini", nil, 128000},
+		{"gpt-4", nil, 8192},
+		{"groq/llama-3.1-70b-versatile", nil, 131072},
+		{"ollama/llama3", nil, 4096},
+		{"ollama/llama3", map[string]int{"ollama/llama3": 8192}, 8192},
+		{"unknown-model", nil, defaultContextWindow},
+	}
+	for _, tt := range tests {
+		if got := contextWindow(tt.model, tt.overrides); got != tt.want {
+			t.Errorf("contextWindow(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestPromptBudget(t *testing.T) {
+	if got := promptBudget(Flags{Model: "ollama/llama3"}, Config{}); got != 3072 {
+		t.Errorf("Expected a quarter of a small window kept for the completion, got %d", got)
+	}
+	if got := promptBudget(Flags{Model: "gpt-4o", Sampling: Sampling{MaxTokens: 1000}}, Config{}); got != 127000 {
+		t.Errorf("Expected --max-tokens kept for the completion, got %d", got)
+	}
+	if got := promptBudget(Flags{Model: "gpt-4o"}, Config{ContextWindows: map[string]int{"gpt-4o": 64000}}); got != 64000-defaultCompletionReserve {
+		t.Errorf("Expected the configured window, got %d", got)
+	}
+}
+
+func TestContextStrategy(t *testing.T) {
+	overrides := map[string]string{"ollama/": contextWarn, "gpt-4": "summarize"}
+	if got, err := contextStrategy("ollama/llama3", overrides); err != nil || got != contextWarn {
+		t.Errorf("Expected the configured strategy, got %q, %v", got, err)
+	}
+	if got, err := contextStrategy("mistral-small", overrides); err != nil || got != contextTruncate {
+		t.Errorf("Expected truncate by default, got %q, %v", got, err)
+	}
+	if _, err := contextStrategy("gpt-4o", overrides); err == nil {
+		t.Errorf("Expected an error for an unknown strategy")
+	}
+}
+
+func TestStripFlavorText(t *testing.T) {
+	task := "--- Day 1: Not Quite Lisp ---\n\n" +
+		"Santa was hoping for a white Christmas, but his weather machine's \"snow\" function is powered by stars.\n\n" +
+		"Here's an easy puzzle to warm you up.\n\n" +
+		"An opening parenthesis, `(`, means he should go up one floor.\n\n" +
+		"For example:\n\n" +
+		"```\n(())\n\n()()\n```\n\n" +
+		"- `(((` results in floor `3`.\n\n" +
+		"To what floor do the instructions take Santa?"
+	want := "--- Day 1: Not Quite Lisp ---\n\n" +
+		"An opening parenthesis, `(`, means he should go up one floor.\n\n" +
+		"For example:\n\n" +
+		"```\n(())\n\n()()\n```\n\n" +
+		"- `(((` results in floor `3`.\n\n" +
+		"To what floor do the instructions take Santa?"
+	if got := stripFlavorText(task); got != want {
+		t.Errorf("stripFlavorText() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,483 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const datasetMetaFile = "dataset.json"
+
+// datasetShardsDir holds the downloaded parquet shards of the dataset.
+// Caches from before shards were supported have a single datasetParquet.
+const datasetShardsDir = "dataset"
+
+// datasetShardsURL lists the parquet shards of the dataset's train split as a
+// JSON array of URLs.
+var datasetShardsURL = "https://huggingface.co/api/datasets/isavita/advent-of-code/parquet/default/train"
+
+// errNotModified is returned by downloadFile when the server answers a
+// conditional request with 304 Not Modified.
+var errNotModified = errors.New("not modified")
+
+// datasetMeta describes the downloaded dataset, so `setup --update` can ask
+// the server whether its shards changed and tell whether their content did.
+type datasetMeta struct {
+	Shards    []shardMeta `json:"shards"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// shardMeta describes one downloaded parquet shard.
+type shardMeta struct {
+	URL          string `json:"url"`
+	File         string `json:"file"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+// shard returns the metadata of the shard stored in file.
+func (meta datasetMeta) shard(file string) (shardMeta, bool) {
+	for _, s := range meta.Shards {
+		if s.File == file {
+			return s, true
+		}
+	}
+	return shardMeta{}, false
+}
+
+// loadDatasetMeta reads the metadata of the downloaded dataset. A dataset
+// downloaded before the metadata was recorded yields empty metadata.
+func loadDatasetMeta() (datasetMeta, error) {
+	var meta datasetMeta
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), datasetMetaFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, nil
+		}
+		return meta, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("error parsing dataset metadata: %v", err)
+	}
+	return meta, nil
+}
+
+func saveDatasetMeta(meta datasetMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getCacheDir(), datasetMetaFile), data, 0644)
+}
+
+// listDatasetShards returns the URLs of the dataset's parquet shards. They
+// are read from manifest, a file or URL with one URL per line or a JSON
+// array of them, or else asked from HuggingFace.
+func listDatasetShards(ctx context.Context, timeout time.Duration, manifest string) ([]string, error) {
+	source := manifest
+	if source == "" {
+		source = datasetShardsURL
+	}
+
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		ctx, cancel := withHTTPTimeout(ctx, timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, "GET", source, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, httpError(ctx, timeout, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, httpError(ctx, timeout, err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			return nil, err
+		}
+	}
+
+	var urls []string
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &urls); err != nil {
+			return nil, fmt.Errorf("error parsing shard list: %v", err)
+		}
+	} else {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				urls = append(urls, line)
+			}
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no parquet shards listed in %s", source)
+	}
+	return urls, nil
+}
+
+// datasetFiles returns the parquet files of the downloaded dataset in shard
+// order.
+func datasetFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(getCacheDir(), datasetShardsDir, "*.parquet"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		sort.Strings(files)
+		return files, nil
+	}
+	legacy := filepath.Join(getCacheDir(), datasetParquet)
+	if _, err := os.Stat(legacy); err != nil {
+		return nil, err
+	}
+	return []string{legacy}, nil
+}
+
+// readDataset reads and concatenates the challenges of all dataset shards,
+// reporting progress to w.
+func readDataset(w io.Writer) ([]Challenge, error) {
+	files, err := datasetFiles()
+	if err != nil {
+		return nil, err
+	}
+	var challenges []Challenge
+	for _, path := range files {
+		shard, err := readParquetFile(path, w)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+		}
+		challenges = append(challenges, shard...)
+	}
+	return challenges, nil
+}
+
+// conditionalHeader returns the headers that make the server skip sending
+// the shard again when it did not change since it was downloaded.
+func (s shardMeta) conditionalHeader() http.Header {
+	header := http.Header{}
+	if s.ETag != "" {
+		header.Set("If-None-Match", s.ETag)
+	}
+	if s.LastModified != "" {
+		header.Set("If-Modified-Since", s.LastModified)
+	}
+	return header
+}
+
+// fileSHA256 returns the hex encoded SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// downloadFile downloads url to path and returns the response headers. The
+// headers in header are sent with the request; if the server answers a
+// conditional one with 304, path is left alone and errNotModified returned.
+//
+// The file is written to path.part first, so a failed download keeps the
+// previous copy. The partial file is kept on failure, with the ETag or
+// Last-Modified time of the response in path.part.validator, and the next call
+// asks the server for only the rest with a Range request, unless the file
+// changed since. If the server names the file's SHA-256, the finished file is
+// checked against it. A progress bar is
+// drawn on progress unless it is nil.
+func downloadFile(ctx context.Context, timeout time.Duration, path string, url string, header http.Header, progress io.Writer) (http.Header, error) {
+	ctx, cancel := withHTTPTimeout(ctx, timeout)
+	defer cancel()
+
+	tmp := path + ".part"
+	validatorFile := tmp + ".validator"
+	var offset int64
+	var validator string
+	if info, err := os.Stat(tmp); err == nil {
+		// Without the validator of the partial file there is no telling
+		// whether the server still has the same file, so start over
+		if data, err := os.ReadFile(validatorFile); err == nil && len(data) > 0 {
+			offset, validator = info.Size(), string(data)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// A file that changed since is sent in full instead of appended
+		req.Header.Set("If-Range", validator)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, httpError(ctx, timeout, err)
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return resp.Header, errNotModified
+	case http.StatusOK:
+		offset = 0
+		if validator := resumeValidator(resp.Header); validator != "" {
+			if err := os.WriteFile(validatorFile, []byte(validator), 0644); err != nil {
+				return nil, err
+			}
+		} else {
+			os.Remove(validatorFile)
+		}
+	case http.StatusPartialContent:
+		flag = os.O_WRONLY | os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is not a prefix of the current one; start over
+		os.Remove(tmp)
+		os.Remove(validatorFile)
+		return downloadFile(ctx, timeout, path, url, header, progress)
+	default:
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	out, err := os.OpenFile(tmp, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	var w io.Writer = out
+	var bar *progressWriter
+	if progress != nil {
+		total := resp.ContentLength
+		if total >= 0 {
+			total += offset
+		}
+		bar = newProgressWriter(progress, filepath.Base(path), offset, total)
+		w = io.MultiWriter(out, bar)
+	}
+	_, err = io.Copy(w, resp.Body)
+	if bar != nil {
+		bar.Finish()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, httpError(ctx, timeout, err)
+	}
+
+	if expected := expectedSHA256(resp.Header); expected != "" {
+		sum, err := fileSHA256(tmp)
+		if err != nil {
+			return nil, err
+		}
+		if sum != expected {
+			os.Remove(tmp)
+			os.Remove(validatorFile)
+			return nil, fmt.Errorf("checksum mismatch for %s: got sha256 %s, expected %s", filepath.Base(path), sum, expected)
+		}
+	}
+	os.Remove(validatorFile)
+	return resp.Header, os.Rename(tmp, path)
+}
+
+// resumeValidator returns the value of If-Range that resumes a download of the
+// response with header only if the file is unchanged: its strong ETag, or else
+// its Last-Modified time. It returns "" if there is neither.
+func resumeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// expectedSHA256 returns the SHA-256 a response names for its file, or ""
+// if it names none. HuggingFace serves LFS files with their SHA-256 as the
+// ETag, or as X-Linked-Etag when redirecting to a CDN.
+func expectedSHA256(header http.Header) string {
+	for _, name := range []string{"X-Linked-Etag", "ETag"} {
+		etag := strings.Trim(strings.TrimPrefix(header.Get(name), "W/"), `"`)
+		if len(etag) != sha256.Size*2 {
+			continue
+		}
+		if _, err := hex.DecodeString(etag); err == nil {
+			return strings.ToLower(etag)
+		}
+	}
+	return ""
+}
+
+// mergeDatasetChallenges adds the dataset challenges that are not stored
+// locally yet, matched by name and solution language. Local records win, so
+// solutions and answers of your own are kept. It returns the merged
+// challenges and how many were added.
+func mergeDatasetChallenges(local, dataset []Challenge) ([]Challenge, int) {
+	key := func(c Challenge) string { return c.Name + "\x00" + strings.ToLower(c.SolutionLang) }
+	seen := make(map[string]bool, len(local))
+	for _, c := range local {
+		seen[key(c)] = true
+	}
+
+	added := 0
+	for _, c := range dataset {
+		if seen[key(c)] {
+			continue
+		}
+		seen[key(c)] = true
+		local = append(local, c)
+		added++
+	}
+	return local, added
+}
+
+// downloadShards downloads every shard in urls into the shards directory
+// and removes shards that are no longer listed. With update set, a shard is
+// only downloaded if the server reports it changed. It returns the metadata
+// of the shards and whether any content differs from prior.
+func downloadShards(ctx context.Context, timeout time.Duration, urls []string, prior datasetMeta, update bool) (datasetMeta, bool, error) {
+	dir := filepath.Join(getCacheDir(), datasetShardsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return datasetMeta{}, false, err
+	}
+
+	meta := datasetMeta{UpdatedAt: time.Now()}
+	changed := len(urls) != len(prior.Shards)
+	keep := make(map[string]bool)
+	for i, url := range urls {
+		file := fmt.Sprintf("%04d.parquet", i)
+		path := filepath.Join(dir, file)
+		keep[file] = true
+
+		old, known := prior.shard(file)
+		header := http.Header{}
+		if _, err := os.Stat(path); update && err == nil && known && old.URL == url {
+			header = old.conditionalHeader()
+		}
+
+		logger.Info(fmt.Sprintf("Downloading shard %d of %d...", i+1, len(urls)))
+		respHeader, err := downloadFile(ctx, timeout, path, url, header, os.Stderr)
+		if errors.Is(err, errNotModified) {
+			meta.Shards = append(meta.Shards, old)
+			continue
+		}
+		if err != nil {
+			return datasetMeta{}, false, fmt.Errorf("shard %d: %v", i+1, err)
+		}
+
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return datasetMeta{}, false, fmt.Errorf("error hashing shard %d: %v", i+1, err)
+		}
+		if !known || sum != old.SHA256 {
+			changed = true
+		}
+		meta.Shards = append(meta.Shards, shardMeta{
+			URL:          url,
+			File:         file,
+			ETag:         respHeader.Get("ETag"),
+			LastModified: respHeader.Get("Last-Modified"),
+			SHA256:       sum,
+		})
+	}
+
+	stale, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+	if err != nil {
+		return datasetMeta{}, false, err
+	}
+	for _, path := range stale {
+		if !keep[filepath.Base(path)] {
+			os.Remove(path)
+			changed = true
+		}
+	}
+	// The single file of caches from before shards were supported
+	os.Remove(filepath.Join(getCacheDir(), datasetParquet))
+	return meta, changed, nil
+}
+
+// setupDataset downloads all parquet shards of the public dataset into the
+// cache and stores their challenges. The shards are listed by --manifest or
+// by HuggingFace. Each request is cancelled with ctx or after --http-timeout.
+//
+// With --update, shards are only downloaded if they changed since the last
+// setup, an unchanged dataset is not processed again, and new challenges are
+// merged into the stored ones instead of replacing them.
+func setupDataset(ctx context.Context, flags Flags) error {
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+	prior, err := loadDatasetMeta()
+	if err != nil {
+		return err
+	}
+
+	urls, err := listDatasetShards(ctx, flags.HTTPTimeout, flags.Manifest)
+	if err != nil {
+		return fmt.Errorf("error listing dataset shards: %v", err)
+	}
+	meta, changed, err := downloadShards(ctx, flags.HTTPTimeout, urls, prior, flags.Update)
+	if err != nil {
+		return fmt.Errorf("error downloading dataset: %v", err)
+	}
+	if flags.Update && !changed {
+		if err := saveDatasetMeta(meta); err != nil {
+			return fmt.Errorf("error saving dataset metadata: %v", err)
+		}
+		fmt.Println("Dataset is up to date.")
+		return nil
+	}
+
+	logger.Info("Processing dataset...")
+	challenges, err := readDataset(os.Stdout)
+	if err != nil {
+		return fmt.Errorf("error processing dataset: %v", err)
+	}
+
+	if flags.Update {
+		local, err := loadChallenges(getCacheDir(), challengesFile)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error loading challenges: %v", err)
+		}
+		var added int
+		challenges, added = mergeDatasetChallenges(local, challenges)
+		logger.Info(fmt.Sprintf("Adding %d new challenges...", added))
+	}
+
+	logger.Info("Saving challenges...")
+	if err := saveChallenges(challenges); err != nil {
+		return fmt.Errorf("error saving challenges: %v", err)
+	}
+	if err := saveDatasetMeta(meta); err != nil {
+		return fmt.Errorf("error saving dataset metadata: %v", err)
+	}
+
+	fmt.Println("Setup complete!")
+	return nil
+}
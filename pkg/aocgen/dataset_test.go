@@ -0,0 +1,410 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+func TestMergeDatasetChallenges(t *testing.T) {
+	local := []Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "go", Solution: "mine"},
+		{Name: "day2_part1_2015", SolutionLang: "python", Solution: "mine"},
+	}
+	dataset := []Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "Go", Solution: "theirs"},
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: "theirs"},
+		{Name: "day3_part1_2015", SolutionLang: "go", Solution: "theirs"},
+	}
+
+	merged, added := mergeDatasetChallenges(local, dataset)
+	if added != 2 || len(merged) != 4 {
+		t.Fatalf("Expected 2 added of 4 challenges, got %d of %d", added, len(merged))
+	}
+	if merged[0].Solution != "mine" || merged[1].Solution != "mine" {
+		t.Errorf("Expected local challenges to be kept, got %+v", merged[:2])
+	}
+	if merged[2].Name != "day1_part1_2015" || merged[2].SolutionLang != "python" || merged[3].Name != "day3_part1_2015" {
+		t.Errorf("Expected the new challenges to be appended, got %+v", merged[2:])
+	}
+}
+
+func TestListDatasetShards(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`["https://example.com/0.parquet","https://example.com/1.parquet"]`))
+	}))
+	defer server.Close()
+	oldURL := datasetShardsURL
+	datasetShardsURL = server.URL
+	defer func() { datasetShardsURL = oldURL }()
+
+	urls, err := listDatasetShards(context.Background(), time.Second, "")
+	if err != nil || len(urls) != 2 || urls[1] != "https://example.com/1.parquet" {
+		t.Errorf("Expected the shards listed by the API, got %v, %v", urls, err)
+	}
+
+	manifest := filepath.Join(t.TempDir(), "shards.txt")
+	os.WriteFile(manifest, []byte("# train split\nhttps://example.com/a.parquet\n\nhttps://example.com/b.parquet\n"), 0644)
+	urls, err = listDatasetShards(context.Background(), time.Second, manifest)
+	if err != nil || len(urls) != 2 || urls[0] != "https://example.com/a.parquet" {
+		t.Errorf("Expected the shards of the manifest, got %v, %v", urls, err)
+	}
+
+	os.WriteFile(manifest, []byte("\n"), 0644)
+	if _, err := listDatasetShards(context.Background(), time.Second, manifest); err == nil {
+		t.Errorf("Expected an error for an empty manifest")
+	}
+}
+
+// datasetServer serves parquet shards and lists them at /shards. A request
+// carrying a shard's current ETag gets 304.
+type datasetServer struct {
+	*httptest.Server
+	shards    [][]Challenge
+	etags     []string
+	downloads int
+}
+
+func newDatasetServer(t *testing.T) *datasetServer {
+	s := &datasetServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/shards" {
+			var urls []string
+			for i := range s.shards {
+				urls = append(urls, fmt.Sprintf("%s/%d.parquet", s.URL, i))
+			}
+			json.NewEncoder(w).Encode(urls)
+			return
+		}
+		var i int
+		if _, err := fmt.Sscanf(r.URL.Path, "/%d.parquet", &i); err != nil || i >= len(s.shards) {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == s.etags[i] {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.downloads++
+		w.Header().Set("ETag", s.etags[i])
+		writeParquet(w, s.shards[i])
+	}))
+	t.Cleanup(s.Close)
+
+	oldURL := datasetShardsURL
+	datasetShardsURL = s.URL + "/shards"
+	t.Cleanup(func() { datasetShardsURL = oldURL })
+	return s
+}
+
+func TestSetupDatasetShards(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := newDatasetServer(t)
+	server.shards = [][]Challenge{
+		{{Name: "day1_part1_2015", Input: "(()", Solution: "print(1)", SolutionLang: "python", Year: 2015, Answer: "1"}},
+		{{Name: "day2_part1_2015", Input: "2x3x4", Solution: "print(58)", SolutionLang: "python", Year: 2015, Answer: "58"}},
+	}
+	server.etags = []string{`"a"`, `"b"`}
+	writeTestDataset(t, filepath.Join(tempDir, datasetParquet), nil)
+
+	if err := setupDataset(context.Background(), Flags{HTTPTimeout: time.Second}); err != nil {
+		t.Fatalf("Failed to set up dataset: %v", err)
+	}
+	challenges, err := loadChallenges(tempDir, challengesFile)
+	if err != nil || len(challenges) != 2 || challenges[1].Name != "day2_part1_2015" {
+		t.Fatalf("Expected the rows of both shards, got %+v, %v", challenges, err)
+	}
+	if files, _ := datasetFiles(); len(files) != 2 {
+		t.Errorf("Expected two shard files, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, datasetParquet)); !os.IsNotExist(err) {
+		t.Errorf("Expected the single-file dataset to be removed, got %v", err)
+	}
+
+	server.shards = server.shards[:1]
+	if err := setupDataset(context.Background(), Flags{HTTPTimeout: time.Second}); err != nil {
+		t.Fatalf("Failed to set up dataset: %v", err)
+	}
+	if files, _ := datasetFiles(); len(files) != 1 {
+		t.Errorf("Expected the unlisted shard to be removed, got %v", files)
+	}
+}
+
+func TestSetupDatasetUpdate(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := newDatasetServer(t)
+	server.shards = [][]Challenge{
+		{{Name: "day1_part1_2015", Input: "(()", Task: "Find the floor.", Solution: "print(1)", SolutionLang: "python", Year: 2015, Answer: "1"}},
+	}
+	server.etags = []string{`"v1"`}
+	flags := Flags{HTTPTimeout: time.Second}
+
+	if err := setupDataset(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to set up dataset: %v", err)
+	}
+	meta, err := loadDatasetMeta()
+	if err != nil || len(meta.Shards) != 1 || meta.Shards[0].ETag != `"v1"` || meta.Shards[0].SHA256 == "" {
+		t.Fatalf("Expected the ETag and hash to be recorded, got %+v, %v", meta, err)
+	}
+
+	local := []Challenge{
+		{Name: "day1_part1_2015", Input: "(()", Solution: "print('mine')", SolutionLang: "python", Year: 2015},
+		{Name: "day5_part1_2015", Input: "abc", SolutionLang: "go", Year: 2015},
+	}
+	if err := saveChallenges(local); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	flags.Update = true
+	if err := setupDataset(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to update dataset: %v", err)
+	}
+	if server.downloads != 1 {
+		t.Errorf("Expected an unchanged dataset not to be downloaded again, got %d downloads", server.downloads)
+	}
+
+	// A new ETag with the same content is downloaded but not processed
+	server.etags[0] = `"v2"`
+	if err := setupDataset(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to update dataset: %v", err)
+	}
+	if challenges, _ := loadChallenges(tempDir, challengesFile); len(challenges) != 2 || challenges[0].Solution != "print('mine')" {
+		t.Errorf("Expected the local challenges to be untouched, got %+v", challenges)
+	}
+
+	server.shards = append(server.shards, []Challenge{
+		{Name: "day2_part1_2015", Input: "2x3x4", Task: "Wrap presents.", Solution: "print(58)", SolutionLang: "python", Year: 2015, Answer: "58"},
+	})
+	server.etags = append(server.etags, `"w1"`)
+	if err := setupDataset(context.Background(), flags); err != nil {
+		t.Fatalf("Failed to update dataset: %v", err)
+	}
+
+	challenges, err := loadChallenges(tempDir, challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	if len(challenges) != 3 {
+		t.Fatalf("Expected the new shard's row to be merged, got %+v", challenges)
+	}
+	if challenges[0].Solution != "print('mine')" || challenges[1].Name != "day5_part1_2015" || challenges[2].Name != "day2_part1_2015" {
+		t.Errorf("Expected local challenges kept and the new one added, got %+v", challenges)
+	}
+	if meta, _ := loadDatasetMeta(); len(meta.Shards) != 2 || meta.Shards[0].ETag != `"v2"` {
+		t.Errorf("Expected both shards to be recorded, got %+v", meta)
+	}
+}
+
+func TestReadParquetFileRowGroups(t *testing.T) {
+	// Columns in a different order than the dataset's, plus one it does not have
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "year", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "difficulty", Type: arrow.BinaryTypes.String},
+		{Name: "answer", Type: arrow.BinaryTypes.String},
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "solution_lang", Type: arrow.BinaryTypes.String},
+		{Name: "task", Type: arrow.BinaryTypes.String},
+		{Name: "input", Type: arrow.BinaryTypes.String},
+		{Name: "solution", Type: arrow.BinaryTypes.String},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	for day := 1; day <= 5; day++ {
+		builder.Field(0).(*array.Int64Builder).Append(2015)
+		builder.Field(1).(*array.StringBuilder).Append("easy")
+		builder.Field(2).(*array.StringBuilder).Append(fmt.Sprint(day * 10))
+		builder.Field(3).(*array.StringBuilder).Append(fmt.Sprintf("day%d_part1_2015", day))
+		builder.Field(4).(*array.StringBuilder).Append("go")
+		builder.Field(5).(*array.StringBuilder).Append("task")
+		builder.Field(6).(*array.StringBuilder).Append("input")
+		builder.Field(7).(*array.StringBuilder).Append("solution")
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+
+	path := filepath.Join(t.TempDir(), "shard.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create parquet file: %v", err)
+	}
+	// Two rows per row group
+	if err := pqarrow.WriteTable(table, f, 2, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps()); err != nil {
+		t.Fatalf("Failed to write parquet file: %v", err)
+	}
+	f.Close()
+
+	var out bytes.Buffer
+	challenges, err := readParquetFile(path, &out)
+	if err != nil {
+		t.Fatalf("Failed to read parquet file: %v", err)
+	}
+	if len(challenges) != 5 {
+		t.Fatalf("Expected 5 challenges, got %d", len(challenges))
+	}
+	expected := Challenge{Name: "day4_part1_2015", Solution: "solution", Input: "input", Task: "task", SolutionLang: "go", Year: 2015, Answer: "40"}
+	if !reflect.DeepEqual(challenges[3], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, challenges[3])
+	}
+	if !strings.Contains(out.String(), "Processed row group 3 of 3") {
+		t.Errorf("Expected progress per row group, got %q", out.String())
+	}
+}
+
+func TestResolveDatasetColumns(t *testing.T) {
+	field := func(name string, typ arrow.DataType) arrow.Field { return arrow.Field{Name: name, Type: typ} }
+	str, i64 := arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int64
+
+	schema := arrow.NewSchema([]arrow.Field{
+		field("extra", str), field("year", i64), field("task", str), field("name", str),
+		field("solution", str), field("input", str), field("solution_lang", str),
+	}, nil)
+	columns, err := resolveDatasetColumns(schema)
+	if err != nil {
+		t.Fatalf("Expected a file without answers to be accepted, got %v", err)
+	}
+	if fmt.Sprint(columns) != "[1 2 3 4 5 6]" {
+		t.Errorf("Expected the dataset columns without the extra one, got %v", columns)
+	}
+
+	schema = arrow.NewSchema([]arrow.Field{field("name", str), field("input", str), field("year", i64)}, nil)
+	if _, err := resolveDatasetColumns(schema); err == nil || !strings.Contains(err.Error(), "solution, task, solution_lang") {
+		t.Errorf("Expected the missing columns to be named, got %v", err)
+	}
+
+	schema = arrow.NewSchema([]arrow.Field{
+		field("name", str), field("solution", str), field("input", str), field("task", str),
+		field("solution_lang", str), field("year", str),
+	}, nil)
+	if _, err := resolveDatasetColumns(schema); err == nil || !strings.Contains(err.Error(), `"year"`) {
+		t.Errorf("Expected an error for a string year column, got %v", err)
+	}
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	content := bytes.Repeat([]byte("advent of code "), 1000)
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	var ranges []string
+	var statuses []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		rec := httptest.NewRecorder()
+		rec.Header().Set("ETag", etag)
+		http.ServeContent(rec, r, "shard.parquet", time.Time{}, bytes.NewReader(content))
+		statuses = append(statuses, rec.Code)
+		for name, values := range rec.Header() {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "0000.parquet")
+	os.WriteFile(path+".part", content[:4000], 0644)
+	os.WriteFile(path+".part.validator", []byte(etag), 0644)
+	var progress bytes.Buffer
+	if _, err := downloadFile(context.Background(), time.Second, path, server.URL, nil, &progress); err != nil {
+		t.Fatalf("Failed to resume download: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, content) {
+		t.Errorf("Expected the resumed file to match, got %d bytes", len(data))
+	}
+	if ranges[0] != "bytes=4000-" || statuses[0] != http.StatusPartialContent {
+		t.Errorf("Expected only the rest to be requested, got %q with status %d", ranges[0], statuses[0])
+	}
+	if !strings.Contains(progress.String(), "100%") {
+		t.Errorf("Expected the progress to count the resumed bytes, got %q", progress.String())
+	}
+
+	if _, err := os.Stat(path + ".part.validator"); !os.IsNotExist(err) {
+		t.Errorf("Expected the validator to be removed with the partial file, got %v", err)
+	}
+
+	// A partial file of a shard that changed since is started over
+	ranges, statuses = nil, nil
+	os.WriteFile(path+".part", content[:4000], 0644)
+	os.WriteFile(path+".part.validator", []byte(`"old"`), 0644)
+	os.Remove(path)
+	if _, err := downloadFile(context.Background(), time.Second, path, server.URL, nil, nil); err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, content) || statuses[0] != http.StatusOK {
+		t.Errorf("Expected the changed shard to be downloaded in full, got %d bytes with status %v", len(data), statuses)
+	}
+
+	// So is a partial file without a validator
+	ranges = nil
+	os.WriteFile(path+".part", content[:4000], 0644)
+	if _, err := downloadFile(context.Background(), time.Second, path, server.URL, nil, nil); err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	if ranges[0] != "" {
+		t.Errorf("Expected no range without a validator, got %q", ranges[0])
+	}
+
+	// A partial file longer than the current one is started over
+	ranges = nil
+	os.WriteFile(path+".part", append(content, content...), 0644)
+	os.WriteFile(path+".part.validator", []byte(etag), 0644)
+	if _, err := downloadFile(context.Background(), time.Second, path, server.URL, nil, nil); err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	if len(ranges) != 2 || ranges[1] != "" {
+		t.Errorf("Expected a full download after the range was refused, got %q", ranges)
+	}
+
+	etag = `"` + strings.Repeat("0", 64) + `"`
+	if _, err := downloadFile(context.Background(), time.Second, path, server.URL, nil, nil); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("Expected the corrupt download to be removed, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, content) {
+		t.Errorf("Expected the previous file to be kept")
+	}
+}
+
+func TestExpectedSHA256(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	tests := []struct {
+		header   http.Header
+		expected string
+	}{
+		{http.Header{"Etag": {`"` + sum + `"`}}, sum},
+		{http.Header{"Etag": {`W/"` + strings.ToUpper(sum) + `"`}}, sum},
+		{http.Header{"X-Linked-Etag": {`"` + sum + `"`}, "Etag": {`"abc"`}}, sum},
+		{http.Header{"Etag": {`"abc-123"`}}, ""},
+		{http.Header{"Etag": {`"` + strings.Repeat("zz", 32) + `"`}}, ""},
+		{http.Header{}, ""},
+	}
+	for _, test := range tests {
+		if got := expectedSHA256(test.header); got != test.expected {
+			t.Errorf("expectedSHA256(%v) = %q, expected %q", test.header, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// DiffSide is one of the two solutions compared by `aocgen diff`.
+type DiffSide struct {
+	Label   string  `json:"label"`
+	Model   string  `json:"model,omitempty"`
+	Attempt int     `json:"attempt,omitempty"`
+	Verdict Verdict `json:"verdict,omitempty"`
+	Code    string  `json:"-"`
+}
+
+// DiffReport is the --json form of `aocgen diff`.
+type DiffReport struct {
+	Challenge string   `json:"challenge"`
+	Lang      string   `json:"lang"`
+	From      DiffSide `json:"from"`
+	To        DiffSide `json:"to"`
+	Identical bool     `json:"identical"`
+	Diff      string   `json:"diff"`
+}
+
+// resolveDiffSide finds the code spec refers to among the attempts of a
+// challenge in one language: an attempt number, "solution" for the stored
+// solution, or a model name for the latest attempt of that model.
+func resolveDiffSide(spec, name string, attempts []Attempt, flags Flags) (DiffSide, error) {
+	if number, err := strconv.Atoi(spec); err == nil {
+		for _, a := range attempts {
+			if a.Number == number {
+				return DiffSide{Label: fmt.Sprintf("attempt #%d", a.Number), Model: a.Model, Attempt: a.Number, Verdict: a.Verdict, Code: a.Code}, nil
+			}
+		}
+		return DiffSide{}, fmt.Errorf("attempt %d of %s in %s not found", number, name, flags.Lang)
+	}
+
+	if spec == "solution" {
+		challenges, err := loadChallenges(getCacheDir(), challengesFile)
+		if err != nil && !os.IsNotExist(err) {
+			return DiffSide{}, fmt.Errorf("error loading challenges: %v", err)
+		}
+		for _, c := range challenges {
+			if c.Name == name && strings.EqualFold(c.SolutionLang, flags.Lang) && c.Solution != "" {
+				return DiffSide{Label: "stored solution", Verdict: VerdictCorrect, Code: c.Solution}, nil
+			}
+		}
+		return DiffSide{}, fmt.Errorf("no %s solution of %s stored", flags.Lang, name)
+	}
+
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if a := attempts[i]; strings.EqualFold(a.Model, spec) {
+			return DiffSide{Label: fmt.Sprintf("attempt #%d", a.Number), Model: a.Model, Attempt: a.Number, Verdict: a.Verdict, Code: a.Code}, nil
+		}
+	}
+	return DiffSide{}, fmt.Errorf("no attempt of %s in %s by %s", name, flags.Lang, spec)
+}
+
+func (s DiffSide) describe(name, lang string) string {
+	var details []string
+	if s.Model != "" {
+		details = append(details, s.Model)
+	}
+	if s.Verdict != "" {
+		details = append(details, string(s.Verdict))
+	}
+	label := fmt.Sprintf("%s %s %s", name, lang, s.Label)
+	if len(details) > 0 {
+		label += " (" + strings.Join(details, ", ") + ")"
+	}
+	return label
+}
+
+// runDiffCommand compares the code of two attempts, or an attempt and the
+// stored solution, of a challenge as a unified diff.
+func runDiffCommand(from, to string, flags Flags, w io.Writer) error {
+	if flags.Day == 0 || flags.Year == 0 || flags.Lang == "" {
+		return fmt.Errorf("--day, --year and --lang are required")
+	}
+	if flags.Part == 0 {
+		flags.Part = 1
+	}
+	name := fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+
+	attempts, err := loadAttempts()
+	if err != nil {
+		return err
+	}
+	attempts = filterAttempts(attempts, flags)
+
+	report := DiffReport{Challenge: name, Lang: flags.Lang}
+	if report.From, err = resolveDiffSide(from, name, attempts, flags); err != nil {
+		return err
+	}
+	if report.To, err = resolveDiffSide(to, name, attempts, flags); err != nil {
+		return err
+	}
+
+	fromCode, toCode := report.From.Code, report.To.Code
+	equal := func(a, b string) bool { return a == b }
+	if flags.IgnoreFormatting {
+		// Formatting both sides the same way leaves only changes to the code;
+		// without a formatter, whitespace is ignored
+		if formatted, err := formatCode(flags.Lang, fromCode); err == nil {
+			fromCode = formatted
+		}
+		if formatted, err := formatCode(flags.Lang, toCode); err == nil {
+			toCode = formatted
+		}
+		equal = func(a, b string) bool {
+			return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ")
+		}
+	}
+
+	hunks := unifiedDiff(splitLines(fromCode), splitLines(toCode), diffContext, equal)
+	report.Identical = len(hunks) == 0
+	if !report.Identical {
+		var diff strings.Builder
+		fmt.Fprintf(&diff, "--- %s\n+++ %s\n", report.From.describe(name, flags.Lang), report.To.describe(name, flags.Lang))
+		for _, line := range hunks {
+			fmt.Fprintln(&diff, line)
+		}
+		report.Diff = diff.String()
+	}
+
+	if flags.JSON {
+		return emitJSON(report)
+	}
+	if report.Identical {
+		fmt.Fprintf(w, "The %s and the %s of %s are identical.\n", report.From.Label, report.To.Label, name)
+		return nil
+	}
+	fmt.Fprint(w, report.Diff)
+	return nil
+}
+
+func splitLines(code string) []string {
+	code = strings.TrimRight(code, "\n")
+	if code == "" {
+		return nil
+	}
+	return strings.Split(code, "\n")
+}
+
+// unifiedDiff returns the hunks of a unified diff from a to b with context
+// unchanged lines around each change, or nothing if the lines are equal.
+// Lines are matched with a longest common subsequence, which is fast enough
+// for solutions of a few hundred lines.
+func unifiedDiff(a, b []string, context int, equal func(x, y string) bool) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if equal(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Each edit is a line prefixed with ' ', '-' or '+', with its line
+	// numbers in a and b
+	type edit struct {
+		op   byte
+		line string
+		i, j int
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && equal(a[i], b[j]):
+			edits = append(edits, edit{' ', b[j], i, j})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	var out []string
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+		// A hunk spans changes that are at most 2*context lines apart
+		first := max(start-context, 0)
+		end := start
+		for k := start; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				end = k
+			} else if k-end > 2*context {
+				break
+			}
+		}
+		last := min(end+context, len(edits)-1)
+
+		var lines []string
+		fromCount, toCount := 0, 0
+		for _, e := range edits[first : last+1] {
+			if e.op != '+' {
+				fromCount++
+			}
+			if e.op != '-' {
+				toCount++
+			}
+			lines = append(lines, string(e.op)+e.line)
+		}
+		fromStart, toStart := edits[first].i+1, edits[first].j+1
+		if fromCount == 0 {
+			fromStart--
+		}
+		if toCount == 0 {
+			toStart--
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", fromStart, fromCount, toStart, toCount))
+		out = append(out, lines...)
+		start = last + 1
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	b := []string{"a", "B", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	got := strings.Join(unifiedDiff(a, b, 1, func(x, y string) bool { return x == y }), "\n")
+	want := "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -10,1 +10,2 @@\n j\n+k"
+	if got != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if hunks := unifiedDiff(a, a, 3, func(x, y string) bool { return x == y }); len(hunks) != 0 {
+		t.Errorf("Expected no hunks for equal input, got %v", hunks)
+	}
+	if got := unifiedDiff(nil, []string{"x"}, 3, func(x, y string) bool { return x == y }); strings.Join(got, "\n") != "@@ -0,0 +1,1 @@\n+x" {
+		t.Errorf("Unexpected diff from empty input: %v", got)
+	}
+}
+
+func TestRunDiffCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	recordAttempt("day1_part1_2015", "python", "gpt-4o", "x = 1\nprint(x)\n", Usage{})
+	recordAttempt("day1_part1_2015", "python", "ollama/llama3", "x = 2\nprint(x)\n", Usage{})
+	recordAttempt("day1_part1_2015", "python", "gpt-4o", "x  =  2\nprint(x)\n", Usage{})
+	recordAttemptVerdict("day1_part1_2015", "python", "x = 2\nprint(x)\n", EvalResult{Verdict: VerdictCorrect})
+
+	var out bytes.Buffer
+	if err := runDiffCommand("1", "ollama/llama3", Flags{Day: 1, Year: 2015, Lang: "python"}, &out); err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	for _, want := range []string{
+		"--- day1_part1_2015 python attempt #1 (gpt-4o)",
+		"+++ day1_part1_2015 python attempt #2 (ollama/llama3, correct)",
+		"-x = 1\n+x = 2\n print(x)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runDiffCommand("2", "gpt-4o", Flags{Day: 1, Year: 2015, Lang: "python", IgnoreFormatting: true}, &out); err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "identical") {
+		t.Errorf("Expected attempts differing in whitespace to be identical, got:\n%s", out.String())
+	}
+
+	data := captureJSON(t, func() error {
+		return runDiffCommand("1", "3", Flags{Day: 1, Year: 2015, Lang: "python", JSON: true}, &out)
+	})
+	var report DiffReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if report.Identical || report.From.Attempt != 1 || report.To.Attempt != 3 || !strings.Contains(report.Diff, "+x  =  2") {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	for _, spec := range []string{"7", "solution", "mistral-large"} {
+		if err := runDiffCommand("1", spec, Flags{Day: 1, Year: 2015, Lang: "python"}, &out); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
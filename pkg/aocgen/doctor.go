@@ -0,0 +1,200 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// doctorTimeout bounds each network check so an unreachable host does not
+// stall the whole report.
+const doctorTimeout = 10 * time.Second
+
+// CheckStatus is the outcome of a single doctor check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "ok"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// DoctorCheck is one line of the doctor checklist.
+type DoctorCheck struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail"`
+}
+
+// runDoctorCommand checks the local environment and prints a pass/fail
+// checklist. It returns an error if any check failed.
+func runDoctorCommand(flags Flags, w io.Writer) error {
+	checks := []DoctorCheck{
+		checkCacheDir(),
+		checkDataset(),
+		checkSession(flags.Session),
+		checkModelAPI(flags.Model, flags.ModelAPI),
+	}
+	checks = append(checks, checkToolchains(flags.Lang)...)
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == CheckFail {
+			failed++
+		}
+	}
+
+	if flags.JSON {
+		if err := emitJSON(checks); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range checks {
+			fmt.Fprintf(w, "[%-4s] %-20s %s\n", c.Status, c.Name, c.Detail)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkCacheDir verifies the cache dir exists, or can be created, and is
+// writable.
+func checkCacheDir() DoctorCheck {
+	check := DoctorCheck{Name: "cache directory"}
+	dir := getCacheDir()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("cannot create %s: %v", dir, err)
+		return check
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("%s is not writable: %v", dir, err)
+		return check
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	check.Status, check.Detail = CheckPass, dir
+	return check
+}
+
+// checkDataset reports how many challenges are stored locally.
+func checkDataset() DoctorCheck {
+	check := DoctorCheck{Name: "challenges"}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			check.Status, check.Detail = CheckFail, "no challenges stored; run 'aocgen setup' or 'aocgen download'"
+		} else {
+			check.Status, check.Detail = CheckFail, fmt.Sprintf("error loading challenges: %v", err)
+		}
+		return check
+	}
+	if len(challenges) == 0 {
+		check.Status, check.Detail = CheckFail, "no challenges stored; run 'aocgen setup' or 'aocgen download'"
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Detail = fmt.Sprintf("%d challenges (%s storage)", len(challenges), storageBackend())
+	if _, err := datasetFiles(); err == nil {
+		check.Detail += ", dataset downloaded"
+	}
+	return check
+}
+
+// checkSession verifies the session cookie by loading the Advent of Code
+// settings page, which redirects to the login page for invalid sessions.
+func checkSession(session string) DoctorCheck {
+	check := DoctorCheck{Name: "session token"}
+	if session == "" {
+		check.Status, check.Detail = CheckFail, "not set; use --session, AOC_SESSION or 'aocgen init'"
+		return check
+	}
+
+	resp, err := requestSettings(commandContext, &http.Client{Timeout: doctorTimeout}, session)
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("cannot reach %s: %v", aocBaseURL, err)
+		return check
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("rejected by Advent of Code (%s); the session may have expired, %s", resp.Status, sessionHelp)
+		return check
+	}
+	check.Status, check.Detail = CheckPass, "valid"
+	return check
+}
+
+// checkModelAPI verifies the configured model API endpoint answers HTTP
+// requests. Any response counts, since most endpoints reject unauthenticated
+// GET requests.
+func checkModelAPI(model, apiURL string) DoctorCheck {
+	check := DoctorCheck{Name: "model API"}
+	if apiURL == "" {
+		apiURL = modelAPIFor(model)
+	}
+	if apiURL == "" {
+		check.Status, check.Detail = CheckSkip, "no model API configured"
+		return check
+	}
+
+	client := &http.Client{Timeout: doctorTimeout}
+	resp, err := client.Get(apiURL)
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("cannot reach %s: %v", apiURL, err)
+		return check
+	}
+	resp.Body.Close()
+
+	check.Status, check.Detail = CheckPass, fmt.Sprintf("%s reachable (%s)", apiURL, resp.Status)
+	return check
+}
+
+// checkToolchains reports the toolchain of the default language, if one is
+// configured, and a summary of the toolchains found on the PATH.
+func checkToolchains(lang string) []DoctorCheck {
+	var checks []DoctorCheck
+	if lang != "" {
+		check := DoctorCheck{Name: "toolchain " + lang}
+		if err := checkToolchain(lang); err != nil {
+			check.Status, check.Detail = CheckFail, err.Error()
+		} else if version, err := toolchainVersion(lang); err != nil {
+			check.Status, check.Detail = CheckFail, err.Error()
+		} else if version != "" {
+			check.Status, check.Detail = CheckPass, "found, "+version
+		} else {
+			check.Status, check.Detail = CheckPass, "found"
+		}
+		checks = append(checks, check)
+	}
+
+	available := detectToolchains()
+	var found, missing []string
+	for _, l := range supportedLanguages() {
+		if available[l] {
+			found = append(found, l)
+		} else {
+			missing = append(missing, l)
+		}
+	}
+
+	summary := DoctorCheck{Name: "toolchains", Status: CheckPass}
+	if len(found) == 0 {
+		summary.Status = CheckFail
+	}
+	summary.Detail = fmt.Sprintf("%d of %d languages available", len(found), len(found)+len(missing))
+	if len(missing) > 0 {
+		summary.Detail += "; missing: " + strings.Join(missing, ", ")
+	}
+	return append(checks, summary)
+}
@@ -0,0 +1,82 @@
+package aocgen
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckSession(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/settings" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "valid" {
+			http.Redirect(w, r, "/auth/login", http.StatusFound)
+			return
+		}
+		w.Write([]byte("settings"))
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	if check := checkSession("valid"); check.Status != CheckPass {
+		t.Errorf("Expected a valid session to pass, got: %+v", check)
+	}
+	if check := checkSession("expired"); check.Status != CheckFail || !strings.Contains(check.Detail, "302") {
+		t.Errorf("Expected a redirected session to fail, got: %+v", check)
+	}
+	if check := checkSession(""); check.Status != CheckFail {
+		t.Errorf("Expected a missing session to fail, got: %+v", check)
+	}
+}
+
+func TestRunDoctorCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	var out bytes.Buffer
+	err := runDoctorCommand(Flags{Model: "gpt-4o-mini", ModelAPI: server.URL, Lang: "go"}, &out)
+	if err == nil {
+		t.Fatalf("Expected failed checks without a dataset or session, got output:\n%s", out.String())
+	}
+
+	output := out.String()
+	for _, expected := range []string{
+		"[ok  ] cache directory",
+		"[fail] challenges",
+		"[fail] session token",
+		"[ok  ] model API",
+		"405 Method Not Allowed",
+		"[ok  ] toolchain go",
+		"toolchains",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected doctor output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if err := saveChallenges([]Challenge{{Name: "day1_part1_2015"}}); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+	if check := checkDataset(); check.Status != CheckPass || !strings.Contains(check.Detail, "1 challenges") {
+		t.Errorf("Expected the stored challenge to be counted, got: %+v", check)
+	}
+}
@@ -0,0 +1,170 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// downloadYear downloads both parts of every day of flags.Year, stopping at the
+// first day that is not unlocked yet. Parts that are already stored are
+// skipped unless --force is set, and part two is skipped while it is still
+// locked. Progress is saved after every day, so an interrupted run loses at
+// most one day. Known answers are copied from the cached dataset at the end.
+func downloadYear(ctx context.Context, flags Flags) ([]DownloadReport, error) {
+	if flags.Session == "" {
+		return nil, fmt.Errorf("session token is required")
+	}
+	if flags.Year == 0 {
+		return nil, fmt.Errorf("--all requires --year")
+	}
+
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	var reports []DownloadReport
+	verified := false
+	for day := 1; day <= 25; day++ {
+		partOne := fmt.Sprintf("day%d_part1_%d", day, flags.Year)
+		partTwo := fmt.Sprintf("day%d_part2_%d", day, flags.Year)
+
+		if !flags.Force && lookupChallenge(challenges, partOne) != nil && lookupChallenge(challenges, partTwo) != nil {
+			fmt.Printf("[%2d/25] day %d: already downloaded\n", day, day)
+			reports = append(reports, DownloadReport{Challenge: partOne}, DownloadReport{Challenge: partTwo})
+			continue
+		}
+
+		if checkUnlocked(flags.Year, day) != nil {
+			fmt.Printf("[%2d/25] day %d: unlocks %s, stopping\n", day, day, unlockTime(flags.Year, day).Local().Format("Jan 2 15:04 MST"))
+			break
+		}
+
+		// The session is checked once, before the first day that is fetched
+		if !verified {
+			if err := verifyCommandSession(ctx, flags); err != nil {
+				return reports, err
+			}
+			verified = true
+		}
+		page, err := fetchDay(ctx, Flags{Year: flags.Year, Day: day, Part: 1, Session: flags.Session, HTTPTimeout: flags.HTTPTimeout})
+		if err == errPuzzleLocked {
+			fmt.Printf("[%2d/25] day %d: not unlocked yet, stopping\n", day, day)
+			break
+		}
+		if err != nil {
+			return reports, fmt.Errorf("error downloading day %d: %v", day, err)
+		}
+
+		status := "downloaded part 1"
+		downloaded := dayChallenges(flags.Year, day, page)
+		for _, challenge := range downloaded {
+			challenges = upsertChallenge(challenges, challenge)
+			reports = append(reports, DownloadReport{Challenge: challenge.Name, Downloaded: true})
+		}
+		if page.PartTwo != "" {
+			status += " and part 2"
+		} else {
+			status += " (part 2 is locked)"
+		}
+
+		if err := saveChallenges(challenges); err != nil {
+			return reports, fmt.Errorf("error saving challenges: %v", err)
+		}
+		for _, challenge := range downloaded {
+			emitEvent(Event{Event: eventDownloaded, Challenge: challenge.Name})
+		}
+		fmt.Printf("[%2d/25] day %d: %s\n", day, day, status)
+	}
+
+	if synced := syncAnswersFromDataset(challenges); synced > 0 {
+		if err := saveChallenges(challenges); err != nil {
+			return reports, fmt.Errorf("error saving challenges: %v", err)
+		}
+		fmt.Printf("Copied %d known answers from the dataset.\n", synced)
+	}
+
+	return reports, nil
+}
+
+// dayChallenges returns the challenges of both parts of a downloaded day,
+// sharing its input, or only part one while part two is still locked.
+func dayChallenges(year, day int, page puzzleDay) []Challenge {
+	title := puzzleTitle(page.PartOne)
+	challenges := []Challenge{{Name: fmt.Sprintf("day%d_part1_%d", day, year), Title: title, Task: page.PartOne, Input: page.Input, Year: int64(year), Examples: page.PartOneExamples}}
+	if page.PartTwo != "" {
+		challenges = append(challenges, Challenge{Name: fmt.Sprintf("day%d_part2_%d", day, year), Title: title, Task: page.PartOne + "\n\n" + page.PartTwo, Input: page.Input, Year: int64(year), Examples: page.PartTwoExamples})
+	}
+	return challenges
+}
+
+// downloadDay downloads both parts of flags.Day with a single fetch of the
+// puzzle page and its input, for `download --part all`. Part two is stored
+// when it is visible, i.e. once part one is solved. The day is skipped when
+// both parts are already stored and --force is not set.
+func downloadDay(ctx context.Context, flags Flags) ([]DownloadReport, error) {
+	if flags.Session == "" {
+		return nil, fmt.Errorf("session token is required")
+	}
+	partOne := fmt.Sprintf("day%d_part1_%d", flags.Day, flags.Year)
+	partTwo := fmt.Sprintf("day%d_part2_%d", flags.Day, flags.Year)
+
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error loading challenges: %v", err)
+	}
+	if !flags.Force && lookupChallenge(challenges, partOne) != nil && lookupChallenge(challenges, partTwo) != nil {
+		fmt.Printf("Both parts of day %d %d are already downloaded, use --force to download them again.\n", flags.Day, flags.Year)
+		return []DownloadReport{{Challenge: partOne}, {Challenge: partTwo}}, nil
+	}
+
+	if flags.Wait {
+		waitForUnlock(os.Stderr, flags.Year, flags.Day)
+	} else if err := checkUnlocked(flags.Year, flags.Day); err != nil {
+		return nil, err
+	}
+
+	if err := verifyCommandSession(ctx, flags); err != nil {
+		return nil, err
+	}
+	dayFlags := Flags{Year: flags.Year, Day: flags.Day, Part: 1, Session: flags.Session, HTTPTimeout: flags.HTTPTimeout}
+	page, err := fetchDay(ctx, dayFlags)
+	// The site may take a moment to serve a puzzle that has just unlocked
+	for retry := 0; flags.Wait && err == errPuzzleLocked && retry < unlockRetries; retry++ {
+		unlockSleep(time.Second)
+		page, err = fetchDay(ctx, dayFlags)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []DownloadReport
+	for _, challenge := range dayChallenges(flags.Year, flags.Day, page) {
+		challenges = upsertChallenge(challenges, challenge)
+		reports = append(reports, DownloadReport{Challenge: challenge.Name, Downloaded: true})
+	}
+	if syncAnswersFromDataset(challenges) > 0 {
+		fmt.Println("Copied the known answers from the dataset.")
+	}
+	if err := saveChallenges(challenges); err != nil {
+		return nil, fmt.Errorf("error saving challenges: %v", err)
+	}
+	for _, report := range reports {
+		emitEvent(Event{Event: eventDownloaded, Challenge: report.Challenge})
+	}
+
+	if page.PartTwo != "" {
+		fmt.Printf("Downloaded both parts of day %d %d.\n", flags.Day, flags.Year)
+	} else {
+		fmt.Printf("Downloaded part 1 of day %d %d, part 2 is still locked.\n", flags.Day, flags.Year)
+	}
+	return reports, nil
+}
@@ -0,0 +1,142 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDownloadYear(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		var day int
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/2022/day/"), "%d", &day)
+		switch {
+		case day > 3:
+			http.NotFound(w, r)
+		case strings.HasSuffix(r.URL.Path, "/input"):
+			fmt.Fprintf(w, "input %d", day)
+		case day == 3:
+			fmt.Fprintf(w, `<article class="day-desc"><h2>--- Day %d ---</h2><p>Part one.</p></article>`, day)
+		default:
+			fmt.Fprintf(w, `<article class="day-desc"><h2>--- Day %d ---</h2><p>Part one.</p></article><article class="day-desc"><h2>--- Part Two ---</h2><p>Part two.</p></article>`, day)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2022", Task: "stored", Solution: "print(1)", SolutionLang: "python"},
+		{Name: "day1_part2_2022", Task: "stored"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	reports, err := downloadYear(context.Background(), Flags{Year: 2022, Session: "test_session"})
+	if err != nil {
+		t.Fatalf("Failed to download year: %v", err)
+	}
+
+	downloaded := 0
+	for _, r := range reports {
+		if r.Downloaded {
+			downloaded++
+		}
+	}
+	if len(reports) != 5 || downloaded != 3 {
+		t.Errorf("Expected day 1 to be skipped and parts 1-2 of day 2 and part 1 of day 3 downloaded, got %+v", reports)
+	}
+	if requests["/2022/day/1"] != 0 {
+		t.Errorf("Expected the stored day to be skipped, got %d requests", requests["/2022/day/1"])
+	}
+	if requests["/2022/day/2"] != 1 || requests["/2022/day/2/input"] != 1 {
+		t.Errorf("Expected one page and one input request per day, got %v", requests)
+	}
+	if requests["/2022/day/5"] != 0 {
+		t.Errorf("Expected the download to stop at the first locked day, got %v", requests)
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	partTwo := lookupChallenge(challenges, "day2_part2_2022")
+	if partTwo == nil || !strings.Contains(partTwo.Task, "Part one.") || !strings.Contains(partTwo.Task, "Part two.") || partTwo.Input != "input 2" {
+		t.Errorf("Unexpected part two record: %+v", partTwo)
+	}
+	if lookupChallenge(challenges, "day3_part2_2022") != nil {
+		t.Errorf("Expected the locked part two of day 3 to be skipped")
+	}
+	if c := lookupChallenge(challenges, "day1_part1_2022"); c == nil || c.Solution != "print(1)" {
+		t.Errorf("Expected the stored challenge to be kept, got %+v", c)
+	}
+
+	if _, err := downloadYear(context.Background(), Flags{Session: "test_session"}); err == nil {
+		t.Errorf("Expected an error without --year")
+	}
+}
+
+func TestDownloadAllParts(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/2022/day/4/input":
+			fmt.Fprint(w, "input 4")
+		default:
+			fmt.Fprint(w, `<article class="day-desc"><h2>--- Day 4 ---</h2><p>Part one.</p></article><article class="day-desc"><h2>--- Part Two ---</h2><p>Part two.</p></article>`)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	flags, err := parseFlags([]string{"--day", "4", "--year", "2022", "--part", "all", "--session", "test_session", "--json"})
+	if err != nil || !flags.AllParts {
+		t.Fatalf("Expected --part all to be accepted, got %+v, %v", flags, err)
+	}
+	var reports []DownloadReport
+	data := captureJSON(t, func() error { return runDownloadCommand(flags) })
+	if err := json.Unmarshal(data, &reports); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if len(reports) != 2 || !reports[0].Downloaded || reports[1].Challenge != "day4_part2_2022" {
+		t.Errorf("Expected both parts to be downloaded, got %+v", reports)
+	}
+	if requests["/2022/day/4"] != 1 || requests["/2022/day/4/input"] != 1 {
+		t.Errorf("Expected one page and one input request, got %v", requests)
+	}
+
+	challenges, _ := loadChallenges(getCacheDir(), challengesFile)
+	partOne, partTwo := lookupChallenge(challenges, "day4_part1_2022"), lookupChallenge(challenges, "day4_part2_2022")
+	if partOne == nil || partTwo == nil || partOne.Input != "input 4" || partTwo.Input != "input 4" || !strings.Contains(partTwo.Task, "Part two.") {
+		t.Errorf("Expected both parts stored with the shared input, got %+v and %+v", partOne, partTwo)
+	}
+
+	// Stored days are skipped
+	reports, err = downloadDay(context.Background(), flags)
+	if err != nil || len(reports) != 2 || reports[0].Downloaded || requests["/2022/day/4"] != 1 {
+		t.Errorf("Expected the stored day to be skipped, got %+v, %v", reports, err)
+	}
+
+	if _, err := parseFlags([]string{"--part", "both"}); err == nil {
+		t.Errorf("Expected an error for an unknown part")
+	}
+}
@@ -0,0 +1,253 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultEmbeddingModel embeds tasks when the config file names no model.
+const defaultEmbeddingModel = "text-embedding-3-small"
+
+// defaultSimilarExamples is the number of examples --similar includes
+// without --examples.
+const defaultSimilarExamples = 3
+
+// embeddingBatchSize is the number of tasks sent in one embeddings request.
+const embeddingBatchSize = 64
+
+// embeddingsFile keeps the embeddings of tasks next to the challenges.
+const embeddingsFile = "embeddings.json"
+
+// EmbeddingsConfig selects the embeddings model --similar uses: an OpenAI
+// or Mistral embeddings model, or a local one as ollama/<name>.
+type EmbeddingsConfig struct {
+	Model string `json:"model,omitempty"`
+	// API is the endpoint of Model (default: its provider's)
+	API string `json:"api,omitempty"`
+}
+
+// storedEmbedding is the embedding of the task of a challenge.
+type storedEmbedding struct {
+	Model string `json:"model"`
+	// Hash is the SHA-256 of the task, so edited tasks are embedded again
+	Hash   string    `json:"hash"`
+	Vector []float64 `json:"vector"`
+}
+
+// embeddingProvider returns the provider serving the embeddings model.
+// Models of neither Ollama nor Mistral are OpenAI's.
+func embeddingProvider(model string) string {
+	switch {
+	case strings.HasPrefix(model, "ollama/"):
+		return "ollama"
+	case isMistralModel(model):
+		return "mistral"
+	}
+	return "openai"
+}
+
+// embeddingAPI returns the endpoint of the embeddings model in cfg.
+func embeddingAPI(cfg EmbeddingsConfig) string {
+	if cfg.API != "" {
+		return cfg.API
+	}
+	switch embeddingProvider(cfg.Model) {
+	case "ollama":
+		return ollamaBaseURL("") + "/api/embed"
+	case "mistral":
+		return "https://api.mistral.ai/v1/embeddings"
+	}
+	return "https://api.openai.com/v1/embeddings"
+}
+
+// taskHash identifies the text of a task.
+func taskHash(task string) string {
+	sum := sha256.Sum256([]byte(task))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadEmbeddings reads the stored embeddings by challenge name. A missing
+// or unreadable file yields none, so they are computed again.
+func loadEmbeddings() map[string]storedEmbedding {
+	embeddings := make(map[string]storedEmbedding)
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), embeddingsFile))
+	if err != nil {
+		return embeddings
+	}
+	if err := json.Unmarshal(data, &embeddings); err != nil {
+		logger.Warn("ignoring unreadable embeddings", "err", err)
+		return make(map[string]storedEmbedding)
+	}
+	return embeddings
+}
+
+func saveEmbeddings(embeddings map[string]storedEmbedding) error {
+	data, err := json.Marshal(embeddings)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(getCacheDir(), embeddingsFile), data, 0644)
+}
+
+// embedTexts returns the embeddings of texts in the same order.
+func embedTexts(ctx context.Context, cfg EmbeddingsConfig, texts []string) ([][]float64, error) {
+	provider := embeddingProvider(cfg.Model)
+	model := cfg.Model
+	if provider == "ollama" {
+		model = ollamaModelName(model)
+	}
+	requestBody, err := json.Marshal(map[string]interface{}{"model": model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", embeddingAPI(cfg), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, key := range providerKeys[provider] {
+		if value := os.Getenv(key); value != "" {
+			req.Header.Set("Authorization", "Bearer "+value)
+			break
+		}
+	}
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, chatCompletionError(resp.Status, body)
+	}
+
+	// Ollama answers {"embeddings": [...]}, the others {"data": [{"index",
+	// "embedding"}]}
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+		Data       []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing embeddings: %v", err)
+	}
+	vectors := result.Embeddings
+	if vectors == nil {
+		vectors = make([][]float64, len(result.Data))
+		for _, d := range result.Data {
+			if d.Index < 0 || d.Index >= len(vectors) {
+				return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+			}
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+	return vectors, nil
+}
+
+// taskEmbeddings returns the embeddings of the tasks of challenges by name,
+// computing and storing those that are missing or out of date.
+func taskEmbeddings(ctx context.Context, cfg EmbeddingsConfig, challenges []Challenge) (map[string][]float64, error) {
+	stored := loadEmbeddings()
+	var missing []Challenge
+	for _, c := range challenges {
+		e, ok := stored[c.Name]
+		if !ok || e.Model != cfg.Model || e.Hash != taskHash(c.Task) {
+			missing = append(missing, c)
+		}
+	}
+
+	if len(missing) > 0 {
+		logger.Info(fmt.Sprintf("Embedding %d tasks with %s...", len(missing), cfg.Model))
+		for start := 0; start < len(missing); start += embeddingBatchSize {
+			batch := missing[start:min(start+embeddingBatchSize, len(missing))]
+			texts := make([]string, len(batch))
+			for i, c := range batch {
+				texts[i] = strings.TrimSpace(c.Task)
+			}
+			vectors, err := embedTexts(ctx, cfg, texts)
+			if err != nil {
+				return nil, fmt.Errorf("error computing embeddings: %v", err)
+			}
+			for i, c := range batch {
+				stored[c.Name] = storedEmbedding{Model: cfg.Model, Hash: taskHash(c.Task), Vector: vectors[i]}
+			}
+		}
+		if err := saveEmbeddings(stored); err != nil {
+			return nil, fmt.Errorf("error saving embeddings: %v", err)
+		}
+	}
+
+	vectors := make(map[string][]float64, len(challenges))
+	for _, c := range challenges {
+		vectors[c.Name] = stored[c.Name].Vector
+	}
+	return vectors, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 if
+// either is zero or their lengths differ.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// similarExamples picks the n solved challenges in lang whose tasks are the
+// most similar to the task of target by embedding, for --similar.
+func similarExamples(ctx context.Context, challenges []Challenge, target Challenge, lang string, n int, cfg EmbeddingsConfig) ([]Challenge, error) {
+	if cfg.Model == "" {
+		cfg.Model = defaultEmbeddingModel
+	}
+	candidates := exampleCandidates(challenges, target, lang)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := taskEmbeddings(ctx, cfg, append([]Challenge{target}, candidates...))
+	if err != nil {
+		return nil, err
+	}
+	similarity := make(map[string]float64, len(candidates))
+	for _, c := range candidates {
+		similarity[c.Name] = cosineSimilarity(vectors[target.Name], vectors[c.Name])
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return similarity[candidates[i].Name] > similarity[candidates[j].Name]
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates, nil
+}
@@ -0,0 +1,158 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newEmbeddingsServer serves an OpenAI-style embeddings API that embeds
+// texts by whether they mention grids, parsing and graphs, and counts the
+// texts it embedded.
+func newEmbeddingsServer(t *testing.T, embedded *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "test-embed" {
+			t.Errorf("Unexpected request: %+v, %v", req, err)
+		}
+		type datum struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		}
+		var data []datum
+		for i, text := range req.Input {
+			vector := make([]float64, 3)
+			for j, word := range []string{"grid", "parse", "graph"} {
+				if strings.Contains(text, word) {
+					vector[j] = 1
+				}
+			}
+			data = append(data, datum{Index: i, Embedding: vector})
+		}
+		*embedded += len(req.Input)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSimilarExamples(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	embedded := 0
+	cfg := EmbeddingsConfig{Model: "test-embed", API: newEmbeddingsServer(t, &embedded).URL}
+	challenges := []Challenge{
+		{Name: "day1_part1_2020", Task: "parse the numbers", Solution: "a", SolutionLang: "go"},
+		{Name: "day2_part1_2020", Task: "walk the grid", Solution: "b", SolutionLang: "go"},
+		{Name: "day3_part1_2020", Task: "a graph of caves", Solution: "c", SolutionLang: "go"},
+		{Name: "day4_part1_2020", Task: "another grid", Solution: "d", SolutionLang: "python"},
+	}
+	target := Challenge{Name: "day5_part1_2020", Task: "count cells in the grid"}
+
+	examples, err := similarExamples(context.Background(), challenges, target, "go", 1, cfg)
+	if err != nil {
+		t.Fatalf("similarExamples failed: %v", err)
+	}
+	if len(examples) != 1 || examples[0].Name != "day2_part1_2020" {
+		t.Errorf("Expected the grid puzzle, got %+v", examples)
+	}
+	if embedded != 4 {
+		t.Errorf("Expected the target and the 3 go solutions to be embedded, got %d", embedded)
+	}
+
+	// Stored embeddings are reused; only the edited task is embedded again
+	challenges[0].Task = "parse the grid"
+	embedded = 0
+	examples, err = similarExamples(context.Background(), challenges, target, "go", 2, cfg)
+	if err != nil {
+		t.Fatalf("similarExamples failed: %v", err)
+	}
+	if embedded != 1 {
+		t.Errorf("Expected only the edited task to be embedded, got %d", embedded)
+	}
+	if len(examples) != 2 || examples[0].Name != "day2_part1_2020" || examples[1].Name != "day1_part1_2020" {
+		t.Errorf("Expected the examples in order of similarity, got %+v", examples)
+	}
+}
+
+func TestBuildPromptSimilar(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	embedded := 0
+	saveConfig(Config{Embeddings: EmbeddingsConfig{Model: "test-embed", API: newEmbeddingsServer(t, &embedded).URL}})
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2020", Task: "parse the numbers", Solution: "parse_solution()", SolutionLang: "python"},
+		{Name: "day2_part1_2020", Task: "walk the grid", Solution: "grid_solution()", SolutionLang: "python"},
+	})
+
+	prompt, err := buildPrompt(Challenge{Name: "day5_part1_2020", Task: "count cells in the grid"}, Flags{Lang: "python", Model: "gpt-4o-mini", Examples: 1, Similar: true})
+	if err != nil {
+		t.Fatalf("buildPrompt failed: %v", err)
+	}
+	if !strings.Contains(prompt, "grid_solution()") || strings.Contains(prompt, "parse_solution()") {
+		t.Errorf("Expected the most similar example in the prompt, got:\n%s", prompt)
+	}
+
+	// Without a working embeddings API the examples are picked at random
+	saveConfig(Config{Embeddings: EmbeddingsConfig{Model: "test-embed", API: "http://127.0.0.1:1"}})
+	prompt, err = buildPrompt(Challenge{Name: "day5_part1_2020", Task: "changed task about the grid"}, Flags{Lang: "python", Model: "gpt-4o-mini", Similar: true})
+	if err != nil || !strings.Contains(prompt, "parse_solution()") || !strings.Contains(prompt, "grid_solution()") {
+		t.Errorf("Expected random examples, got %v:\n%s", err, prompt)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := cosineSimilarity([]float64{1, 0}, []float64{1, 1}); math.Abs(got-math.Sqrt2/2) > 1e-9 {
+		t.Errorf("Expected cos 45°, got %v", got)
+	}
+	if got := cosineSimilarity([]float64{0, 0}, []float64{1, 1}); got != 0 {
+		t.Errorf("Expected 0 for a zero vector, got %v", got)
+	}
+	if got := cosineSimilarity([]float64{1}, []float64{1, 1}); got != 0 {
+		t.Errorf("Expected 0 for vectors of different lengths, got %v", got)
+	}
+}
+
+func TestEmbeddingAPI(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tests := map[string]string{
+		"text-embedding-3-small":  "https://api.openai.com/v1/embeddings",
+		"mistral-embed":           "https://api.mistral.ai/v1/embeddings",
+		"ollama/nomic-embed-text": "http://localhost:11434/api/embed",
+	}
+	for model, want := range tests {
+		if got := embeddingAPI(EmbeddingsConfig{Model: model}); got != want {
+			t.Errorf("embeddingAPI(%s) = %s, want %s", model, got, want)
+		}
+	}
+}
+
+func TestEmbedTextsOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("Expected the model without its prefix, got %q", req.Model)
+		}
+		w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.1,0.2],[0.3,0.4]]}`))
+	}))
+	defer server.Close()
+
+	vectors, err := embedTexts(context.Background(), EmbeddingsConfig{Model: "ollama/nomic-embed-text", API: server.URL}, []string{"a", "b"})
+	if err != nil || len(vectors) != 2 || vectors[1][1] != 0.4 {
+		t.Errorf("Unexpected embeddings: %v, %v", vectors, err)
+	}
+}
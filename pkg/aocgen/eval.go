@@ -0,0 +1,162 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Verdict is the outcome of evaluating a solution.
+type Verdict string
+
+const (
+	VerdictCorrect      Verdict = "correct"
+	VerdictWrongAnswer  Verdict = "wrong answer"
+	VerdictCompileError Verdict = "compile error"
+	VerdictRuntimeError Verdict = "runtime error"
+	VerdictTimeout      Verdict = "timeout"
+	// VerdictUnknownAnswer is given to a solution that ran fine on a
+	// challenge whose answer is not known, so it could not be checked.
+	VerdictUnknownAnswer Verdict = "unknown answer"
+	// VerdictMissingToolchain is only reported by `aocgen eval`;
+	// judgeSolution returns a *MissingToolchainError instead.
+	VerdictMissingToolchain Verdict = "missing toolchain"
+)
+
+// ExitCode returns the status `aocgen eval` exits with for v, so scripts and
+// CI pipelines can tell the kinds of failure apart.
+func (v Verdict) ExitCode() int {
+	switch v {
+	case VerdictCorrect:
+		return 0
+	case VerdictWrongAnswer:
+		return 1
+	case VerdictRuntimeError:
+		return 2
+	case VerdictCompileError:
+		return 3
+	case VerdictTimeout:
+		return 4
+	case VerdictMissingToolchain:
+		return 5
+	case VerdictUnknownAnswer:
+		return 6
+	default:
+		return 1
+	}
+}
+
+// verdictLine formats the machine-readable first line of the eval report.
+func verdictLine(challenge, lang string, verdict Verdict, duration time.Duration) string {
+	return fmt.Sprintf("verdict=%s exit_code=%d challenge=%s lang=%s duration_ms=%d",
+		strings.ReplaceAll(string(verdict), " ", "_"), verdict.ExitCode(), challenge, lang, duration.Milliseconds())
+}
+
+// EvalResult describes how a solution fared against a challenge.
+type EvalResult struct {
+	Verdict Verdict
+	// Output is the combined stdout and stderr of the program, or the
+	// compiler diagnostics for a compile error.
+	Output   string
+	Duration time.Duration
+	// Err is the underlying failure for compile and runtime errors.
+	Err error
+	// Expected is the expected answer and Answer the one taken from the
+	// output, for correct and wrong answers.
+	Expected string
+	Answer   string
+	// Case names the example or test case the solution failed on, e.g.
+	// "Example 1 of 1"; empty for the real input.
+	Case string
+}
+
+// judgeSolution builds and runs a solution within limits, passing it args,
+// and classifies the result. If ctx is cancelled the solution is killed and
+// errInterrupted is returned.
+// The returned error is reserved for problems with the evaluation itself, such
+// as an unsupported language; failures of the solution are reported in the
+// verdict.
+func judgeSolution(ctx context.Context, challenge Challenge, filename string, lang string, limits Limits, lenient bool, args ...string) (EvalResult, error) {
+	return judgeSolutionLive(ctx, challenge, filename, lang, limits, answerMatch{Lenient: lenient}, nil, args...)
+}
+
+// judgeSolutionLive is judgeSolution that compares the answers as match says
+// and also copies the output of the solution to live, if not nil, while it
+// runs. live must be safe for concurrent writes from stdout and stderr.
+func judgeSolutionLive(ctx context.Context, challenge Challenge, filename string, lang string, limits Limits, match answerMatch, live io.Writer, args ...string) (EvalResult, error) {
+	cmd, cleanup, err := getCommand(lang, filename)
+	defer cleanup()
+	if ctx.Err() != nil {
+		return EvalResult{}, errInterrupted
+	}
+	if err != nil {
+		if compileErr, ok := err.(*CompileError); ok {
+			return EvalResult{Verdict: VerdictCompileError, Output: compileErr.Output, Err: compileErr.Err}, nil
+		}
+		return EvalResult{}, err
+	}
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Dir = filepath.Dir(filename)
+
+	var out lockedBuffer
+	var stdout bytes.Buffer
+	cmd.Stdout = io.MultiWriter(&out, &stdout)
+	cmd.Stderr = &out
+	if live != nil {
+		cmd.Stdout = io.MultiWriter(&out, &stdout, live)
+		cmd.Stderr = io.MultiWriter(&out, live)
+	}
+	// The solution may spawn processes of its own, e.g. the binary built by
+	// `go run`; they are killed with it and always reaped
+	cmd.WaitDelay = processWaitDelay
+	if err := limitMemory(cmd, limits.Memory); err != nil {
+		return EvalResult{}, err
+	}
+
+	start := time.Now()
+	err = osRunner.Start(cmd)
+	if err != nil {
+		return EvalResult{}, fmt.Errorf("failed to start command: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- osRunner.Wait(cmd)
+	}()
+
+	select {
+	case <-ctx.Done():
+		osRunner.Kill(cmd)
+		<-done
+		return EvalResult{}, errInterrupted
+	case <-time.After(limits.Timeout):
+		if err := osRunner.Kill(cmd); err != nil {
+			return EvalResult{}, fmt.Errorf("failed to kill process: %v", err)
+		}
+		<-done
+		return EvalResult{Verdict: VerdictTimeout, Output: out.String(), Duration: time.Since(start)}, nil
+	case err := <-done:
+		// A solution that died with the interrupt is not judged
+		if ctx.Err() != nil {
+			return EvalResult{}, errInterrupted
+		}
+		if err != nil {
+			return EvalResult{Verdict: VerdictRuntimeError, Output: out.String(), Duration: time.Since(start), Err: err}, nil
+		}
+	}
+
+	result := EvalResult{Verdict: VerdictWrongAnswer, Output: out.String(), Duration: time.Since(start), Expected: strings.TrimSpace(challenge.Answer)}
+	var correct bool
+	result.Answer, correct = match.check(stdout.String(), result.Output, challenge.Answer)
+	switch {
+	case correct:
+		result.Verdict = VerdictCorrect
+	case match.normalize(challenge.Answer) == "":
+		result.Verdict = VerdictUnknownAnswer
+	}
+	return result, nil
+}
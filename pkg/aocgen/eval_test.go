@@ -0,0 +1,284 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJudgeSolutionVerdicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		code     string
+		timeout  time.Duration
+		expected Verdict
+	}{
+		{
+			name:     "correct",
+			lang:     "python",
+			code:     "print(42)",
+			expected: VerdictCorrect,
+		},
+		{
+			name:     "wrong answer",
+			lang:     "python",
+			code:     "print(43)",
+			expected: VerdictWrongAnswer,
+		},
+		{
+			name:     "runtime error",
+			lang:     "python",
+			code:     "print(42)\nraise ValueError('boom')",
+			expected: VerdictRuntimeError,
+		},
+		{
+			name:     "timeout",
+			lang:     "python",
+			code:     "import time\ntime.sleep(10)",
+			timeout:  200 * time.Millisecond,
+			expected: VerdictTimeout,
+		},
+		{
+			name:     "go compile error",
+			lang:     "go",
+			code:     "package main\n\nfunc main() {\n\tundefinedFunction()\n}\n",
+			expected: VerdictCompileError,
+		},
+		{
+			name:     "go correct after compile check",
+			lang:     "go",
+			code:     "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(42)\n}\n",
+			expected: VerdictCorrect,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			oldWd, err := os.Getwd()
+			if err != nil {
+				t.Fatalf("Failed to get current working directory: %v", err)
+			}
+			defer os.Chdir(oldWd)
+			if err := os.Chdir(tmpDir); err != nil {
+				t.Fatalf("Failed to change to temp directory: %v", err)
+			}
+
+			ext, _ := getFileExtension(tt.lang)
+			filename := filepath.Join(tmpDir, "solution."+ext)
+			if err := os.WriteFile(filename, []byte(tt.code), 0644); err != nil {
+				t.Fatalf("Failed to write solution file: %v", err)
+			}
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+
+			result, err := judgeSolution(context.Background(), Challenge{Answer: "42"}, filename, tt.lang, Limits{Timeout: timeout}, false)
+			if err != nil {
+				t.Fatalf("Failed to judge solution: %v", err)
+			}
+
+			if result.Verdict != tt.expected {
+				t.Errorf("Expected verdict %q, got %q. Output: %s", tt.expected, result.Verdict, result.Output)
+			}
+
+			if tt.expected == VerdictCompileError && !strings.Contains(result.Output, "undefinedFunction") {
+				t.Errorf("Expected compiler diagnostics in output, got: %s", result.Output)
+			}
+		})
+	}
+}
+
+func TestEvaluateSolutionReportsCompileError(t *testing.T) {
+	tmpDir := t.TempDir()
+	filename := filepath.Join(tmpDir, "solution.go")
+	if err := os.WriteFile(filename, []byte("package main\n\nfunc main() {\n"), 0644); err != nil {
+		t.Fatalf("Failed to write solution file: %v", err)
+	}
+
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	_, _, err := evaluateSolution(Challenge{Answer: "42"}, filename, "go", 10*time.Second, false)
+	if err == nil || !strings.Contains(err.Error(), "compile error") {
+		t.Errorf("Expected compile error, got: %v", err)
+	}
+}
+
+func TestJudgeSolutionInterrupted(t *testing.T) {
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "solution.py")
+	if err := os.WriteFile(filename, []byte("import time\ntime.sleep(10)\nprint(42)"), 0644); err != nil {
+		t.Fatalf("Failed to write solution: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := judgeSolution(ctx, Challenge{Answer: "42"}, filename, "python", Limits{Timeout: 10 * time.Second}, false)
+	if err != errInterrupted {
+		t.Errorf("Expected errInterrupted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the solution to be killed when interrupted, took %v", elapsed)
+	}
+}
+
+// captureStdout returns what fn prints to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	original := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	os.Stdout = original
+	return <-done
+}
+
+// verdictExitCode returns the status runEvaluationCommand asks to exit with.
+func verdictExitCode(err error) int {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+func TestRunEvaluationCommandExitCodes(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	toolchains["fake"] = languageToolchain{Run: []string{"aocgen-no-such-interpreter", "{file}"}}
+	defer delete(toolchains, "fake")
+	languageExtensions["fake"] = "fake"
+	defer delete(languageExtensions, "fake")
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Answer: "42"}})
+
+	tests := []struct {
+		lang     string
+		code     string
+		expected int
+	}{
+		{"python", "print(42)", 0},
+		{"python", "print(41)", 1},
+		{"python", "raise SystemExit(3)", 2},
+		{"python", "import time\ntime.sleep(10)", 4},
+		{"fake", "42", 5},
+	}
+	for _, tt := range tests {
+		ext, _ := getFileExtension(tt.lang)
+		os.WriteFile(testSolutionPath(t, "day1_part1_2015", ext), []byte(tt.code), 0644)
+
+		var err error
+		output := captureStdout(t, func() {
+			err = runEvaluationCommand(Flags{Day: 1, Part: 1, Year: 2015, Lang: tt.lang, Timeout: 500})
+		})
+		if code := verdictExitCode(err); code != tt.expected {
+			t.Errorf("%q: expected exit code %d, got %d (%v)", tt.code, tt.expected, code, err)
+		}
+		if !strings.HasPrefix(output, "verdict=") || !strings.Contains(output, fmt.Sprintf("exit_code=%d challenge=day1_part1_2015 lang=%s", tt.expected, tt.lang)) {
+			t.Errorf("%q: expected a structured verdict line, got:\n%s", tt.code, output)
+		}
+	}
+}
+
+func TestVerdictLine(t *testing.T) {
+	line := verdictLine("day1_part1_2015", "go", VerdictCompileError, 1500*time.Millisecond)
+	expected := "verdict=compile_error exit_code=3 challenge=day1_part1_2015 lang=go duration_ms=1500"
+	if line != expected {
+		t.Errorf("Expected %q, got %q", expected, line)
+	}
+}
+
+func TestEvaluateUnknownAnswer(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	for _, tt := range []struct {
+		code    string
+		lenient bool
+	}{
+		{"", false},
+		{"print(42)", true},
+	} {
+		saveChallenges([]Challenge{{Name: "day1_part1_2015", Input: "(()"}})
+		os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte(tt.code), 0644)
+
+		challenge, result, err := evaluateChallengeSolution(context.Background(), Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Timeout: 5000, Lenient: tt.lenient}, nil)
+		if err != nil {
+			t.Fatalf("%q: failed to evaluate: %v", tt.code, err)
+		}
+		if result.Verdict != VerdictUnknownAnswer {
+			t.Errorf("%q (lenient %v): expected an unknown answer, got %s", tt.code, tt.lenient, result.Verdict)
+		}
+		if challenge.Solution != "" {
+			t.Errorf("%q: expected no solution, got %q", tt.code, challenge.Solution)
+		}
+
+		challenges, _ := loadChallenges(tempDir, challengesFile)
+		if len(challenges) != 1 || challenges[0].Solution != "" || challenges[0].Answer != "" {
+			t.Errorf("%q: expected the store to be unchanged, got %+v", tt.code, challenges)
+		}
+		if records, _ := loadEvalLog(); len(records) != 0 {
+			t.Errorf("%q: expected no eval records, got %+v", tt.code, records)
+		}
+		if attempts, _ := loadAttempts(); len(attempts) != 0 {
+			t.Errorf("%q: expected no attempts, got %+v", tt.code, attempts)
+		}
+	}
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runEvaluationCommand(Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Timeout: 5000})
+	})
+	if code := verdictExitCode(err); code != 6 {
+		t.Errorf("Expected exit code 6 for an unknown answer, got %d (%v)", code, err)
+	}
+	if !strings.HasPrefix(output, "verdict=unknown_answer exit_code=6") || !strings.Contains(output, "No answer is stored") || strings.Contains(output, "incorrect") {
+		t.Errorf("Expected an unknown answer report, got:\n%s", output)
+	}
+}
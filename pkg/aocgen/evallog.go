@@ -0,0 +1,74 @@
+package aocgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const evalLogFile = "evals.json"
+
+// EvalRecord is one entry of the eval history kept for `aocgen stats`.
+type EvalRecord struct {
+	Challenge string        `json:"challenge"`
+	Lang      string        `json:"lang"`
+	Verdict   Verdict       `json:"verdict"`
+	Duration  time.Duration `json:"duration"`
+	Time      time.Time     `json:"time"`
+	// Hash identifies the solution and input that were evaluated, see
+	// evalHash
+	Hash string `json:"hash,omitempty"`
+}
+
+// evalHash hashes a solution, without its provenance header, together with
+// the input it ran on, so an eval can be matched to the code it judged.
+func evalHash(code, input string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(stripProvenanceHeader(code)) + "\x00" + strings.TrimSpace(input)))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadEvalLog reads the eval history. A missing history yields no records.
+func loadEvalLog() ([]EvalRecord, error) {
+	var records []EvalRecord
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), evalLogFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("error parsing eval history: %v", err)
+	}
+	return records, nil
+}
+
+// recordEval appends the outcome of an eval run to the history.
+func recordEval(challenge Challenge, lang, code string, result EvalResult) error {
+	records, err := loadEvalLog()
+	if err != nil {
+		return err
+	}
+	records = append(records, EvalRecord{
+		Challenge: challenge.Name,
+		Lang:      lang,
+		Verdict:   result.Verdict,
+		Duration:  result.Duration,
+		Time:      time.Now(),
+		Hash:      evalHash(code, challenge.Input),
+	})
+
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getCacheDir(), evalLogFile), data, 0644)
+}
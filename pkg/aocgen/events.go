@@ -0,0 +1,103 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Steps of a command that --events reports.
+const (
+	eventDownloaded   = "downloaded"
+	eventPromptSent   = "prompt_sent"
+	eventCodeReceived = "code_received"
+	eventEvalStarted  = "eval_started"
+	eventVerdict      = "verdict"
+)
+
+// Event is a line of the --events stream.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	Challenge string    `json:"challenge,omitempty"`
+	Lang      string    `json:"lang,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Verdict   Verdict   `json:"verdict,omitempty"`
+	// DurationMs is how long the model took to answer, for code_received,
+	// or the solution ran, for verdict
+	DurationMs       int64  `json:"duration_ms,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+	Cached           bool   `json:"cached,omitempty"`
+	Error            string `json:"error,omitempty"`
+	// Failure classifies the error of code_received like a failure of a
+	// benchmark: an API error or an extraction failure
+	Failure string `json:"failure,omitempty"`
+}
+
+// eventStream writes events as JSON lines. It is safe for concurrent use by
+// the workers of a benchmark.
+type eventStream struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// events receives the steps of the running command, or is nil without
+// --events.
+var events *eventStream
+
+// setupEvents points events at the file of --events, or stdout for "-". It
+// returns a function that closes the file and turns events off again.
+func setupEvents(flags Flags) (func(), error) {
+	switch flags.Events {
+	case "":
+		return func() {}, nil
+	case "-":
+		if flags.JSON {
+			return nil, fmt.Errorf("--events - and --json both write to stdout, write the events to a file instead")
+		}
+		events = &eventStream{w: os.Stdout}
+		return func() { events = nil }, nil
+	}
+	file, err := os.OpenFile(flags.Events, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening events file: %v", err)
+	}
+	events = &eventStream{w: file}
+	return func() {
+		events = nil
+		file.Close()
+	}, nil
+}
+
+// emitEvent counts e in the metrics and writes it to the --events stream,
+// if any, stamped with the current time.
+func emitEvent(e Event) {
+	metrics.observe(e)
+	stream := events
+	if stream == nil {
+		return
+	}
+	e.Time = time.Now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		logger.Debug("failed to encode event", "err", err)
+		return
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if _, err := stream.w.Write(append(data, '\n')); err != nil {
+		logger.Debug("failed to write event", "err", err)
+	}
+}
+
+// errorString returns the message of err, or "" for nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
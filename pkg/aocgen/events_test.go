@@ -0,0 +1,115 @@
+package aocgen
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readEvents returns the events in a --events file.
+func readEvents(t *testing.T, path string) []Event {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open events: %v", err)
+	}
+	defer file.Close()
+	var lines []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Invalid event %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, e)
+	}
+	return lines
+}
+
+func TestSetupEvents(t *testing.T) {
+	if _, err := setupEvents(Flags{Events: "-", JSON: true}); err == nil {
+		t.Errorf("Expected an error for --events - with --json")
+	}
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	restore, err := setupEvents(Flags{Events: path})
+	if err != nil {
+		t.Fatalf("setupEvents failed: %v", err)
+	}
+	emitEvent(Event{Event: eventDownloaded, Challenge: "day1_part1_2015"})
+	restore()
+	// Without --events nothing is written
+	emitEvent(Event{Event: eventDownloaded, Challenge: "day2_part1_2015"})
+
+	lines := readEvents(t, path)
+	if len(lines) != 1 || lines[0].Event != eventDownloaded || lines[0].Challenge != "day1_part1_2015" || lines[0].Time.IsZero() {
+		t.Errorf("Unexpected events: %+v", lines)
+	}
+}
+
+func TestGenerateAndEvalEvents(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Sum the numbers.", Input: "1\n2\n", Answer: "3"}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(sum(int(l) for l in open('input.txt')))\n```"}},
+			},
+			"usage": map[string]int{"prompt_tokens": 12, "completion_tokens": 34},
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(tempDir, "events.jsonl")
+	restore, err := setupEvents(Flags{Events: path})
+	if err != nil {
+		t.Fatalf("setupEvents failed: %v", err)
+	}
+	defer restore()
+
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL, NoFormat: true}
+	if _, err := generateChallengeSolution(context.Background(), flags); err != nil {
+		t.Fatalf("Generation failed: %v", err)
+	}
+	if _, _, err := evaluateChallengeSolution(context.Background(), flags, nil); err != nil {
+		t.Fatalf("Evaluation failed: %v", err)
+	}
+
+	lines := readEvents(t, path)
+	var kinds []string
+	for _, e := range lines {
+		kinds = append(kinds, e.Event)
+		if e.Challenge != "day1_part1_2015" || e.Lang != "python" {
+			t.Errorf("Expected the challenge and language in %+v", e)
+		}
+	}
+	want := []string{eventPromptSent, eventCodeReceived, eventEvalStarted, eventVerdict}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("Expected events %v, got %v", want, kinds)
+			break
+		}
+	}
+	if received := lines[1]; received.Model != "gpt-4o-mini" || received.CompletionTokens != 34 {
+		t.Errorf("Unexpected code_received event: %+v", received)
+	}
+	if verdict := lines[3]; verdict.Verdict != VerdictCorrect {
+		t.Errorf("Expected a correct verdict, got %+v", verdict)
+	}
+}
@@ -0,0 +1,164 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Example is a worked example from a puzzle description: an example input
+// and the answer the task gives for it.
+type Example struct {
+	Input  string `json:"input"`
+	Answer string `json:"answer"`
+}
+
+// extractExamples finds the example of one part of the puzzle page. Advent
+// of Code shows the example input in a <pre><code> block and highlights its
+// answer as <code><em>answer</em></code>, so the example is the first block
+// of the part's description (part two usually reuses the one of part one)
+// with the last highlighted answer of the description. Descriptions without
+// both yield no example.
+func extractExamples(htmlContent string, part int) []Example {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil
+	}
+
+	var articles []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Article && hasClass(n, "day-desc") {
+			articles = append(articles, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if part < 1 || part > len(articles) {
+		return nil
+	}
+
+	article := articles[part-1]
+	input := firstExampleInput(article)
+	if input == "" && part > 1 {
+		input = firstExampleInput(articles[0])
+	}
+	answer := lastHighlightedAnswer(article)
+	if input == "" || answer == "" {
+		return nil
+	}
+	return []Example{{Input: input, Answer: answer}}
+}
+
+// firstExampleInput returns the text of the first <pre> block under n.
+func firstExampleInput(n *html.Node) string {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Pre {
+		text := strings.TrimRight(nodeText(n), "\n")
+		if text == "" {
+			return ""
+		}
+		return text + "\n"
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if input := firstExampleInput(c); input != "" {
+			return input
+		}
+	}
+	return ""
+}
+
+// lastHighlightedAnswer returns the text of the last <code><em> or <em><code>
+// outside of <pre> blocks under n.
+func lastHighlightedAnswer(n *html.Node) string {
+	var answer string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Pre:
+				return
+			case atom.Code, atom.Em:
+				other := atom.Em
+				if n.DataAtom == atom.Em {
+					other = atom.Code
+				}
+				if only := onlyElementChild(n); only != nil && only.DataAtom == other {
+					if text := strings.TrimSpace(nodeText(n)); text != "" {
+						answer = text
+					}
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return answer
+}
+
+// onlyElementChild returns the child element of n if it is the only child
+// apart from whitespace.
+func onlyElementChild(n *html.Node) *html.Node {
+	var only *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+		case c.Type == html.ElementNode && only == nil:
+			only = c
+		default:
+			return nil
+		}
+	}
+	return only
+}
+
+// checkExamples runs a solution on each of cases, the examples or test cases
+// of challenge, like judgeSolutionLive does on the real input, writing the
+// case's input where the solution reads its input. It returns the result of
+// the first case that does not pass, with the Case named after label, or ok
+// if all pass. Unless the input path is passed as an argument, input.txt
+// next to the solution holds the real input again afterwards.
+func checkExamples(ctx context.Context, challenge Challenge, cases []Example, label, filename, lang string, limits Limits, match answerMatch, flags Flags) (result EvalResult, ok bool, err error) {
+	dir := filepath.Dir(filename)
+	if !flags.InputArg {
+		defer func() {
+			if restoreErr := writeInputFile(dir, challenge); restoreErr != nil && err == nil {
+				err = fmt.Errorf("error creating input file: %v", restoreErr)
+			}
+		}()
+	}
+
+	for i, example := range cases {
+		exampleChallenge := Challenge{Name: challenge.Name, Input: example.Input, Answer: example.Answer}
+		var args []string
+		if flags.InputArg {
+			inputPath, cleanup, err := writeTempInputFile(exampleChallenge)
+			if err != nil {
+				return EvalResult{}, false, fmt.Errorf("error creating %s input file: %v", strings.ToLower(label), err)
+			}
+			defer cleanup()
+			args = append(args, inputPath)
+		} else if err := writeInputFile(dir, exampleChallenge); err != nil {
+			return EvalResult{}, false, fmt.Errorf("error creating %s input file: %v", strings.ToLower(label), err)
+		}
+
+		result, err := judgeSolutionLive(ctx, exampleChallenge, filename, lang, limits, match, nil, args...)
+		if err != nil {
+			return EvalResult{}, false, err
+		}
+		if result.Verdict != VerdictCorrect {
+			result.Case = fmt.Sprintf("%s %d of %d", label, i+1, len(cases))
+			return result, false, nil
+		}
+	}
+	return EvalResult{}, true, nil
+}
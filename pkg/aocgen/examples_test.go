@@ -0,0 +1,100 @@
+package aocgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractExamples(t *testing.T) {
+	page := `<main>
+<article class="day-desc"><h2>--- Day 1: Trebuchet?! ---</h2>
+<p>For example:</p>
+<pre><code>1abc2
+pqr3stu8vwx
+</code></pre>
+<p>The values of these lines are <code>12</code> and <code>38</code>. Adding these together produces <code><em>50</em></code>.</p>
+</article>
+<p>Your puzzle answer was <code>54331</code>.</p>
+<article class="day-desc"><h2 id="part2">--- Part Two ---</h2>
+<p>Some digits are spelled out. In this example, the sum is <em><code>281</code></em>.</p>
+</article>
+</main>`
+
+	part1 := extractExamples(page, 1)
+	if expected := []Example{{Input: "1abc2\npqr3stu8vwx\n", Answer: "50"}}; !reflect.DeepEqual(part1, expected) {
+		t.Errorf("Unexpected part 1 examples: %+v", part1)
+	}
+	part2 := extractExamples(page, 2)
+	if expected := []Example{{Input: "1abc2\npqr3stu8vwx\n", Answer: "281"}}; !reflect.DeepEqual(part2, expected) {
+		t.Errorf("Expected part 2 to reuse the example input, got %+v", part2)
+	}
+
+	if examples := extractExamples(`<article class="day-desc"><p>No example here, just <code>code</code>.</p></article>`, 1); examples != nil {
+		t.Errorf("Expected no examples, got %+v", examples)
+	}
+	if examples := extractExamples(page, 3); examples != nil {
+		t.Errorf("Expected no examples for a missing part, got %+v", examples)
+	}
+}
+
+func TestEvaluateChecksExamples(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{
+		Name:     "day1_part1_2015",
+		Input:    "10\n20\n",
+		Answer:   "30",
+		Examples: []Example{{Input: "1\n2\n", Answer: "3"}},
+	}})
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Timeout: 5000}
+
+	tests := []struct {
+		name     string
+		code     string
+		flags    Flags
+		expected Verdict
+		failed   string
+	}{
+		{"correct", "print(sum(int(l) for l in open('input.txt')))", flags, VerdictCorrect, ""},
+		// The examples are guessed, so with a known answer the real input
+		// decides
+		{"wrong on the example", "print(30)", flags, VerdictCorrect, ""},
+		{"examples skipped", "print(30)", Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Timeout: 5000, SkipExamples: true}, VerdictCorrect, ""},
+	}
+	for _, tt := range tests {
+		os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte(tt.code), 0644)
+		_, result, err := evaluateChallengeSolution(context.Background(), tt.flags, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to evaluate: %v", tt.name, err)
+		}
+		if result.Verdict != tt.expected || result.Case != tt.failed || result.Answer != "30" {
+			t.Errorf("%s: expected %s on %q, got %+v", tt.name, tt.expected, tt.failed, result)
+		}
+		if input, _ := os.ReadFile(filepath.Join(defaultWorkdir(), "day1_part1_2015", "input.txt")); string(input) != "10\n20\n" {
+			t.Errorf("%s: expected input.txt to hold the real input, got %q", tt.name, input)
+		}
+	}
+
+	// Without a known answer a failed example is the verdict
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Input: "10\n20\n", Examples: []Example{{Input: "1\n2\n", Answer: "3"}}}})
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print(30)"), 0644)
+	_, result, err := evaluateChallengeSolution(context.Background(), flags, nil)
+	if err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+	if result.Verdict != VerdictWrongAnswer || result.Case != "Example 1 of 1" {
+		t.Errorf("Expected a wrong answer on the example, got %+v", result)
+	}
+}
@@ -0,0 +1,252 @@
+package aocgen
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+// datasetColumns are the columns of the HuggingFace dataset in order. Exports
+// use the same layout so they can be contributed back.
+var datasetColumns = []string{"name", "solution", "input", "task", "solution_lang", "year", "answer"}
+
+// exportFormats maps the formats of `aocgen export` to their writers.
+var exportFormats = map[string]func(w io.Writer, challenges []Challenge) error{
+	"parquet": writeParquet,
+	"jsonl":   writeJSONL,
+	"csv":     writeCSV,
+}
+
+// runExportCommand writes the stored challenges, limited to --year and --lang
+// if set, to w in the format given by --format.
+func runExportCommand(flags Flags, w io.Writer) error {
+	if flags.HFDataset != "" {
+		return runHFDatasetExport(flags, w)
+	}
+	format := flags.Format
+	if format == "" || format == "table" {
+		format = "jsonl"
+	}
+	write, ok := exportFormats[format]
+	if !ok {
+		return fmt.Errorf("unsupported export format: %s (expected parquet, jsonl or csv)", format)
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	var selected []Challenge
+	for _, c := range challenges {
+		if flags.Year != 0 && int(c.Year) != flags.Year {
+			continue
+		}
+		if flags.Lang != "" && !strings.EqualFold(c.SolutionLang, flags.Lang) {
+			continue
+		}
+		// Provenance headers stay in the local store, out of datasets
+		c.Solution = stripProvenanceHeader(c.Solution)
+		selected = append(selected, c)
+	}
+
+	return write(w, selected)
+}
+
+func writeJSONL(w io.Writer, challenges []Challenge) error {
+	enc := json.NewEncoder(w)
+	for _, c := range challenges {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, challenges []Challenge) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(datasetColumns); err != nil {
+		return err
+	}
+	for _, c := range challenges {
+		record := []string{c.Name, c.Solution, c.Input, c.Task, c.SolutionLang, strconv.FormatInt(c.Year, 10), c.Answer}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeParquet(w io.Writer, challenges []Challenge) error {
+	fields := make([]arrow.Field, len(datasetColumns))
+	for i, name := range datasetColumns {
+		fields[i] = arrow.Field{Name: name, Type: arrow.BinaryTypes.String}
+		if name == "year" {
+			fields[i].Type = arrow.PrimitiveTypes.Int64
+		}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	for _, c := range challenges {
+		builder.Field(0).(*array.StringBuilder).Append(c.Name)
+		builder.Field(1).(*array.StringBuilder).Append(c.Solution)
+		builder.Field(2).(*array.StringBuilder).Append(c.Input)
+		builder.Field(3).(*array.StringBuilder).Append(c.Task)
+		builder.Field(4).(*array.StringBuilder).Append(c.SolutionLang)
+		builder.Field(5).(*array.Int64Builder).Append(c.Year)
+		builder.Field(6).(*array.StringBuilder).Append(c.Answer)
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+
+	return pqarrow.WriteTable(table, w, 1024, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+}
+
+// runImportCommand merges the challenges in path into the store. The format
+// is taken from the file extension: .parquet, .jsonl, .csv or .json.
+func runImportCommand(path string, w io.Writer) error {
+	imported, err := readChallengesFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+
+	challenges, added := mergeChallenges(challenges, imported)
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	if err := replaceChallenges(challenges); err != nil {
+		return fmt.Errorf("error saving challenges: %v", err)
+	}
+
+	fmt.Fprintf(w, "Imported %d challenges (%d new, %d updated).\n", len(imported), added, len(imported)-added)
+	return nil
+}
+
+// mergeChallenges adds imported to challenges. A record with the same name
+// and solution language as an imported one is replaced by it. It returns the
+// merged challenges and how many were new.
+func mergeChallenges(challenges, imported []Challenge) ([]Challenge, int) {
+	index := make(map[string]int)
+	key := func(c Challenge) string { return c.Name + "\x00" + strings.ToLower(c.SolutionLang) }
+	for i, c := range challenges {
+		if _, ok := index[key(c)]; !ok {
+			index[key(c)] = i
+		}
+	}
+
+	added := 0
+	for _, c := range imported {
+		if i, ok := index[key(c)]; ok {
+			challenges[i] = c
+			continue
+		}
+		index[key(c)] = len(challenges)
+		challenges = append(challenges, c)
+		added++
+	}
+	return challenges, added
+}
+
+// readChallengesFile reads challenges from an export or dataset file.
+func readChallengesFile(path string) ([]Challenge, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".parquet":
+		return readParquetFile(path, io.Discard)
+	case ".json":
+		return jsonStore{dir: filepath.Dir(path), file: filepath.Base(path)}.Load()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl":
+		return readJSONL(f)
+	case ".csv":
+		return readCSV(f)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q (expected .parquet, .jsonl, .csv or .json)", filepath.Ext(path))
+	}
+}
+
+func readJSONL(r io.Reader) ([]Challenge, error) {
+	var challenges []Challenge
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var c Challenge
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		challenges = append(challenges, c)
+	}
+	return challenges, scanner.Err()
+}
+
+func readCSV(r io.Reader) ([]Challenge, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("missing name column")
+	}
+	value := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	challenges := make([]Challenge, 0, len(records)-1)
+	for _, record := range records[1:] {
+		year, _ := strconv.ParseInt(value(record, "year"), 10, 64)
+		challenges = append(challenges, Challenge{
+			Name:         value(record, "name"),
+			Solution:     value(record, "solution"),
+			Input:        value(record, "input"),
+			Task:         value(record, "task"),
+			SolutionLang: value(record, "solution_lang"),
+			Year:         year,
+			Answer:       value(record, "answer"),
+		})
+	}
+	return challenges, nil
+}
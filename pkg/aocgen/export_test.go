@@ -0,0 +1,91 @@
+package aocgen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	stored := []Challenge{
+		{Name: "day1_part1_2015", Solution: "print(1)", Input: "(()\n", Task: "Find the floor,\n\"quoted\".", SolutionLang: "python", Year: 2015, Answer: "1"},
+		{Name: "day1_part1_2015", Solution: "package main", Input: "(()\n", Task: "Find the floor.", SolutionLang: "go", Year: 2015, Answer: "1"},
+		{Name: "day2_part1_2016", Input: "R2", Task: "Walk.", Year: 2016},
+	}
+
+	for _, format := range []string{"parquet", "jsonl", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			if err := saveChallenges(stored); err != nil {
+				t.Fatalf("Failed to save challenges: %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := runExportCommand(Flags{Format: format, Year: 2015}, &out); err != nil {
+				t.Fatalf("Failed to export: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "export."+format)
+			if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+				t.Fatalf("Failed to write export: %v", err)
+			}
+
+			if err := saveChallenges([]Challenge{{Name: "day1_part1_2015", Input: "(()\n", SolutionLang: "go", Year: 2015}}); err != nil {
+				t.Fatalf("Failed to save challenges: %v", err)
+			}
+
+			out.Reset()
+			if err := runImportCommand(path, &out); err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+			if !strings.Contains(out.String(), "Imported 2 challenges (1 new, 1 updated)") {
+				t.Errorf("Unexpected import output: %s", out.String())
+			}
+
+			challenges, err := loadChallenges(tempDir, challengesFile)
+			if err != nil {
+				t.Fatalf("Failed to load challenges: %v", err)
+			}
+			if !reflect.DeepEqual(challenges, []Challenge{stored[1], stored[0]}) {
+				t.Errorf("Unexpected challenges after import:\n%+v", challenges)
+			}
+		})
+	}
+}
+
+func TestExportFilters(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "python", Year: 2015},
+		{Name: "day1_part1_2015", SolutionLang: "go", Year: 2015},
+		{Name: "day1_part1_2016", SolutionLang: "go", Year: 2016},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runExportCommand(Flags{Lang: "go"}, &out); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	challenges, err := readJSONL(&out)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if len(challenges) != 2 || challenges[0].Year != 2015 || challenges[1].Year != 2016 {
+		t.Errorf("Expected both go solutions as JSONL, got %+v", challenges)
+	}
+
+	if err := runExportCommand(Flags{Format: "xml"}, &out); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+	if _, err := readChallengesFile("challenges.txt"); err == nil {
+		t.Errorf("Expected an error for an unsupported file type")
+	}
+}
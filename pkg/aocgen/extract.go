@@ -0,0 +1,158 @@
+package aocgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeBlock is a fenced code block of a model response.
+type codeBlock struct {
+	tag  string
+	code string
+}
+
+// languageTags are the info strings models tag code blocks with besides the
+// language name and its file extension.
+var languageTags = map[string][]string{
+	"go":         {"golang"},
+	"python":     {"python3", "py3"},
+	"javascript": {"node", "nodejs"},
+	"typescript": {"ts"},
+	"csharp":     {"c#", "cs"},
+	"fsharp":     {"f#"},
+	"cpp":        {"c++", "cxx"},
+	"objectivec": {"objective-c", "objc"},
+	"elixir":     {"exs"},
+	"fortran90":  {"fortran"},
+}
+
+// nonCodeTags mark blocks that show a command or output rather than the
+// program, such as how to run it.
+var nonCodeTags = map[string]bool{
+	"text": true, "txt": true, "plaintext": true, "console": true, "output": true,
+	"shell": true, "sh": true, "bash": true, "zsh": true, "shell-session": true,
+}
+
+// inlineFence matches a code block that opens and closes on one line.
+var inlineFence = regexp.MustCompile("```(?:.*\n)?([\\s\\S]*?)```")
+
+// parseCodeBlocks returns the fenced code blocks of response in order. A
+// block is closed by a fence of the same character that is at least as long
+// as its opening fence; a block left open, as in a truncated response, runs
+// to the end.
+func parseCodeBlocks(response string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var lines []string
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			for _, c := range []string{"`", "~"} {
+				if strings.HasPrefix(trimmed, c+c+c) {
+					n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+					tag := strings.TrimSpace(trimmed[n:])
+					if c == "`" && strings.Contains(tag, "`") {
+						break // inline code, not a fence
+					}
+					if fields := strings.Fields(tag); len(fields) > 0 {
+						tag = strings.ToLower(strings.Trim(fields[0], "{}."))
+					}
+					current, fence, lines = &codeBlock{tag: tag}, strings.Repeat(c, n), nil
+					break
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.code = strings.Join(lines, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if current != nil {
+		current.code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// matchesLanguage reports whether a block tagged tag is in lang.
+func matchesLanguage(tag, lang string) bool {
+	if tag == "" || lang == "" {
+		return false
+	}
+	lang = strings.ToLower(lang)
+	if tag == lang || tag == languageExtensions[lang] {
+		return true
+	}
+	for _, alias := range languageTags[lang] {
+		if tag == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// selectCodeBlock picks the program among blocks: the longest block tagged
+// with lang, or else the longest block that is not a shell command or
+// output, or else the longest block.
+func selectCodeBlock(blocks []codeBlock, lang string) (codeBlock, bool) {
+	var best codeBlock
+	found := false
+	for _, pick := range []func(codeBlock) bool{
+		func(b codeBlock) bool { return matchesLanguage(b.tag, lang) },
+		func(b codeBlock) bool { return !nonCodeTags[b.tag] },
+		func(b codeBlock) bool { return true },
+	} {
+		for _, b := range blocks {
+			if pick(b) && strings.TrimSpace(b.code) != "" && (!found || len(strings.TrimSpace(b.code)) > len(strings.TrimSpace(best.code))) {
+				best, found = b, true
+			}
+		}
+		if found {
+			return best, true
+		}
+	}
+	return best, false
+}
+
+// stripStrayMarkdown removes what is left of the markdown around a program:
+// fence lines the model left inside the block.
+func stripStrayMarkdown(code string) string {
+	lines := strings.Split(strings.TrimSpace(code), "\n")
+	isFence := func(line string) bool {
+		line = strings.TrimSpace(line)
+		return strings.HasPrefix(line, "```") && !strings.Contains(strings.TrimLeft(line, "`"), "`")
+	}
+	for len(lines) > 0 && isFence(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && isFence(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// extractCode returns the program in a model response. When the response
+// has several code blocks, such as snippets in the explanation or a usage
+// example next to the program, the longest block in lang is taken.
+func extractCode(response, lang string) (string, error) {
+	var code string
+	if block, ok := selectCodeBlock(parseCodeBlocks(response), lang); ok {
+		code = block.code
+	} else if matches := inlineFence.FindStringSubmatch(response); len(matches) == 2 {
+		code = matches[1]
+	} else {
+		return "", fmt.Errorf("no code found in the response")
+	}
+
+	code = stripStrayMarkdown(code)
+	if code == "" {
+		return "", fmt.Errorf("extracted code is empty")
+	}
+	return code, nil
+}
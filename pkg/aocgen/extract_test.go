@@ -0,0 +1,97 @@
+package aocgen
+
+import "testing"
+
+func TestExtractCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		lang     string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "single block",
+			response: "Here you go:\n```python\nprint(1)\n```\nDone.",
+			lang:     "python",
+			want:     "print(1)",
+		},
+		{
+			name:     "usage example first",
+			response: "Run it with:\n```bash\npython solution.py\n```\n\nThe solution:\n```python\nwith open('input.txt') as f:\n    print(len(f.read()))\n```",
+			lang:     "python",
+			want:     "with open('input.txt') as f:\n    print(len(f.read()))",
+		},
+		{
+			name:     "snippet in the explanation is longer but in another language",
+			response: "The input looks like:\n```text\n1000\n2000\n3000\n4000\n5000\n6000\n```\n```go\npackage main\n```",
+			lang:     "go",
+			want:     "package main",
+		},
+		{
+			name:     "tag alias",
+			response: "```golang\npackage main\n```",
+			lang:     "go",
+			want:     "package main",
+		},
+		{
+			name:     "longest untagged block",
+			response: "First, parse:\n```\nlines = read()\n```\nFull program:\n```\nlines = read()\nprint(len(lines))\n```",
+			lang:     "python",
+			want:     "lines = read()\nprint(len(lines))",
+		},
+		{
+			name:     "output block is not the program",
+			response: "```\nprint(42)\n```\nOutput:\n```output\n42 is the answer to everything\n```",
+			lang:     "python",
+			want:     "print(42)",
+		},
+		{
+			name:     "block wrapped in a longer fence",
+			response: "````markdown\n```python\nprint(1)\n```\n````",
+			lang:     "python",
+			want:     "print(1)",
+		},
+		{
+			name:     "stray fence inside the block",
+			response: "```python\n```python\nprint(1)\n```",
+			lang:     "python",
+			want:     "print(1)",
+		},
+		{
+			name:     "truncated response",
+			response: "```python\nprint(1)\nprint(2",
+			lang:     "python",
+			want:     "print(1)\nprint(2",
+		},
+		{
+			name:     "inline fence",
+			response: "Use ```print(1)``` for this.",
+			lang:     "python",
+			want:     "print(1)",
+		},
+		{
+			name:     "no code",
+			response: "I cannot solve this.",
+			lang:     "python",
+			wantErr:  true,
+		},
+		{
+			name:     "empty block",
+			response: "```python\n\n```",
+			lang:     "python",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractCode(tt.response, tt.lang)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("extractCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
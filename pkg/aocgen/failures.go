@@ -0,0 +1,78 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Failures of a benchmarked challenge that happen before its solution is
+// evaluated. The other failures are the verdicts of the evaluation.
+const (
+	failureAPIError   = "api error"
+	failureExtraction = "extraction failure"
+)
+
+// failureClasses orders the failures of a run from those of the model or
+// its provider to those of the environment, followed by errors of aocgen
+// itself.
+var failureClasses = []string{
+	failureAPIError,
+	failureExtraction,
+	string(VerdictCompileError),
+	string(VerdictRuntimeError),
+	string(VerdictWrongAnswer),
+	string(VerdictTimeout),
+	string(VerdictMissingToolchain),
+	outcomeError,
+}
+
+// FailureCount is the number of challenges of a run that failed one way.
+type FailureCount struct {
+	Class string `json:"class"`
+	Count int    `json:"count"`
+}
+
+// generationFailure classifies a generation that returned no code: the
+// model answered without a program it could be extracted from, or the
+// request got no answer at all.
+func generationFailure(transcript Transcript) string {
+	if strings.TrimSpace(transcript.Response) != "" {
+		return failureExtraction
+	}
+	return failureAPIError
+}
+
+// countFailures counts the challenges of run that did not pass by the way
+// they failed, in the order of failureClasses.
+func countFailures(run *BenchmarkRun) []FailureCount {
+	counts := make(map[string]int)
+	for _, result := range run.Results {
+		if outcome := runOutcome(run, result); outcome != outcomePass && outcome != outcomeUnchecked {
+			counts[outcome]++
+		}
+	}
+	failures := []FailureCount{}
+	for _, class := range failureClasses {
+		if counts[class] > 0 {
+			failures = append(failures, FailureCount{Class: class, Count: counts[class]})
+		}
+	}
+	return failures
+}
+
+// describeFailures lists failures as "2 wrong answer, 1 timeout".
+func describeFailures(failures []FailureCount) string {
+	parts := make([]string, len(failures))
+	for i, f := range failures {
+		parts[i] = fmt.Sprintf("%d %s", f.Count, f.Class)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printFailures(w io.Writer, failures []FailureCount) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Failures: %s\n", describeFailures(failures))
+}
@@ -0,0 +1,105 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountFailures(t *testing.T) {
+	run := &BenchmarkRun{ID: "run1", Lang: "python", Model: "gpt-4o-mini", TimeoutMs: 1000, Results: []RunResult{
+		{Challenge: "day1_part1_2015", Verdict: VerdictCorrect},
+		{Challenge: "day2_part1_2015", Verdict: VerdictWrongAnswer},
+		{Challenge: "day3_part1_2015", Verdict: VerdictWrongAnswer},
+		{Challenge: "day4_part1_2015", Verdict: VerdictMissingToolchain, Error: "go not found"},
+		{Challenge: "day5_part1_2015", Failure: failureAPIError, Error: "error generating code with AI: 429 Too Many Requests"},
+		{Challenge: "day6_part1_2015", Failure: failureExtraction, Error: "error generating code with AI: no code found in the response"},
+		{Challenge: "day7_part1_2015", Verdict: VerdictTimeout, Duration: time.Second},
+		{Challenge: "day8_part1_2015", Error: "error creating input file: disk full"},
+		// Without a known answer the solution is neither passed nor failed
+		{Challenge: "day9_part1_2015", Verdict: VerdictUnknownAnswer},
+	}}
+	want := []FailureCount{
+		{failureAPIError, 1},
+		{failureExtraction, 1},
+		{string(VerdictWrongAnswer), 2},
+		{string(VerdictTimeout), 1},
+		{string(VerdictMissingToolchain), 1},
+		{outcomeError, 1},
+	}
+	if got := countFailures(run); !reflect.DeepEqual(got, want) {
+		t.Errorf("countFailures() = %v, want %v", got, want)
+	}
+
+	summary := summarizeRun(run)
+	if summary.Challenges != 8 || summary.Unchecked != 1 || describeRun(summary) != "1/8 passed (12.5%), 1 unchecked without a known answer" {
+		t.Errorf("Expected the unchecked challenge out of the pass rate, got %+v", summary)
+	}
+	var out bytes.Buffer
+	printFailures(&out, summary.Failures)
+	if want := "Failures: 1 api error, 1 extraction failure, 2 wrong answer, 1 timeout, 1 missing toolchain, 1 error\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	report, err := buildRunReport(run)
+	if err != nil {
+		t.Fatalf("buildRunReport failed: %v", err)
+	}
+	if report.Challenges != 8 || report.Errors != 6 {
+		t.Errorf("Expected the unchecked challenge out of the report totals, got %d challenges and %d errors", report.Challenges, report.Errors)
+	}
+	out.Reset()
+	writeMarkdownReport(&out, report)
+	for _, expected := range []string{"## Failures", "| extraction failure | 1 |", "| wrong answer | 2 |"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Markdown report missing %q:\n%s", expected, out.String())
+		}
+	}
+	out.Reset()
+	if err := writeHTMLReport(&out, report); err != nil || !strings.Contains(out.String(), "<tr><td>api error</td><td class=\"num\">1</td></tr>") {
+		t.Errorf("HTML report missing the failures: %v", err)
+	}
+}
+
+func TestGenerationFailure(t *testing.T) {
+	if got := generationFailure(Transcript{Response: "I cannot help with that."}); got != failureExtraction {
+		t.Errorf("Expected an extraction failure for a response without code, got %q", got)
+	}
+	if got := generationFailure(Transcript{}); got != failureAPIError {
+		t.Errorf("Expected an API error without a response, got %q", got)
+	}
+}
+
+func TestBenchmarkClassifiesGenerationFailures(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Sum the numbers.", Input: "1\n2\n", Answer: "3"}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "Add up the lines of the input."}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	flags := Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL, NoFormat: true}
+	if err := runBenchmarkCommand(flags, &out); err != nil {
+		t.Fatalf("benchmark failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Failures: 1 extraction failure\n") {
+		t.Errorf("Expected an extraction failure, got:\n%s", out.String())
+	}
+}
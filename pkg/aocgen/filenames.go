@@ -0,0 +1,85 @@
+package aocgen
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFileNameBytes keeps file names well below the 255 bytes most file
+// systems allow, leaving room for the extension.
+const maxFileNameBytes = 200
+
+// reservedFileNames are the device names Windows does not allow as file
+// names, with or without an extension.
+var reservedFileNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// sanitizeFileName turns name into a file name that is valid on Windows,
+// macOS and Linux and stays in its directory: path separators, characters
+// Windows reserves and control characters become underscores, as do leading
+// dots, trailing dots and spaces are dropped, and reserved device names get
+// an underscore prefix.
+func sanitizeFileName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"/\|?*`, r) {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	name = strings.TrimRight(b.String(), ". ")
+	if trimmed := strings.TrimLeft(name, "."); trimmed != name {
+		name = strings.Repeat("_", len(name)-len(trimmed)) + trimmed
+	}
+	for len(name) > maxFileNameBytes {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" {
+		return "_"
+	}
+	base, _, _ := strings.Cut(name, ".")
+	if reservedFileNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		name = "_" + name
+	}
+	return name
+}
+
+// solutionFileName returns the name of the solution file of the challenge
+// name in the language with extension ext.
+func solutionFileName(name, ext string) string {
+	return sanitizeFileName(name) + "." + ext
+}
+
+// checkOverwrite refuses to replace the solution file filename of challenge
+// unless --force is set or the file holds code aocgen generated or stored
+// for it, so a solution edited by hand is never lost.
+func checkOverwrite(filename string, challenge Challenge, flags Flags) error {
+	if flags.Force {
+		return nil
+	}
+	existing, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", filename, err)
+	}
+	code := strings.TrimSpace(string(existing))
+	if code == "" || (strings.EqualFold(challenge.SolutionLang, flags.Lang) && code == strings.TrimSpace(challenge.Solution)) {
+		return nil
+	}
+	if attempts, err := loadAttempts(); err == nil {
+		for _, a := range attempts {
+			if a.Challenge == challenge.Name && strings.EqualFold(a.Lang, flags.Lang) && strings.TrimSpace(a.Code) == code {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s exists and was not generated by aocgen, use --force to overwrite it or --out to write the solution elsewhere", filename)
+}
@@ -0,0 +1,127 @@
+package aocgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := map[string]string{
+		"day1_part1_2015":        "day1_part1_2015",
+		"../../etc/passwd":       "___.._etc_passwd",
+		`a<b>c:d"e|f?g*h\i`:      "a_b_c_d_e_f_g_h_i",
+		"name. . ":               "name",
+		"CON":                    "_CON",
+		"com1.tar":               "_com1.tar",
+		"console":                "console",
+		"tab\there":              "tab_here",
+		"..":                     "_",
+		"":                       "_",
+		strings.Repeat("é", 150): strings.Repeat("é", 100),
+	}
+	for name, want := range tests {
+		if got := sanitizeFileName(name); got != want {
+			t.Errorf("sanitizeFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGenerateDoesNotOverwriteEditedSolution(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Greet.", Input: "x"}})
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Model: "test", NoFormat: true}
+
+	// Generating again replaces a generated solution
+	for i := 0; i < 2; i++ {
+		if _, err := generateChallengeSolution(context.Background(), flags); err != nil {
+			t.Fatalf("Generation %d failed: %v", i+1, err)
+		}
+	}
+
+	solution := testSolutionPath(t, "day1_part1_2015", "py")
+	os.WriteFile(solution, []byte("print('mine')\n"), 0644)
+	if _, err := generateChallengeSolution(context.Background(), flags); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("Expected generate to refuse overwriting an edited solution, got %v", err)
+	}
+	if code, _ := os.ReadFile(solution); string(code) != "print('mine')\n" {
+		t.Errorf("Expected the edited solution to be kept, got %q", code)
+	}
+
+	flags.Force = true
+	if _, err := generateChallengeSolution(context.Background(), flags); err != nil {
+		t.Fatalf("Generation with --force failed: %v", err)
+	}
+	if code, _ := os.ReadFile(solution); strings.Contains(string(code), "mine") {
+		t.Errorf("Expected --force to overwrite the solution")
+	}
+}
+
+func TestGenerateOut(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Greet.", Input: "x", Answer: "Hello, World!"}})
+	out := filepath.Join("solutions", "2015")
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Model: "test", NoFormat: true, Out: out}
+
+	report, err := generateChallengeSolution(context.Background(), flags)
+	if err != nil {
+		t.Fatalf("Generation failed: %v", err)
+	}
+	if report.File != filepath.Join(out, "day1_part1_2015.py") {
+		t.Errorf("Expected the solution under --out, got %s", report.File)
+	}
+	for _, file := range []string{"day1_part1_2015.py", "input.txt"} {
+		if _, err := os.Stat(filepath.Join(out, file)); err != nil {
+			t.Errorf("Expected %s under --out: %v", file, err)
+		}
+	}
+	if _, err := os.Stat("day1_part1_2015.py"); !os.IsNotExist(err) {
+		t.Errorf("Expected no solution in the current directory")
+	}
+
+	if _, result, err := evaluateChallengeSolution(context.Background(), flags, nil); err != nil || result.Verdict != VerdictCorrect {
+		t.Errorf("Expected eval to read the solution from --out, got %v, %v", result.Verdict, err)
+	}
+
+	if err := runPerformanceBenchmark(Flags{Lang: "python", Out: out, Workers: 1}); err != nil {
+		t.Fatalf("perf failed: %v", err)
+	}
+	runs, _ := os.ReadDir(filepath.Join(tempDir, runsDir))
+	if len(runs) != 1 {
+		t.Fatalf("Expected one run, got %d", len(runs))
+	}
+	run, err := loadBenchmarkRun(strings.TrimSuffix(runs[0].Name(), ".json"))
+	if err != nil {
+		t.Fatalf("Failed to load run: %v", err)
+	}
+	if len(run.Results) != 1 || run.Results[0].Error != "" {
+		t.Errorf("Expected perf to time the solution under --out, got %+v", run.Results)
+	}
+	os.Chdir(t.TempDir())
+	if code := solutionCode(run, "day1_part1_2015", "py", nil); !strings.Contains(code, "Hello, World!") {
+		t.Errorf("Expected the report to read the solution under --out from anywhere, got %q", code)
+	}
+	os.Chdir(tempDir)
+
+	flags.Workdir = "work"
+	if _, err := generateChallengeSolution(context.Background(), flags); err == nil {
+		t.Errorf("Expected --out and --workdir to be rejected together")
+	}
+}
@@ -0,0 +1,55 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// formatTimeout bounds how long a formatter may run on a single solution.
+const formatTimeout = 30 * time.Second
+
+// formatters maps languages to a formatter that reads source code on stdin and
+// writes the formatted code to stdout.
+var formatters = map[string][]string{
+	"go":         {"gofmt"},
+	"python":     {"black", "--quiet", "-"},
+	"javascript": {"prettier", "--stdin-filepath", "solution.js"},
+	"typescript": {"prettier", "--stdin-filepath", "solution.ts"},
+	"rust":       {"rustfmt", "--edition", "2021", "--emit", "stdout"},
+	"elixir":     {"mix", "format", "-"},
+}
+
+// formatCode runs the formatter for lang over code. Code is returned unchanged
+// if the language has no formatter or it is not installed; an error is only
+// returned when the formatter itself fails, typically on a syntax error.
+func formatCode(lang, code string) (string, error) {
+	args, ok := formatters[strings.ToLower(lang)]
+	if !ok {
+		return code, nil
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return code, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), formatTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(code)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return code, fmt.Errorf("%s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	formatted := stdout.String()
+	if strings.TrimSpace(formatted) == "" {
+		return code, nil
+	}
+	return strings.TrimRight(formatted, "\n"), nil
+}
@@ -0,0 +1,35 @@
+package aocgen
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestFormatCode(t *testing.T) {
+	if _, err := exec.LookPath("gofmt"); err != nil {
+		t.Skip("gofmt is not installed")
+	}
+
+	code := "package main\nimport \"fmt\"\nfunc main(){\nfmt.Println(  42)\n}"
+	expected := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(42)\n}"
+	formatted, err := formatCode("go", code)
+	if err != nil {
+		t.Fatalf("Failed to format code: %v", err)
+	}
+	if formatted != expected {
+		t.Errorf("Unexpected formatted code.\nExpected:\n%s\nGot:\n%s", expected, formatted)
+	}
+
+	invalid := "package main\nfunc main() {"
+	formatted, err = formatCode("go", invalid)
+	if err == nil {
+		t.Errorf("Expected an error for invalid code")
+	}
+	if formatted != invalid {
+		t.Errorf("Expected invalid code to be returned unchanged, got: %q", formatted)
+	}
+
+	if formatted, err := formatCode("brainfuck", "+++."); err != nil || formatted != "+++." {
+		t.Errorf("Expected code without a formatter to be unchanged, got %q, %v", formatted, err)
+	}
+}
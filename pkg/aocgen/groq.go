@@ -0,0 +1,100 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const groqAPIURL = "https://api.groq.com/openai/v1/chat/completions"
+
+// callGroqAPI calls Groq's OpenAI-compatible chat completions API with the
+// GROQ_API_KEY. apiURL overrides the default endpoint when set.
+func callGroqAPI(ctx context.Context, apiURL, model, system, prompt string, stream, structured bool, sampling Sampling) (string, Usage, error) {
+	apiKey := os.Getenv("GROQ_API_KEY")
+	if apiKey == "" {
+		return "", Usage{}, fmt.Errorf("GROQ_API_KEY is not set")
+	}
+	if apiURL == "" {
+		apiURL = groqAPIURL
+	}
+
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": chatMessages(system, prompt),
+		"stream":   stream,
+	}
+	setSamplingParams(payload, sampling, "seed")
+	// Only some Groq models support JSON schemas, all support JSON mode
+	if structured {
+		setResponseFormat(payload, false)
+	}
+	requestBody, err := json.Marshal(payload)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	if stream && resp.StatusCode == http.StatusOK {
+		return readStreamedCompletion(resp.Body, streamOutput)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := chatCompletionError(resp.Status, body)
+		if limits := groqRateLimits(resp.Header); limits != "" {
+			return "", Usage{}, fmt.Errorf("%v; %s", err, limits)
+		}
+		return "", Usage{}, err
+	}
+
+	return parseChatCompletion(body)
+}
+
+// groqRateLimits summarizes the rate-limit headers of a Groq response, so a
+// rejected request tells the user how long to wait and which limit was hit.
+func groqRateLimits(header http.Header) string {
+	var parts []string
+	if v := header.Get("Retry-After"); v != "" {
+		parts = append(parts, "retry after "+v+"s")
+	}
+	for _, limit := range []string{"requests", "tokens"} {
+		remaining := header.Get("X-Ratelimit-Remaining-" + limit)
+		if remaining == "" {
+			continue
+		}
+		part := fmt.Sprintf("%s %s remaining", remaining, limit)
+		if max := header.Get("X-Ratelimit-Limit-" + limit); max != "" {
+			part = fmt.Sprintf("%s of %s %s remaining", remaining, max, limit)
+		}
+		if reset := header.Get("X-Ratelimit-Reset-" + limit); reset != "" {
+			part += ", resets in " + reset
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "rate limit: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,66 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCallGroqAPI(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	t.Setenv("GROQ_API_KEY", "groq-key")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer groq-key" {
+			t.Errorf("Unexpected Authorization header: %q", auth)
+		}
+		var requestBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		if requestBody["model"] == "limited" {
+			w.Header().Set("Retry-After", "7")
+			w.Header().Set("X-Ratelimit-Limit-Requests", "14400")
+			w.Header().Set("X-Ratelimit-Remaining-Requests", "0")
+			w.Header().Set("X-Ratelimit-Reset-Requests", "2m59.56s")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"tokens"}}`))
+			return
+		}
+		if requestBody["model"] != "llama3-70b-8192" {
+			t.Errorf("Expected the groq/ prefix to be stripped, got model: %v", requestBody["model"])
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```go\npackage main\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	code, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "groq/llama3-70b-8192", ModelAPI: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to generate code with AI: %v", err)
+	}
+	if code != "package main" {
+		t.Errorf("Unexpected code: %q", code)
+	}
+
+	_, err = generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "groq/limited", ModelAPI: server.URL})
+	if err == nil {
+		t.Fatalf("Expected a rate limit error")
+	}
+	for _, expected := range []string{"Rate limit reached", "retry after 7s", "0 of 14400 requests remaining, resets in 2m59.56s"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got: %v", expected, err)
+		}
+	}
+
+	t.Setenv("GROQ_API_KEY", "")
+	if _, _, err := callGroqAPI(context.Background(), server.URL, "llama3-70b-8192", "", "prompt", false, false, Sampling{}); err == nil || !strings.Contains(err.Error(), "GROQ_API_KEY") {
+		t.Errorf("Expected an error without GROQ_API_KEY, got: %v", err)
+	}
+}
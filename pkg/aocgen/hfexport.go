@@ -0,0 +1,128 @@
+package aocgen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// HFExportReport is the --json form of `aocgen export --hf-dataset`.
+type HFExportReport struct {
+	File     string `json:"file"`
+	Exported int    `json:"exported"`
+	// Unverified solutions have no correct eval of their code on their input
+	// as the latest verdict
+	Unverified int `json:"unverified"`
+	// Upstream solutions are already in the downloaded dataset
+	Upstream int `json:"upstream"`
+	// Incomplete records lack the input, task or answer the dataset has
+	Incomplete int `json:"incomplete"`
+}
+
+// latestVerdicts returns the verdict of the latest eval of each solution,
+// keyed by name, lower-cased language and the evalHash of the code and
+// input. Evals recorded without a hash verify nothing.
+func latestVerdicts(records []EvalRecord) map[string]Verdict {
+	verdicts := make(map[string]Verdict)
+	for _, r := range records {
+		if r.Hash != "" {
+			verdicts[r.Challenge+"\x00"+strings.ToLower(r.Lang)+"\x00"+r.Hash] = r.Verdict
+		}
+	}
+	return verdicts
+}
+
+// selectHFContributions picks the solutions of challenges that can be
+// contributed to the dataset: those in --year and --lang whose latest eval
+// of the same code on the same input was correct, complete with input, task
+// and answer, and not already in upstream with the same code. Provenance
+// headers are stripped.
+func selectHFContributions(challenges, upstream []Challenge, evals []EvalRecord, flags Flags) ([]Challenge, HFExportReport) {
+	var report HFExportReport
+	verdicts := latestVerdicts(evals)
+	known := make(map[string]string, len(upstream))
+	for _, c := range upstream {
+		known[c.Name+"\x00"+strings.ToLower(c.SolutionLang)] = strings.TrimSpace(c.Solution)
+	}
+
+	var selected []Challenge
+	for _, c := range challenges {
+		_, _, year, ok := parseChallengeName(c.Name)
+		if !ok || strings.TrimSpace(c.Solution) == "" || c.SolutionLang == "" {
+			continue
+		}
+		if (flags.Year != 0 && year != flags.Year) || (flags.Lang != "" && !strings.EqualFold(c.SolutionLang, flags.Lang)) {
+			continue
+		}
+		key := c.Name + "\x00" + strings.ToLower(c.SolutionLang)
+		verified := verdicts[key+"\x00"+evalHash(c.Solution, c.Input)] == VerdictCorrect
+		c.Solution = stripProvenanceHeader(c.Solution)
+		switch {
+		case !verified:
+			report.Unverified++
+		case strings.TrimSpace(c.Input) == "" || strings.TrimSpace(c.Task) == "" || strings.TrimSpace(c.Answer) == "":
+			report.Incomplete++
+		case known[key] == strings.TrimSpace(c.Solution):
+			report.Upstream++
+		default:
+			// The dataset names languages in lower case and always has the
+			// year
+			c.SolutionLang, c.Year = strings.ToLower(c.SolutionLang), int64(year)
+			selected = append(selected, c)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Name != selected[j].Name {
+			return challengeLess(selected[i].Name, selected[j].Name)
+		}
+		return selected[i].SolutionLang < selected[j].SolutionLang
+	})
+	report.Exported = len(selected)
+	return selected, report
+}
+
+// runHFDatasetExport writes the verified solutions that are not in the
+// dataset yet to flags.HFDataset as parquet in the schema of the
+// HuggingFace dataset, ready to be contributed to isavita/advent-of-code.
+func runHFDatasetExport(flags Flags, w io.Writer) error {
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+	evals, err := loadEvalLog()
+	if err != nil {
+		return fmt.Errorf("error loading eval history: %v", err)
+	}
+	// Without a downloaded dataset every verified solution is a contribution
+	upstream, err := readDataset(io.Discard)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading the dataset: %v", err)
+	}
+	if err != nil {
+		logger.Warn("the dataset is not downloaded, so solutions already in it are exported too; run 'aocgen setup' first to leave them out")
+	}
+
+	selected, report := selectHFContributions(challenges, upstream, evals, flags)
+	report.File = flags.HFDataset
+	if len(selected) == 0 {
+		return fmt.Errorf("no verified solutions to export: %d unverified, %d incomplete, %d already in the dataset", report.Unverified, report.Incomplete, report.Upstream)
+	}
+
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, selected); err != nil {
+		return fmt.Errorf("error writing parquet: %v", err)
+	}
+	if err := writeFileAtomic(flags.HFDataset, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", flags.HFDataset, err)
+	}
+
+	if flags.JSON {
+		return emitJSON(report)
+	}
+	fmt.Fprintf(w, "Wrote %d verified solutions to %s\n", report.Exported, report.File)
+	fmt.Fprintf(w, "Left out %d unverified, %d incomplete and %d already in the dataset\n", report.Unverified, report.Incomplete, report.Upstream)
+	return nil
+}
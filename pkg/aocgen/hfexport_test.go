@@ -0,0 +1,87 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunHFDatasetExport(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	header := "# aocgen: generated by aocgen dev\n# aocgen: model test\n# aocgen: time 2024-12-01T06:00:00Z\n# aocgen: prompt sha256:0123456789abcdef\n"
+	stored := []Challenge{
+		{Name: "day2_part1_2016", Solution: header + "print(2)", Input: "R2", Task: "Walk.", SolutionLang: "Python", Answer: "2"},
+		{Name: "day1_part1_2015", Solution: "print(1)", Input: "(()", Task: "Floor.", SolutionLang: "python", Year: 2015, Answer: "1"},
+		{Name: "day1_part1_2015", Solution: "package main", Input: "(()", Task: "Floor.", SolutionLang: "go", Year: 2015, Answer: "1"},
+		{Name: "day1_part2_2015", Solution: "print(3)", Input: "(()", Task: "Basement.", SolutionLang: "python", Year: 2015, Answer: "3"},
+		{Name: "day3_part1_2015", Solution: "print(4)", Input: "^v", Task: "Houses.", SolutionLang: "python", Year: 2015},
+		{Name: "day4_part1_2015", Input: "abc", Task: "Mine.", Year: 2015},
+		{Name: "day5_part1_2015", Solution: "print(5)", Input: "aaa", Task: "Nice.", SolutionLang: "python", Year: 2015, Answer: "5"},
+	}
+	if err := saveChallenges(stored); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	evals := []EvalRecord{
+		{Challenge: "day2_part1_2016", Lang: "python", Verdict: VerdictCorrect, Hash: evalHash("print(2)", "R2")},
+		{Challenge: "day1_part1_2015", Lang: "python", Verdict: VerdictCorrect, Hash: evalHash("print(1)", "(()")},
+		{Challenge: "day1_part1_2015", Lang: "go", Verdict: VerdictCorrect, Hash: evalHash("package main", "(()")},
+		{Challenge: "day1_part2_2015", Lang: "python", Verdict: VerdictCorrect, Hash: evalHash("print(3)", "(()")},
+		{Challenge: "day1_part2_2015", Lang: "python", Verdict: VerdictWrongAnswer, Hash: evalHash("print(3)", "(()")},
+		{Challenge: "day3_part1_2015", Lang: "python", Verdict: VerdictCorrect, Hash: evalHash("print(4)", "^v")},
+		// The correct eval was of other code than the stored solution
+		{Challenge: "day5_part1_2015", Lang: "python", Verdict: VerdictCorrect, Hash: evalHash("print(6)", "aaa")},
+	}
+	data, err := json.Marshal(evals)
+	if err != nil {
+		t.Fatalf("Failed to encode evals: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, evalLogFile), data, 0644); err != nil {
+		t.Fatalf("Failed to write evals: %v", err)
+	}
+
+	// The go solution is already in the dataset
+	var upstream bytes.Buffer
+	if err := writeParquet(&upstream, []Challenge{stored[2]}); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, datasetParquet), upstream.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "contribution.parquet")
+	var out bytes.Buffer
+	if err := runExportCommand(Flags{HFDataset: path}, &out); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	for _, want := range []string{
+		"Wrote 2 verified solutions to " + path,
+		"Left out 2 unverified, 1 incomplete and 1 already in the dataset",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	exported, err := readParquetFile(path, &out)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	want := []Challenge{
+		{Name: "day1_part1_2015", Solution: "print(1)", Input: "(()", Task: "Floor.", SolutionLang: "python", Year: 2015, Answer: "1"},
+		{Name: "day2_part1_2016", Solution: "print(2)", Input: "R2", Task: "Walk.", SolutionLang: "python", Year: 2016, Answer: "2"},
+	}
+	if !reflect.DeepEqual(exported, want) {
+		t.Errorf("Unexpected export:\n%+v", exported)
+	}
+
+	if err := runExportCommand(Flags{HFDataset: path, Year: 2015, Lang: "go"}, &out); err == nil || !strings.Contains(err.Error(), "no verified solutions to export") {
+		t.Errorf("Expected an error without solutions to export, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultHTTPTimeout bounds a puzzle download, a model request or the dataset
+// download unless --http-timeout says otherwise.
+const defaultHTTPTimeout = 5 * time.Minute
+
+// commandContext is the context of the running subcommand. Main cancels it on
+// Ctrl-C so in-flight HTTP requests are aborted and running solutions are
+// killed; tests and library callers keep the background context.
+var commandContext = context.Background()
+
+// errInterrupted is returned by work that was cut short by Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// notifyInterrupt returns a context that is cancelled by the first Ctrl-C or
+// SIGTERM.
+// After that the default handling is restored, so a second Ctrl-C exits at
+// once.
+func notifyInterrupt(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// withHTTPTimeout returns a context that expires after timeout. A zero
+// timeout means no limit.
+func withHTTPTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// httpError replaces the error of a request that ran out of time with one
+// that tells how to allow more.
+func httpError(ctx context.Context, timeout time.Duration, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("no response within %v, use --http-timeout to allow more time", timeout)
+	}
+	return err
+}
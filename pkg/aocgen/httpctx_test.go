@@ -0,0 +1,108 @@
+package aocgen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hangingServer never answers until the test ends.
+func hangingServer(t *testing.T) *httptest.Server {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		server.Close()
+	})
+	return server
+}
+
+func TestGenerateCodeHTTPTimeout(t *testing.T) {
+	server := hangingServer(t)
+
+	flags := Flags{Lang: "go", Model: "gpt-4o-mini", ModelAPI: server.URL, HTTPTimeout: 50 * time.Millisecond}
+	_, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, flags)
+	if err == nil || !strings.Contains(err.Error(), "--http-timeout") {
+		t.Errorf("Expected a timeout error mentioning --http-timeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	flags.HTTPTimeout = 0
+	start := time.Now()
+	_, err = generateCodeWithAI(ctx, Challenge{Task: "test task"}, flags)
+	if err == nil || strings.Contains(err.Error(), "--http-timeout") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the request to stop when cancelled, took %v", elapsed)
+	}
+}
+
+func TestFetchDayHTTPTimeout(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	server := hangingServer(t)
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	_, err := fetchDay(context.Background(), Flags{Year: 2015, Day: 1, Session: "test_session", HTTPTimeout: 50 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "--http-timeout") {
+		t.Errorf("Expected a timeout error mentioning --http-timeout, got %v", err)
+	}
+}
+
+func TestSubmitAnswerHTTPTimeout(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	server := hangingServer(t)
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	_, err := submitAnswer(Flags{Year: 2015, Day: 1, Part: 1, Session: "test_session", HTTPTimeout: 50 * time.Millisecond}, "42")
+	if err == nil || !strings.Contains(err.Error(), "--http-timeout") {
+		t.Errorf("Expected a timeout error mentioning --http-timeout, got %v", err)
+	}
+}
+
+func TestDownloadFileCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "dataset.parquet")
+	if _, err := downloadFile(context.Background(), time.Second, path, server.URL, nil, nil); err != nil {
+		t.Fatalf("Failed to download file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := downloadFile(ctx, time.Second, path, server.URL, nil, nil); err == nil {
+		t.Errorf("Expected an error for a cancelled download")
+	}
+}
+
+func TestParseFlagsHTTPTimeout(t *testing.T) {
+	flags, err := parseFlags([]string{"--day", "1"})
+	if err != nil || flags.HTTPTimeout != defaultHTTPTimeout {
+		t.Errorf("Expected the default timeout, got %v, %v", flags.HTTPTimeout, err)
+	}
+	flags, err = parseFlags([]string{"--http-timeout", "30s"})
+	if err != nil || flags.HTTPTimeout != 30*time.Second {
+		t.Errorf("Expected a 30s timeout, got %v, %v", flags.HTTPTimeout, err)
+	}
+}
@@ -0,0 +1,318 @@
+package aocgen
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultModelAPI suggests the chat completions endpoint for a model name.
+func defaultModelAPI(model string) string {
+	switch {
+	case strings.HasPrefix(model, "gpt-"):
+		return "https://api.openai.com/v1/chat/completions"
+	case strings.HasPrefix(model, "ollama/"):
+		return "http://localhost:11434/v1/chat/completions"
+	case strings.HasPrefix(model, "groq/"):
+		return groqAPIURL
+	case strings.HasPrefix(model, "codestral-"):
+		return codestralAPIURL
+	case strings.HasPrefix(model, "mistral-"):
+		return mistralAPIURL
+	default:
+		return ""
+	}
+}
+
+// modelAPIFor returns the endpoint of model when --model_api is not given:
+// the one set for its provider under "model_apis" in the config file, or the
+// provider's default.
+func modelAPIFor(model string) string {
+	if cfg, err := loadConfig(); err == nil {
+		if apiURL := cfg.ModelAPIs[modelProvider(model)]; apiURL != "" {
+			return apiURL
+		}
+	}
+	return defaultModelAPI(model)
+}
+
+// supportedLanguages returns the names of all supported languages in sorted order.
+func supportedLanguages() []string {
+	langs := make([]string, 0, len(languageExtensions))
+	for lang := range languageExtensions {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// detectToolchains reports, for every supported language, whether its
+// toolchain is available on the PATH.
+func detectToolchains() map[string]bool {
+	available := make(map[string]bool)
+	for _, lang := range supportedLanguages() {
+		available[lang] = checkToolchain(lang) == nil
+	}
+	return available
+}
+
+// providerKeys are the environment variables the API key of each provider
+// can be set in.
+var providerKeys = map[string][]string{
+	"openai":  {"OPENAI_API_KEY"},
+	"groq":    {"GROQ_API_KEY"},
+	"mistral": {"MISTRAL_API_KEY", "CODESTRAL_API_KEY"},
+}
+
+// checkModel verifies that model can be used at apiURL: its provider's API
+// key is set and the endpoint answers, or for Ollama, that the model is
+// pulled.
+func checkModel(model, apiURL string) DoctorCheck {
+	check := DoctorCheck{Name: "model"}
+	if keys, ok := providerKeys[modelProvider(model)]; ok {
+		found := false
+		for _, key := range keys {
+			found = found || os.Getenv(key) != ""
+		}
+		if !found {
+			check.Status, check.Detail = CheckFail, fmt.Sprintf("%s is not set", strings.Join(keys, " or "))
+			return check
+		}
+	}
+	if modelProvider(model) != "ollama" {
+		check = checkModelAPI(model, apiURL)
+		check.Name = "model"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(commandContext, doctorTimeout)
+	defer cancel()
+	models, err := listOllamaModels(ctx, ollamaBaseURL(apiURL))
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("cannot reach Ollama: %v", err)
+		return check
+	}
+	if !hasOllamaModel(models, ollamaModelName(model)) {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("%s is not pulled", ollamaModelName(model))
+		return check
+	}
+	check.Status, check.Detail = CheckPass, fmt.Sprintf("%s is available", ollamaModelName(model))
+	return check
+}
+
+// runInitCommand walks the user through first-run configuration, checking
+// the session token and the model as they are entered, offers to download
+// the dataset and writes the config file. Pressing enter at a prompt keeps
+// the value shown in brackets. Once the input ends, every answer left is
+// the default and no question is asked again.
+func runInitCommand(flags Flags, in io.Reader, out io.Writer) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	reader := bufio.NewReader(in)
+	eof := false
+	ask := func(question, current string) (string, error) {
+		if current != "" {
+			fmt.Fprintf(out, "%s [%s]: ", question, current)
+		} else {
+			fmt.Fprintf(out, "%s: ", question)
+		}
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			eof = true
+		} else if err != nil {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return current, nil
+		}
+		return line, nil
+	}
+	// confirm asks a yes or no question; at the end of the input the answer
+	// is no
+	confirm := func(question string, yes bool) (bool, error) {
+		choices := "y/N"
+		if yes {
+			choices = "Y/n"
+		}
+		answer, err := ask(fmt.Sprintf("%s [%s]", question, choices), "")
+		if err != nil || (eof && answer == "") {
+			return false, err
+		}
+		if answer == "" {
+			return yes, nil
+		}
+		return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+	}
+	report := func(check DoctorCheck) {
+		fmt.Fprintf(out, "[%-4s] %s\n", check.Status, check.Detail)
+	}
+
+	fmt.Fprintln(out, "Welcome to aocgen! Let's set up your defaults.")
+	fmt.Fprintln(out)
+
+	fmt.Fprintln(out, "Your Advent of Code session token is the value of the 'session' cookie")
+	fmt.Fprintln(out, "on adventofcode.com (use your browser's developer tools to find it).")
+	session := cfg.Session
+	if keychainEnabled(cfg) {
+		if stored := keychainSession(); stored != "" {
+			session = stored
+		}
+	}
+	for {
+		answer, err := ask("Session token", maskSecret(session))
+		if err != nil {
+			return err
+		}
+		if answer != maskSecret(session) {
+			session = answer
+		}
+		if session == "" {
+			break
+		}
+		check := checkSession(session)
+		report(check)
+		if check.Status == CheckPass || eof {
+			break
+		}
+		keep, err := confirm("Keep it anyway?", false)
+		if err != nil {
+			return err
+		}
+		if keep || eof {
+			break
+		}
+	}
+	// The config file only holds the token where there is no keychain
+	cfg.Session = session
+	if session != "" && keychainEnabled(cfg) && storeSession(session) {
+		cfg.Session = ""
+		fmt.Fprintln(out, "The session token is stored in the keychain.")
+	}
+	fmt.Fprintln(out)
+
+	fmt.Fprintf(out, "Supported languages: %s\n", strings.Join(supportedLanguages(), ", "))
+	for {
+		lang, err := ask("Default language", cfg.Lang)
+		if err != nil {
+			return err
+		}
+		if _, err := getFileExtension(lang); lang != "" && err != nil {
+			fmt.Fprintf(out, "%v\n", err)
+			if eof {
+				break
+			}
+			continue
+		}
+		cfg.Lang = lang
+		break
+	}
+	fmt.Fprintln(out)
+
+	fmt.Fprintln(out, "Models are selected by prefix: gpt-* (OpenAI), ollama/<name> (Ollama), groq/<name> (Groq).")
+	for {
+		model, err := ask("Default model", cfg.Model)
+		if err != nil {
+			return err
+		}
+		if model != cfg.Model && defaultModelAPI(model) != "" {
+			// A new provider needs a new endpoint, so offer the provider default
+			cfg.ModelAPI = defaultModelAPI(model)
+		}
+		cfg.Model = model
+
+		modelAPI, err := ask("Model API endpoint", cfg.ModelAPI)
+		if err != nil {
+			return err
+		}
+		cfg.ModelAPI = modelAPI
+		if cfg.Model == "" {
+			break
+		}
+
+		check := checkModel(cfg.Model, cfg.ModelAPI)
+		report(check)
+		if check.Status == CheckFail && modelProvider(cfg.Model) == "ollama" && strings.HasSuffix(check.Detail, "is not pulled") {
+			pull, err := confirm("Pull it now?", true)
+			if err != nil {
+				return err
+			}
+			if pull {
+				name := ollamaModelName(cfg.Model)
+				err := pullOllamaModel(commandContext, ollamaBaseURL(cfg.ModelAPI), name, func(status string) {
+					fmt.Fprintln(out, status)
+				})
+				if err != nil {
+					fmt.Fprintf(out, "Cannot pull %s: %v\n", name, err)
+				} else {
+					check.Status = CheckPass
+				}
+			}
+		}
+		if check.Status != CheckFail || eof {
+			break
+		}
+		keep, err := confirm("Keep it anyway?", false)
+		if err != nil {
+			return err
+		}
+		if keep || eof {
+			break
+		}
+	}
+	fmt.Fprintln(out)
+
+	fmt.Fprintln(out, "Detected toolchains:")
+	available := detectToolchains()
+	for _, lang := range supportedLanguages() {
+		status := "missing"
+		if available[lang] {
+			status = "found"
+		}
+		fmt.Fprintf(out, "  %-12s %s\n", lang, status)
+	}
+	if cfg.Lang != "" {
+		if !available[cfg.Lang] {
+			fmt.Fprintf(out, "Warning: no toolchain found for your default language %s; eval will not work until it is installed.\n", cfg.Lang)
+		}
+	}
+	fmt.Fprintln(out)
+
+	if err := saveConfig(cfg); err != nil {
+		return fmt.Errorf("error saving config: %v", err)
+	}
+	fmt.Fprintf(out, "Configuration saved to %s\n", filepath.Join(getCacheDir(), configFile))
+
+	if _, err := datasetFiles(); err == nil {
+		return nil
+	}
+	download, err := confirm("Download the dataset of solved challenges now?", true)
+	if err != nil {
+		return err
+	}
+	if !download {
+		fmt.Fprintln(out, "Run 'aocgen setup' to download the dataset.")
+		return nil
+	}
+	if err := setupDataset(commandContext, flags); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "Dataset downloaded.")
+	return nil
+}
+
+// maskSecret hides all but the last four characters of a secret.
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
@@ -0,0 +1,185 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newSessionServer serves the Advent of Code settings page to the session
+// valid and redirects every other session to the login page.
+func newSessionServer(t *testing.T, valid string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != valid {
+			http.Redirect(w, r, "/auth/login", http.StatusFound)
+			return
+		}
+		w.Write([]byte("settings"))
+	}))
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	t.Cleanup(func() {
+		aocBaseURL = originalAocBaseURL
+		server.Close()
+	})
+}
+
+func TestRunInitCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	newSessionServer(t, "abc123session")
+
+	// session, invalid language, valid language, model, accept suggested endpoint
+	input := strings.NewReader("abc123session\nklingon\npython\nollama/llama3\n\n")
+	var out bytes.Buffer
+
+	if err := runInitCommand(Flags{}, input, &out); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "unsupported language: klingon") {
+		t.Errorf("Expected invalid language to be rejected, got output:\n%s", out.String())
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	expected := Config{
+		Session:  "abc123session",
+		Lang:     "python",
+		Model:    "ollama/llama3",
+		ModelAPI: "http://localhost:11434/v1/chat/completions",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Unexpected config.\nExpected: %+v\nGot: %+v", expected, cfg)
+	}
+
+	info, err := os.Stat(filepath.Join(tempDir, configFile))
+	if err != nil {
+		t.Fatalf("Config file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected config file mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// Rerunning and pressing enter everywhere keeps the existing values
+	if err := runInitCommand(Flags{}, strings.NewReader("\n\n\n\n"), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Second init failed: %v", err)
+	}
+	cfg, err = loadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Rerunning init changed the config.\nExpected: %+v\nGot: %+v", expected, cfg)
+	}
+}
+
+func TestRunInitCommandChecks(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	newSessionServer(t, "fresh")
+	ollama, pulls := newOllamaServer(t)
+
+	// expired session, re-entered; model that is not pulled, pulled; no
+	// dataset download
+	input := strings.NewReader("expired\nn\nfresh\npython\nollama/llama3\n" + ollama.URL + "/v1/chat/completions\n\nn\n")
+	var out bytes.Buffer
+	if err := runInitCommand(Flags{}, input, &out); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	for _, want := range []string{"[fail] rejected by Advent of Code", "[ok  ] valid", "[fail] llama3 is not pulled", "success", "Run 'aocgen setup'"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in the output:\n%s", want, out.String())
+		}
+	}
+	if len(*pulls) != 1 || (*pulls)[0] != "llama3" {
+		t.Errorf("Expected llama3 to be pulled, got %v", *pulls)
+	}
+	cfg, _ := loadConfig()
+	if cfg.Session != "fresh" || cfg.Model != "ollama/llama3" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestCheckModel(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	if check := checkModel("gpt-4o", "http://localhost:1"); check.Status != CheckFail || !strings.Contains(check.Detail, "OPENAI_API_KEY") {
+		t.Errorf("Expected a missing API key to fail, got %+v", check)
+	}
+
+	ollama, _ := newOllamaServer(t, "llama3:latest")
+	if check := checkModel("ollama/llama3", ollama.URL+"/api/chat"); check.Status != CheckPass {
+		t.Errorf("Expected a pulled model to pass, got %+v", check)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	cfg := Config{Session: "cfg-session", Lang: "go", Model: "gpt-4o", ModelAPI: "http://cfg"}
+
+	flags := applyConfig(Flags{Lang: "python"}, cfg)
+
+	if flags.Lang != "python" {
+		t.Errorf("Command-line flag should win over config, got lang %q", flags.Lang)
+	}
+	if flags.Session != "cfg-session" || flags.Model != "gpt-4o" || flags.ModelAPI != "http://cfg" {
+		t.Errorf("Expected unset flags to be filled from config, got %+v", flags)
+	}
+
+	flags = applyConfig(Flags{Model: "groq/llama3-70b-8192"}, cfg)
+	if flags.ModelAPI != "" {
+		t.Errorf("Expected the endpoint of an OpenAI model not to be used for Groq, got %q", flags.ModelAPI)
+	}
+}
+
+func TestModelAPIFor(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tests := map[string]string{
+		"gpt-4o-mini":          "https://api.openai.com/v1/chat/completions",
+		"ollama/llama3":        "http://localhost:11434/v1/chat/completions",
+		"groq/llama3-70b-8192": groqAPIURL,
+		"codestral-latest":     codestralAPIURL,
+		"bedrock/meta.llama3":  "",
+	}
+	for model, want := range tests {
+		if got := modelAPIFor(model); got != want {
+			t.Errorf("modelAPIFor(%q) = %q, want %q", model, got, want)
+		}
+	}
+
+	saveConfig(Config{ModelAPIs: map[string]string{"ollama": "http://gpu-box:11434/v1/chat/completions"}})
+	if got := modelAPIFor("ollama/llama3"); got != "http://gpu-box:11434/v1/chat/completions" {
+		t.Errorf("Expected the configured Ollama endpoint, got %q", got)
+	}
+	if got := modelAPIFor("gpt-4o"); got != tests["gpt-4o-mini"] {
+		t.Errorf("Expected the default for other providers, got %q", got)
+	}
+
+	// Generation without --model_api goes to the configured endpoint
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(1)\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+	saveConfig(Config{ModelAPIs: map[string]string{"openai": server.URL}})
+	if _, err := completeCode(context.Background(), "prompt", Flags{Lang: "python", Model: "gpt-4o-mini"}); err != nil || requests != 1 {
+		t.Errorf("Expected one request to the configured endpoint, got %d: %v", requests, err)
+	}
+}
@@ -0,0 +1,127 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultInputHead is the number of lines `aocgen input` shows by default.
+const defaultInputHead = 20
+
+// InputStats describes the shape of a puzzle input.
+type InputStats struct {
+	Lines         int    `json:"lines"`
+	Bytes         int    `json:"bytes"`
+	MaxLineLength int    `json:"max_line_length"`
+	Charset       string `json:"charset"`
+}
+
+// InputReport is the --json form of `aocgen input`.
+type InputReport struct {
+	Name  string      `json:"name"`
+	Head  string      `json:"head"`
+	Stats *InputStats `json:"stats,omitempty"`
+	Saved string      `json:"saved,omitempty"`
+}
+
+// inputStats returns the line count, size, longest line and characters of
+// input.
+func inputStats(input string) InputStats {
+	stats := InputStats{Bytes: len(input)}
+	seen := make(map[rune]bool)
+	for _, line := range strings.Split(strings.TrimRight(input, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		stats.Lines++
+		stats.MaxLineLength = max(stats.MaxLineLength, utf8.RuneCountInString(line))
+		for _, r := range line {
+			seen[r] = true
+		}
+	}
+	stats.Charset = formatCharset(seen)
+	return stats
+}
+
+// formatCharset lists the characters in set in order, shortening runs of
+// three or more consecutive characters to ranges such as 0-9 or a-z.
+func formatCharset(set map[rune]bool) string {
+	runes := make([]rune, 0, len(set))
+	for r := range set {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	var sb strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			fmt.Fprintf(&sb, "%c-%c", runes[i], runes[j])
+		} else {
+			for _, r := range runes[i : j+1] {
+				sb.WriteRune(r)
+			}
+		}
+		i = j + 1
+	}
+	return sb.String()
+}
+
+// runInputCommand prints the start of the stored input of a challenge, with
+// --stats its shape, and with --save writes it to a file.
+func runInputCommand(flags Flags, w io.Writer) error {
+	if flags.Day == 0 || flags.Year == 0 {
+		return fmt.Errorf("--day and --year are required")
+	}
+	if flags.Part == 0 {
+		flags.Part = 1
+	}
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return err
+	}
+	if challenge.Input == "" {
+		return fmt.Errorf("no input stored for %s, download it with --session", challenge.Name)
+	}
+
+	report := InputReport{Name: challenge.Name, Head: inputSample(challenge.Input, flags.Head)}
+	if flags.Stats {
+		stats := inputStats(challenge.Input)
+		report.Stats = &stats
+	}
+	if flags.Save != "" {
+		if err := os.WriteFile(flags.Save, []byte(challenge.Input), 0644); err != nil {
+			return fmt.Errorf("error saving input: %v", err)
+		}
+		report.Saved = flags.Save
+	}
+
+	if flags.JSON {
+		return emitJSON(report)
+	}
+	if report.Head != "" {
+		fmt.Fprintln(w, report.Head)
+		lines := strings.Count(strings.TrimRight(challenge.Input, "\n"), "\n") + 1
+		if shown := strings.Count(report.Head, "\n") + 1; shown < lines {
+			fmt.Fprintf(w, "... (%d more lines)\n", lines-shown)
+		}
+	}
+	if report.Stats != nil {
+		if report.Head != "" {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%-17s %d\n", "Lines:", report.Stats.Lines)
+		fmt.Fprintf(w, "%-17s %s\n", "Size:", formatBytes(int64(report.Stats.Bytes)))
+		fmt.Fprintf(w, "%-17s %d\n", "Max line length:", report.Stats.MaxLineLength)
+		fmt.Fprintf(w, "%-17s %q\n", "Characters:", report.Stats.Charset)
+	}
+	if report.Saved != "" {
+		fmt.Fprintf(w, "Saved the input of %s to %s\n", challenge.Name, report.Saved)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInputStats(t *testing.T) {
+	stats := inputStats("467..114..\n...*......\n..35..633.\n")
+	want := InputStats{Lines: 3, Bytes: 33, MaxLineLength: 10, Charset: "*.13-7"}
+	if stats != want {
+		t.Errorf("inputStats() = %+v, want %+v", stats, want)
+	}
+	if got := inputStats("a b\r\nxyz\r\n").Charset; got != " abx-z" {
+		t.Errorf("Unexpected charset %q", got)
+	}
+}
+
+func TestInputCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2022", Input: "1000\n2000\n\n3000\n4000\n"}})
+
+	var out bytes.Buffer
+	if err := runInputCommand(Flags{Day: 1, Year: 2022, Head: 2, Stats: true}, &out); err != nil {
+		t.Fatalf("input failed: %v", err)
+	}
+	for _, want := range []string{"1000\n2000\n... (3 more lines)\n", "Lines:            5", `Characters:       "0-4"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+
+	path := filepath.Join(tempDir, "saved.txt")
+	data := captureJSON(t, func() error {
+		return runInputCommand(Flags{Day: 1, Year: 2022, Head: 20, Save: path, JSON: true}, &out)
+	})
+	var report InputReport
+	if err := json.Unmarshal(data, &report); err != nil || report.Head != "1000\n2000\n\n3000\n4000" || report.Stats != nil || report.Saved != path {
+		t.Errorf("Unexpected JSON report %s: %v", data, err)
+	}
+	if saved, err := os.ReadFile(path); err != nil || string(saved) != "1000\n2000\n\n3000\n4000\n" {
+		t.Errorf("Expected the input in %s, got %q, %v", path, saved, err)
+	}
+
+	if err := runInputCommand(Flags{Day: 2, Year: 2022, Head: 20}, &out); err == nil {
+		t.Errorf("Expected an error for a challenge that is not stored")
+	}
+}
@@ -0,0 +1,95 @@
+package aocgen
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// keychainService is the service the secrets of aocgen are stored under.
+const keychainService = "aocgen"
+
+// sessionAccount is the account of the Advent of Code session token.
+const sessionAccount = "session"
+
+// errSecretNotFound is returned by Keychain.Get for an account without a
+// secret.
+var errSecretNotFound = errors.New("secret not found")
+
+// Keychain stores secrets in the credential store of the operating system:
+// the macOS Keychain, the Secret Service of Linux desktops, or the Windows
+// Credential Manager.
+type Keychain interface {
+	// Get returns the secret of account, or errSecretNotFound.
+	Get(account string) (string, error)
+	// Set stores the secret of account, replacing the one stored before.
+	Set(account, secret string) error
+}
+
+// osKeychain is the Keychain of the operating system aocgen was built for.
+var osKeychain Keychain = newOSKeychain()
+
+// runSecretCommand runs a credential store tool with stdin as its input, so
+// secrets never appear in the process list, and returns its output.
+func runSecretCommand(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// keychainEnabled reports whether the session token is kept in the keychain.
+// Setting "disable_keychain" in the config file or AOCGEN_NO_KEYCHAIN turns
+// it off, e.g. on headless machines where the keychain would ask for a
+// password.
+func keychainEnabled(cfg Config) bool {
+	return !cfg.DisableKeychain && os.Getenv("AOCGEN_NO_KEYCHAIN") == ""
+}
+
+// keychainSession returns the session token stored in the keychain, or ""
+// if there is none or the keychain cannot be used.
+func keychainSession() string {
+	session, err := osKeychain.Get(sessionAccount)
+	if err != nil {
+		if !errors.Is(err, errSecretNotFound) {
+			logger.Debug("cannot read the session token from the keychain", "err", err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(session)
+}
+
+// storeSession keeps session in the keychain for later commands and
+// reports whether it is stored there.
+func storeSession(session string) bool {
+	if err := osKeychain.Set(sessionAccount, session); err != nil {
+		logger.Debug("cannot store the session token in the keychain", "err", err)
+		return false
+	}
+	return true
+}
+
+// resolveSession returns the session token of a command that was not given
+// --session: the one in the keychain, or else $AOC_SESSION,
+// $ADVENT_OF_CODE_SESSION or the config file. A token found outside the
+// keychain is stored there, so the next commands need neither the variable
+// nor the config file.
+func resolveSession(cfg Config) string {
+	if keychainEnabled(cfg) {
+		if session := keychainSession(); session != "" {
+			return session
+		}
+	}
+	session := os.Getenv("AOC_SESSION")
+	if session == "" {
+		session = os.Getenv("ADVENT_OF_CODE_SESSION")
+	}
+	if session == "" {
+		session = cfg.Session
+	}
+	if session != "" && keychainEnabled(cfg) && storeSession(session) {
+		logger.Info("Stored the session token in the keychain")
+	}
+	return session
+}
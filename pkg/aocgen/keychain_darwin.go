@@ -0,0 +1,35 @@
+package aocgen
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// macKeychain keeps secrets in the login keychain with the security tool.
+type macKeychain struct{}
+
+func newOSKeychain() Keychain { return macKeychain{} }
+
+func (macKeychain) Get(account string) (string, error) {
+	out, err := runSecretCommand("", "security", "find-generic-password", "-s", keychainService, "-a", account, "-w")
+	var exitErr *exec.ExitError
+	// security exits with errSecItemNotFound, 44, for a missing item
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return "", errSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading the keychain: %v", err)
+	}
+	return out, nil
+}
+
+func (macKeychain) Set(account, secret string) error {
+	// Commands read from stdin keep the secret out of the arguments
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keychainService, account, strconv.Quote(secret))
+	if _, err := runSecretCommand(command, "security", "-i"); err != nil {
+		return fmt.Errorf("error writing the keychain: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package aocgen
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// memoryKeychain is a Keychain that keeps secrets in a map.
+type memoryKeychain map[string]string
+
+func (k memoryKeychain) Get(account string) (string, error) {
+	secret, ok := k[account]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return secret, nil
+}
+
+func (k memoryKeychain) Set(account, secret string) error {
+	k[account] = secret
+	return nil
+}
+
+// unavailableKeychain is a Keychain of a machine without a credential store.
+type unavailableKeychain struct{}
+
+func (unavailableKeychain) Get(string) (string, error) { return "", errors.New("no keychain") }
+func (unavailableKeychain) Set(string, string) error   { return errors.New("no keychain") }
+
+func TestResolveSession(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	keychain := memoryKeychain{}
+	osKeychain = keychain
+	t.Setenv("AOC_SESSION", "")
+	t.Setenv("ADVENT_OF_CODE_SESSION", "")
+	t.Setenv("AOCGEN_NO_KEYCHAIN", "")
+
+	if session := resolveSession(Config{}); session != "" {
+		t.Errorf("Expected no session, got %q", session)
+	}
+
+	// A token from the environment is stored on first use
+	t.Setenv("AOC_SESSION", "from-env")
+	if session := resolveSession(Config{Session: "from-config"}); session != "from-env" || keychain[sessionAccount] != "from-env" {
+		t.Errorf("Expected the environment's token to be used and stored, got %q and %v", session, keychain)
+	}
+
+	// and read from the keychain afterwards
+	t.Setenv("AOC_SESSION", "")
+	if session := resolveSession(Config{Session: "from-config"}); session != "from-env" {
+		t.Errorf("Expected the keychain's token, got %q", session)
+	}
+
+	if session := resolveSession(Config{Session: "from-config", DisableKeychain: true}); session != "from-config" {
+		t.Errorf("Expected the keychain to be skipped, got %q", session)
+	}
+
+	// --session replaces the stored token
+	if flags, err := parseCommandFlags([]string{"--session", "from-flag"}); err != nil || flags.Session != "from-flag" {
+		t.Errorf("Expected the flag's token, got %q, %v", flags.Session, err)
+	}
+	if keychain[sessionAccount] != "from-flag" {
+		t.Errorf("Expected --session to be stored, got %v", keychain)
+	}
+
+	// Without a keychain the environment and the config file still work
+	osKeychain = unavailableKeychain{}
+	if session := resolveSession(Config{Session: "from-config"}); session != "from-config" {
+		t.Errorf("Expected the config's token, got %q", session)
+	}
+}
+
+func TestRunInitCommandKeychain(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	newSessionServer(t, "secret")
+
+	keychain := memoryKeychain{}
+	osKeychain = keychain
+	var out bytes.Buffer
+	if err := runInitCommand(Flags{}, strings.NewReader("secret\npython\n\n\n"), &out); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	cfg, _ := loadConfig()
+	if cfg.Session != "" || keychain[sessionAccount] != "secret" {
+		t.Errorf("Expected the token in the keychain and not in the config file, got %q and %v", cfg.Session, keychain)
+	}
+	if !strings.Contains(out.String(), "stored in the keychain") {
+		t.Errorf("Expected the keychain to be mentioned, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !darwin && !windows
+
+package aocgen
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// secretServiceKeychain keeps secrets in the Secret Service of the desktop,
+// such as GNOME Keyring or KWallet, with the secret-tool of libsecret.
+type secretServiceKeychain struct{}
+
+func newOSKeychain() Keychain { return secretServiceKeychain{} }
+
+func (secretServiceKeychain) Get(account string) (string, error) {
+	out, err := runSecretCommand("", "secret-tool", "lookup", "service", keychainService, "account", account)
+	var exitErr *exec.ExitError
+	// secret-tool exits with 1 and prints nothing for a missing secret
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+		return "", errSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading the Secret Service: %v", err)
+	}
+	return out, nil
+}
+
+func (secretServiceKeychain) Set(account, secret string) error {
+	_, err := runSecretCommand(secret, "secret-tool", "store", "--label", keychainService+" "+account, "service", keychainService, "account", account)
+	if err != nil {
+		return fmt.Errorf("error writing the Secret Service: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// versionTimeout bounds a language's version check.
+const versionTimeout = 10 * time.Second
+
+// LanguageConfig adds a language, or changes how a built-in one is built and
+// run, under "languages" in the config file:
+//
+//	"languages": {
+//	  "gleam": {
+//	    "extension": "gleam",
+//	    "run": ["gleam", "run", "--module", "{entry}"],
+//	    "version": ["gleam", "--version"]
+//	  }
+//	}
+//
+// Commands are argument lists with the placeholders of the built-in
+// toolchains: {file}, {dir}, {bin} and {entry}.
+type LanguageConfig struct {
+	// Extension is the file extension of solutions, without the dot. It may
+	// be omitted for built-in languages.
+	Extension string `json:"extension,omitempty"`
+	// Build compiles the solution in a temporary directory; omit it for
+	// languages that run straight from source.
+	Build []string `json:"build,omitempty"`
+	// Run executes the solution from the working directory.
+	Run []string `json:"run"`
+	// Source is the file name the compiler needs the solution to have, if
+	// any; {file} then refers to a copy with that name.
+	Source string `json:"source,omitempty"`
+	// Version prints the version of the toolchain, for `aocgen doctor`.
+	Version []string `json:"version,omitempty"`
+	// Comment starts a line comment, for the header of --header.
+	Comment string `json:"comment,omitempty"`
+}
+
+// RegisterLanguage makes a language available to generate, eval, run and the
+// other commands, or replaces the commands of a built-in one.
+func RegisterLanguage(name string, lang LanguageConfig) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("language name is required")
+	}
+	ext := strings.TrimPrefix(lang.Extension, ".")
+	if ext == "" {
+		ext = languageExtensions[name]
+	}
+	if ext == "" {
+		return fmt.Errorf("language %s: extension is required", name)
+	}
+	if len(lang.Run) == 0 {
+		return fmt.Errorf("language %s: run command is required", name)
+	}
+
+	tc := languageToolchain{Build: lang.Build, Run: lang.Run, Source: lang.Source, Version: lang.Version}
+	if tc.Source == "" {
+		// Built-in languages keep the entry point detection their commands
+		// may rely on
+		tc.Source, tc.Entry = toolchains[name].Source, toolchains[name].Entry
+	}
+	languageExtensions[name] = ext
+	toolchains[name] = tc
+	if lang.Comment != "" {
+		lineComments[name] = commentStyle{Prefix: lang.Comment}
+	}
+	return nil
+}
+
+// registerConfigLanguages registers the languages of the config file.
+func registerConfigLanguages(cfg Config) error {
+	for name, lang := range cfg.Languages {
+		if err := RegisterLanguage(name, lang); err != nil {
+			return fmt.Errorf("invalid language in config: %v", err)
+		}
+	}
+	return nil
+}
+
+// toolchainVersion runs the version check of lang and returns the first line
+// of its output. Languages without a version check yield "".
+func toolchainVersion(lang string) (string, error) {
+	tc, ok := toolchains[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported language: %s", lang)
+	}
+	if len(tc.Version) == 0 {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	path, err := osRunner.LookPath(tc.Version[0])
+	if err != nil {
+		return "", &MissingToolchainError{Lang: lang, Program: tc.Version[0]}
+	}
+	output, err := exec.CommandContext(ctx, path, tc.Version[1:]...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v", strings.Join(tc.Version, " "), err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line), nil
+}
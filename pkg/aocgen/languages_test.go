@@ -0,0 +1,75 @@
+package aocgen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigLanguages(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("Skipping: sh not available")
+	}
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	defer delete(toolchains, "shell")
+	defer delete(languageExtensions, "shell")
+
+	cfg := Config{Languages: map[string]LanguageConfig{
+		"shell": {
+			Extension: ".shell",
+			Run:       []string{"sh", "{file}", "{entry}"},
+			Version:   []string{"sh", "-c", "echo shell 1.0; echo more"},
+		},
+	}}
+	if err := saveConfig(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if _, err := parseCommandFlags(nil); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	ext, err := getFileExtension("shell")
+	if err != nil || ext != "shell" {
+		t.Fatalf("Expected the configured extension, got %q, %v", ext, err)
+	}
+	if version, err := toolchainVersion("shell"); err != nil || version != "shell 1.0" {
+		t.Errorf("Expected the first line of the version check, got %q, %v", version, err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "day1_part1_2015.shell")
+	if err := os.WriteFile(filename, []byte(`echo "answer from $1"`), 0644); err != nil {
+		t.Fatalf("Failed to write solution file: %v", err)
+	}
+	cmd, cleanupCmd, err := getCommand("shell", filename)
+	if err != nil {
+		t.Fatalf("Failed to get command: %v", err)
+	}
+	output, err := cmd.Output()
+	cleanupCmd()
+	if err != nil {
+		t.Fatalf("Failed to run command: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "answer from day1_part1_2015" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+}
+
+func TestRegisterLanguageValidation(t *testing.T) {
+	if err := RegisterLanguage("odin", LanguageConfig{Run: []string{"{bin}"}}); err == nil {
+		t.Error("Expected an error for a new language without an extension")
+	}
+	if err := RegisterLanguage("odin", LanguageConfig{Extension: "odin"}); err == nil {
+		t.Error("Expected an error for a language without a run command")
+	}
+
+	defer func(tc languageToolchain) { toolchains["python"] = tc }(toolchains["python"])
+	if err := RegisterLanguage("Python", LanguageConfig{Run: []string{"pypy3", "{file}"}}); err != nil {
+		t.Fatalf("Failed to override python: %v", err)
+	}
+	if languageExtensions["python"] != "py" || toolchains["python"].Run[0] != "pypy3" {
+		t.Errorf("Expected python to keep its extension and run with pypy3, got %q, %v", languageExtensions["python"], toolchains["python"].Run)
+	}
+}
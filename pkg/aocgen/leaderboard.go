@@ -0,0 +1,247 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const leaderboardsDir = "leaderboards"
+
+// leaderboardRefresh is how long a fetched leaderboard is reused. The site
+// asks that the leaderboard API is requested at most once every 15 minutes.
+const leaderboardRefresh = 15 * time.Minute
+
+// Leaderboard is a private leaderboard as served by the site's JSON API.
+type Leaderboard struct {
+	Event   string                       `json:"event"`
+	OwnerID int64                        `json:"owner_id"`
+	Members map[string]LeaderboardMember `json:"members"`
+}
+
+// LeaderboardMember is one member of a private leaderboard.
+type LeaderboardMember struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Stars       int    `json:"stars"`
+	LocalScore  int    `json:"local_score"`
+	GlobalScore int    `json:"global_score"`
+	LastStarTS  int64  `json:"last_star_ts"`
+	// CompletionDayLevel maps day and part, both as strings, to the star
+	// the member earned for it.
+	CompletionDayLevel map[string]map[string]LeaderboardStar `json:"completion_day_level"`
+}
+
+// LeaderboardStar records when a member solved one part of a day.
+type LeaderboardStar struct {
+	GetStarTS int64 `json:"get_star_ts"`
+	StarIndex int64 `json:"star_index"`
+}
+
+// displayName returns the member's name, or the label the site shows for
+// anonymous users.
+func (m LeaderboardMember) displayName() string {
+	if m.Name == "" {
+		return fmt.Sprintf("(anonymous user #%d)", m.ID)
+	}
+	return m.Name
+}
+
+// dayStars returns how many parts of day the member has solved.
+func (m LeaderboardMember) dayStars(day int) int {
+	return len(m.CompletionDayLevel[strconv.Itoa(day)])
+}
+
+// cachedLeaderboard is a leaderboard stored in the cache with the time it was
+// fetched.
+type cachedLeaderboard struct {
+	FetchedAt   time.Time   `json:"fetched_at"`
+	Leaderboard Leaderboard `json:"leaderboard"`
+}
+
+// LeaderboardReport is the --json form of `aocgen leaderboard`, with the
+// members ranked by local score.
+type LeaderboardReport struct {
+	ID        int                 `json:"id"`
+	Year      int                 `json:"year"`
+	FetchedAt time.Time           `json:"fetched_at"`
+	Members   []LeaderboardMember `json:"members"`
+}
+
+// currentEventYear returns the year of the latest Advent of Code event that
+// has started.
+func currentEventYear() int {
+	now := unlockNow().In(aocTimeZone)
+	if now.Month() < time.December {
+		return now.Year() - 1
+	}
+	return now.Year()
+}
+
+func leaderboardPath(year, id int) string {
+	return filepath.Join(getCacheDir(), leaderboardsDir, fmt.Sprintf("%d_%d.json", year, id))
+}
+
+// loadLeaderboard returns private leaderboard id of year. A copy fetched less
+// than 15 minutes ago is served from the cache; otherwise it is downloaded
+// with the session token and cached.
+func loadLeaderboard(ctx context.Context, flags Flags, year, id int) (cachedLeaderboard, error) {
+	path := leaderboardPath(year, id)
+	var cached cachedLeaderboard
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &cached); err == nil && unlockNow().Sub(cached.FetchedAt) < leaderboardRefresh {
+			return cached, nil
+		}
+	}
+
+	if flags.Session == "" {
+		return cachedLeaderboard{}, fmt.Errorf("session token is required")
+	}
+	leaderboard, err := fetchLeaderboard(ctx, flags, year, id)
+	if err != nil {
+		return cachedLeaderboard{}, err
+	}
+
+	cached = cachedLeaderboard{FetchedAt: unlockNow(), Leaderboard: leaderboard}
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return cachedLeaderboard{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return cachedLeaderboard{}, fmt.Errorf("error caching leaderboard: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return cachedLeaderboard{}, fmt.Errorf("error caching leaderboard: %v", err)
+	}
+	return cached, nil
+}
+
+// fetchLeaderboard downloads a private leaderboard from the JSON API.
+func fetchLeaderboard(ctx context.Context, flags Flags, year, id int) (Leaderboard, error) {
+	ctx, cancel := withHTTPTimeout(ctx, flags.HTTPTimeout)
+	defer cancel()
+
+	if err := verifySession(ctx, flags.Session); err != nil {
+		return Leaderboard{}, err
+	}
+
+	url := fmt.Sprintf("%s/%d/leaderboard/private/view/%d.json", aocBaseURL, year, id)
+	req, err := newAoCRequest(ctx, "GET", url, flags.Session, nil)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	resp, err := doAoCRequest(&http.Client{}, req)
+	if err != nil {
+		return Leaderboard{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Leaderboard{}, fmt.Errorf("leaderboard %d not found for %d", id, year)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Leaderboard{}, fmt.Errorf("failed to download leaderboard: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Leaderboard{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	// Without access the site redirects to an HTML page instead of the JSON
+	var leaderboard Leaderboard
+	if err := json.Unmarshal(body, &leaderboard); err != nil {
+		return Leaderboard{}, fmt.Errorf("leaderboard %d is not accessible, check your session token and that you are a member", id)
+	}
+	return leaderboard, nil
+}
+
+// rankMembers returns the members ordered by local score, then stars, then
+// name.
+func rankMembers(leaderboard Leaderboard) []LeaderboardMember {
+	members := make([]LeaderboardMember, 0, len(leaderboard.Members))
+	for _, m := range leaderboard.Members {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].LocalScore != members[j].LocalScore {
+			return members[i].LocalScore > members[j].LocalScore
+		}
+		if members[i].Stars != members[j].Stars {
+			return members[i].Stars > members[j].Stars
+		}
+		return members[i].displayName() < members[j].displayName()
+	})
+	return members
+}
+
+// printLeaderboard writes the ranked members as a table. The star column has
+// one symbol per day: * both parts, + part one only, . unsolved.
+func printLeaderboard(w io.Writer, report LeaderboardReport) {
+	fmt.Fprintf(w, "Private leaderboard %d (%d), fetched %s\n\n", report.ID, report.Year, report.FetchedAt.Local().Format("Jan 2 15:04"))
+	if len(report.Members) == 0 {
+		fmt.Fprintln(w, "The leaderboard has no members.")
+		return
+	}
+
+	fmt.Fprintf(w, "%4s  %5s  %5s  %-25s  %s\n", "Rank", "Score", "Stars", "Days", "Name")
+	for i, m := range report.Members {
+		var days strings.Builder
+		for day := 1; day <= 25; day++ {
+			switch m.dayStars(day) {
+			case 0:
+				days.WriteByte('.')
+			case 1:
+				days.WriteByte('+')
+			default:
+				days.WriteByte('*')
+			}
+		}
+		fmt.Fprintf(w, "%4d  %5d  %5d  %s  %s\n", i+1, m.LocalScore, m.Stars, days.String(), m.displayName())
+	}
+}
+
+func runLeaderboardCommand(flags Flags, w io.Writer) error {
+	if flags.LeaderboardID == 0 {
+		return fmt.Errorf("--id is required")
+	}
+	year := flags.Year
+	if year == 0 {
+		year = currentEventYear()
+	}
+
+	cached, err := loadLeaderboard(commandContext, flags, year, flags.LeaderboardID)
+	if err != nil {
+		return err
+	}
+
+	report := LeaderboardReport{
+		ID:        flags.LeaderboardID,
+		Year:      year,
+		FetchedAt: cached.FetchedAt,
+		Members:   rankMembers(cached.Leaderboard),
+	}
+	switch {
+	case flags.JSON:
+		return emitJSON(report)
+	case flags.Format == "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	case flags.Format == "" || flags.Format == "table":
+		printLeaderboard(w, report)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", flags.Format)
+	}
+}
@@ -0,0 +1,126 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLeaderboard = `{"event":"2023","owner_id":1,"members":{
+"1":{"id":1,"name":"alice","stars":3,"local_score":10,"global_score":0,"last_star_ts":1701500000,
+ "completion_day_level":{"1":{"1":{"get_star_ts":1701400000,"star_index":1},"2":{"get_star_ts":1701400100,"star_index":2}},"2":{"1":{"get_star_ts":1701500000,"star_index":5}}}},
+"2":{"id":2,"name":null,"stars":4,"local_score":12,"global_score":0,"last_star_ts":1701500100,
+ "completion_day_level":{"1":{"1":{"get_star_ts":1701400200,"star_index":3},"2":{"get_star_ts":1701400300,"star_index":4}},"2":{"1":{"get_star_ts":1701500050,"star_index":6},"2":{"get_star_ts":1701500100,"star_index":7}}}}}}`
+
+func TestRunLeaderboardCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	withoutAoCThrottle(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/settings" {
+			return
+		}
+		requests++
+		if r.URL.Path != "/2023/leaderboard/private/view/42.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if c, err := r.Cookie("session"); err != nil || c.Value != "test_session" {
+			w.Write([]byte("<html>log in</html>"))
+			return
+		}
+		w.Write([]byte(testLeaderboard))
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	now := time.Date(2023, time.December, 3, 12, 0, 0, 0, aocTimeZone)
+	originalNow := unlockNow
+	unlockNow = func() time.Time { return now }
+	defer func() { unlockNow = originalNow }()
+
+	flags := Flags{LeaderboardID: 42, Session: "test_session"}
+	var out bytes.Buffer
+	if err := runLeaderboardCommand(flags, &out); err != nil {
+		t.Fatalf("Failed to show leaderboard: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[0], "42 (2023)") {
+		t.Fatalf("Unexpected table:\n%s", out.String())
+	}
+	if !strings.Contains(lines[3], "12      4  **.......................  (anonymous user #2)") {
+		t.Errorf("Expected the anonymous member first with both days solved, got %q", lines[3])
+	}
+	if !strings.Contains(lines[4], "10      3  *+.......................  alice") {
+		t.Errorf("Expected alice second with part one of day 2, got %q", lines[4])
+	}
+
+	// Within 15 minutes the cached copy is used
+	now = now.Add(10 * time.Minute)
+	out.Reset()
+	flags.Format = "json"
+	if err := runLeaderboardCommand(flags, &out); err != nil {
+		t.Fatalf("Failed to show leaderboard: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the cached leaderboard to be used, got %d requests", requests)
+	}
+	var report LeaderboardReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out.String())
+	}
+	if report.Year != 2023 || len(report.Members) != 2 || report.Members[1].Name != "alice" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if err := runLeaderboardCommand(flags, &bytes.Buffer{}); err != nil {
+		t.Fatalf("Failed to show leaderboard: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected the leaderboard to be fetched again after 15 minutes, got %d requests", requests)
+	}
+
+	if err := runLeaderboardCommand(Flags{LeaderboardID: 42, Year: 2022, Session: "test_session"}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+	if err := runLeaderboardCommand(Flags{LeaderboardID: 42, Year: 2023, Session: "bad"}, &bytes.Buffer{}); err != nil {
+		t.Errorf("Expected the cached leaderboard regardless of the session, got %v", err)
+	}
+	now = now.Add(time.Hour)
+	if err := runLeaderboardCommand(Flags{LeaderboardID: 42, Year: 2023, Session: "bad"}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "not accessible") {
+		t.Errorf("Expected an access error for a rejected session, got %v", err)
+	}
+	if err := runLeaderboardCommand(Flags{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected an error without --id")
+	}
+}
+
+func TestCurrentEventYear(t *testing.T) {
+	originalNow := unlockNow
+	defer func() { unlockNow = originalNow }()
+
+	tests := []struct {
+		now      time.Time
+		expected int
+	}{
+		{time.Date(2024, time.November, 30, 23, 0, 0, 0, aocTimeZone), 2023},
+		{time.Date(2024, time.December, 1, 0, 0, 0, 0, aocTimeZone), 2024},
+		{time.Date(2025, time.January, 5, 0, 0, 0, 0, aocTimeZone), 2024},
+	}
+	for _, tt := range tests {
+		unlockNow = func() time.Time { return tt.now }
+		if got := currentEventYear(); got != tt.expected {
+			t.Errorf("currentEventYear() at %v = %d, expected %d", tt.now, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Client downloads challenges from Advent of Code.
+type Client struct {
+	// Session is the value of the adventofcode.com session cookie.
+	Session string
+}
+
+// Download fetches the task and input of one part of a puzzle. The task of
+// part 2 includes the text of part 1. The challenge is not stored; use a
+// Store for that. Cancelling ctx aborts the requests.
+func (c Client) Download(ctx context.Context, year, day, part int) (Challenge, error) {
+	if c.Session == "" {
+		return Challenge{}, fmt.Errorf("session token is required")
+	}
+	flags := Flags{Year: year, Day: day, Part: part, Session: c.Session, HTTPTimeout: defaultHTTPTimeout}
+	if err := verifyCommandSession(ctx, flags); err != nil {
+		return Challenge{}, err
+	}
+	return fetchChallenge(ctx, flags)
+}
+
+// Store reads and writes challenges in a cache directory.
+type Store struct {
+	// Dir is the cache directory; empty means ~/.aocgen.
+	Dir string
+	// Backend is "json", "sqlite" or a name passed to RegisterStoreBackend;
+	// empty means the storage configured in the config file.
+	Backend string
+}
+
+func (s Store) dir() string {
+	if s.Dir == "" {
+		return getCacheDir()
+	}
+	return s.Dir
+}
+
+func (s Store) backend() string {
+	if s.Backend == "" {
+		return storageBackend()
+	}
+	return s.Backend
+}
+
+// Load returns every stored challenge. An empty store yields no challenges.
+func (s Store) Load() ([]Challenge, error) {
+	store, err := openStore(s.dir(), s.backend())
+	if err != nil {
+		return nil, err
+	}
+
+	challenges, err := store.Load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error loading challenges: %v", err)
+	}
+	return challenges, nil
+}
+
+// Save replaces the stored challenges.
+func (s Store) Save(challenges []Challenge) error {
+	store, err := openStore(s.dir(), s.backend())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir(), 0755); err != nil {
+		return err
+	}
+	return store.Save(challenges)
+}
+
+// Find returns the first stored record of a challenge.
+func (s Store) Find(year, day, part int) (Challenge, error) {
+	challenges, err := s.Load()
+	if err != nil {
+		return Challenge{}, err
+	}
+	return findChallenge(challenges, Flags{Year: year, Day: day, Part: part})
+}
+
+// Put stores a downloaded challenge. An existing record gets the new task and
+// input but keeps its solutions and answers.
+func (s Store) Put(challenge Challenge) error {
+	challenges, err := s.Load()
+	if err != nil {
+		return err
+	}
+	return s.Save(upsertChallenge(challenges, challenge))
+}
+
+// Generator asks a model to write solutions.
+type Generator struct {
+	// Model selects the provider by prefix, e.g. gpt-4o-mini, ollama/llama3,
+	// groq/llama3-70b-8192 or bedrock/<model-id>.
+	Model string
+	// ModelAPI overrides the provider's default endpoint.
+	ModelAPI string
+	Lang     string
+	// Examples is the number of solved challenges included as few-shot
+	// examples.
+	Examples int
+	// WithPart1 includes the stored part 1 solution in the prompt of part 2.
+	WithPart1 bool
+	// Template is a prompt template file used instead of the default.
+	Template string
+	// InputArg asks for a program that reads the input file path from its
+	// first argument; evaluate it with Evaluator.InputArg.
+	InputArg bool
+	// InputLines is the number of lines of the puzzle input shown in the
+	// prompt, like --input-lines; zero leaves the input out.
+	InputLines int
+	Sampling   Sampling
+	// CacheResponses reuses the stored response to an identical earlier
+	// request instead of sending it again.
+	CacheResponses bool
+}
+
+// Generate returns the solution code the model wrote for challenge.
+// Cancelling ctx aborts the model request.
+func (g Generator) Generate(ctx context.Context, challenge Challenge) (string, error) {
+	if g.Lang == "" {
+		return "", fmt.Errorf("language is required")
+	}
+	return generateCodeWithAI(ctx, challenge, Flags{
+		Lang:           g.Lang,
+		Model:          g.Model,
+		ModelAPI:       g.ModelAPI,
+		Examples:       g.Examples,
+		WithPart1:      g.WithPart1,
+		Template:       g.Template,
+		InputArg:       g.InputArg,
+		InputLines:     g.InputLines,
+		Sampling:       g.Sampling,
+		CacheResponses: g.CacheResponses,
+		HTTPTimeout:    defaultHTTPTimeout,
+	})
+}
+
+// Evaluator builds and runs solutions and checks their answers.
+type Evaluator struct {
+	Lang string
+	// Timeout overrides the language's default time limit.
+	Timeout time.Duration
+	// Memory is a memory limit in bytes (Linux only); zero means no limit.
+	Memory int64
+	// Lenient accepts the answer anywhere in the output instead of only on
+	// the last line.
+	Lenient bool
+	// Normalize names normalizations applied to both answers before they
+	// are compared: "casefold", "commas" or "spaces".
+	Normalize []string
+	// InputArg passes the path of a temporary input file to the solution as
+	// its first argument, which makes concurrent evaluations safe.
+	InputArg bool
+}
+
+// Evaluate runs the solution in filename against challenge in the directory
+// of filename. The input is written to input.txt there first, or to a
+// temporary file whose path is passed to the solution when InputArg is set.
+// Cancelling ctx kills the solution.
+func (e Evaluator) Evaluate(ctx context.Context, challenge Challenge, filename string) (EvalResult, error) {
+	var args []string
+	if e.InputArg {
+		inputPath, cleanup, err := writeTempInputFile(challenge)
+		if err != nil {
+			return EvalResult{}, fmt.Errorf("error creating input file: %v", err)
+		}
+		defer cleanup()
+		args = append(args, inputPath)
+	} else if err := writeInputFile(filepath.Dir(filename), challenge); err != nil {
+		return EvalResult{}, fmt.Errorf("error creating input file: %v", err)
+	}
+
+	limits := resolveLimits(e.Lang, Flags{}, Config{})
+	if e.Timeout > 0 {
+		limits.Timeout = e.Timeout
+	}
+	limits.Memory = e.Memory
+
+	normalize, err := parseNormalize(strings.Join(e.Normalize, ","))
+	if err != nil {
+		return EvalResult{}, err
+	}
+	return judgeSolutionLive(ctx, challenge, filename, e.Lang, limits, answerMatch{Lenient: e.Lenient, Normalize: normalize}, nil, args...)
+}
+
+// SetLogger replaces the logger aocgen writes progress messages and warnings
+// to, and at debug level the model API requests and responses with
+// credentials redacted. It returns a function that restores the previous
+// logger.
+func SetLogger(l *slog.Logger) func() {
+	old := logger
+	logger = l
+	return func() { logger = old }
+}
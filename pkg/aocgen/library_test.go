@@ -0,0 +1,146 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir(), Backend: "json"}
+
+	challenges, err := store.Load()
+	if err != nil || len(challenges) != 0 {
+		t.Fatalf("Expected an empty store, got %v, %v", challenges, err)
+	}
+
+	err = store.Save([]Challenge{{Name: "day1_part1_2015", Task: "old task", Solution: "print(1)", SolutionLang: "python"}})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+	if err := store.Put(Challenge{Name: "day1_part1_2015", Task: "new task", Input: "()"}); err != nil {
+		t.Fatalf("Failed to put challenge: %v", err)
+	}
+	if err := store.Put(Challenge{Name: "day2_part1_2015", Task: "wrap"}); err != nil {
+		t.Fatalf("Failed to put challenge: %v", err)
+	}
+
+	challenge, err := store.Find(2015, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to find challenge: %v", err)
+	}
+	if challenge.Task != "new task" || challenge.Input != "()" || challenge.Solution != "print(1)" {
+		t.Errorf("Expected the new task and input with the old solution, got: %+v", challenge)
+	}
+	if _, err := store.Find(2015, 3, 1); err == nil {
+		t.Errorf("Expected an error for a missing challenge")
+	}
+}
+
+func TestClientDownload(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2015/day/1":
+			w.Write([]byte(`<article class="day-desc"><h2>--- Day 1: Not Quite Lisp ---</h2><p>Find the floor.</p></article>`))
+		case "/2015/day/1/input":
+			w.Write([]byte("(()"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	challenge, err := Client{Session: "test_session"}.Download(context.Background(), 2015, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to download challenge: %v", err)
+	}
+	if challenge.Name != "day1_part1_2015" || challenge.Input != "(()" || challenge.Year != 2015 {
+		t.Errorf("Unexpected challenge: %+v", challenge)
+	}
+
+	if _, err := (Client{}).Download(context.Background(), 2015, 1, 1); err == nil {
+		t.Errorf("Expected an error without a session")
+	}
+}
+
+func TestGeneratorAndEvaluator(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(open('input.txt').read().count('('))\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	challenge := Challenge{Name: "day1_part1_2015", Input: "(()", Answer: "2"}
+
+	code, err := Generator{Model: "gpt-4o-mini", ModelAPI: server.URL, Lang: "python"}.Generate(context.Background(), challenge)
+	if err != nil {
+		t.Fatalf("Failed to generate solution: %v", err)
+	}
+
+	wd, _ := os.Getwd()
+	dir := t.TempDir()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+
+	filename := filepath.Join(dir, "day1_part1_2015.py")
+	if err := os.WriteFile(filename, []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write solution: %v", err)
+	}
+
+	result, err := Evaluator{Lang: "python"}.Evaluate(context.Background(), challenge, filename)
+	if err != nil {
+		t.Fatalf("Failed to evaluate solution: %v", err)
+	}
+	if result.Verdict != VerdictCorrect {
+		t.Errorf("Expected a correct verdict, got %s: %s", result.Verdict, result.Output)
+	}
+}
+
+func TestEvaluatorInputArg(t *testing.T) {
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	dir := t.TempDir()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+
+	filename := filepath.Join(dir, "day1_part1_2015.py")
+	code := "import sys\nprint(open(sys.argv[1]).read().count('('))\n"
+	if err := os.WriteFile(filename, []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write solution: %v", err)
+	}
+
+	challenge := Challenge{Name: "day1_part1_2015", Input: "(()", Answer: "2"}
+	result, err := Evaluator{Lang: "python", InputArg: true}.Evaluate(context.Background(), challenge, filename)
+	if err != nil {
+		t.Fatalf("Failed to evaluate solution: %v", err)
+	}
+	if result.Verdict != VerdictCorrect {
+		t.Errorf("Expected a correct verdict, got %s: %s", result.Verdict, result.Output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "input.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected no input.txt in the working directory")
+	}
+}
@@ -0,0 +1,66 @@
+package aocgen
+
+import (
+	"strings"
+	"time"
+)
+
+// Limits bounds the resources a solution may use while it is evaluated.
+type Limits struct {
+	Timeout time.Duration
+	// Memory is the maximum data segment size in bytes; zero means no limit.
+	Memory int64
+}
+
+// LanguageLimits overrides the evaluation limits for one language in the
+// config file.
+type LanguageLimits struct {
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
+	MemoryMB  int64 `json:"memory_mb,omitempty"`
+}
+
+const defaultEvalTimeout = 20 * time.Second
+
+// languageTimeouts holds the default timeout for languages whose runtime or
+// interpreter takes a long time to start.
+var languageTimeouts = map[string]time.Duration{
+	"java":    60 * time.Second,
+	"kotlin":  60 * time.Second,
+	"scala":   90 * time.Second,
+	"groovy":  60 * time.Second,
+	"clojure": 60 * time.Second,
+	"csharp":  60 * time.Second,
+	"fsharp":  60 * time.Second,
+	"julia":   60 * time.Second,
+	"dart":    40 * time.Second,
+	"racket":  40 * time.Second,
+}
+
+// resolveLimits picks the evaluation limits for lang. Flags win over the
+// language's entry in the config file, which wins over the built-in defaults.
+func resolveLimits(lang string, flags Flags, cfg Config) Limits {
+	lang = strings.ToLower(lang)
+
+	limits := Limits{Timeout: defaultEvalTimeout}
+	if timeout, ok := languageTimeouts[lang]; ok {
+		limits.Timeout = timeout
+	}
+
+	if l, ok := cfg.Limits[lang]; ok {
+		if l.TimeoutMs > 0 {
+			limits.Timeout = time.Duration(l.TimeoutMs) * time.Millisecond
+		}
+		if l.MemoryMB > 0 {
+			limits.Memory = l.MemoryMB << 20
+		}
+	}
+
+	if flags.Timeout > 0 {
+		limits.Timeout = time.Duration(flags.Timeout) * time.Millisecond
+	}
+	if flags.Memory > 0 {
+		limits.Memory = flags.Memory << 20
+	}
+
+	return limits
+}
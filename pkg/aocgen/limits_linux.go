@@ -0,0 +1,26 @@
+package aocgen
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// limitMemory makes cmd start with its data segment capped. The shell sets
+// the limit and then execs the solution, so the cap is in place before the
+// solution runs and is inherited by every process it forks. RLIMIT_DATA is
+// used rather than RLIMIT_AS because managed runtimes such as the JVM and Go
+// reserve far more address space than they ever touch.
+func limitMemory(cmd *exec.Cmd, bytes int64) error {
+	if bytes <= 0 || cmd.Err != nil {
+		return nil
+	}
+	shell, err := exec.LookPath("sh")
+	if err != nil {
+		return fmt.Errorf("failed to set memory limit: %v", err)
+	}
+	// ulimit counts in kilobytes
+	script := fmt.Sprintf(`ulimit -d %d && exec "$0" "$@"`, (bytes+1023)/1024)
+	cmd.Args = append([]string{"sh", "-c", script, cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = shell
+	return nil
+}
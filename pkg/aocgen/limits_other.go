@@ -0,0 +1,15 @@
+//go:build !linux
+
+package aocgen
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func limitMemory(cmd *exec.Cmd, bytes int64) error {
+	if bytes <= 0 {
+		return nil
+	}
+	return fmt.Errorf("memory limits are only supported on Linux")
+}
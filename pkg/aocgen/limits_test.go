@@ -0,0 +1,91 @@
+package aocgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestResolveLimits(t *testing.T) {
+	cfg := Config{Limits: map[string]LanguageLimits{
+		"haskell": {TimeoutMs: 45000, MemoryMB: 512},
+		"java":    {MemoryMB: 1024},
+	}}
+
+	tests := []struct {
+		name     string
+		lang     string
+		flags    Flags
+		expected Limits
+	}{
+		{"default", "python", Flags{}, Limits{Timeout: defaultEvalTimeout}},
+		{"built-in language default", "scala", Flags{}, Limits{Timeout: 90 * time.Second}},
+		{"config", "haskell", Flags{}, Limits{Timeout: 45 * time.Second, Memory: 512 << 20}},
+		{"config keeps built-in timeout", "java", Flags{}, Limits{Timeout: 60 * time.Second, Memory: 1024 << 20}},
+		{"flags win", "haskell", Flags{Timeout: 1500, Memory: 64}, Limits{Timeout: 1500 * time.Millisecond, Memory: 64 << 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveLimits(tt.lang, tt.flags, cfg); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestJudgeSolutionMemoryLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory limits are only enforced on Linux")
+	}
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "solution.py")
+	os.WriteFile(filename, []byte("data = bytearray(512 * 1024 * 1024)\nprint(42)\n"), 0644)
+
+	result, err := judgeSolution(context.Background(), Challenge{Answer: "42"}, filename, "python", Limits{Timeout: 10 * time.Second}, false)
+	if err != nil {
+		t.Fatalf("Evaluation failed: %v", err)
+	}
+	if result.Verdict != VerdictCorrect {
+		t.Fatalf("Expected the solution to pass without a memory limit, got %s: %s", result.Verdict, result.Output)
+	}
+
+	result, err = judgeSolution(context.Background(), Challenge{Answer: "42"}, filename, "python", Limits{Timeout: 10 * time.Second, Memory: 128 << 20}, false)
+	if err != nil {
+		t.Fatalf("Evaluation failed: %v", err)
+	}
+	if result.Verdict != VerdictRuntimeError {
+		t.Errorf("Expected a runtime error under a 128MB limit, got %s: %s", result.Verdict, result.Output)
+	}
+}
+
+func TestJudgeSolutionMemoryLimitCoversChildren(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory limits are only enforced on Linux")
+	}
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	// The limit is already in place when the solution starts a process
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "solution.py")
+	os.WriteFile(filename, []byte(`import subprocess, sys
+print(subprocess.check_output([sys.executable, "-c", "import resource; print(resource.getrlimit(resource.RLIMIT_DATA)[0])"], text=True).strip())
+`), 0644)
+
+	result, err := judgeSolution(context.Background(), Challenge{Answer: "134217728"}, filename, "python", Limits{Timeout: 10 * time.Second, Memory: 128 << 20}, false)
+	if err != nil {
+		t.Fatalf("Evaluation failed: %v", err)
+	}
+	if result.Verdict != VerdictCorrect {
+		t.Errorf("Expected the child to run under the 128MB limit, got %s: %s", result.Verdict, result.Output)
+	}
+}
@@ -0,0 +1,290 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logger receives the diagnostics of every command: progress at info level,
+// warnings, errors, and request/response details of model API calls at debug
+// level. By default info and above are written to stderr.
+var logger = slog.New(newConsoleHandler(os.Stderr, slog.LevelInfo))
+
+// logBodyLimit is the number of bytes of a request or response body logged.
+const logBodyLimit = 4096
+
+// redactedHeaders are the request headers whose values never reach the log.
+var redactedHeaders = map[string]bool{
+	"Authorization":        true,
+	"Api-Key":              true,
+	"Cookie":               true,
+	"X-Api-Key":            true,
+	"X-Goog-Api-Key":       true,
+	"X-Amz-Security-Token": true,
+}
+
+// redactedParams are the query parameters whose values never reach the log.
+var redactedParams = []string{"key", "api_key", "token"}
+
+// setupLogging configures logger for --verbose, --quiet and --log-file. The
+// log file gets every message, including debug ones, whatever the console
+// level. It returns a function that closes the log file and restores the
+// previous logger.
+func setupLogging(flags Flags) (func(), error) {
+	if flags.Verbose && flags.Quiet {
+		return nil, fmt.Errorf("--verbose and --quiet cannot be used together")
+	}
+	level := slog.LevelInfo
+	switch {
+	case flags.Verbose:
+		level = slog.LevelDebug
+	case flags.Quiet:
+		level = slog.LevelWarn
+	}
+
+	old := logger
+	var handler slog.Handler = newConsoleHandler(os.Stderr, level)
+	var file *os.File
+	if flags.LogFile != "" {
+		var err error
+		file, err = os.OpenFile(flags.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("error opening log file: %v", err)
+		}
+		handler = multiHandler{handler, slog.NewTextHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})}
+	}
+	logger = slog.New(handler)
+
+	return func() {
+		logger = old
+		if file != nil {
+			file.Close()
+		}
+	}, nil
+}
+
+// consoleHandler writes log records for people reading a terminal: the
+// message prefixed by its level, e.g. "Warning: ...", followed by its
+// attributes as key=value pairs. Info records have no prefix.
+type consoleHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+}
+
+func newConsoleHandler(w io.Writer, level slog.Level) consoleHandler {
+	return consoleHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("debug: ")
+	}
+	b.WriteString(r.Message)
+	writeAttr := func(a slog.Attr) {
+		value := a.Value.Resolve().String()
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(&b, " %s=%s", a.Key, value)
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(a)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return h
+}
+
+// WithGroup is not supported on the console; grouped attributes are written
+// with their plain keys.
+func (h consoleHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// multiHandler passes each record to every handler that is enabled for it.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range m {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// logProviderRequests logs model API requests and their responses at debug
+// level, with credentials redacted. It sits between the registered middleware
+// and the network, so it logs what is actually sent.
+func logProviderRequests(next ProviderHandler) ProviderHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			return next(req)
+		}
+
+		var body []byte
+		if req.Body != nil {
+			var err error
+			body, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		logger.DebugContext(ctx, "model API request", "method", req.Method, "url", redactURL(req.URL), "headers", redactHeaders(req.Header), "body", truncateLogBody(body, len(body)))
+
+		start := time.Now()
+		resp, err := next(req)
+		if err != nil {
+			logger.DebugContext(ctx, "model API request failed", "url", redactURL(req.URL), "duration", time.Since(start), "err", err)
+			return nil, err
+		}
+		logger.DebugContext(ctx, "model API response", "url", redactURL(req.URL), "status", resp.Status, "duration", time.Since(start))
+		// The body is logged as it is read, so streamed responses still
+		// arrive as they are generated
+		resp.Body = &loggedBody{ReadCloser: resp.Body, ctx: ctx, url: redactURL(req.URL)}
+		return resp, nil
+	}
+}
+
+// loggedBody logs the beginning of a response body when it is closed.
+type loggedBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	url    string
+	head   []byte
+	total  int
+	logged bool
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := logBodyLimit - len(b.head); room > 0 {
+		b.head = append(b.head, p[:min(n, room)]...)
+	}
+	b.total += n
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	if !b.logged {
+		b.logged = true
+		logger.DebugContext(b.ctx, "model API response body", "url", b.url, "body", truncateLogBody(b.head, b.total))
+	}
+	return b.ReadCloser.Close()
+}
+
+// truncateLogBody returns the first logBodyLimit bytes of a body of total
+// bytes, noting how much was left out.
+func truncateLogBody(body []byte, total int) string {
+	if len(body) > logBodyLimit {
+		body = body[:logBodyLimit]
+	}
+	if total > len(body) {
+		return fmt.Sprintf("%s... (%d more bytes)", body, total-len(body))
+	}
+	return string(body)
+}
+
+// redactHeaders formats headers for the log, hiding the values of
+// redactedHeaders.
+func redactHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ",")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		parts = append(parts, name+": "+value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// redactURL formats u for the log, hiding the values of redactedParams and
+// any password.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	changed := false
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
@@ -0,0 +1,101 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newConsoleHandler(&buf, slog.LevelInfo))
+	l.Debug("hidden")
+	l.Info("Benchmarking day1_part1_2015...")
+	l.Warn("failed to record attempt", "err", "disk full")
+	l.With("run", "abc").Error("benchmarking failed")
+
+	want := "Benchmarking day1_part1_2015...\n" +
+		"Warning: failed to record attempt err=\"disk full\"\n" +
+		"Error: benchmarking failed run=abc\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected console output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestSetupLogging(t *testing.T) {
+	if _, err := setupLogging(Flags{Verbose: true, Quiet: true}); err == nil {
+		t.Error("Expected an error for --verbose with --quiet")
+	}
+
+	logFile := filepath.Join(t.TempDir(), "aocgen.log")
+	restore, err := setupLogging(Flags{Quiet: true, LogFile: logFile})
+	if err != nil {
+		t.Fatalf("setupLogging failed: %v", err)
+	}
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected debug messages to be enabled for the log file")
+	}
+	logger.Debug("model API request", "url", "http://localhost")
+	logger.Info("Processing dataset...")
+	restore()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	for _, want := range []string{"level=DEBUG msg=\"model API request\"", "level=INFO msg=\"Processing dataset...\""} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected log file to contain %q, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestLogProviderRequestsRedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(42)\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	var buf bytes.Buffer
+	defer SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))()
+
+	flags := Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL + "?key=secret-key"}
+	if _, err := generateCodeWithAI(context.Background(), Challenge{Task: "Print the answer."}, flags); err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+
+	log := buf.String()
+	for _, secret := range []string{"sk-secret", "secret-key"} {
+		if strings.Contains(log, secret) {
+			t.Errorf("Log leaks %q:\n%s", secret, log)
+		}
+	}
+	for _, want := range []string{"Authorization: [REDACTED]", "key=REDACTED", "Print the answer.", "status=\"200 OK\"", "print(42)"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Expected log to contain %q, got:\n%s", want, log)
+		}
+	}
+}
+
+func TestTruncateLogBody(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), logBodyLimit+10)
+	got := truncateLogBody(body, len(body))
+	if !strings.HasSuffix(got, "... (10 more bytes)") || len(got) != logBodyLimit+len("... (10 more bytes)") {
+		t.Errorf("Unexpected truncated body of length %d: %q", len(got), got[len(got)-30:])
+	}
+	if got := truncateLogBody([]byte("short"), 5); got != "short" {
+		t.Errorf("Expected short body unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,180 @@
+package aocgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	markdownSpaceRe   = regexp.MustCompile(`\s+`)
+	markdownNewlineRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// extractArticles returns the puzzle descriptions (<article class="day-desc">)
+// found in an Advent of Code page, each converted to Markdown.
+func extractArticles(htmlContent string) []string {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil
+	}
+
+	var articles []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Article && hasClass(n, "day-desc") {
+			articles = append(articles, htmlToMarkdown(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return articles
+}
+
+// htmlToMarkdown renders the children of n as Markdown. Example blocks become
+// fenced code, lists keep their bullets or numbers and emphasis is kept, so
+// the puzzle reads the same way it does on the website.
+func htmlToMarkdown(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderMarkdown(&sb, c)
+	}
+
+	// Indentation from the HTML source leaks into text nodes; trim it
+	// everywhere except inside code fences.
+	lines := strings.Split(sb.String(), "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			lines[i] = strings.TrimSpace(line)
+		}
+	}
+
+	markdown := strings.Join(lines, "\n")
+	markdown = markdownNewlineRe.ReplaceAllString(markdown, "\n\n")
+	return strings.TrimSpace(markdown)
+}
+
+func renderMarkdown(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(markdownSpaceRe.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Form:
+	case atom.Pre:
+		code := strings.Trim(nodeText(n), "\n")
+		fmt.Fprintf(sb, "\n\n```\n%s\n```\n\n", code)
+	case atom.Code:
+		code := nodeText(n)
+		fence := "`"
+		if strings.Contains(code, "`") {
+			fence = "``"
+		}
+		sb.WriteString(fence + code + fence)
+	case atom.Em, atom.I, atom.Strong, atom.B:
+		var inner strings.Builder
+		renderChildren(&inner, n)
+		text := inner.String()
+		if strings.TrimSpace(text) == "" {
+			sb.WriteString(text)
+			return
+		}
+		marker := "*"
+		if n.DataAtom == atom.Strong || n.DataAtom == atom.B {
+			marker = "**"
+		}
+		// Keep surrounding spaces outside the markers
+		trimmed := strings.TrimSpace(text)
+		lead := text[:strings.Index(text, trimmed)]
+		trail := text[len(lead)+len(trimmed):]
+		sb.WriteString(lead + marker + trimmed + marker + trail)
+	case atom.A:
+		var inner strings.Builder
+		renderChildren(&inner, n)
+		href := attr(n, "href")
+		if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+			fmt.Fprintf(sb, "[%s](%s)", inner.String(), href)
+		} else {
+			sb.WriteString(inner.String())
+		}
+	case atom.Br:
+		sb.WriteString("\n")
+	case atom.Ul, atom.Ol:
+		sb.WriteString("\n\n")
+		index := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+				continue
+			}
+			index++
+			if n.DataAtom == atom.Ol {
+				fmt.Fprintf(sb, "\n%d. ", index)
+			} else {
+				sb.WriteString("\n- ")
+			}
+			var item strings.Builder
+			renderChildren(&item, c)
+			sb.WriteString(strings.TrimSpace(item.String()))
+		}
+		sb.WriteString("\n\n")
+	case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Blockquote:
+		sb.WriteString("\n\n")
+		renderChildren(sb, n)
+		sb.WriteString("\n\n")
+	default:
+		renderChildren(sb, n)
+	}
+}
+
+func renderChildren(sb *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderMarkdown(sb, c)
+	}
+}
+
+// nodeText returns the raw text content of n, preserving whitespace.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
+	}
+	return sb.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
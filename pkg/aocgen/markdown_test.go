@@ -0,0 +1,67 @@
+package aocgen
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestExtractArticlesMarkdown(t *testing.T) {
+	page := `<html><body><main>
+<article class="day-desc"><h2>--- Day 1: Not Quite Lisp ---</h2>
+<p>Santa is trying to deliver presents. An opening parenthesis, <code>(</code>, means he should go <em>up</em> one floor.</p>
+<p>For example:</p>
+<ul>
+<li><code>(())</code> and <code>()()</code> both result in floor <code>0</code>.</li>
+<li><code>)))</code> and <code>)())())</code> both result in floor <code>-3</code>.</li>
+</ul>
+<pre><code>199
+  200
+208
+</code></pre>
+<ol><li>First</li><li>Second</li></ol>
+<p>See <a href="https://en.wikipedia.org/wiki/Floor">floors</a> and <a href="/2015/day/2">day 2</a>. To <em>what floor</em> do the instructions take Santa?</p>
+</article>
+<p>Your puzzle answer was <code>280</code>.</p>
+<article class="day-desc"><h2 id="part2">--- Part Two ---</h2><p>Find the <em>position</em>.</p></article>
+</main></body></html>`
+
+	articles := extractArticles(page)
+	if len(articles) != 2 {
+		t.Fatalf("Expected 2 articles, got %d", len(articles))
+	}
+
+	expected := "--- Day 1: Not Quite Lisp ---\n\n" +
+		"Santa is trying to deliver presents. An opening parenthesis, `(`, means he should go *up* one floor.\n\n" +
+		"For example:\n\n" +
+		"- `(())` and `()()` both result in floor `0`.\n" +
+		"- `)))` and `)())())` both result in floor `-3`.\n\n" +
+		"```\n199\n  200\n208\n```\n\n" +
+		"1. First\n" +
+		"2. Second\n\n" +
+		"See [floors](https://en.wikipedia.org/wiki/Floor) and day 2. To *what floor* do the instructions take Santa?"
+	if articles[0] != expected {
+		t.Errorf("Unexpected Markdown.\nExpected:\n%s\nGot:\n%s", expected, articles[0])
+	}
+
+	if articles[1] != "--- Part Two ---\n\nFind the *position*." {
+		t.Errorf("Unexpected Part Two Markdown: %q", articles[1])
+	}
+}
+
+func TestCleanTaskDescriptionRemovesAnswers(t *testing.T) {
+	page := `<article class="day-desc"><h2>--- Day 1: Test ---</h2><p>Do it.</p><p>Your puzzle answer was <code>1</code>.</p>
+<h2 id="part2">--- Part Two ---</h2><pre><code>a
+b</code></pre><p>Your puzzle answer was <code>2</code>.</p></article>`
+
+	partOne, partTwo := cleanTaskDescription(context.Background(), page, Flags{Part: 2}, nil)
+	if partOne != "--- Day 1: Test ---\n\nDo it." {
+		t.Errorf("Unexpected part one: %q", partOne)
+	}
+	if partTwo != "--- Part Two ---\n\n```\na\nb\n```" {
+		t.Errorf("Unexpected part two: %q", partTwo)
+	}
+	if strings.Contains(partOne+partTwo, "Your puzzle answer") {
+		t.Errorf("Expected puzzle answers to be removed")
+	}
+}
@@ -0,0 +1,196 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upper bounds of the histogram buckets, in seconds.
+var (
+	generationBuckets = []float64{1, 2, 5, 10, 20, 30, 60, 120, 300}
+	solutionBuckets   = []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60}
+)
+
+// histogram counts observations in cumulative buckets, the way Prometheus
+// histograms do.
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// metricsRegistry collects the metrics of the process from its events, for
+// `serve` and `benchmark --metrics-addr` to expose at /metrics.
+type metricsRegistry struct {
+	mu sync.Mutex
+	// The series of each metric are keyed by their labels
+	apiRequests map[string]uint64
+	tokens      map[string]uint64
+	failures    map[string]uint64
+	generation  map[string]*histogram
+	runtime     map[string]*histogram
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		apiRequests: make(map[string]uint64),
+		tokens:      make(map[string]uint64),
+		failures:    make(map[string]uint64),
+		generation:  make(map[string]*histogram),
+		runtime:     make(map[string]*histogram),
+	}
+}
+
+// metrics receives every event, whether or not --events is set.
+var metrics = newMetricsRegistry()
+
+// observe counts e: a model response as an API request with its tokens and
+// latency, and a verdict with the runtime of the solution. Failures are
+// classified like the failures of a benchmark.
+func (m *metricsRegistry) observe(e Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e.Event {
+	case eventCodeReceived:
+		if e.Cached {
+			break
+		}
+		result := "ok"
+		if e.Failure == failureAPIError {
+			result = "error"
+		}
+		m.apiRequests[metricLabels("model", e.Model, "result", result)]++
+		m.tokens[metricLabels("model", e.Model, "kind", "prompt")] += uint64(e.PromptTokens)
+		m.tokens[metricLabels("model", e.Model, "kind", "completion")] += uint64(e.CompletionTokens)
+		if result == "ok" {
+			observe(m.generation, metricLabels("model", e.Model), generationBuckets, float64(e.DurationMs)/1000)
+		}
+		if e.Failure != "" {
+			m.failures[metricLabels("class", e.Failure)]++
+		}
+	case eventVerdict:
+		switch {
+		case e.Verdict == "":
+			m.failures[metricLabels("class", outcomeError)]++
+		case e.Verdict != VerdictCorrect && e.Verdict != VerdictUnknownAnswer:
+			m.failures[metricLabels("class", string(e.Verdict))]++
+		}
+		if e.Verdict != "" && e.Verdict != VerdictMissingToolchain {
+			observe(m.runtime, metricLabels("lang", e.Lang), solutionBuckets, float64(e.DurationMs)/1000)
+		}
+	}
+}
+
+// observe adds v to the histogram of histograms with labels, creating it
+// with bounds.
+func observe(histograms map[string]*histogram, labels string, bounds []float64, v float64) {
+	if histograms[labels] == nil {
+		histograms[labels] = newHistogram(bounds)
+	}
+	histograms[labels].observe(v)
+}
+
+// labelEscaper escapes label values in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricLabels formats pairs of label names and values as name="value",...
+func metricLabels(pairs ...string) string {
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, fmt.Sprintf("%s=\"%s\"", pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return strings.Join(labels, ",")
+}
+
+// sortedKeys returns the keys of m in order, so the series of a metric are
+// always written in the same order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// write writes the metrics in the Prometheus text exposition format.
+func (m *metricsRegistry) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounters(w, "aocgen_api_requests_total", "Requests to model APIs by model and result.", m.apiRequests)
+	writeCounters(w, "aocgen_tokens_total", "Tokens used by model and kind.", m.tokens)
+	writeCounters(w, "aocgen_failures_total", "Failed generations and evaluations by class.", m.failures)
+	writeHistograms(w, "aocgen_generation_seconds", "Time models took to answer, by model.", m.generation)
+	writeHistograms(w, "aocgen_solution_seconds", "Runtime of evaluated solutions, by language.", m.runtime)
+}
+
+func writeCounters(w io.Writer, name, help string, counters map[string]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	for _, labels := range sortedKeys(counters) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, labels, counters[labels])
+	}
+}
+
+func writeHistograms(w io.Writer, name, help string, histograms map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for _, labels := range sortedKeys(histograms) {
+		h := histograms[labels]
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%g\"} %d\n", name, labels, bound, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %g\n", name, labels, h.sum)
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+// handleMetrics serves the metrics of the process.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.write(w)
+}
+
+// serveMetrics serves /metrics on addr in the background, for the duration
+// of a benchmark. It returns a function that stops the server.
+func serveMetrics(addr string, w io.Writer) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %v", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(listener)
+
+	fmt.Fprintf(w, "Serving metrics on http://%s/metrics\n", listener.Addr())
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}, nil
+}
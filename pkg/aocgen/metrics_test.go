@@ -0,0 +1,93 @@
+package aocgen
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsRegistry(t *testing.T) {
+	m := newMetricsRegistry()
+	m.observe(Event{Event: eventCodeReceived, Model: "gpt-4o-mini", DurationMs: 1500, PromptTokens: 100, CompletionTokens: 40})
+	m.observe(Event{Event: eventCodeReceived, Model: "gpt-4o-mini", DurationMs: 3000, PromptTokens: 100, CompletionTokens: 60, Failure: failureExtraction})
+	m.observe(Event{Event: eventCodeReceived, Model: "gpt-4o-mini", Failure: failureAPIError})
+	// Cached responses were not requested
+	m.observe(Event{Event: eventCodeReceived, Model: "gpt-4o-mini", Cached: true, PromptTokens: 100})
+	m.observe(Event{Event: eventVerdict, Lang: "python", Verdict: VerdictCorrect, DurationMs: 50})
+	m.observe(Event{Event: eventVerdict, Lang: "python", Verdict: VerdictWrongAnswer, DurationMs: 2000})
+	m.observe(Event{Event: eventVerdict, Lang: "go", Verdict: VerdictMissingToolchain})
+
+	var out bytes.Buffer
+	m.write(&out)
+	for _, want := range []string{
+		"# TYPE aocgen_api_requests_total counter\n",
+		`aocgen_api_requests_total{model="gpt-4o-mini",result="error"} 1`,
+		`aocgen_api_requests_total{model="gpt-4o-mini",result="ok"} 2`,
+		`aocgen_tokens_total{model="gpt-4o-mini",kind="completion"} 100`,
+		`aocgen_tokens_total{model="gpt-4o-mini",kind="prompt"} 200`,
+		`aocgen_failures_total{class="api error"} 1`,
+		`aocgen_failures_total{class="extraction failure"} 1`,
+		`aocgen_failures_total{class="missing toolchain"} 1`,
+		`aocgen_failures_total{class="wrong answer"} 1`,
+		"# TYPE aocgen_generation_seconds histogram\n",
+		`aocgen_generation_seconds_bucket{model="gpt-4o-mini",le="2"} 1`,
+		`aocgen_generation_seconds_bucket{model="gpt-4o-mini",le="5"} 2`,
+		`aocgen_generation_seconds_bucket{model="gpt-4o-mini",le="+Inf"} 2`,
+		`aocgen_generation_seconds_sum{model="gpt-4o-mini"} 4.5`,
+		`aocgen_solution_seconds_bucket{lang="python",le="0.1"} 1`,
+		`aocgen_solution_seconds_count{lang="python"} 2`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Metrics missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), `lang="go"`) {
+		t.Errorf("Expected no runtime for a missing toolchain:\n%s", out.String())
+	}
+}
+
+func TestMetricLabels(t *testing.T) {
+	if got, want := metricLabels("model", `a"b\c`, "kind", "prompt"), `model="a\"b\\c",kind="prompt"`; got != want {
+		t.Errorf("metricLabels() = %s, want %s", got, want)
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	emitEvent(Event{Event: eventVerdict, Lang: "metrics-test", Verdict: VerdictCorrect, DurationMs: 5})
+
+	var out bytes.Buffer
+	stop, err := serveMetrics("127.0.0.1:0", &out)
+	if err != nil {
+		t.Fatalf("serveMetrics failed: %v", err)
+	}
+	defer stop()
+	url := strings.TrimSpace(strings.TrimPrefix(out.String(), "Serving metrics on "))
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `aocgen_solution_seconds_count{lang="metrics-test"} 1`) {
+		t.Errorf("Expected the emitted verdict in the metrics:\n%s", body)
+	}
+
+	// serve exposes them behind its token
+	server := httptest.NewServer(newServer(Flags{}, "secret").handler())
+	defer server.Close()
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "secret": http.StatusOK} {
+		req, _ := http.NewRequest("GET", server.URL+"/metrics", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Expected status %d with token %q, got %d", want, token, resp.StatusCode)
+		}
+	}
+}
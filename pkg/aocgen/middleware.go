@@ -0,0 +1,78 @@
+package aocgen
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ProviderHandler sends a single request to a model provider and returns its response.
+type ProviderHandler func(req *http.Request) (*http.Response, error)
+
+// ProviderMiddleware wraps a ProviderHandler. Middleware can mutate the request
+// (e.g. to sign it), inspect or replace the response (logging, caching), or
+// short-circuit the call entirely without touching the network.
+type ProviderMiddleware func(next ProviderHandler) ProviderHandler
+
+// registeredMiddleware is one middleware registered by UseProviderMiddleware;
+// registering the same function twice gives two entries.
+type registeredMiddleware struct {
+	mw ProviderMiddleware
+}
+
+var (
+	providerMiddlewareMu sync.RWMutex
+	providerMiddleware   []*registeredMiddleware
+)
+
+// UseProviderMiddleware registers middleware applied to every model API request.
+// Middleware runs in registration order, so the first one registered sees the
+// request first and the response last. It returns a function that removes the
+// middleware registered by this call and leaves any registered since in place.
+func UseProviderMiddleware(mw ...ProviderMiddleware) func() {
+	providerMiddlewareMu.Lock()
+	defer providerMiddlewareMu.Unlock()
+
+	added := make(map[*registeredMiddleware]bool, len(mw))
+	chain := append([]*registeredMiddleware{}, providerMiddleware...)
+	for _, m := range mw {
+		entry := &registeredMiddleware{mw: m}
+		added[entry] = true
+		chain = append(chain, entry)
+	}
+	providerMiddleware = chain
+	return func() {
+		providerMiddlewareMu.Lock()
+		defer providerMiddlewareMu.Unlock()
+		var kept []*registeredMiddleware
+		for _, entry := range providerMiddleware {
+			if !added[entry] {
+				kept = append(kept, entry)
+			}
+		}
+		providerMiddleware = kept
+	}
+}
+
+// providerTransport runs requests through the registered middleware chain
+// and through retryProviderRequests, recordExchanges and logProviderRequests
+// before handing them to the underlying transport.
+type providerTransport struct {
+	base http.RoundTripper
+}
+
+func (t providerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	providerMiddlewareMu.RLock()
+	chain := providerMiddleware
+	providerMiddlewareMu.RUnlock()
+
+	handler := retryProviderRequests(recordExchanges(logProviderRequests(t.base.RoundTrip)))
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i].mw(handler)
+	}
+	return handler(req)
+}
+
+// newProviderClient returns the HTTP client used for all model API calls.
+func newProviderClient() *http.Client {
+	return &http.Client{Transport: providerTransport{base: http.DefaultTransport}}
+}
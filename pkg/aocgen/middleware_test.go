@@ -0,0 +1,130 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderMiddlewareMutatesRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Signature"); got != "signed" {
+			t.Errorf("Expected X-Signature header to be set by middleware, got: %q", got)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"response": "```python\nprint(42)\n```",
+		})
+	}))
+	defer server.Close()
+
+	var order []string
+	restore := UseProviderMiddleware(
+		func(next ProviderHandler) ProviderHandler {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, "outer")
+				return next(req)
+			}
+		},
+		func(next ProviderHandler) ProviderHandler {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, "inner")
+				req.Header.Set("X-Signature", "signed")
+				return next(req)
+			}
+		},
+	)
+	defer restore()
+
+	flags := Flags{
+		Lang:     "python",
+		Model:    "ollama/llama3",
+		ModelAPI: server.URL,
+	}
+
+	code, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, flags)
+	if err != nil {
+		t.Fatalf("Failed to generate code with AI: %v", err)
+	}
+
+	if code != "print(42)" {
+		t.Errorf("Unexpected code: %q", code)
+	}
+
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("Middleware ran in unexpected order: %v", order)
+	}
+}
+
+func TestProviderMiddlewareShortCircuit(t *testing.T) {
+	restore := UseProviderMiddleware(func(next ProviderHandler) ProviderHandler {
+		return func(req *http.Request) (*http.Response, error) {
+			body, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{
+					{"message": map[string]string{"content": "```go\npackage main\n```"}},
+				},
+			})
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Request:    req,
+			}, nil
+		}
+	})
+
+	// The URL is never contacted because the middleware answers the request itself
+	code, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{
+		Lang:     "go",
+		Model:    "gpt-4o-mini",
+		ModelAPI: "http://127.0.0.1:1/v1/chat/completions",
+	})
+	restore()
+	if err != nil {
+		t.Fatalf("Failed to generate code with AI: %v", err)
+	}
+	if code != "package main" {
+		t.Errorf("Unexpected code: %q", code)
+	}
+
+	providerMiddlewareMu.RLock()
+	remaining := len(providerMiddleware)
+	providerMiddlewareMu.RUnlock()
+	if remaining != 0 {
+		t.Errorf("Expected middleware to be restored, %d still registered", remaining)
+	}
+}
+
+func TestProviderMiddlewareRestoreOutOfOrder(t *testing.T) {
+	var order []string
+	named := func(name string) ProviderMiddleware {
+		return func(next ProviderHandler) ProviderHandler {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next(req)
+			}
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	restoreA := UseProviderMiddleware(named("a"))
+	restoreB := UseProviderMiddleware(named("b"))
+	defer restoreB()
+
+	// Removing a keeps b, which was registered after it
+	restoreA()
+	resp, err := newProviderClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if strings.Join(order, ",") != "b" {
+		t.Errorf("Expected only b to run, got %v", order)
+	}
+}
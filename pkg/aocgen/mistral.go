@@ -0,0 +1,86 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	mistralAPIURL   = "https://api.mistral.ai/v1/chat/completions"
+	codestralAPIURL = "https://codestral.mistral.ai/v1/chat/completions"
+)
+
+// isMistralModel reports whether model is served by Mistral's La Plateforme,
+// e.g. mistral-large-latest or codestral-latest.
+func isMistralModel(model string) bool {
+	return strings.HasPrefix(model, "mistral-") || strings.HasPrefix(model, "codestral-")
+}
+
+// mistralEndpoint returns the default URL and API key for a Mistral model.
+// Codestral has its own endpoint and keys; a CODESTRAL_API_KEY is used for it
+// when set, otherwise the regular MISTRAL_API_KEY.
+func mistralEndpoint(model string) (string, string) {
+	if strings.HasPrefix(model, "codestral-") {
+		if key := os.Getenv("CODESTRAL_API_KEY"); key != "" {
+			return codestralAPIURL, key
+		}
+		return codestralAPIURL, os.Getenv("MISTRAL_API_KEY")
+	}
+	return mistralAPIURL, os.Getenv("MISTRAL_API_KEY")
+}
+
+// callMistralAPI calls Mistral's OpenAI-compatible chat completions API.
+// apiURL overrides the default endpoint for the model when set.
+func callMistralAPI(ctx context.Context, apiURL, model, system, prompt string, stream, structured bool, sampling Sampling) (string, Usage, error) {
+	defaultURL, apiKey := mistralEndpoint(model)
+	if apiURL == "" {
+		apiURL = defaultURL
+	}
+
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": chatMessages(system, prompt),
+		"stream":   stream,
+	}
+	setSamplingParams(payload, sampling, "random_seed")
+	if structured {
+		setResponseFormat(payload, true)
+	}
+	requestBody, err := json.Marshal(payload)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := newProviderClient().Do(req)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	if stream && resp.StatusCode == http.StatusOK {
+		return readStreamedCompletion(resp.Body, streamOutput)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", Usage{}, chatCompletionError(resp.Status, body)
+	}
+
+	return parseChatCompletion(body)
+}
@@ -0,0 +1,70 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCodeWithAIMistral(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	t.Setenv("MISTRAL_API_KEY", "mistral-key")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer mistral-key" {
+			t.Errorf("Unexpected Authorization header: %q", auth)
+		}
+		var requestBody map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		if requestBody["model"] == "mistral-bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Unauthorized","type":"invalid_request_error"}}`))
+			return
+		}
+		if requestBody["model"] != "mistral-large-latest" {
+			t.Errorf("Unexpected model: %v", requestBody["model"])
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```go\npackage main\n```"}},
+			},
+			"usage": map[string]int{"prompt_tokens": 10, "completion_tokens": 5},
+		})
+	}))
+	defer server.Close()
+
+	code, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "mistral-large-latest", ModelAPI: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to generate code with AI: %v", err)
+	}
+	if code != "package main" {
+		t.Errorf("Unexpected code: %q", code)
+	}
+
+	_, err = generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "mistral-bad", ModelAPI: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("Expected the API error message, got: %v", err)
+	}
+}
+
+func TestMistralEndpoint(t *testing.T) {
+	t.Setenv("MISTRAL_API_KEY", "mistral-key")
+	t.Setenv("CODESTRAL_API_KEY", "")
+
+	if url, key := mistralEndpoint("mistral-small-latest"); url != mistralAPIURL || key != "mistral-key" {
+		t.Errorf("Unexpected endpoint for mistral-small-latest: %s %s", url, key)
+	}
+	if url, key := mistralEndpoint("codestral-latest"); url != codestralAPIURL || key != "mistral-key" {
+		t.Errorf("Unexpected endpoint for codestral-latest: %s %s", url, key)
+	}
+
+	t.Setenv("CODESTRAL_API_KEY", "codestral-key")
+	if _, key := mistralEndpoint("codestral-latest"); key != "codestral-key" {
+		t.Errorf("Expected CODESTRAL_API_KEY to be preferred for codestral, got %s", key)
+	}
+}
@@ -0,0 +1,107 @@
+package aocgen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// splitLanguages returns the languages of a --lang given as a comma
+// separated list or several times, lower-cased and without duplicates.
+func splitLanguages(value string) []string {
+	var langs []string
+	seen := make(map[string]bool)
+	for _, lang := range strings.Split(value, ",") {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+// generateLanguages generates a solution of the challenge in flags in each
+// of langs and evaluates it, downloading the task at most once. A language
+// that fails does not stop the others; the summary shows what happened in
+// each, and the command fails if any generation did.
+func generateLanguages(flags Flags, langs []string, w io.Writer) error {
+	if flags.Open {
+		return fmt.Errorf("--open takes a single --lang")
+	}
+	// Unknown languages are reported before any model is asked
+	for _, lang := range langs {
+		if _, err := getFileExtension(lang); err != nil {
+			return err
+		}
+	}
+
+	var reports []GenerateReport
+	failed := 0
+	for i, lang := range langs {
+		langFlags := flags
+		langFlags.Lang = lang
+		logger.Info(fmt.Sprintf("[%d/%d] Generating a %s solution...", i+1, len(langs), lang))
+
+		report, err := generateChallengeSolution(commandContext, langFlags)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("generating the %s solution failed", lang), "err", err)
+			failed++
+			reports = append(reports, GenerateReport{Lang: lang, Model: flags.Model, Error: err.Error()})
+			continue
+		}
+		report.Verdict, report.Error = evaluateGenerated(langFlags)
+		reports = append(reports, report)
+	}
+
+	if flags.JSON {
+		if err := emitJSON(reports); err != nil {
+			return err
+		}
+	} else {
+		printLanguageReports(w, reports)
+	}
+	if failed > 0 {
+		return fmt.Errorf("generation failed in %d of %d languages", failed, len(langs))
+	}
+	return nil
+}
+
+// evaluateGenerated evaluates the solution just generated in flags.Lang and
+// returns its verdict, or why it was not evaluated.
+func evaluateGenerated(flags Flags) (Verdict, string) {
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return "", err.Error()
+	}
+	if challenge.Answer == "" {
+		return "", "no known answer, not evaluated"
+	}
+	_, result, err := evaluateChallengeSolution(commandContext, flags, nil)
+	var missing *MissingToolchainError
+	if errors.As(err, &missing) {
+		return VerdictMissingToolchain, err.Error()
+	}
+	if err != nil {
+		return "", err.Error()
+	}
+	return result.Verdict, ""
+}
+
+// printLanguageReports writes a table of the solutions generated in each
+// language.
+func printLanguageReports(w io.Writer, reports []GenerateReport) {
+	fmt.Fprintf(w, "%-12s  %-18s  %s\n", "Language", "Verdict", "File")
+	for _, r := range reports {
+		verdict, detail := string(r.Verdict), r.File
+		if verdict == "" {
+			verdict = "-"
+		}
+		if r.Error != "" {
+			detail = r.Error
+		}
+		fmt.Fprintf(w, "%-12s  %-18s  %s\n", r.Lang, verdict, detail)
+	}
+}
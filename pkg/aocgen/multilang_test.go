@@ -0,0 +1,65 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitLanguages(t *testing.T) {
+	got := splitLanguages("go, Python,,rust,go")
+	if want := []string{"go", "python", "rust"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	flags, err := parseFlags([]string{"--lang", "go,python", "--lang", "rust"})
+	if err != nil || flags.Lang != "go,python,rust" {
+		t.Errorf("Expected repeated --lang to add up, got %q, %v", flags.Lang, err)
+	}
+}
+
+func TestGenerateLanguages(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	for _, lang := range []string{"python", "bash"} {
+		if err := checkToolchain(lang); err != nil {
+			t.Skip(err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	// The test model answers with a Python program printing Hello, World!
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Greet.", Input: "x", Answer: "Hello, World!"}})
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python,bash", Model: "test"}
+
+	var out bytes.Buffer
+	if err := generateLanguages(flags, splitLanguages(flags.Lang), &out); err != nil {
+		t.Fatalf("Generation failed: %v", err)
+	}
+	for _, ext := range []string{"py", "sh"} {
+		if _, err := os.Stat(testSolutionPath(t, "day1_part1_2015", ext)); err != nil {
+			t.Errorf("Expected the %s solution to be written: %v", ext, err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "correct") || strings.Contains(lines[2], "correct") {
+		t.Errorf("Expected python to pass and bash to fail, got:\n%s", out.String())
+	}
+
+	flags.JSON = true
+	var reports []GenerateReport
+	data := captureJSON(t, func() error { return generateLanguages(flags, []string{"python"}, &out) })
+	if err := json.Unmarshal(data, &reports); err != nil || len(reports) != 1 || reports[0].Verdict != VerdictCorrect {
+		t.Errorf("Unexpected JSON reports: %s, %v", data, err)
+	}
+
+	if err := generateLanguages(flags, []string{"python", "klingon"}, &out); err == nil {
+		t.Errorf("Expected an error for an unknown language")
+	}
+}
@@ -0,0 +1,150 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// answerNormalizations are the --normalize steps that may be applied to both
+// the expected and the actual answer before they are compared. Surrounding
+// whitespace is always trimmed.
+var answerNormalizations = map[string]func(string) string{
+	"casefold": strings.ToLower,
+	"commas": func(s string) string {
+		return strings.ReplaceAll(s, ",", "")
+	},
+	"spaces": func(s string) string {
+		return strings.Join(strings.Fields(s), "")
+	},
+}
+
+// answerMatch is how the output of a solution is compared with the expected
+// answer.
+type answerMatch struct {
+	// Lenient accepts the answer anywhere in the output instead of only on
+	// the last line
+	Lenient bool
+	// Normalize names answerNormalizations applied to both sides
+	Normalize []string
+}
+
+// parseNormalize parses a comma-separated list of answerNormalizations.
+func parseNormalize(value string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := answerNormalizations[name]; !ok {
+			return nil, fmt.Errorf("unknown normalization %q, expected %s", name, strings.Join(normalizationNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// newAnswerMatch returns the comparison eval uses for flags.
+func newAnswerMatch(flags Flags) (answerMatch, error) {
+	normalize, err := parseNormalize(flags.Normalize)
+	if err != nil {
+		return answerMatch{}, err
+	}
+	return answerMatch{Lenient: flags.Lenient, Normalize: normalize}, nil
+}
+
+func normalizationNames() []string {
+	names := make([]string, 0, len(answerNormalizations))
+	for name := range answerNormalizations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m answerMatch) normalize(s string) string {
+	s = strings.TrimSpace(s)
+	for _, name := range m.Normalize {
+		s = answerNormalizations[name](s)
+	}
+	return s
+}
+
+// check returns the answer extracted from stdout and whether it matches
+// expected. In lenient mode the normalized expected answer may appear
+// anywhere in output. Without an expected answer nothing matches, so that
+// unchecked output is never taken for a correct answer.
+func (m answerMatch) check(stdout, output, expected string) (string, bool) {
+	actual := extractAnswer(stdout)
+	if m.normalize(expected) == "" {
+		return actual, false
+	}
+	if m.Lenient {
+		return actual, strings.Contains(m.normalize(output), m.normalize(expected))
+	}
+	return actual, m.normalize(actual) == m.normalize(expected)
+}
+
+// writeWrongAnswer explains a wrong answer: the expected and the actual
+// answer, hints for answers that only differ in formatting, and the whole
+// output with the line the answer was taken from marked with > and lines
+// containing the expected answer with =.
+func writeWrongAnswer(w io.Writer, result EvalResult, match answerMatch) {
+	if result.Case != "" {
+		fmt.Fprintf(w, "Solution is incorrect on %s.\n", strings.ToLower(result.Case[:1])+result.Case[1:])
+	} else {
+		fmt.Fprintln(w, "Solution is incorrect.")
+	}
+	expected := result.Expected
+	if strings.TrimSpace(expected) == "" {
+		expected = "(unknown)"
+	}
+	fmt.Fprintf(w, "Expected: %s\nActual:   %s\n", expected, result.Answer)
+
+	if result.Expected != "" {
+		all := answerMatch{Normalize: normalizationNames()}
+		if !match.Lenient && all.normalize(result.Answer) == all.normalize(result.Expected) {
+			var needed []string
+			for _, name := range normalizationNames() {
+				f := answerNormalizations[name]
+				if f(result.Answer) != result.Answer || f(result.Expected) != result.Expected {
+					needed = append(needed, name)
+				}
+			}
+			fmt.Fprintf(w, "Hint: the answers only differ in formatting; they match with --normalize %s\n", strings.Join(needed, ","))
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(result.Output, "\n"), "\n")
+	answerLine := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if result.Answer != "" && strings.TrimSpace(lines[i]) == result.Answer {
+			answerLine = i
+			break
+		}
+	}
+	markers := make([]string, len(lines))
+	expectedLine := -1
+	for i, line := range lines {
+		markers[i] = " "
+		switch {
+		case i == answerLine:
+			markers[i] = ">"
+		case strings.TrimSpace(result.Expected) != "" && strings.Contains(match.normalize(line), match.normalize(result.Expected)):
+			markers[i] = "="
+			if expectedLine < 0 {
+				expectedLine = i
+			}
+		}
+	}
+	if expectedLine >= 0 && !match.Lenient {
+		fmt.Fprintf(w, "Hint: the expected answer appears on line %d, but the answer is taken from the last non-empty line of stdout (--lenient accepts it anywhere)\n", expectedLine+1)
+	}
+
+	fmt.Fprintln(w, "Output:")
+	for i, line := range lines {
+		fmt.Fprintf(w, "%s %4d | %s\n", markers[i], i+1, line)
+	}
+}
@@ -0,0 +1,73 @@
+package aocgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAnswerMatch(t *testing.T) {
+	if _, err := parseNormalize("casefold,bogus"); err == nil || !strings.Contains(err.Error(), "casefold, commas, spaces") {
+		t.Errorf("Expected an error listing the normalizations, got %v", err)
+	}
+
+	tests := []struct {
+		normalize string
+		lenient   bool
+		stdout    string
+		expected  string
+		correct   bool
+	}{
+		{"", false, "Answer:\n 1234 \n", "1234", true},
+		{"", false, "1,234\n", "1234", false},
+		{"commas", false, "1,234\n", "1234", true},
+		{"", false, "ABC\n", "abc", false},
+		{"casefold", false, "ABC\n", "abc", true},
+		{"spaces", false, "1 2 3\n", "123", true},
+		{"", true, "The answer is 1,234.\nDone\n", "1234", false},
+		{"commas", true, "The answer is 1,234.\nDone\n", "1234", true},
+		{"", false, "", "", false},
+		{"", true, "anything\n", " ", false},
+	}
+	for _, tt := range tests {
+		normalize, err := parseNormalize(tt.normalize)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tt.normalize, err)
+		}
+		match := answerMatch{Lenient: tt.lenient, Normalize: normalize}
+		if _, correct := match.check(tt.stdout, tt.stdout, tt.expected); correct != tt.correct {
+			t.Errorf("%q against %q with %q (lenient %v): expected %v", tt.stdout, tt.expected, tt.normalize, tt.lenient, tt.correct)
+		}
+	}
+}
+
+func TestWriteWrongAnswer(t *testing.T) {
+	var out bytes.Buffer
+	result := EvalResult{Verdict: VerdictWrongAnswer, Output: "Part 1: 1,234\nDone\n1,234\n", Expected: "1234", Answer: "1,234"}
+	writeWrongAnswer(&out, result, answerMatch{})
+	expected := "Solution is incorrect.\n" +
+		"Expected: 1234\n" +
+		"Actual:   1,234\n" +
+		"Hint: the answers only differ in formatting; they match with --normalize commas\n" +
+		"Output:\n" +
+		"     1 | Part 1: 1,234\n" +
+		"     2 | Done\n" +
+		">    3 | 1,234\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected report.\nExpected:\n%s\nGot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	result = EvalResult{Verdict: VerdictWrongAnswer, Output: "The answer is 42\ndone\n", Expected: "42", Answer: "done", Case: "Test 2 of 3"}
+	writeWrongAnswer(&out, result, answerMatch{})
+	for _, line := range []string{
+		"Solution is incorrect on test 2 of 3.",
+		"Hint: the expected answer appears on line 1",
+		"=    1 | The answer is 42",
+		">    2 | done",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
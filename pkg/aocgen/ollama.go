@@ -0,0 +1,194 @@
+package aocgen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OllamaModel is a model installed in the local Ollama, as /api/tags lists it.
+type OllamaModel struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ollamaBaseURL returns the root of the Ollama server behind apiURL, the chat
+// endpoint generate sends to, or of the default local server.
+func ollamaBaseURL(apiURL string) string {
+	if apiURL == "" {
+		apiURL = modelAPIFor("ollama/")
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(apiURL, "/")
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// ollamaAPI returns the --model_api the models commands talk to. The
+// endpoint of a default model of another provider is not Ollama's.
+func ollamaAPI(flags Flags) string {
+	if flags.Model != "" && modelProvider(flags.Model) != "ollama" {
+		return ""
+	}
+	return flags.ModelAPI
+}
+
+// ollamaModelName strips the ollama/ prefix aocgen selects the provider by.
+func ollamaModelName(model string) string {
+	return strings.TrimPrefix(model, "ollama/")
+}
+
+// hasOllamaModel reports whether model is among models. A name without a tag
+// means the latest one, as it does for Ollama.
+func hasOllamaModel(models []OllamaModel, model string) bool {
+	for _, m := range models {
+		if m.Name == model || m.Name == model+":latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// listOllamaModels returns the models installed in the Ollama server at base.
+func listOllamaModels(ctx context.Context, base string) ([]OllamaModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach Ollama at %s, is it running? %v", base, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from Ollama at %s: %s", base, resp.Status)
+	}
+
+	var tags struct {
+		Models []OllamaModel `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("error parsing Ollama models: %v", err)
+	}
+	return tags.Models, nil
+}
+
+// pullOllamaModel downloads model into the Ollama server at base, calling
+// progress with every new status Ollama streams, e.g. "pulling manifest".
+func pullOllamaModel(ctx context.Context, base, model string, progress func(status string)) error {
+	body, err := json.Marshal(map[string]interface{}{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", base+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot reach Ollama at %s, is it running? %v", base, err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var last string
+	for scanner.Scan() {
+		var update struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			continue
+		}
+		if update.Error != "" {
+			return fmt.Errorf("error pulling %s: %s", model, update.Error)
+		}
+		if update.Status != "" && update.Status != last {
+			last = update.Status
+			progress(update.Status)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error pulling %s: %v", model, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error pulling %s: %s", model, resp.Status)
+	}
+	if last != "success" {
+		return fmt.Errorf("error pulling %s: the download ended early", model)
+	}
+	return nil
+}
+
+// ensureOllamaModel pulls the Ollama model of flags when it is not installed
+// yet, so generating with it does not fail on the missing model. Other
+// models are left alone.
+func ensureOllamaModel(ctx context.Context, flags Flags) error {
+	if !strings.HasPrefix(flags.Model, "ollama/") {
+		return nil
+	}
+	base := ollamaBaseURL(flags.ModelAPI)
+	model := ollamaModelName(flags.Model)
+	models, err := listOllamaModels(ctx, base)
+	if err != nil {
+		return err
+	}
+	if hasOllamaModel(models, model) {
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Model %s is not installed in Ollama, pulling it...", model))
+	return pullOllamaModel(ctx, base, model, func(status string) {
+		logger.Info(fmt.Sprintf("%s: %s", model, status))
+	})
+}
+
+// runModelsListCommand prints the models installed in Ollama.
+func runModelsListCommand(flags Flags, w io.Writer) error {
+	models, err := listOllamaModels(commandContext, ollamaBaseURL(ollamaAPI(flags)))
+	if err != nil {
+		return err
+	}
+	if flags.JSON {
+		if models == nil {
+			models = []OllamaModel{}
+		}
+		return emitJSON(models)
+	}
+	if len(models) == 0 {
+		fmt.Fprintln(w, "No models installed, pull one with: aocgen models pull <name>")
+		return nil
+	}
+	fmt.Fprintf(w, "%-40s  %10s  %-16s\n", "Model", "Size", "Modified")
+	for _, m := range models {
+		fmt.Fprintf(w, "%-40s  %10s  %-16s\n", "ollama/"+m.Name, formatBytes(m.Size), m.ModifiedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// runModelsPullCommand downloads model into Ollama, printing its progress.
+func runModelsPullCommand(model string, flags Flags, w io.Writer) error {
+	model = ollamaModelName(model)
+	if model == "" {
+		return fmt.Errorf("expected a model name, e.g. 'aocgen models pull llama3'")
+	}
+	err := pullOllamaModel(commandContext, ollamaBaseURL(ollamaAPI(flags)), model, func(status string) {
+		fmt.Fprintln(w, status)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Generate with it using --model ollama/%s\n", model)
+	return nil
+}
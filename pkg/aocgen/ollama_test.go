@@ -0,0 +1,140 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newOllamaServer fakes the model management API of Ollama with installed
+// models. Pulled models are installed, except "broken", which fails.
+func newOllamaServer(t *testing.T, installed ...string) (*httptest.Server, *[]string) {
+	var pulls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			models := []map[string]interface{}{}
+			for _, name := range installed {
+				models = append(models, map[string]interface{}{"name": name, "size": 4661224676, "modified_at": "2024-12-01T10:00:00Z"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": models})
+		case "/api/pull":
+			var body struct {
+				Model string `json:"model"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			pulls = append(pulls, body.Model)
+			fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+			if body.Model == "broken" {
+				fmt.Fprintln(w, `{"error":"pull model manifest: file does not exist"}`)
+				return
+			}
+			fmt.Fprintln(w, `{"status":"downloading","total":100,"completed":50}`)
+			fmt.Fprintln(w, `{"status":"downloading","total":100,"completed":100}`)
+			fmt.Fprintln(w, `{"status":"success"}`)
+			installed = append(installed, body.Model+":latest")
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &pulls
+}
+
+func TestOllamaBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"": "http://localhost:11434",
+		"http://gpu-box:11434/v1/chat/completions": "http://gpu-box:11434",
+		"http://localhost:11434/api/chat":          "http://localhost:11434",
+	}
+	for apiURL, want := range tests {
+		if got := ollamaBaseURL(apiURL); got != want {
+			t.Errorf("ollamaBaseURL(%q) = %q, want %q", apiURL, got, want)
+		}
+	}
+}
+
+func TestEnsureOllamaModel(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server, pulls := newOllamaServer(t, "llama3:latest", "gemma2:2b")
+	flags := Flags{Model: "ollama/llama3", ModelAPI: server.URL + "/v1/chat/completions"}
+	if err := ensureOllamaModel(context.Background(), flags); err != nil {
+		t.Fatalf("ensureOllamaModel failed: %v", err)
+	}
+	flags.Model = "ollama/gemma2:2b"
+	if err := ensureOllamaModel(context.Background(), flags); err != nil {
+		t.Fatalf("ensureOllamaModel failed: %v", err)
+	}
+	if len(*pulls) != 0 {
+		t.Errorf("Expected installed models not to be pulled, got %v", *pulls)
+	}
+
+	flags.Model = "ollama/qwen2.5-coder"
+	if err := ensureOllamaModel(context.Background(), flags); err != nil {
+		t.Fatalf("ensureOllamaModel failed: %v", err)
+	}
+	if err := ensureOllamaModel(context.Background(), flags); err != nil {
+		t.Fatalf("ensureOllamaModel failed: %v", err)
+	}
+	if len(*pulls) != 1 || (*pulls)[0] != "qwen2.5-coder" {
+		t.Errorf("Expected the missing model to be pulled once, got %v", *pulls)
+	}
+
+	flags.Model = "ollama/broken"
+	if err := ensureOllamaModel(context.Background(), flags); err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("Expected the pull error, got %v", err)
+	}
+
+	// Other providers are not checked
+	if err := ensureOllamaModel(context.Background(), Flags{Model: "gpt-4o-mini", ModelAPI: server.URL}); err != nil {
+		t.Errorf("Expected no check for OpenAI models, got %v", err)
+	}
+
+	server.Close()
+	flags.Model = "ollama/llama3"
+	if err := ensureOllamaModel(context.Background(), flags); err == nil || !strings.Contains(err.Error(), "cannot reach Ollama") {
+		t.Errorf("Expected an error when Ollama is down, got %v", err)
+	}
+}
+
+func TestModelsCommands(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server, _ := newOllamaServer(t, "llama3:latest")
+	flags := Flags{ModelAPI: server.URL}
+
+	var out bytes.Buffer
+	if err := runModelsPullCommand("ollama/codellama", flags, &out); err != nil {
+		t.Fatalf("pull failed: %v", err)
+	}
+	if out.String() != "pulling manifest\ndownloading\nsuccess\nGenerate with it using --model ollama/codellama\n" {
+		t.Errorf("Unexpected pull output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runModelsListCommand(flags, &out); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	for _, want := range []string{"ollama/llama3:latest", "4.3 GiB", "ollama/codellama:latest"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, out.String())
+		}
+	}
+
+	var models []OllamaModel
+	data := captureJSON(t, func() error {
+		flags.JSON = true
+		return runModelsListCommand(flags, &out)
+	})
+	if err := json.Unmarshal(data, &models); err != nil || len(models) != 2 {
+		t.Errorf("Unexpected JSON: %v\n%s", err, data)
+	}
+}
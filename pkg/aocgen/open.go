@@ -0,0 +1,104 @@
+package aocgen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// runEditor runs the editor command and waits for the editor to exit.
+var runEditor = func(cmd *exec.Cmd) error { return cmd.Run() }
+
+// splitEditors are the editors told to show the files side by side rather
+// than one after the other.
+var splitEditors = map[string]string{
+	"vi": "-O", "vim": "-O", "nvim": "-O", "gvim": "-O", "mvim": "-O",
+}
+
+// editorCommand returns the editor to open files with and its arguments:
+// $VISUAL, $EDITOR, the editor of the config file, or else vi (notepad on
+// Windows).
+func editorCommand() []string {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		if cfg, err := loadConfig(); err == nil {
+			editor = cfg.Editor
+		}
+	}
+	if fields := strings.Fields(editor); len(fields) > 0 {
+		return fields
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// taskFile returns a file with the task of challenge to open next to its
+// solution: the README.md of its workspace, or else a copy of the task
+// written to the temporary directory, which keeps the working directory free
+// of files clean does not know about.
+func taskFile(challenge Challenge, flags Flags) (string, error) {
+	if flags.Workspace {
+		readme := filepath.Join(challengeDir(flags), "README.md")
+		if _, err := os.Stat(readme); err == nil {
+			return readme, nil
+		}
+	}
+	dir := filepath.Join(os.TempDir(), "aocgen-tasks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %v", dir, err)
+	}
+	path := filepath.Join(dir, challenge.Name+".md")
+	if err := os.WriteFile(path, []byte(taskMarkdown(challenge)), 0644); err != nil {
+		return "", fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return path, nil
+}
+
+// openChallenge opens the solution of the challenge in flags and its task
+// side by side in the editor and waits for the editor to exit.
+func openChallenge(flags Flags) error {
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return err
+	}
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return err
+	}
+	solution := filepath.Join(challengeDir(flags), solutionFileName(challenge.Name, ext))
+	if _, err := os.Stat(solution); err != nil {
+		return fmt.Errorf("solution %s not found, run generate first", solution)
+	}
+	task, err := taskFile(challenge, flags)
+	if err != nil {
+		return err
+	}
+
+	editor := editorCommand()
+	args := editor[1:]
+	if split, ok := splitEditors[filepath.Base(editor[0])]; ok && len(args) == 0 {
+		args = append(args, split)
+	}
+	args = append(args, solution, task)
+	logger.Info(fmt.Sprintf("Opening %s and %s in %s", solution, task, editor[0]))
+
+	cmd := exec.Command(editor[0], args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := runEditor(cmd); err != nil {
+		return fmt.Errorf("error running editor %s: %v", editor[0], err)
+	}
+	return nil
+}
+
+// runOpenCommand opens a generated solution and its task in the editor.
+func runOpenCommand(flags Flags) error {
+	return openChallenge(flags)
+}
@@ -0,0 +1,60 @@
+package aocgen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenChallenge(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	var opened []string
+	originalRunEditor := runEditor
+	runEditor = func(cmd *exec.Cmd) error {
+		opened = cmd.Args
+		return nil
+	}
+	defer func() { runEditor = originalRunEditor }()
+
+	saveChallenges([]Challenge{{Name: "day3_part1_2023", Task: "Sum the gears.", Input: "467..114.."}})
+	flags := Flags{Day: 3, Part: 1, Year: 2023, Lang: "python", Model: "test"}
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if err := openChallenge(flags); err == nil {
+		t.Errorf("Expected an error before the solution is generated")
+	}
+
+	flags.Open = true
+	if err := generateSolution(flags); err != nil {
+		t.Fatalf("Failed to generate solution: %v", err)
+	}
+	if len(opened) != 4 || opened[0] != "code" || opened[1] != "--wait" || opened[2] != testSolutionPath(t, "day3_part1_2023", "py") {
+		t.Fatalf("Unexpected editor command: %v", opened)
+	}
+	task, err := os.ReadFile(opened[3])
+	if err != nil || !strings.Contains(string(task), "Sum the gears.") {
+		t.Errorf("Expected the task in %s, got %q, %v", opened[3], task, err)
+	}
+
+	// vim gets the files side by side, and a workspace's README is the task
+	t.Setenv("EDITOR", "")
+	saveConfig(Config{Editor: "vim"})
+	flags.Workspace = true
+	if err := generateSolution(flags); err != nil {
+		t.Fatalf("Failed to generate solution: %v", err)
+	}
+	dir := filepath.Join("2023", "day03", "part1")
+	want := []string{"vim", "-O", filepath.Join(dir, "day3_part1_2023.py"), filepath.Join(dir, "README.md")}
+	if strings.Join(opened, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected %v, got %v", want, opened)
+	}
+}
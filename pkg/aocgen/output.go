@@ -0,0 +1,109 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// jsonStdout receives the JSON document written by commands run with --json.
+// In JSON mode os.Stdout is pointed at stderr, so the human-readable progress
+// messages the commands print along the way can't corrupt the document.
+var jsonStdout io.Writer = os.Stdout
+
+// enableJSONOutput switches the process into JSON mode and returns a function
+// that switches it back.
+func enableJSONOutput() func() {
+	original := os.Stdout
+	jsonStdout = original
+	os.Stdout = os.Stderr
+	return func() {
+		os.Stdout = original
+	}
+}
+
+// emitJSON writes v as an indented JSON document to jsonStdout.
+func emitJSON(v interface{}) error {
+	enc := json.NewEncoder(jsonStdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ListEntry is the --json form of a challenge in `aocgen list`.
+type ListEntry struct {
+	Name      string   `json:"name"`
+	Title     string   `json:"title,omitempty"`
+	Languages []string `json:"languages"`
+}
+
+// EvalReport is the --json form of `aocgen eval`.
+type EvalReport struct {
+	Challenge  string  `json:"challenge"`
+	Lang       string  `json:"lang"`
+	Verdict    Verdict `json:"verdict"`
+	Correct    bool    `json:"correct"`
+	Output     string  `json:"output"`
+	DurationMs int64   `json:"duration_ms"`
+	ExitCode   int     `json:"exit_code"`
+	Error      string  `json:"error,omitempty"`
+	// Expected and Answer are the expected answer and the one taken from
+	// the output
+	Expected string `json:"expected,omitempty"`
+	Answer   string `json:"answer,omitempty"`
+	// Case is the example or test case the solution failed on
+	Case string `json:"case,omitempty"`
+}
+
+func newEvalReport(challenge, lang string, result EvalResult) EvalReport {
+	report := EvalReport{
+		Challenge:  challenge,
+		Lang:       lang,
+		Verdict:    result.Verdict,
+		Correct:    result.Verdict == VerdictCorrect,
+		Output:     result.Output,
+		DurationMs: result.Duration.Milliseconds(),
+		ExitCode:   result.Verdict.ExitCode(),
+		Expected:   result.Expected,
+		Answer:     result.Answer,
+		Case:       result.Case,
+	}
+	if result.Err != nil {
+		report.Error = result.Err.Error()
+	}
+	return report
+}
+
+// DownloadReport is the --json form of `aocgen download`.
+type DownloadReport struct {
+	Challenge string `json:"challenge"`
+	// Downloaded is false when the challenge was already stored.
+	Downloaded bool `json:"downloaded"`
+}
+
+// GenerateReport is the --json form of `aocgen generate`.
+type GenerateReport struct {
+	Challenge string `json:"challenge"`
+	Lang      string `json:"lang"`
+	Model     string `json:"model"`
+	File      string `json:"file"`
+	Attempt   int    `json:"attempt,omitempty"`
+	// Verdict and Error are set when generate evaluates the solutions of
+	// several languages.
+	Verdict Verdict `json:"verdict,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// BenchmarkReport is the --json form of `aocgen perf`.
+type BenchmarkReport struct {
+	RunID   string                  `json:"run_id"`
+	Lang    string                  `json:"lang"`
+	Results []BenchmarkReportResult `json:"results"`
+}
+
+// BenchmarkReportResult is a single benchmarked challenge in BenchmarkReport.
+type BenchmarkReportResult struct {
+	Challenge  string `json:"challenge"`
+	DurationMs int64  `json:"duration_ms"`
+	TimedOut   bool   `json:"timed_out"`
+	Error      string `json:"error,omitempty"`
+}
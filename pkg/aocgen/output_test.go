@@ -0,0 +1,108 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureJSON runs fn in JSON mode and returns what it wrote as JSON.
+func captureJSON(t *testing.T, fn func() error) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	original := jsonStdout
+	jsonStdout = &out
+	defer func() { jsonStdout = original }()
+
+	if err := fn(); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestListJSON(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	data := captureJSON(t, func() error { return runListCommand(Flags{JSON: true}) })
+	if string(bytes.TrimSpace(data)) != "[]" {
+		t.Errorf("Expected an empty JSON array, got %s", data)
+	}
+
+	saveChallenges([]Challenge{
+		{Name: "day2_part1_2015", SolutionLang: "python"},
+		{Name: "day1_part1_2015", SolutionLang: "go"},
+		{Name: "day1_part1_2015"},
+	})
+
+	var entries []ListEntry
+	data = captureJSON(t, func() error { return runListCommand(Flags{JSON: true}) })
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if len(entries) != 2 || entries[0].Name != "day1_part1_2015" || len(entries[0].Languages) != 2 || entries[0].Languages[1] != "unsolved" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestEvalJSON(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Answer: "42"}})
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print(42)\n"), 0644)
+
+	var report EvalReport
+	data := captureJSON(t, func() error {
+		return runEvaluationCommand(Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", JSON: true})
+	})
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if report.Challenge != "day1_part1_2015" || report.Verdict != VerdictCorrect || !report.Correct || report.Output != "42\n" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestDownloadJSON(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/input") {
+			w.Write([]byte("1\n"))
+			return
+		}
+		w.Write([]byte(`<article class="day-desc"><h2>--- Day 1 ---</h2></article>`))
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	flags := Flags{Day: 1, Year: 2020, Session: "test_session", JSON: true}
+	for _, expected := range []bool{true, false} {
+		var report DownloadReport
+		data := captureJSON(t, func() error { return runDownloadCommand(flags) })
+		if err := json.Unmarshal(data, &report); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, data)
+		}
+		if report.Challenge != "day1_part1_2020" || report.Downloaded != expected {
+			t.Errorf("Expected downloaded=%v, got %+v", expected, report)
+		}
+	}
+}
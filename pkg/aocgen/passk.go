@@ -0,0 +1,173 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// passAtKValues are the k reported by `aocgen stats`.
+var passAtKValues = []int{1, 5, 10}
+
+// PassAtKStats summarizes the evaluated attempts of one model in one
+// language, for one year or, with Year zero, for all years.
+type PassAtKStats struct {
+	Model      string `json:"model"`
+	Lang       string `json:"lang"`
+	Year       int    `json:"year,omitempty"`
+	Challenges int    `json:"challenges"`
+	Samples    int    `json:"samples"`
+	// PassAt maps k to the estimated chance that at least one of k samples
+	// is correct. It is missing a k when no challenge has k samples.
+	PassAt map[int]float64 `json:"pass_at"`
+	// AvgDurationMs is the mean eval wall-clock time per sample.
+	AvgDurationMs int64 `json:"avg_duration_ms"`
+	// AvgTokens is the mean number of prompt and completion tokens per sample.
+	AvgTokens int `json:"avg_tokens"`
+}
+
+// passAtK is the unbiased estimator of pass@k from n samples of which c are
+// correct: 1 - C(n-c, k) / C(n, k).
+func passAtK(n, c, k int) float64 {
+	if n-c < k {
+		return 1
+	}
+	prob := 1.0
+	for i := n - c + 1; i <= n; i++ {
+		prob *= 1 - float64(k)/float64(i)
+	}
+	return 1 - prob
+}
+
+// computePassAtK treats every evaluated attempt as a sample and computes
+// pass@k per model and language, per year and over all years. Attempts that
+// were never evaluated, or could not be checked without a known answer, are
+// ignored.
+func computePassAtK(attempts []Attempt) []PassAtKStats {
+	type sample struct {
+		challenge string
+		year      int
+		attempt   Attempt
+	}
+	groups := make(map[[2]string][]sample)
+	for _, a := range attempts {
+		if a.Verdict == "" || a.Verdict == VerdictUnknownAnswer {
+			continue
+		}
+		_, _, year, ok := parseChallengeName(a.Challenge)
+		if !ok {
+			continue
+		}
+		key := [2]string{a.Model, strings.ToLower(a.Lang)}
+		groups[key] = append(groups[key], sample{challenge: a.Challenge, year: year, attempt: a})
+	}
+
+	var keys [][2]string
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	summarize := func(model, lang string, year int, samples []sample) PassAtKStats {
+		stats := PassAtKStats{Model: model, Lang: lang, Year: year, Samples: len(samples), PassAt: map[int]float64{}}
+		total := make(map[string]int)
+		correct := make(map[string]int)
+		var duration time.Duration
+		var timed, tokens, counted int
+		for _, s := range samples {
+			total[s.challenge]++
+			if s.attempt.Verdict == VerdictCorrect {
+				correct[s.challenge]++
+			}
+			if s.attempt.Duration > 0 {
+				duration += s.attempt.Duration
+				timed++
+			}
+			if n := s.attempt.Usage.PromptTokens + s.attempt.Usage.CompletionTokens; n > 0 {
+				tokens += n
+				counted++
+			}
+		}
+		stats.Challenges = len(total)
+
+		for _, k := range passAtKValues {
+			sum, challenges := 0.0, 0
+			for name, n := range total {
+				if n >= k {
+					sum += passAtK(n, correct[name], k)
+					challenges++
+				}
+			}
+			if challenges > 0 {
+				stats.PassAt[k] = sum / float64(challenges)
+			}
+		}
+		if timed > 0 {
+			stats.AvgDurationMs = (duration / time.Duration(timed)).Milliseconds()
+		}
+		if counted > 0 {
+			stats.AvgTokens = tokens / counted
+		}
+		return stats
+	}
+
+	var result []PassAtKStats
+	for _, key := range keys {
+		samples := groups[key]
+		byYear := make(map[int][]sample)
+		var years []int
+		for _, s := range samples {
+			if byYear[s.year] == nil {
+				years = append(years, s.year)
+			}
+			byYear[s.year] = append(byYear[s.year], s)
+		}
+		sort.Ints(years)
+
+		for _, year := range years {
+			result = append(result, summarize(key[0], key[1], year, byYear[year]))
+		}
+		result = append(result, summarize(key[0], key[1], 0, samples))
+	}
+	return result
+}
+
+// printPassAtK prints the pass@k table of `aocgen stats`.
+func printPassAtK(w io.Writer, stats []PassAtKStats) {
+	fmt.Fprintf(w, "%-24s  %-10s  %-5s  %10s  %7s", "Model", "Language", "Year", "Challenges", "Samples")
+	for _, k := range passAtKValues {
+		fmt.Fprintf(w, "  %8s", fmt.Sprintf("pass@%d", k))
+	}
+	fmt.Fprintf(w, "  %9s  %10s\n", "Avg time", "Avg tokens")
+
+	for _, s := range stats {
+		year := "all"
+		if s.Year != 0 {
+			year = fmt.Sprint(s.Year)
+		}
+		fmt.Fprintf(w, "%-24s  %-10s  %-5s  %10d  %7d", s.Model, s.Lang, year, s.Challenges, s.Samples)
+		for _, k := range passAtKValues {
+			value := "-"
+			if p, ok := s.PassAt[k]; ok {
+				value = fmt.Sprintf("%.1f%%", p*100)
+			}
+			fmt.Fprintf(w, "  %8s", value)
+		}
+		avgTime := "-"
+		if s.AvgDurationMs > 0 {
+			avgTime = (time.Duration(s.AvgDurationMs) * time.Millisecond).String()
+		}
+		avgTokens := "-"
+		if s.AvgTokens > 0 {
+			avgTokens = fmt.Sprint(s.AvgTokens)
+		}
+		fmt.Fprintf(w, "  %9s  %10s\n", avgTime, avgTokens)
+	}
+}
@@ -0,0 +1,83 @@
+package aocgen
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPassAtK(t *testing.T) {
+	tests := []struct {
+		n, c, k  int
+		expected float64
+	}{
+		{1, 1, 1, 1},
+		{1, 0, 1, 0},
+		{10, 3, 1, 0.3},
+		{10, 0, 5, 0},
+		{10, 6, 5, 1},
+		{5, 1, 5, 1},
+		{10, 1, 5, 0.5},
+		{4, 2, 2, 1 - 1.0/6},
+	}
+	for _, tt := range tests {
+		if got := passAtK(tt.n, tt.c, tt.k); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("passAtK(%d, %d, %d) = %v, expected %v", tt.n, tt.c, tt.k, got, tt.expected)
+		}
+	}
+}
+
+func TestComputePassAtK(t *testing.T) {
+	var attempts []Attempt
+	add := func(challenge, model string, verdict Verdict, duration time.Duration, tokens int) {
+		attempts = append(attempts, Attempt{Challenge: challenge, Lang: "Go", Model: model, Verdict: verdict, Duration: duration, Usage: Usage{PromptTokens: tokens}})
+	}
+	for i := 0; i < 5; i++ {
+		verdict := VerdictWrongAnswer
+		if i == 0 {
+			verdict = VerdictCorrect
+		}
+		add("day1_part1_2015", "gpt-4o", verdict, 100*time.Millisecond, 1000)
+		add("day1_part1_2016", "gpt-4o", VerdictCorrect, 300*time.Millisecond, 2000)
+	}
+	add("day2_part1_2016", "gpt-4o", VerdictCompileError, 0, 0)
+	add("day1_part1_2015", "gpt-4o-mini", VerdictCorrect, 0, 0)
+	attempts = append(attempts, Attempt{Challenge: "day3_part1_2015", Lang: "go", Model: "gpt-4o"})
+	add("day4_part1_2015", "gpt-4o", VerdictUnknownAnswer, 0, 0)
+
+	stats := computePassAtK(attempts)
+	if len(stats) != 5 {
+		t.Fatalf("Expected rows for 2015, 2016 and all years of gpt-4o and 2015 and all of gpt-4o-mini, got %+v", stats)
+	}
+
+	y2015 := stats[0]
+	if y2015.Model != "gpt-4o" || y2015.Lang != "go" || y2015.Year != 2015 || y2015.Challenges != 1 || y2015.Samples != 5 {
+		t.Errorf("Unexpected 2015 row: %+v", y2015)
+	}
+	if math.Abs(y2015.PassAt[1]-0.2) > 1e-9 || y2015.PassAt[5] != 1 {
+		t.Errorf("Expected pass@1 20%% and pass@5 100%%, got %v", y2015.PassAt)
+	}
+	if _, ok := y2015.PassAt[10]; ok {
+		t.Errorf("Expected no pass@10 with 5 samples, got %v", y2015.PassAt)
+	}
+
+	overall := stats[2]
+	if overall.Year != 0 || overall.Challenges != 3 || overall.Samples != 11 {
+		t.Errorf("Unexpected overall row: %+v", overall)
+	}
+	if math.Abs(overall.PassAt[1]-(0.2+1+0)/3) > 1e-9 {
+		t.Errorf("Expected pass@1 averaged over challenges, got %v", overall.PassAt)
+	}
+	if overall.AvgDurationMs != 200 || overall.AvgTokens != 1500 {
+		t.Errorf("Expected averages over samples with a duration and usage, got %d ms and %d tokens", overall.AvgDurationMs, overall.AvgTokens)
+	}
+
+	var out bytes.Buffer
+	printPassAtK(&out, stats)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 || !strings.Contains(lines[0], "pass@10") || !strings.Contains(lines[3], "all") || !strings.Contains(lines[3], "200ms") {
+		t.Errorf("Unexpected table:\n%s", out.String())
+	}
+}
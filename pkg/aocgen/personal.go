@@ -0,0 +1,292 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const personalTimesFile = "personal_times.json"
+
+// PersonalTime is when you solved one part of a puzzle, from your personal
+// stats page on adventofcode.com.
+type PersonalTime struct {
+	Year int `json:"year"`
+	Day  int `json:"day"`
+	Part int `json:"part"`
+	// Elapsed is the time from the puzzle's unlock to the star. The site
+	// only reports it for the first 24 hours; later stars have Over24h set
+	// and no Elapsed or CompletedAt.
+	Elapsed     time.Duration `json:"elapsed,omitempty"`
+	Over24h     bool          `json:"over_24h,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	Rank        int           `json:"rank,omitempty"`
+	Score       int           `json:"score"`
+}
+
+// PersonalTimeRow is a day of `aocgen times`: your times for both parts and
+// the fastest correct model solution for each, measured the same way.
+type PersonalTimeRow struct {
+	Day   int             `json:"day"`
+	Parts [2]PersonalPart `json:"parts"`
+}
+
+// PersonalPart compares your time for one part with the models'.
+type PersonalPart struct {
+	Time  *PersonalTime `json:"time,omitempty"`
+	Model string        `json:"model,omitempty"`
+	// ModelElapsed is the time from unlock to the generation of the first
+	// solution that passed eval.
+	ModelElapsed time.Duration `json:"model_elapsed,omitempty"`
+}
+
+var personalStatsRowRe = regexp.MustCompile(`^\s*(\d+)\s+(\S+)\s+(\S+)\s+(\S+)(?:\s+(\S+)\s+(\S+)\s+(\S+))?\s*$`)
+
+// parsePersonalStats reads the table of the leaderboard/self page of year.
+func parsePersonalStats(page string, year int) ([]PersonalTime, error) {
+	if !strings.Contains(page, "Part 1") {
+		return nil, fmt.Errorf("personal stats not found, check your session token")
+	}
+
+	var times []PersonalTime
+	for _, line := range strings.Split(html.UnescapeString(stripTags(page)), "\n") {
+		m := personalStatsRowRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		day, _ := strconv.Atoi(m[1])
+		for part, fields := range [][]string{m[2:5], m[5:8]} {
+			if fields[0] == "" || fields[0] == "-" {
+				continue
+			}
+			t := PersonalTime{Year: year, Day: day, Part: part + 1}
+			t.Rank, _ = strconv.Atoi(fields[1])
+			t.Score, _ = strconv.Atoi(fields[2])
+			if fields[0] == ">24h" {
+				t.Over24h = true
+			} else {
+				elapsed, err := parseClock(fields[0])
+				if err != nil {
+					return nil, fmt.Errorf("day %d: %v", day, err)
+				}
+				t.Elapsed = elapsed
+				completed := unlockTime(year, day).Add(elapsed)
+				t.CompletedAt = &completed
+			}
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
+// parseClock parses an hh:mm:ss duration.
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// fetchPersonalTimes downloads and parses your personal stats for year.
+func fetchPersonalTimes(ctx context.Context, flags Flags, year int) ([]PersonalTime, error) {
+	ctx, cancel := withHTTPTimeout(ctx, flags.HTTPTimeout)
+	defer cancel()
+
+	if err := verifySession(ctx, flags.Session); err != nil {
+		return nil, err
+	}
+
+	req, err := newAoCRequest(ctx, "GET", fmt.Sprintf("%s/%d/leaderboard/self", aocBaseURL, year), flags.Session, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doAoCRequest(&http.Client{}, req)
+	if err != nil {
+		return nil, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download personal stats: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	return parsePersonalStats(string(body), year)
+}
+
+// loadPersonalTimes reads the stored personal times. Nothing stored yields no
+// times.
+func loadPersonalTimes() ([]PersonalTime, error) {
+	var times []PersonalTime
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), personalTimesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return times, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &times); err != nil {
+		return nil, fmt.Errorf("error parsing personal times: %v", err)
+	}
+	return times, nil
+}
+
+// savePersonalTimes replaces the stored times of year with times.
+func savePersonalTimes(year int, times []PersonalTime) error {
+	stored, err := loadPersonalTimes()
+	if err != nil {
+		return err
+	}
+	merged := append([]PersonalTime{}, times...)
+	for _, t := range stored {
+		if t.Year != year {
+			merged = append(merged, t)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		return a.Part < b.Part
+	})
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getCacheDir(), personalTimesFile), data, 0644)
+}
+
+// comparePersonalTimes builds the rows of year from your times and the
+// attempts. A model's time is measured from the puzzle's unlock to the
+// generation of its first attempt that passed eval.
+func comparePersonalTimes(year int, times []PersonalTime, attempts []Attempt) []PersonalTimeRow {
+	var rows []PersonalTimeRow
+	for day := 1; day <= 25; day++ {
+		row := PersonalTimeRow{Day: day}
+		found := false
+		for part := 1; part <= 2; part++ {
+			p := &row.Parts[part-1]
+			for i := range times {
+				if times[i].Year == year && times[i].Day == day && times[i].Part == part {
+					p.Time = &times[i]
+					found = true
+				}
+			}
+
+			name := fmt.Sprintf("day%d_part%d_%d", day, part, year)
+			for _, a := range attempts {
+				if a.Challenge != name || a.Verdict != VerdictCorrect {
+					continue
+				}
+				elapsed := a.Time.Sub(unlockTime(year, day))
+				if p.Model == "" || elapsed < p.ModelElapsed {
+					p.Model, p.ModelElapsed = a.Model, elapsed
+					found = true
+				}
+			}
+		}
+		if found {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// formatSolveTime formats a time since unlock the way the site does.
+func formatSolveTime(d time.Duration, over24h bool) string {
+	if over24h || d >= 24*time.Hour {
+		return ">24h"
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+func printPersonalTimes(w io.Writer, year int, rows []PersonalTimeRow) {
+	if len(rows) == 0 {
+		fmt.Fprintf(w, "No stars in %d yet.\n", year)
+		return
+	}
+	fmt.Fprintf(w, "%3s  %8s  %6s  %-20s  %8s  %6s  %s\n", "Day", "Part 1", "Rank", "Model", "Part 2", "Rank", "Model")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%3d", row.Day)
+		for i, p := range row.Parts {
+			yours, rank := "-", "-"
+			if p.Time != nil {
+				yours = formatSolveTime(p.Time.Elapsed, p.Time.Over24h)
+				rank = strconv.Itoa(p.Time.Rank)
+			}
+			model := "-"
+			if p.Model != "" {
+				model = p.Model + " " + formatSolveTime(p.ModelElapsed, false)
+			}
+			if i == 0 {
+				fmt.Fprintf(w, "  %8s  %6s  %-20s", yours, rank, model)
+			} else {
+				fmt.Fprintf(w, "  %8s  %6s  %s\n", yours, rank, model)
+			}
+		}
+	}
+}
+
+// runTimesCommand fetches your personal times for --year, stores them and
+// compares them with the models' solve times.
+func runTimesCommand(flags Flags, w io.Writer) error {
+	if flags.Session == "" {
+		return fmt.Errorf("session token is required")
+	}
+	year := flags.Year
+	if year == 0 {
+		year = currentEventYear()
+	}
+
+	times, err := fetchPersonalTimes(commandContext, flags, year)
+	if err != nil {
+		return err
+	}
+	if err := savePersonalTimes(year, times); err != nil {
+		return fmt.Errorf("error saving personal times: %v", err)
+	}
+	attempts, err := loadAttempts()
+	if err != nil {
+		return err
+	}
+
+	rows := comparePersonalTimes(year, times, attempts)
+	if flags.JSON {
+		if rows == nil {
+			rows = []PersonalTimeRow{}
+		}
+		return emitJSON(rows)
+	}
+	printPersonalTimes(w, year, rows)
+	return nil
+}
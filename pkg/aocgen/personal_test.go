@@ -0,0 +1,98 @@
+package aocgen
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPersonalStats = `<article><p>These are your personal leaderboard statistics.</p>
+<pre><span class="leaderboard-daydesc-first">      --------Part 1--------   </span><span class="leaderboard-daydesc-both">--------Part 2--------</span>
+Day   <span class="leaderboard-daydesc-first">    Time   Rank  Score</span>   <span class="leaderboard-daydesc-both">    Time   Rank  Score</span>
+  3   01:02:03   4567      0          -      -      -
+  2       &gt;24h  50000      0       &gt;24h  45000      0
+  1   00:05:00    120      0   00:10:30    150      0
+</pre></article>`
+
+func TestParsePersonalStats(t *testing.T) {
+	times, err := parsePersonalStats(testPersonalStats, 2023)
+	if err != nil {
+		t.Fatalf("Failed to parse personal stats: %v", err)
+	}
+	if len(times) != 5 {
+		t.Fatalf("Expected 5 stars, got %+v", times)
+	}
+
+	day3 := times[0]
+	if day3.Day != 3 || day3.Part != 1 || day3.Elapsed != time.Hour+2*time.Minute+3*time.Second || day3.Rank != 4567 {
+		t.Errorf("Unexpected day 3 time: %+v", day3)
+	}
+	if expected := unlockTime(2023, 3).Add(day3.Elapsed); day3.CompletedAt == nil || !day3.CompletedAt.Equal(expected) {
+		t.Errorf("Expected completion at %v, got %v", expected, day3.CompletedAt)
+	}
+	if day2 := times[1]; !day2.Over24h || day2.CompletedAt != nil || day2.Rank != 50000 {
+		t.Errorf("Unexpected day 2 time: %+v", day2)
+	}
+	if day1 := times[4]; day1.Day != 1 || day1.Part != 2 || day1.Elapsed != 10*time.Minute+30*time.Second {
+		t.Errorf("Unexpected day 1 part 2 time: %+v", day1)
+	}
+
+	if _, err := parsePersonalStats("<html>log in</html>", 2023); err == nil {
+		t.Errorf("Expected an error for a page without stats")
+	}
+}
+
+func TestRunTimesCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	withoutAoCThrottle(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2023/leaderboard/self" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testPersonalStats))
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	saveAttempts([]Attempt{
+		{Challenge: "day1_part1_2023", Lang: "python", Number: 1, Model: "gpt-4o", Time: unlockTime(2023, 1).Add(time.Minute), Verdict: VerdictCorrect},
+		{Challenge: "day1_part1_2023", Lang: "python", Number: 2, Model: "gpt-4o-mini", Time: unlockTime(2023, 1).Add(30 * time.Second), Verdict: VerdictWrongAnswer},
+	})
+	savePersonalTimes(2022, []PersonalTime{{Year: 2022, Day: 1, Part: 1, Elapsed: time.Minute}})
+
+	var out bytes.Buffer
+	if err := runTimesCommand(Flags{Year: 2023, Session: "test_session"}, &out); err != nil {
+		t.Fatalf("Failed to fetch times: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a header and 3 days, got:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "00:05:00") || !strings.Contains(lines[1], "gpt-4o 00:01:00") || !strings.Contains(lines[1], "00:10:30") {
+		t.Errorf("Expected day 1 with your times and the model's, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], ">24h") {
+		t.Errorf("Expected day 2 over 24 hours, got %q", lines[2])
+	}
+
+	stored, err := loadPersonalTimes()
+	if err != nil {
+		t.Fatalf("Failed to load personal times: %v", err)
+	}
+	if len(stored) != 6 || stored[0].Year != 2022 {
+		t.Errorf("Expected the 2023 times stored next to 2022's, got %+v", stored)
+	}
+
+	if err := runTimesCommand(Flags{Year: 2023}, &out); err == nil {
+		t.Errorf("Expected an error without a session token")
+	}
+}
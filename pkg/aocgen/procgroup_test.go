@@ -0,0 +1,63 @@
+//go:build linux
+
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// processAlive reports whether pid is running and not a zombie.
+func processAlive(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestJudgeSolutionKillsProcessGroup(t *testing.T) {
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+	code := fmt.Sprintf(`import subprocess, time
+child = subprocess.Popen(["sleep", "30"])
+open(%q, "w").write(str(child.pid))
+time.sleep(30)
+`, pidFile)
+	filename := filepath.Join(dir, "solution.py")
+	if err := os.WriteFile(filename, []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write solution: %v", err)
+	}
+
+	result, err := judgeSolution(context.Background(), Challenge{Answer: "42"}, filename, "python", Limits{Timeout: 500 * time.Millisecond}, false)
+	if err != nil {
+		t.Fatalf("Failed to judge solution: %v", err)
+	}
+	if result.Verdict != VerdictTimeout {
+		t.Fatalf("Expected a timeout, got %s: %s", result.Verdict, result.Output)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("Solution did not start its child: %v", err)
+	}
+	pid, _ := strconv.Atoi(string(data))
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		t.Errorf("Expected the solution's child process %d to be killed", pid)
+	}
+}
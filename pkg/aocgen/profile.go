@@ -0,0 +1,66 @@
+package aocgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of model settings in the config file, selected
+// with --profile, such as a fast model for drafts and a strong one for hard
+// puzzles.
+type Profile struct {
+	Model string `json:"model,omitempty"`
+	// ModelAPI is the endpoint of Model (default: its provider's)
+	ModelAPI string      `json:"model_api,omitempty"`
+	Sampling Sampling    `json:"sampling,omitempty"`
+	Retry    RetryPolicy `json:"retry,omitempty"`
+}
+
+// retryPolicyKey is the context key of the retry policy of a model request.
+type retryPolicyKey struct{}
+
+// profileNames returns the names of the profiles of cfg in sorted order.
+func profileNames(cfg Config) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withProfile returns cfg with the settings of the profile name taking the
+// place of its own, so flags still take precedence over them. An empty name
+// leaves cfg as it is.
+func (cfg Config) withProfile(name string) (Config, error) {
+	if name == "" {
+		return cfg, nil
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		if len(cfg.Profiles) == 0 {
+			return cfg, fmt.Errorf("unknown profile %q, the config file defines none", name)
+		}
+		return cfg, fmt.Errorf("unknown profile %q, expected one of: %s", name, strings.Join(profileNames(cfg), ", "))
+	}
+
+	// The endpoint of the default model does not serve the profile's model
+	if profile.Model != "" {
+		cfg.Model, cfg.ModelAPI = profile.Model, ""
+	}
+	if profile.ModelAPI != "" {
+		cfg.ModelAPI = profile.ModelAPI
+	}
+	cfg.Sampling = profile.Sampling.withDefaults(cfg.Sampling)
+	if profile.Retry.MaxAttempts > 0 {
+		cfg.Retry.MaxAttempts = profile.Retry.MaxAttempts
+	}
+	if profile.Retry.BaseDelayMs > 0 {
+		cfg.Retry.BaseDelayMs = profile.Retry.BaseDelayMs
+	}
+	if profile.Retry.MaxDelayMs > 0 {
+		cfg.Retry.MaxDelayMs = profile.Retry.MaxDelayMs
+	}
+	return cfg, nil
+}
@@ -0,0 +1,97 @@
+package aocgen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithProfile(t *testing.T) {
+	temperature, zero := 0.2, 0.0
+	cfg := Config{
+		Model:    "gpt-4o-mini",
+		ModelAPI: "http://proxy",
+		Sampling: Sampling{Temperature: &zero, MaxTokens: 4096},
+		Retry:    RetryPolicy{MaxAttempts: 3, MaxDelayMs: 5000},
+		Profiles: map[string]Profile{
+			"fast": {Model: "groq/llama-3.1-8b-instant", Sampling: Sampling{Temperature: &temperature}, Retry: RetryPolicy{MaxAttempts: 1}},
+			"best": {Model: "gpt-4o"},
+		},
+	}
+
+	fast, err := cfg.withProfile("fast")
+	if err != nil {
+		t.Fatalf("withProfile failed: %v", err)
+	}
+	if fast.Model != "groq/llama-3.1-8b-instant" || fast.ModelAPI != "" {
+		t.Errorf("Expected the profile's model with its default endpoint, got %q at %q", fast.Model, fast.ModelAPI)
+	}
+	if *fast.Sampling.Temperature != 0.2 || fast.Sampling.MaxTokens != 4096 {
+		t.Errorf("Expected the profile's sampling over the config's, got %+v", fast.Sampling)
+	}
+	if fast.Retry != (RetryPolicy{MaxAttempts: 1, MaxDelayMs: 5000}) {
+		t.Errorf("Expected the profile's retry policy over the config's, got %+v", fast.Retry)
+	}
+	if *cfg.Sampling.Temperature != 0 {
+		t.Errorf("Expected the config to be left as it is")
+	}
+
+	if same, err := cfg.withProfile(""); err != nil || same.Model != cfg.Model {
+		t.Errorf("Expected no profile to leave the config as it is, got %+v, %v", same, err)
+	}
+	if _, err := cfg.withProfile("cheap"); err == nil || !strings.Contains(err.Error(), "best, fast") {
+		t.Errorf("Expected an error listing the profiles, got %v", err)
+	}
+}
+
+func TestParseCommandFlagsProfile(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveConfig(Config{
+		Model:   "gpt-4o-mini",
+		Profile: "best",
+		Profiles: map[string]Profile{
+			"fast": {Model: "groq/llama-3.1-8b-instant", Retry: RetryPolicy{MaxAttempts: 1}},
+			"best": {Model: "gpt-4o"},
+		},
+	})
+
+	flags, err := parseCommandFlags(nil)
+	if err != nil || flags.Model != "gpt-4o" {
+		t.Errorf("Expected the default profile's model, got %q, %v", flags.Model, err)
+	}
+	flags, err = parseCommandFlags([]string{"--profile", "fast"})
+	if err != nil || flags.Model != "groq/llama-3.1-8b-instant" || flags.RetryPolicy.MaxAttempts != 1 {
+		t.Errorf("Expected the settings of --profile, got %+v, %v", flags, err)
+	}
+	flags, err = parseCommandFlags([]string{"--profile", "fast", "--model", "gpt-4"})
+	if err != nil || flags.Model != "gpt-4" {
+		t.Errorf("Expected --model to win over the profile, got %q, %v", flags.Model, err)
+	}
+	if _, err := parseCommandFlags([]string{"--profile", "cheap"}); err == nil {
+		t.Errorf("Expected an error for an unknown profile")
+	}
+}
+
+func TestProfileRetryPolicy(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	flags := Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL, RetryPolicy: RetryPolicy{MaxAttempts: 1}}
+	if _, err := completeCode(context.Background(), "prompt", flags); err == nil {
+		t.Errorf("Expected an error for 503")
+	}
+	if requests != 1 {
+		t.Errorf("Expected the profile's retry policy to turn retries off, got %d requests", requests)
+	}
+}
@@ -0,0 +1,70 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const progressBarWidth = 30
+
+// progressWriter counts the bytes written through it and redraws a progress
+// line for them on out. With a known total the line has a bar and a
+// percentage, otherwise only the size so far. A download that resumes a
+// partial file starts with the bytes it already has.
+type progressWriter struct {
+	out     io.Writer
+	label   string
+	total   int64
+	written int64
+	drawn   time.Time
+}
+
+func newProgressWriter(out io.Writer, label string, written, total int64) *progressWriter {
+	return &progressWriter{out: out, label: label, written: written, total: total}
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	// Redrawing on every chunk floods slow terminals
+	if time.Since(p.drawn) >= 100*time.Millisecond {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+func (p *progressWriter) draw() {
+	p.drawn = time.Now()
+	if p.total <= 0 {
+		fmt.Fprintf(p.out, "\r%s %s", p.label, formatBytes(p.written))
+		return
+	}
+	done := int(float64(progressBarWidth) * float64(p.written) / float64(p.total))
+	if done > progressBarWidth {
+		done = progressBarWidth
+	}
+	fmt.Fprintf(p.out, "\r%s [%s%s] %3d%% %s/%s", p.label,
+		strings.Repeat("#", done), strings.Repeat(" ", progressBarWidth-done),
+		p.written*100/p.total, formatBytes(p.written), formatBytes(p.total))
+}
+
+// Finish draws the final state and ends the line.
+func (p *progressWriter) Finish() {
+	p.draw()
+	fmt.Fprintln(p.out)
+}
+
+// formatBytes formats a size with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
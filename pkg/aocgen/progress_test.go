@@ -0,0 +1,47 @@
+package aocgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressWriter(t *testing.T) {
+	var out bytes.Buffer
+	p := newProgressWriter(&out, "0000.parquet", 1024, 2048)
+	p.Write(make([]byte, 512))
+	p.Write(make([]byte, 512))
+	p.Finish()
+
+	lines := strings.Split(out.String(), "\r")
+	last := lines[len(lines)-1]
+	if !strings.Contains(last, "100%") || !strings.Contains(last, "2.0 KiB/2.0 KiB") || !strings.HasSuffix(last, "\n") {
+		t.Errorf("Unexpected final progress line: %q", last)
+	}
+	if !strings.Contains(last, "["+strings.Repeat("#", progressBarWidth)+"]") {
+		t.Errorf("Expected a full bar, got %q", last)
+	}
+
+	out.Reset()
+	p = newProgressWriter(&out, "shard", 0, -1)
+	p.Write(make([]byte, 10))
+	p.Finish()
+	if !strings.HasSuffix(out.String(), "\rshard 10 B\n") {
+		t.Errorf("Expected only the size without a total, got %q", out.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		1024:              "1.0 KiB",
+		1536:              "1.5 KiB",
+		100 * 1024 * 1024: "100.0 MiB",
+	}
+	for n, expected := range tests {
+		if got := formatBytes(n); got != expected {
+			t.Errorf("formatBytes(%d) = %q, expected %q", n, got, expected)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package aocgen
+
+import (
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// runPromptCommand prints the prompt generate would send for a challenge
+// without calling any model API.
+func runPromptCommand(flags Flags, w io.Writer) error {
+	if flags.Lang == "" {
+		return fmt.Errorf("language is required")
+	}
+
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return err
+	}
+
+	prompt, err := buildPrompt(challenge, flags)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, prompt)
+	return nil
+}
+
+// defaultPromptTemplate is used unless the user provides their own template.
+//
+//go:embed templates/prompt.tmpl
+var defaultPromptTemplate string
+
+// promptTemplateFile is looked up under the templates directory of the cache
+// dir when no --template flag is given.
+const promptTemplateFile = "prompt.tmpl"
+
+// defaultInputLines is the default of --input-lines.
+const defaultInputLines = 10
+
+// maxInputSampleTokens caps the tokens of the input sample that
+// --input-lines puts in the prompt, however large the context window.
+const maxInputSampleTokens = 2000
+
+// maxSampleLineLength is the number of characters a line of the input sample
+// is cut to.
+const maxSampleLineLength = 200
+
+// PromptData is the data available to prompt templates.
+type PromptData struct {
+	Name string
+	Day  int
+	Part int
+	Year int
+	Lang string
+	Task string
+	// Input is the first --input-lines lines of the puzzle input, cut to fit
+	// the context window, or empty. The built-in template shows it when it
+	// is set.
+	Input string
+	// InputArg is set when the program must read the input file path from
+	// its first command-line argument instead of opening input.txt.
+	InputArg bool
+	Examples []PromptExample
+	// Part1Solution is the stored solution of part 1 in Lang, set for part 2
+	// with --with-part1.
+	Part1Solution string
+}
+
+// PromptExample is a solved challenge shown to the model as a few-shot example.
+type PromptExample struct {
+	Number   int
+	Task     string
+	Solution string
+}
+
+// buildPrompt renders the prompt sent to the model for a challenge from the
+// user's template if there is one, or the built-in template otherwise.
+func buildPrompt(challenge Challenge, flags Flags) (string, error) {
+	day, part, year, _ := parseChallengeName(challenge.Name)
+	data := PromptData{
+		Name:     challenge.Name,
+		Day:      day,
+		Part:     part,
+		Year:     year,
+		Lang:     flags.Lang,
+		Task:     challenge.Task,
+		InputArg: flags.InputArg,
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Warn("cannot read config, using the built-in context windows", "err", err)
+	}
+
+	numExamples := flags.Examples
+	if numExamples == 0 && flags.Similar {
+		numExamples = defaultSimilarExamples
+	}
+	withPart1 := flags.WithPart1 && part == 2
+	var challenges []Challenge
+	if numExamples > 0 || withPart1 {
+		var err error
+		challenges, err = loadChallenges(getCacheDir(), challengesFile)
+		if err != nil {
+			return "", fmt.Errorf("error loading examples: %v", err)
+		}
+	}
+	if withPart1 {
+		data.Part1Solution = part1Solution(challenges, day, year, flags.Lang)
+		if data.Part1Solution == "" {
+			logger.Warn(fmt.Sprintf("no %s solution of day%d_part1_%d stored, the prompt will not include it", flags.Lang, day, year))
+		}
+	}
+	if numExamples > 0 {
+		examples := selectExamples(challenges, challenge, flags.Lang, numExamples)
+		if flags.Similar {
+			ctx, cancel := withHTTPTimeout(commandContext, flags.HTTPTimeout)
+			similar, err := similarExamples(ctx, challenges, challenge, flags.Lang, numExamples, cfg.Embeddings)
+			cancel()
+			if err != nil {
+				logger.Warn("cannot pick similar examples, using random ones", "err", err)
+			} else {
+				examples = similar
+			}
+		}
+		for i, example := range examples {
+			data.Examples = append(data.Examples, PromptExample{
+				Number:   i + 1,
+				Task:     strings.TrimSpace(example.Task),
+				Solution: strings.TrimSpace(stripProvenanceHeader(example.Solution)),
+			})
+		}
+	}
+
+	text, err := loadPromptTemplate(flags.Template)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing prompt template: %v", err)
+	}
+
+	render := func() (string, error) {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return "", fmt.Errorf("error rendering prompt template: %v", err)
+		}
+		return strings.TrimSpace(sb.String()), nil
+	}
+	system, err := loadSystemPrompt(flags.SystemPrompt)
+	if err != nil {
+		return "", err
+	}
+	if flags.Structured {
+		system = structuredSystemPrompt(system)
+	}
+	budget := promptBudget(flags, cfg) - countTokens(system)
+
+	prompt, err := render()
+	if err != nil {
+		return "", err
+	}
+	if tokens := countTokens(prompt); tokens > budget {
+		strategy, err := contextStrategy(flags.Model, cfg.ContextStrategies)
+		if err != nil {
+			return "", err
+		}
+		logger.Warn(fmt.Sprintf("the prompt of %s takes about %d tokens, more than the %d the context window of %s leaves", challenge.Name, tokens, budget, flags.Model))
+		if strategy == contextTruncate {
+			if prompt, err = truncatePrompt(&data, render, budget); err != nil {
+				return "", err
+			}
+		}
+	}
+	if flags.InputLines <= 0 || challenge.Input == "" {
+		return prompt, nil
+	}
+
+	sampleBudget := min(maxInputSampleTokens, budget-countTokens(prompt))
+	data.Input = promptInputSample(challenge.Input, flags.InputLines, sampleBudget)
+	if data.Input == "" {
+		logger.Warn(fmt.Sprintf("the prompt leaves no room for the input sample in the context window of %s", flags.Model))
+		return prompt, nil
+	}
+	return render()
+}
+
+// truncatePrompt shortens the prompt render makes of data until it takes
+// at most budget tokens: few-shot examples are dropped from the last one,
+// then the flavor text of the task. A prompt that is still too long is
+// returned as it is.
+func truncatePrompt(data *PromptData, render func() (string, error), budget int) (string, error) {
+	prompt, err := render()
+	dropped := 0
+	for err == nil && countTokens(prompt) > budget && len(data.Examples) > 0 {
+		data.Examples = data.Examples[:len(data.Examples)-1]
+		dropped++
+		prompt, err = render()
+	}
+	if dropped > 0 {
+		logger.Info(fmt.Sprintf("Dropped %d few-shot examples to fit the context window", dropped))
+	}
+	if err == nil && countTokens(prompt) > budget {
+		if task := stripFlavorText(data.Task); countTokens(task) < countTokens(data.Task) {
+			data.Task = task
+			logger.Info("Dropped the flavor text of the task to fit the context window")
+			prompt, err = render()
+		}
+	}
+	if err == nil && countTokens(prompt) > budget {
+		logger.Warn("the prompt still does not fit in the context window, the model may not see all of it")
+	}
+	return prompt, err
+}
+
+// promptInputSample returns at most n lines from the start of input that
+// together take at most budget tokens. Lines longer than
+// maxSampleLineLength are cut and marked as such.
+func promptInputSample(input string, n, budget int) string {
+	var lines []string
+	tokens := 0
+	for _, line := range strings.Split(strings.TrimRight(input, "\n"), "\n") {
+		if len(lines) == n {
+			break
+		}
+		line = strings.TrimSuffix(line, "\r")
+		if runes := []rune(line); len(runes) > maxSampleLineLength {
+			line = fmt.Sprintf("%s... (%d more characters)", string(runes[:maxSampleLineLength]), len(runes)-maxSampleLineLength)
+		}
+		if tokens += countTokens(line + "\n"); tokens > budget {
+			logger.Info(fmt.Sprintf("Cut the input sample to %d lines to fit the context window", len(lines)))
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadPromptTemplate returns the template at path, or the user's default
+// template in the cache dir, falling back to the built-in one.
+func loadPromptTemplate(path string) (string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading prompt template: %v", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), "templates", promptTemplateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultPromptTemplate, nil
+		}
+		return "", fmt.Errorf("error reading prompt template: %v", err)
+	}
+	return string(data), nil
+}
+
+// inputSample returns at most n lines from the start of input.
+func inputSample(input string, n int) string {
+	lines := strings.SplitAfter(input, "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.TrimRight(strings.Join(lines, ""), "\n")
+}
+
+// part1Solution returns the stored solution of part 1 of a puzzle in lang, or
+// "" if there is none. Solutions are stored once they pass eval.
+func part1Solution(challenges []Challenge, day, year int, lang string) string {
+	name := fmt.Sprintf("day%d_part1_%d", day, year)
+	for _, c := range challenges {
+		if c.Name == name && strings.EqualFold(c.SolutionLang, lang) && strings.TrimSpace(c.Solution) != "" {
+			return strings.TrimSpace(stripProvenanceHeader(c.Solution))
+		}
+	}
+	return ""
+}
+
+// selectExamples picks up to n solved challenges in lang to use as few-shot
+// examples for target. Other parts of the target's own puzzle are never used.
+// The choice is pseudo-random but seeded by the target name, so the same
+// challenge always gets the same examples.
+func selectExamples(challenges []Challenge, target Challenge, lang string, n int) []Challenge {
+	candidates := exampleCandidates(challenges, target, lang)
+
+	h := fnv.New64a()
+	h.Write([]byte(target.Name))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// exampleCandidates returns the challenges solved in lang that may serve as
+// examples for target, sorted by name: one record per challenge, and none of
+// the target's own puzzle.
+func exampleCandidates(challenges []Challenge, target Challenge, lang string) []Challenge {
+	targetDay, _, targetYear, _ := parseChallengeName(target.Name)
+
+	seen := make(map[string]bool)
+	var candidates []Challenge
+	for _, c := range challenges {
+		if !strings.EqualFold(c.SolutionLang, lang) || strings.TrimSpace(c.Solution) == "" || seen[c.Name] {
+			continue
+		}
+		day, _, year, _ := parseChallengeName(c.Name)
+		if c.Name == target.Name || (day == targetDay && year == targetYear) {
+			continue
+		}
+		seen[c.Name] = true
+		candidates = append(candidates, c)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates
+}
+
+// loadSystemPrompt returns the system prompt given with --system-prompt. A
+// value starting with @ names a file to read it from.
+func loadSystemPrompt(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+	if err != nil {
+		return "", fmt.Errorf("error reading system prompt: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// chatMessages returns the messages of a chat completion request for prompt,
+// preceded by a system message if system is set.
+func chatMessages(system, prompt string) []map[string]string {
+	var messages []map[string]string
+	if system != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": system})
+	}
+	return append(messages, map[string]string{"role": "user", "content": prompt})
+}
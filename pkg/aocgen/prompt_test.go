@@ -0,0 +1,369 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectExamples(t *testing.T) {
+	challenges := []Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "go", Solution: "package main // 1", Task: "task 1"},
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: "print(1)", Task: "task 1"},
+		{Name: "day2_part1_2015", SolutionLang: "go", Solution: "package main // 2", Task: "task 2"},
+		{Name: "day3_part1_2015", SolutionLang: "go", Solution: "", Task: "unsolved"},
+		{Name: "day4_part1_2016", SolutionLang: "go", Solution: "package main // 4", Task: "task 4"},
+		{Name: "day5_part1_2016", SolutionLang: "go", Solution: "package main // target part 1", Task: "target part 1"},
+		{Name: "day5_part2_2016", SolutionLang: "go", Solution: "package main // target", Task: "target"},
+	}
+	target := Challenge{Name: "day5_part2_2016"}
+
+	examples := selectExamples(challenges, target, "go", 10)
+	if len(examples) != 3 {
+		t.Fatalf("Expected 3 examples, got %d: %+v", len(examples), examples)
+	}
+	for _, e := range examples {
+		if e.SolutionLang != "go" || e.Solution == "" {
+			t.Errorf("Selected an unsuitable example: %+v", e)
+		}
+		if strings.HasPrefix(e.Name, "day5_") {
+			t.Errorf("Selected part of the target puzzle as an example: %s", e.Name)
+		}
+	}
+
+	first := selectExamples(challenges, target, "go", 2)
+	second := selectExamples(challenges, target, "go", 2)
+	if len(first) != 2 || first[0].Name != second[0].Name || first[1].Name != second[1].Name {
+		t.Errorf("Expected example selection to be deterministic, got %v and %v", first, second)
+	}
+}
+
+func TestBuildPromptWithExamples(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: "print('floor')", Task: "Find the floor."},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	challenge := Challenge{Name: "day2_part1_2015", Task: "Wrap the presents."}
+
+	prompt, err := buildPrompt(challenge, Flags{Lang: "python", Examples: 1})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+
+	for _, expected := range []string{"Example 1:", "Find the floor.", "```python\nprint('floor')\n```", "Wrap the presents."} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", expected, prompt)
+		}
+	}
+	if strings.Index(prompt, "Find the floor.") > strings.Index(prompt, "Wrap the presents.") {
+		t.Errorf("Expected examples to come before the challenge")
+	}
+
+	prompt, err = buildPrompt(challenge, Flags{Lang: "python"})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if strings.Contains(prompt, "Example") {
+		t.Errorf("Expected no examples without --examples, got:\n%s", prompt)
+	}
+}
+
+func TestBuildPromptWithPart1(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: "print(open('input.txt').read().count('('))\n"},
+		{Name: "day1_part1_2015", SolutionLang: "go", Solution: "package main"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	challenge := Challenge{Name: "day1_part2_2015", Task: "Find the basement."}
+	prompt, err := buildPrompt(challenge, Flags{Lang: "python", WithPart1: true})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	expected := "Find the basement.\n\nHere is a working python solution to part 1 of this challenge. Extend it to solve part 2 instead of starting from scratch:\n```python\nprint(open('input.txt').read().count('('))\n```\n\nThe program should"
+	if !strings.Contains(prompt, expected) {
+		t.Errorf("Expected the part 1 solution after the task, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "package main") {
+		t.Errorf("Expected only the solution in the requested language, got:\n%s", prompt)
+	}
+
+	for _, flags := range []Flags{{Lang: "python"}, {Lang: "rust", WithPart1: true}} {
+		prompt, err := buildPrompt(challenge, flags)
+		if err != nil {
+			t.Fatalf("Failed to build prompt: %v", err)
+		}
+		if strings.Contains(prompt, "part 1") {
+			t.Errorf("Expected no part 1 solution with %+v, got:\n%s", flags, prompt)
+		}
+	}
+}
+
+func TestRunPromptCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "go", Solution: "package main", Task: "Find the floor."},
+		{Name: "day2_part1_2015", Task: "Wrap the presents.", Input: "2x3x4"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	var out bytes.Buffer
+	err = runPromptCommand(Flags{Day: 2, Part: 1, Year: 2015, Lang: "go", Examples: 1}, &out)
+	if err != nil {
+		t.Fatalf("Failed to render prompt: %v", err)
+	}
+
+	expected, err := buildPrompt(Challenge{Name: "day2_part1_2015", Task: "Wrap the presents."}, Flags{Lang: "go", Examples: 1})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if out.String() != expected+"\n" {
+		t.Errorf("Expected printed prompt to match the generated one.\nExpected:\n%s\nGot:\n%s", expected, out.String())
+	}
+
+	if err := runPromptCommand(Flags{Day: 2, Part: 1, Year: 2015}, &out); err == nil {
+		t.Errorf("Expected an error without a language")
+	}
+}
+
+func TestBuildPromptDefaultTemplate(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	prompt, err := buildPrompt(Challenge{Name: "day1_part1_2015", Task: "Find the floor."}, Flags{Lang: "go"})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+
+	expected := "Write a go program that solves the following coding challenge:\n\nFind the floor.\n\nThe program should read input from a file called 'input.txt' and print the output to standard output.\n\nRespond ONLY with the code surrounded by triple backticks and the language name, like this:\n```go\n<YOUR CODE HERE>\n```\nDo not include any explanations or comments outside the code block."
+	if prompt != expected {
+		t.Errorf("Unexpected default prompt.\nExpected:\n%q\nGot:\n%q", expected, prompt)
+	}
+}
+
+func TestBuildPromptInputArg(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	prompt, err := buildPrompt(Challenge{Name: "day1_part1_2015", Task: "Find the floor."}, Flags{Lang: "go", InputArg: true})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+
+	if !strings.Contains(prompt, "\n\nThe program should read input from the file whose path is given as the first command-line argument") {
+		t.Errorf("Expected the prompt to ask for the input path argument, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "input.txt") {
+		t.Errorf("Expected no mention of input.txt, got:\n%s", prompt)
+	}
+}
+
+func TestBuildPromptInputSample(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	challenge := Challenge{Name: "day1_part1_2015", Task: "Find the floor.", Input: "(()\n" + strings.Repeat(")", 250) + "\n((\n"}
+	prompt, err := buildPrompt(challenge, Flags{Lang: "go", Model: "gpt-4o", InputLines: 2})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	want := "Here are the first lines of the actual input file, showing its format:\n```\n(()\n" + strings.Repeat(")", 200) + "... (50 more characters)\n```\n\nThe program should read input"
+	if !strings.Contains(prompt, want) {
+		t.Errorf("Expected the input sample before the instructions, got:\n%s", prompt)
+	}
+
+	// With --input-lines 0 the built-in prompt shows no input
+	if prompt, _ := buildPrompt(challenge, Flags{Lang: "go", Model: "gpt-4o", InputLines: 0}); strings.Contains(prompt, "(()") {
+		t.Errorf("Expected no input sample, got:\n%s", prompt)
+	}
+
+	// A context window the prompt already fills leaves no room for the sample
+	saveConfig(Config{ContextWindows: map[string]int{"ollama/tiny": 100}})
+	prompt, err = buildPrompt(challenge, Flags{Lang: "go", Model: "ollama/tiny", InputLines: 2})
+	if err != nil || strings.Contains(prompt, "(()") {
+		t.Errorf("Expected the sample to be left out, got %v:\n%s", err, prompt)
+	}
+}
+
+func TestPromptInputSample(t *testing.T) {
+	input := "1 2 3\n4 5 6\n7 8 9\n"
+	if got := promptInputSample(input, 10, 1000); got != "1 2 3\n4 5 6\n7 8 9" {
+		t.Errorf("Expected the whole input, got %q", got)
+	}
+	if got := promptInputSample(input, 2, 1000); got != "1 2 3\n4 5 6" {
+		t.Errorf("Expected two lines, got %q", got)
+	}
+	if got := promptInputSample(input, 10, countTokens("1 2 3\n")); got != "1 2 3" {
+		t.Errorf("Expected the sample cut to the budget, got %q", got)
+	}
+}
+
+func TestBuildPromptOverContextWindow(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	long := strings.Repeat("print('floor')\n", 50)
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: long, Task: "Find the floor."},
+		{Name: "day3_part1_2015", SolutionLang: "python", Solution: long, Task: "Count the houses."},
+	})
+	saveConfig(Config{ContextWindows: map[string]int{"ollama/tiny": 400}})
+	flags := Flags{Lang: "python", Model: "ollama/tiny", Examples: 2}
+
+	// Examples go first
+	prompt, err := buildPrompt(Challenge{Name: "day2_part1_2015", Task: "Wrap the presents."}, flags)
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if strings.Contains(prompt, "Example") || !strings.Contains(prompt, "Wrap the presents.") {
+		t.Errorf("Expected the examples to be dropped, got:\n%s", prompt)
+	}
+
+	// Then the flavor text
+	story := strings.Repeat("The elves are running low on wrapping paper. ", 40)
+	task := story + "\n\nHow many square feet of `paper` should they order?"
+	prompt, err = buildPrompt(Challenge{Name: "day2_part1_2015", Task: task}, flags)
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if strings.Contains(prompt, "elves") || !strings.Contains(prompt, "How many square feet") {
+		t.Errorf("Expected the flavor text to be dropped, got:\n%s", prompt)
+	}
+
+	saveConfig(Config{ContextWindows: map[string]int{"ollama/tiny": 400}, ContextStrategies: map[string]string{"ollama/": contextWarn}})
+	prompt, err = buildPrompt(Challenge{Name: "day2_part1_2015", Task: task}, flags)
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Example 2:") || !strings.Contains(prompt, "elves") {
+		t.Errorf("Expected the prompt to be kept whole with the warn strategy")
+	}
+}
+
+func TestBuildPromptCustomTemplate(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	challenge := Challenge{Name: "day3_part2_2023", Task: "Sum the gears.", Input: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"}
+
+	templatesDir := filepath.Join(tempDir, "templates")
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		t.Fatalf("Failed to create templates dir: %v", err)
+	}
+	userTemplate := "{{.Lang}} day {{.Day}} part {{.Part}} of {{.Year}}: {{.Task}}\n{{.Input}}"
+	if err := os.WriteFile(filepath.Join(templatesDir, promptTemplateFile), []byte(userTemplate), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	prompt, err := buildPrompt(challenge, Flags{Lang: "rust", InputLines: defaultInputLines})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if expected := "rust day 3 part 2 of 2023: Sum the gears.\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10"; prompt != expected {
+		t.Errorf("Unexpected prompt from user template.\nExpected:\n%q\nGot:\n%q", expected, prompt)
+	}
+
+	// .Input follows --input-lines
+	prompt, err = buildPrompt(challenge, Flags{Lang: "rust", InputLines: 2})
+	if err != nil || !strings.HasSuffix(prompt, "Sum the gears.\n1\n2") {
+		t.Errorf("Expected two lines of input, got %v: %q", err, prompt)
+	}
+
+	override := filepath.Join(tempDir, "short.tmpl")
+	os.WriteFile(override, []byte("Solve in {{.Lang}}: {{.Task}}"), 0644)
+	prompt, err = buildPrompt(challenge, Flags{Lang: "rust", Template: override})
+	if err != nil {
+		t.Fatalf("Failed to build prompt: %v", err)
+	}
+	if prompt != "Solve in rust: Sum the gears." {
+		t.Errorf("Expected --template to take precedence, got: %q", prompt)
+	}
+
+	os.WriteFile(override, []byte("{{.Missing"), 0644)
+	if _, err := buildPrompt(challenge, Flags{Lang: "rust", Template: override}); err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+}
+
+func TestLoadSystemPrompt(t *testing.T) {
+	if system, err := loadSystemPrompt("Use only the standard library."); err != nil || system != "Use only the standard library." {
+		t.Errorf("Expected the literal system prompt, got %q, %v", system, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "system.txt")
+	os.WriteFile(path, []byte("You write idiomatic Go.\n"), 0644)
+	if system, err := loadSystemPrompt("@" + path); err != nil || system != "You write idiomatic Go." {
+		t.Errorf("Expected the system prompt from the file, got %q, %v", system, err)
+	}
+
+	if _, err := loadSystemPrompt("@" + filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestSystemPromptSentToProviders(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	var messages []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requestBody struct {
+			Messages []map[string]string `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		messages = requestBody.Messages
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```go\npackage main\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		model    string
+		system   string
+		expected string
+	}{
+		{"gpt-4o-mini", "", ""},
+		{"gpt-4o-mini", "No external libraries.", "No external libraries."},
+		{"ollama/llama3", "", ollamaSystemPrompt},
+		{"ollama/llama3", "No external libraries.", "No external libraries."},
+	}
+	for _, tt := range tests {
+		_, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: tt.model, ModelAPI: server.URL, SystemPrompt: tt.system})
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		if tt.expected == "" {
+			if len(messages) != 1 || messages[0]["role"] != "user" {
+				t.Errorf("%s: expected only the user message, got %v", tt.model, messages)
+			}
+			continue
+		}
+		if len(messages) != 2 || messages[0]["role"] != "system" || messages[0]["content"] != tt.expected {
+			t.Errorf("%s: expected the system message %q, got %v", tt.model, tt.expected, messages)
+		}
+	}
+}
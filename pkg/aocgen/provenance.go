@@ -0,0 +1,118 @@
+package aocgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Version is the version of aocgen, set at build time with
+// -ldflags "-X aocgen/pkg/aocgen.Version=v1.2.3".
+var Version = ""
+
+// headerMarker starts every line of a provenance header after the comment
+// delimiter, so the header can be recognized and stripped.
+const headerMarker = "aocgen:"
+
+// commentStyle is how a language comments out a line: Prefix before it and,
+// for languages with only block comments, Suffix after it.
+type commentStyle struct {
+	Prefix string
+	Suffix string
+}
+
+// lineComments are the comment styles of the built-in languages. Languages
+// added in the config file set theirs with "comment".
+var lineComments = map[string]commentStyle{
+	"go": {Prefix: "//"}, "javascript": {Prefix: "//"}, "typescript": {Prefix: "//"},
+	"java": {Prefix: "//"}, "scala": {Prefix: "//"}, "kotlin": {Prefix: "//"}, "groovy": {Prefix: "//"},
+	"csharp": {Prefix: "//"}, "fsharp": {Prefix: "//"}, "swift": {Prefix: "//"}, "objectivec": {Prefix: "//"},
+	"rust": {Prefix: "//"}, "c": {Prefix: "//"}, "cpp": {Prefix: "//"}, "zig": {Prefix: "//"},
+	"dart": {Prefix: "//"}, "d": {Prefix: "//"}, "v": {Prefix: "//"}, "php": {Prefix: "//"}, "pascal": {Prefix: "//"},
+	"python": {Prefix: "#"}, "r": {Prefix: "#"}, "ruby": {Prefix: "#"}, "elixir": {Prefix: "#"},
+	"perl": {Prefix: "#"}, "crystal": {Prefix: "#"}, "julia": {Prefix: "#"}, "bash": {Prefix: "#"},
+	"awk": {Prefix: "#"}, "nim": {Prefix: "#"}, "tcl": {Prefix: "#"}, "coffeescript": {Prefix: "#"},
+	"haskell": {Prefix: "--"}, "lua": {Prefix: "--"},
+	"clojure": {Prefix: ";;"}, "racket": {Prefix: ";;"}, "scheme": {Prefix: ";;"},
+	"erlang": {Prefix: "%"}, "prolog": {Prefix: "%"},
+	"fortran90": {Prefix: "!"},
+	"ocaml":     {Prefix: "(*", Suffix: "*)"},
+}
+
+// headerLine matches a line of a provenance header in any comment style.
+var headerLine = regexp.MustCompile(`^\s*\S+\s+` + headerMarker + ` (generated by|model|time|prompt) .*$`)
+
+// aocgenVersion returns Version, or else the module version Go recorded in
+// the binary, or "dev" for a local build.
+func aocgenVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// promptHash identifies the system prompt and prompt of a generation.
+func promptHash(transcript Transcript) string {
+	sum := sha256.Sum256([]byte(transcript.System + "\x00" + transcript.Prompt))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// addProvenanceHeader prepends a comment to code noting the model, time
+// and prompt of the generation in transcript and the version of aocgen. It
+// goes after a shebang or the opening tag of PHP, which must come first.
+// Languages without a known comment style get no header.
+func addProvenanceHeader(code, lang string, transcript Transcript) string {
+	style, ok := lineComments[strings.ToLower(lang)]
+	if !ok {
+		logger.Warn(fmt.Sprintf("no header for %s, set its \"comment\" in the config file", lang))
+		return code
+	}
+	generated := transcript.Time
+	if generated.IsZero() {
+		generated = time.Now()
+	}
+	var header strings.Builder
+	for _, field := range [][2]string{
+		{"generated by", "aocgen " + aocgenVersion()},
+		{"model", transcript.Model},
+		{"time", generated.UTC().Format(time.RFC3339)},
+		{"prompt", promptHash(transcript)},
+	} {
+		line := fmt.Sprintf("%s %s %s %s", style.Prefix, headerMarker, field[0], field[1])
+		if style.Suffix != "" {
+			line += " " + style.Suffix
+		}
+		header.WriteString(line + "\n")
+	}
+
+	first, rest, _ := strings.Cut(code, "\n")
+	if strings.HasPrefix(first, "#!") || strings.HasPrefix(strings.TrimSpace(first), "<?php") {
+		return first + "\n" + header.String() + rest
+	}
+	return header.String() + code
+}
+
+// stripProvenanceHeader removes the header addProvenanceHeader added to
+// code, if any.
+func stripProvenanceHeader(code string) string {
+	lines := strings.SplitAfter(code, "\n")
+	start := 0
+	if len(lines) > 0 && (strings.HasPrefix(lines[0], "#!") || strings.HasPrefix(strings.TrimSpace(lines[0]), "<?php")) {
+		start = 1
+	}
+	end := start
+	for end < len(lines) && headerLine.MatchString(strings.TrimRight(lines[end], "\r\n")) {
+		end++
+	}
+	if end == start {
+		return code
+	}
+	return strings.Join(lines[:start], "") + strings.Join(lines[end:], "")
+}
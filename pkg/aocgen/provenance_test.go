@@ -0,0 +1,81 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvenanceHeader(t *testing.T) {
+	transcript := Transcript{Model: "gpt-4o-mini", Time: time.Date(2024, 12, 1, 5, 0, 0, 0, time.UTC), System: "system", Prompt: "prompt"}
+	tests := []struct {
+		lang, code, first string
+	}{
+		{"python", "print(1)\n", "# aocgen: generated by aocgen "},
+		{"go", "package main\n", "// aocgen: generated by aocgen "},
+		{"ocaml", "let () = print_int 1\n", "(* aocgen: generated by aocgen "},
+		{"bash", "#!/bin/bash\necho 1\n", "#!/bin/bash\n# aocgen: generated by aocgen "},
+		{"php", "<?php\necho 1;\n", "<?php\n// aocgen: generated by aocgen "},
+	}
+	for _, tt := range tests {
+		code := addProvenanceHeader(tt.code, tt.lang, transcript)
+		if !strings.HasPrefix(code, tt.first) {
+			t.Errorf("Expected the %s header to start with %q, got:\n%s", tt.lang, tt.first, code)
+		}
+		for _, field := range []string{"model gpt-4o-mini", "time 2024-12-01T05:00:00Z", "prompt " + promptHash(transcript)} {
+			if !strings.Contains(code, field) {
+				t.Errorf("Expected %q in the %s header:\n%s", field, tt.lang, code)
+			}
+		}
+		if stripped := stripProvenanceHeader(code); stripped != tt.code {
+			t.Errorf("Expected the %s header to be stripped, got:\n%s", tt.lang, stripped)
+		}
+	}
+
+	// Code without a header is left alone
+	if code := "# aocgen: is a tool\nprint(1)\n"; stripProvenanceHeader(code) != code {
+		t.Errorf("Expected code without a header to be unchanged")
+	}
+	if code := addProvenanceHeader("x", "klingon", transcript); code != "x" {
+		t.Errorf("Expected no header for a language without a comment style, got %q", code)
+	}
+}
+
+func TestGenerateWithHeader(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Greet.", Input: "x"}})
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Model: "test", NoFormat: true, Header: true}
+	if _, err := generateChallengeSolution(context.Background(), flags); err != nil {
+		t.Fatalf("Generation failed: %v", err)
+	}
+	code, _ := os.ReadFile(testSolutionPath(t, "day1_part1_2015", "py"))
+	if !strings.HasPrefix(string(code), "# aocgen: generated by aocgen dev\n# aocgen: model test\n") {
+		t.Errorf("Expected a provenance header, got:\n%s", code)
+	}
+	// The file is still known as generated
+	if _, err := generateChallengeSolution(context.Background(), flags); err != nil {
+		t.Errorf("Expected a solution with a header to be replaced, got %v", err)
+	}
+
+	challenges, _ := loadChallenges(getCacheDir(), challengesFile)
+	challenges[0].Solution = string(code)
+	saveChallenges(challenges)
+	var out bytes.Buffer
+	if err := runExportCommand(Flags{Format: "jsonl"}, &out); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var exported Challenge
+	if err := json.Unmarshal(out.Bytes(), &exported); err != nil || strings.Contains(exported.Solution, "aocgen:") || !strings.Contains(exported.Solution, "Hello, World!") {
+		t.Errorf("Expected the header to be stripped from the export, got %q, %v", exported.Solution, err)
+	}
+}
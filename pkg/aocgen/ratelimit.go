@@ -0,0 +1,144 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProviderLimits caps what is sent to one provider, under "rate_limits" in
+// the config file keyed by provider: openai, ollama, groq, mistral or
+// bedrock. Zero means no limit.
+type ProviderLimits struct {
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int `json:"tokens_per_minute,omitempty"`
+}
+
+// limitSleep waits for a rate limit and limitNow tells the time; both are
+// replaced in tests.
+var (
+	limitSleep = sleepContext
+	limitNow   = time.Now
+)
+
+// tokenBucket holds up to capacity tokens and refills at capacity per minute.
+// Its level may drop below zero when more is used than was reserved, which
+// delays the next reservation accordingly.
+type tokenBucket struct {
+	capacity float64
+	level    float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int) *tokenBucket {
+	return &tokenBucket{capacity: float64(perMinute), level: float64(perMinute), last: limitNow()}
+}
+
+func (b *tokenBucket) refill() {
+	now := limitNow()
+	b.level = min(b.capacity, b.level+now.Sub(b.last).Minutes()*b.capacity)
+	b.last = now
+}
+
+// wait returns how long to wait until n tokens are available. Requests larger
+// than the bucket only wait for a full one.
+func (b *tokenBucket) wait(n float64) time.Duration {
+	b.refill()
+	n = min(n, b.capacity)
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.capacity * float64(time.Minute))
+}
+
+func (b *tokenBucket) take(n float64) {
+	b.refill()
+	b.level -= n
+}
+
+// providerLimiter rate limits the requests to one provider across all
+// concurrent generations.
+type providerLimiter struct {
+	mu       sync.Mutex
+	limits   ProviderLimits
+	requests *tokenBucket
+	tokens   *tokenBucket
+}
+
+var (
+	providerLimitersMu sync.Mutex
+	providerLimiters   = make(map[string]*providerLimiter)
+)
+
+// limiterFor returns the limiter of provider, or nil if the config file sets
+// no limits for it.
+func limiterFor(provider string) *providerLimiter {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	limits := cfg.RateLimits[provider]
+	if limits == (ProviderLimits{}) {
+		return nil
+	}
+
+	providerLimitersMu.Lock()
+	defer providerLimitersMu.Unlock()
+	limiter := providerLimiters[provider]
+	if limiter == nil || limiter.limits != limits {
+		limiter = &providerLimiter{limits: limits}
+		if limits.RequestsPerMinute > 0 {
+			limiter.requests = newTokenBucket(limits.RequestsPerMinute)
+		}
+		if limits.TokensPerMinute > 0 {
+			limiter.tokens = newTokenBucket(limits.TokensPerMinute)
+		}
+		providerLimiters[provider] = limiter
+	}
+	return limiter
+}
+
+// reserve waits until a request of about tokens tokens may be sent and
+// reserves it.
+func (l *providerLimiter) reserve(ctx context.Context, provider string, tokens int) error {
+	for {
+		l.mu.Lock()
+		var wait time.Duration
+		if l.requests != nil {
+			wait = max(wait, l.requests.wait(1))
+		}
+		if l.tokens != nil {
+			wait = max(wait, l.tokens.wait(float64(tokens)))
+		}
+		if wait == 0 {
+			if l.requests != nil {
+				l.requests.take(1)
+			}
+			if l.tokens != nil {
+				l.tokens.take(float64(tokens))
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		logger.Debug(fmt.Sprintf("waiting %v for the %s rate limit", wait.Round(time.Millisecond), provider))
+		if err := limitSleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// settle charges the tokens a request used beyond its reservation, once the
+// response tells how many that were.
+func (l *providerLimiter) settle(reserved int, usage Usage) {
+	if l.tokens == nil {
+		return
+	}
+	if extra := usage.PromptTokens + usage.CompletionTokens - reserved; extra > 0 {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.tokens.take(float64(extra))
+	}
+}
@@ -0,0 +1,124 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLimitClock makes the limiter's waits advance a fake clock instead of
+// sleeping, and returns the total time waited.
+func fakeLimitClock(t *testing.T) *time.Duration {
+	now := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	var waited time.Duration
+	limitNow = func() time.Time { return now }
+	limitSleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		waited += d
+		return nil
+	}
+	t.Cleanup(func() {
+		limitNow, limitSleep = time.Now, sleepContext
+		providerLimiters = make(map[string]*providerLimiter)
+	})
+	return &waited
+}
+
+func TestProviderLimiterRequests(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	waited := fakeLimitClock(t)
+
+	saveConfig(Config{RateLimits: map[string]ProviderLimits{"openai": {RequestsPerMinute: 2}}})
+	if limiterFor("groq") != nil {
+		t.Errorf("Expected no limiter for a provider without limits")
+	}
+	limiter := limiterFor("openai")
+	if limiter == nil || limiterFor("openai") != limiter {
+		t.Fatalf("Expected one shared limiter for openai")
+	}
+
+	// A burst of 2 goes through, after which requests are spaced 30s apart
+	for i := 0; i < 4; i++ {
+		if err := limiter.reserve(context.Background(), "openai", 100); err != nil {
+			t.Fatalf("reserve failed: %v", err)
+		}
+	}
+	if *waited != time.Minute {
+		t.Errorf("Expected to wait a minute for 4 requests at 2/min, waited %v", *waited)
+	}
+
+	saveConfig(Config{RateLimits: map[string]ProviderLimits{"openai": {RequestsPerMinute: 10}}})
+	if limiterFor("openai") == limiter {
+		t.Errorf("Expected a new limiter after the limits changed")
+	}
+}
+
+func TestProviderLimiterTokens(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	waited := fakeLimitClock(t)
+
+	saveConfig(Config{RateLimits: map[string]ProviderLimits{"groq": {TokensPerMinute: 1000}}})
+	limiter := limiterFor("groq")
+	if err := limiter.reserve(context.Background(), "groq", 400); err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	// The response used 1000 tokens more than reserved, so the bucket is 400
+	// in debt and the next 500 tokens take 54s to refill
+	limiter.settle(400, Usage{PromptTokens: 400, CompletionTokens: 1000})
+	if err := limiter.reserve(context.Background(), "groq", 500); err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	if *waited != 54*time.Second {
+		t.Errorf("Expected to wait 54s, waited %v", *waited)
+	}
+
+	// A prompt larger than the limit waits for a full bucket rather than
+	// forever
+	*waited = 0
+	if err := limiter.reserve(context.Background(), "groq", 5000); err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	if *waited != time.Minute {
+		t.Errorf("Expected to wait a minute, waited %v", *waited)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limitSleep = sleepContext
+	if err := limiter.reserve(ctx, "groq", 500); err != context.Canceled {
+		t.Errorf("Expected a cancelled wait to fail, got %v", err)
+	}
+}
+
+func TestCompleteCodeRateLimited(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	waited := fakeLimitClock(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(1)\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	saveConfig(Config{RateLimits: map[string]ProviderLimits{"openai": {RequestsPerMinute: 1}}})
+	for i := 0; i < 3; i++ {
+		if _, err := completeCode(context.Background(), "prompt", Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL}); err != nil {
+			t.Fatalf("completeCode failed: %v", err)
+		}
+	}
+	if requests.Load() != 3 || *waited != 2*time.Minute {
+		t.Errorf("Expected 3 requests a minute apart, got %d after %v", requests.Load(), *waited)
+	}
+}
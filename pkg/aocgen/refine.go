@@ -0,0 +1,252 @@
+package aocgen
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultRefineTemplate is the prompt of each refinement round unless the
+// user provides their own in the templates directory.
+//
+//go:embed templates/refine.tmpl
+var defaultRefineTemplate string
+
+const (
+	refineTemplateFile  = "refine.tmpl"
+	defaultRefineRounds = 3
+	// refineFeedbackLines bounds how much compiler or program output is sent
+	// back to the model
+	refineFeedbackLines = 30
+)
+
+// RefineData is the data available to the refinement prompt template.
+type RefineData struct {
+	// Prompt is the prompt the solution was first generated from
+	Prompt   string
+	Lang     string
+	Code     string
+	Feedback string
+}
+
+// RefineRound is one evaluation in the --json report of `aocgen refine`.
+type RefineRound struct {
+	Round   int     `json:"round"`
+	Verdict Verdict `json:"verdict"`
+	Answer  string  `json:"answer,omitempty"`
+	Case    string  `json:"case,omitempty"`
+	// Hardcoded is set when the program contained the expected answer and
+	// was not evaluated
+	Hardcoded bool `json:"hardcoded,omitempty"`
+}
+
+// RefineReport is the --json form of `aocgen refine`.
+type RefineReport struct {
+	Challenge string        `json:"challenge"`
+	Lang      string        `json:"lang"`
+	Model     string        `json:"model"`
+	Solved    bool          `json:"solved"`
+	Rounds    []RefineRound `json:"rounds"`
+}
+
+// runRefineCommand evaluates the solution of a challenge and, while it is not
+// correct, asks the model to fix it for up to --rounds rounds. The model only
+// learns what went wrong, e.g. "your program output X but the expected answer
+// is Y". Once the answer has been revealed, a program containing it is
+// rejected without running it, so the model cannot just print it.
+func runRefineCommand(flags Flags, w io.Writer) error {
+	if flags.Lang == "" || flags.Model == "" {
+		return fmt.Errorf("--lang and --model are required")
+	}
+	rounds := flags.Rounds
+
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(challenge.Answer) == "" {
+		return fmt.Errorf("the answer of %s is unknown, run 'aocgen answers sync' or submit it first", challenge.Name)
+	}
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(challengeDir(flags), solutionFileName(challenge.Name, ext))
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		fmt.Fprintf(w, "No solution yet, generating one with %s...\n", flags.Model)
+		if _, err := generateChallengeSolution(commandContext, flags); err != nil {
+			return err
+		}
+	}
+
+	dir, err := openChallengeDir(flags, false)
+	if err != nil {
+		return err
+	}
+	if !flags.InputArg {
+		if err := writeInputFile(dir, challenge); err != nil {
+			return fmt.Errorf("error creating input file: %v", err)
+		}
+	}
+
+	prompt, err := buildPrompt(challenge, flags)
+	if err != nil {
+		return err
+	}
+
+	report := RefineReport{Challenge: challenge.Name, Lang: flags.Lang, Model: flags.Model, Rounds: []RefineRound{}}
+	revealed := false
+	var feedback string
+	for round := 0; ; round++ {
+		code, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("error reading solution: %v", err)
+		}
+
+		entry := RefineRound{Round: round}
+		if revealed && containsAnswer(string(code), challenge.Answer) {
+			entry.Hardcoded = true
+			entry.Verdict = VerdictWrongAnswer
+			feedback = "Your program contains the expected answer as a literal. It must compute the answer from the input instead."
+			fmt.Fprintf(w, "Round %d: rejected, the program contains the expected answer\n", round)
+		} else {
+			_, result, err := evaluateChallengeSolution(commandContext, flags, nil)
+			if err != nil {
+				return err
+			}
+			entry.Verdict, entry.Answer, entry.Case = result.Verdict, result.Answer, result.Case
+			fmt.Fprintf(w, "Round %d: %s\n", round, describeRefineResult(result))
+			if result.Verdict == VerdictCorrect {
+				report.Solved = true
+				report.Rounds = append(report.Rounds, entry)
+				break
+			}
+			feedback = refineFeedback(result, flags)
+			if result.Verdict == VerdictWrongAnswer && result.Case == "" {
+				revealed = true
+			}
+		}
+		report.Rounds = append(report.Rounds, entry)
+
+		if round == rounds {
+			break
+		}
+		// The fix replaces the solution, so a version edited by hand is kept
+		// next to it
+		if checkOverwrite(filename, challenge, flags) != nil {
+			if err := os.WriteFile(filename+".orig", code, 0644); err != nil {
+				return fmt.Errorf("error keeping the original solution: %v", err)
+			}
+			logger.Info(fmt.Sprintf("Kept your version of the solution as %s.orig", filename))
+		}
+		fmt.Fprintf(w, "Asking %s to fix the solution (round %d of %d)...\n", flags.Model, round+1, rounds)
+		refinePrompt, err := buildRefinePrompt(RefineData{Prompt: prompt, Lang: flags.Lang, Code: strings.TrimSpace(string(code)), Feedback: feedback})
+		if err != nil {
+			return err
+		}
+		transcript, err := completeCode(commandContext, refinePrompt, flags)
+		if err != nil {
+			return fmt.Errorf("error generating code with AI: %v", err)
+		}
+		if err := saveSolutionCode(filename, challenge, flags, transcript); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case report.Solved && len(report.Rounds) == 1:
+		fmt.Fprintf(w, "The solution of %s is already correct.\n", challenge.Name)
+	case report.Solved:
+		fmt.Fprintf(w, "Solved %s after %d round(s) of refinement.\n", challenge.Name, len(report.Rounds)-1)
+	default:
+		fmt.Fprintf(w, "%s is still not solved after %d round(s) of refinement.\n", challenge.Name, rounds)
+	}
+	if flags.JSON {
+		if err := emitJSON(report); err != nil {
+			return err
+		}
+	}
+	return verdictStatus(report.Rounds[len(report.Rounds)-1].Verdict)
+}
+
+// describeRefineResult summarizes a verdict for the progress of refine.
+func describeRefineResult(result EvalResult) string {
+	switch result.Verdict {
+	case VerdictWrongAnswer:
+		where := ""
+		if result.Case != "" {
+			where = " on " + strings.ToLower(result.Case)
+		}
+		return fmt.Sprintf("wrong answer%s (got %q)", where, result.Answer)
+	case VerdictTimeout:
+		return fmt.Sprintf("timeout after %v", result.Duration.Round(time.Millisecond))
+	default:
+		return string(result.Verdict)
+	}
+}
+
+// refineFeedback tells the model what went wrong with its program.
+func refineFeedback(result EvalResult, flags Flags) string {
+	output := tailLines(result.Output, refineFeedbackLines)
+	switch result.Verdict {
+	case VerdictWrongAnswer:
+		got := "nothing"
+		if result.Answer != "" {
+			got = result.Answer
+		}
+		if result.Case != "" {
+			return fmt.Sprintf("On %s from the task, your program output %s but the expected answer is %s.", strings.ToLower(result.Case), got, result.Expected)
+		}
+		return fmt.Sprintf("Your program output %s but the expected answer is %s.", got, result.Expected)
+	case VerdictCompileError:
+		return fmt.Sprintf("Your program failed to compile:\n%s", output)
+	case VerdictRuntimeError:
+		return fmt.Sprintf("Your program failed at runtime (%v). Its output ended with:\n%s", result.Err, output)
+	case VerdictTimeout:
+		return fmt.Sprintf("Your program did not finish within %v. Make it faster.", resolveLimits(flags.Lang, flags, Config{}).Timeout)
+	}
+	return ""
+}
+
+// tailLines returns at most the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// containsAnswer reports whether code contains answer as a separate token.
+func containsAnswer(code, answer string) bool {
+	answer = strings.TrimSpace(answer)
+	re := regexp.MustCompile(`(^|[^0-9A-Za-z_])` + regexp.QuoteMeta(answer) + `($|[^0-9A-Za-z_])`)
+	return re.MatchString(code)
+}
+
+// buildRefinePrompt renders the refinement prompt from the user's
+// templates/refine.tmpl in the cache dir or the built-in template.
+func buildRefinePrompt(data RefineData) (string, error) {
+	text := defaultRefineTemplate
+	if custom, err := os.ReadFile(filepath.Join(getCacheDir(), "templates", refineTemplateFile)); err == nil {
+		text = string(custom)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("error reading refine template: %v", err)
+	}
+
+	tmpl, err := template.New("refine").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing refine template: %v", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error rendering refine template: %v", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
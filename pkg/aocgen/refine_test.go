@@ -0,0 +1,102 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunRefineCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Sum the numbers.", Input: "40\n2\n", Answer: "42"}})
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print(41)\n"), 0644)
+
+	responses := []string{
+		"print(42)",
+		"print(sum(int(l) for l in open('input.txt')))",
+	}
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requestBody struct {
+			Messages []map[string]string `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		prompts = append(prompts, requestBody.Messages[len(requestBody.Messages)-1]["content"])
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\n" + responses[len(prompts)-1] + "\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL, Rounds: 3, NoFormat: true, Timeout: 5000}
+	var report RefineReport
+	out := captureJSON(t, func() error {
+		flags.JSON = true
+		return runRefineCommand(flags, io.Discard)
+	})
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+
+	if !report.Solved || len(report.Rounds) != 3 {
+		t.Fatalf("Expected the solution to be fixed in the second round, got %+v", report)
+	}
+	if report.Rounds[0].Verdict != VerdictWrongAnswer || report.Rounds[0].Answer != "41" || !report.Rounds[1].Hardcoded || report.Rounds[2].Verdict != VerdictCorrect {
+		t.Errorf("Unexpected rounds: %+v", report.Rounds)
+	}
+
+	if len(prompts) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(prompts))
+	}
+	for _, expected := range []string{"Sum the numbers.", "```python\nprint(41)\n```", "Your program output 41 but the expected answer is 42."} {
+		if !strings.Contains(prompts[0], expected) {
+			t.Errorf("Expected the first refine prompt to contain %q, got:\n%s", expected, prompts[0])
+		}
+	}
+	if !strings.Contains(prompts[1], "contains the expected answer as a literal") {
+		t.Errorf("Expected the second prompt to reject the hardcoded answer, got:\n%s", prompts[1])
+	}
+
+	attempts, _ := loadAttempts()
+	if len(attempts) != 2 {
+		t.Errorf("Expected both fixes to be recorded as attempts, got %d", len(attempts))
+	}
+	if orig, err := os.ReadFile(testSolutionPath(t, "day1_part1_2015", "py.orig")); err != nil || string(orig) != "print(41)\n" {
+		t.Errorf("Expected the hand-written solution to be kept, got %q, %v", orig, err)
+	}
+}
+
+func TestContainsAnswer(t *testing.T) {
+	tests := map[string]bool{
+		"print(42)":          true,
+		"x = 420":            false,
+		"print('abc')":       true,
+		"total += 142":       false,
+		"answer = 42\nprint": true,
+	}
+	for code, expected := range tests {
+		answer := "42"
+		if strings.Contains(code, "abc") {
+			answer = "abc"
+		}
+		if got := containsAnswer(code, answer); got != expected {
+			t.Errorf("containsAnswer(%q, %q) = %v, expected %v", code, answer, got, expected)
+		}
+	}
+}
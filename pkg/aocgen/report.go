@@ -0,0 +1,329 @@
+package aocgen
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportTemplate renders the HTML form of `aocgen benchmark report`.
+//
+//go:embed templates/report.html.tmpl
+var reportTemplate string
+
+// reportBarWidth is the width of the bars in Markdown reports, in characters.
+const reportBarWidth = 20
+
+// runtimeBuckets are the upper bounds of the runtime distribution of a report.
+var runtimeBuckets = []struct {
+	label string
+	limit time.Duration
+}{
+	{"< 10ms", 10 * time.Millisecond},
+	{"10-100ms", 100 * time.Millisecond},
+	{"100ms-1s", time.Second},
+	{"1-10s", 10 * time.Second},
+	{"≥ 10s", 0},
+}
+
+// ReportGroup is the pass rate of the challenges of one year or day.
+type ReportGroup struct {
+	Label    string  `json:"label"`
+	Total    int     `json:"total"`
+	Passed   int     `json:"passed"`
+	PassRate float64 `json:"pass_rate"`
+	// MedianMs is the median runtime of the passed challenges
+	MedianMs int64 `json:"median_ms"`
+}
+
+// ReportBucket is a bar of the runtime distribution of a report.
+type ReportBucket struct {
+	Label string  `json:"label"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// ReportChallenge is a challenge of a run with the code that was benchmarked.
+type ReportChallenge struct {
+	Challenge  string `json:"challenge"`
+	Outcome    string `json:"outcome"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	Code       string `json:"code,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+// RunReport is the data of `aocgen benchmark report`, and its JSON form.
+type RunReport struct {
+	ID         string            `json:"id"`
+	Lang       string            `json:"lang"`
+	StartedAt  time.Time         `json:"started_at"`
+	TimeoutMs  int64             `json:"timeout_ms"`
+	Challenges int               `json:"challenges"`
+	Passed     int               `json:"passed"`
+	Timeouts   int               `json:"timeouts"`
+	Errors     int               `json:"errors"`
+	PassRate   float64           `json:"pass_rate"`
+	Failures   []FailureCount    `json:"failures"`
+	Years      []ReportGroup     `json:"years"`
+	Days       []ReportGroup     `json:"days"`
+	Runtimes   []ReportBucket    `json:"runtimes"`
+	Results    []ReportChallenge `json:"results"`
+}
+
+// reportGroupBuilder collects the results of a ReportGroup.
+type reportGroupBuilder struct {
+	group     ReportGroup
+	durations []time.Duration
+}
+
+func (b *reportGroupBuilder) add(outcome string, duration time.Duration) {
+	b.group.Total++
+	if outcome == outcomePass {
+		b.group.Passed++
+		b.durations = append(b.durations, duration)
+	}
+}
+
+func (b *reportGroupBuilder) build() ReportGroup {
+	if b.group.Total > 0 {
+		b.group.PassRate = float64(b.group.Passed) / float64(b.group.Total)
+	}
+	if len(b.durations) > 0 {
+		sort.Slice(b.durations, func(i, j int) bool { return b.durations[i] < b.durations[j] })
+		b.group.MedianMs = b.durations[len(b.durations)/2].Milliseconds()
+	}
+	return b.group
+}
+
+// solutionCode returns the code of a challenge's solution: the file the run
+// evaluated, or else the stored solution.
+func solutionCode(run *BenchmarkRun, challenge, ext string, stored map[string]string) string {
+	if code, err := os.ReadFile(run.solutionPath(challenge, ext)); err == nil {
+		return string(code)
+	}
+	return stored[challenge]
+}
+
+// buildRunReport summarizes a perf run per year, day and runtime.
+func buildRunReport(run *BenchmarkRun) (RunReport, error) {
+	report := RunReport{
+		ID:         run.ID,
+		Lang:       run.Lang,
+		StartedAt:  run.StartedAt,
+		TimeoutMs:  run.TimeoutMs,
+		Challenges: len(run.Results),
+		Years:      []ReportGroup{},
+		Days:       []ReportGroup{},
+		Runtimes:   []ReportBucket{},
+		Results:    []ReportChallenge{},
+	}
+
+	ext, err := getFileExtension(strings.ToLower(run.Lang))
+	if err != nil {
+		return report, err
+	}
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return report, fmt.Errorf("error loading challenges: %v", err)
+	}
+	stored := make(map[string]string)
+	for _, c := range challenges {
+		if strings.EqualFold(c.SolutionLang, run.Lang) {
+			stored[c.Name] = c.Solution
+		}
+	}
+
+	years := make(map[int]*reportGroupBuilder)
+	days := make(map[int]*reportGroupBuilder)
+	buckets := make([]int, len(runtimeBuckets))
+	for _, result := range run.Results {
+		outcome := runOutcome(run, result)
+		switch outcome {
+		case outcomeUnchecked:
+			report.Challenges--
+		case outcomePass:
+			report.Passed++
+			for i, bucket := range runtimeBuckets {
+				if bucket.limit == 0 || result.Duration < bucket.limit {
+					buckets[i]++
+					break
+				}
+			}
+		case outcomeTimeout:
+			report.Timeouts++
+		default:
+			report.Errors++
+		}
+
+		if day, _, year, ok := parseChallengeName(result.Challenge); ok && outcome != outcomeUnchecked {
+			if years[year] == nil {
+				years[year] = &reportGroupBuilder{group: ReportGroup{Label: fmt.Sprint(year)}}
+			}
+			if days[day] == nil {
+				days[day] = &reportGroupBuilder{group: ReportGroup{Label: fmt.Sprint(day)}}
+			}
+			years[year].add(outcome, result.Duration)
+			days[day].add(outcome, result.Duration)
+		}
+
+		report.Results = append(report.Results, ReportChallenge{
+			Challenge:  result.Challenge,
+			Outcome:    outcome,
+			DurationMs: result.Duration.Milliseconds(),
+			Error:      result.Error,
+			Code:       solutionCode(run, result.Challenge, ext, stored),
+			Output:     result.Output,
+		})
+	}
+	if report.Challenges > 0 {
+		report.PassRate = float64(report.Passed) / float64(report.Challenges)
+	}
+	report.Failures = countFailures(run)
+
+	for _, groups := range []struct {
+		builders map[int]*reportGroupBuilder
+		out      *[]ReportGroup
+	}{{years, &report.Years}, {days, &report.Days}} {
+		keys := make([]int, 0, len(groups.builders))
+		for key := range groups.builders {
+			keys = append(keys, key)
+		}
+		sort.Ints(keys)
+		for _, key := range keys {
+			*groups.out = append(*groups.out, groups.builders[key].build())
+		}
+	}
+	for i, bucket := range runtimeBuckets {
+		entry := ReportBucket{Label: bucket.label, Count: buckets[i]}
+		if report.Passed > 0 {
+			entry.Share = float64(buckets[i]) / float64(report.Passed)
+		}
+		report.Runtimes = append(report.Runtimes, entry)
+	}
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return challengeLess(report.Results[i].Challenge, report.Results[j].Challenge)
+	})
+	return report, nil
+}
+
+// runBenchmarkReportCommand writes a standalone HTML or Markdown report of a
+// perf run.
+func runBenchmarkReportCommand(id string, flags Flags, w io.Writer) error {
+	run, err := loadBenchmarkRun(id)
+	if err != nil {
+		return err
+	}
+	report, err := buildRunReport(run)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flags.JSON:
+		return emitJSON(report)
+	case flags.Format == "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	case flags.Format == "markdown":
+		writeMarkdownReport(w, report)
+		return nil
+	case flags.Format == "" || flags.Format == "table" || flags.Format == "html":
+		return writeHTMLReport(w, report)
+	default:
+		return fmt.Errorf("unsupported format: %s", flags.Format)
+	}
+}
+
+func formatPercent(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
+
+func formatReportMs(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%dms", ms)
+}
+
+func writeHTMLReport(w io.Writer, report RunReport) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"percent": formatPercent,
+		"ms":      formatReportMs,
+		"width":   func(share float64) string { return fmt.Sprintf("%.1f", share*100) },
+	}).Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("error parsing report template: %v", err)
+	}
+	return tmpl.Execute(w, report)
+}
+
+// reportBar draws share as a bar of block characters.
+func reportBar(share float64) string {
+	return strings.Repeat("█", int(share*reportBarWidth+0.5))
+}
+
+func writeMarkdownReport(w io.Writer, r RunReport) {
+	fmt.Fprintf(w, "# aocgen run %s\n\n", r.ID)
+	fmt.Fprintf(w, "%s, started %s", r.Lang, r.StartedAt.Format("2006-01-02 15:04"))
+	if r.TimeoutMs > 0 {
+		fmt.Fprintf(w, ", timeout %dms", r.TimeoutMs)
+	}
+	fmt.Fprintf(w, ".\n\n**%d/%d passed (%s)**, %d timed out, %d failed.\n", r.Passed, r.Challenges, formatPercent(r.PassRate), r.Timeouts, r.Errors)
+
+	if len(r.Failures) > 0 {
+		fmt.Fprintln(w, "\n## Failures")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Failure | Challenges |")
+		fmt.Fprintln(w, "| --- | ---: |")
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "| %s | %d |\n", f.Class, f.Count)
+		}
+	}
+
+	for _, section := range []struct {
+		title, label string
+		groups       []ReportGroup
+	}{{"Pass rate per year", "Year", r.Years}, {"Difficulty per day", "Day", r.Days}} {
+		fmt.Fprintf(w, "\n## %s\n\n", section.title)
+		fmt.Fprintf(w, "| %s | Passed | Pass rate | | Median runtime |\n", section.label)
+		fmt.Fprintln(w, "| --- | ---: | ---: | --- | ---: |")
+		for _, g := range section.groups {
+			fmt.Fprintf(w, "| %s | %d/%d | %s | %s | %s |\n", g.Label, g.Passed, g.Total, formatPercent(g.PassRate), reportBar(g.PassRate), formatReportMs(g.MedianMs))
+		}
+	}
+
+	fmt.Fprintln(w, "\n## Runtime distribution")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Runtime | Solutions | |")
+	fmt.Fprintln(w, "| --- | ---: | --- |")
+	for _, b := range r.Runtimes {
+		fmt.Fprintf(w, "| %s | %d | %s |\n", b.Label, b.Count, reportBar(b.Share))
+	}
+
+	fmt.Fprintln(w, "\n## Challenges")
+	fence := strings.ToLower(r.Lang)
+	for _, c := range r.Results {
+		fmt.Fprintf(w, "\n<details>\n<summary><code>%s</code> %s %dms</summary>\n\n", c.Challenge, c.Outcome, c.DurationMs)
+		if c.Error != "" {
+			fmt.Fprintf(w, "Error: %s\n\n", c.Error)
+		}
+		if c.Code != "" {
+			fmt.Fprintf(w, "```%s\n%s\n```\n\n", fence, strings.TrimRight(c.Code, "\n"))
+		}
+		if c.Output != "" {
+			fmt.Fprintf(w, "Output:\n\n```\n%s\n```\n\n", strings.TrimRight(c.Output, "\n"))
+		}
+		fmt.Fprintln(w, "</details>")
+	}
+}
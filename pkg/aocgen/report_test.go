@@ -0,0 +1,68 @@
+package aocgen
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunBenchmarkReportCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	challenges := []Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: "print(1)\n"},
+		{Name: "day2_part1_2015", SolutionLang: "python", Solution: "print('<2>')\n"},
+	}
+	if err := saveChallenges(challenges); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+	saveTestRun(t, "run1", 1000,
+		RunResult{Challenge: "day1_part1_2015", Duration: 5 * time.Millisecond, Output: "1\n"},
+		RunResult{Challenge: "day2_part1_2015", Duration: 300 * time.Millisecond},
+		RunResult{Challenge: "day1_part1_2016", Duration: time.Second},
+		RunResult{Challenge: "day1_part2_2016", Error: "error running command: exit status 1", Output: "Traceback\n"},
+	)
+
+	var out bytes.Buffer
+	if err := runBenchmarkReportCommand("run1", Flags{Format: "markdown"}, &out); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	for _, want := range []string{
+		"**2/4 passed (50.0%)**, 1 timed out, 1 failed.",
+		"| 2015 | 2/2 | 100.0% | ████████████████████ | 300ms |",
+		"| 1 | 1/3 | 33.3% | ███████ | 5ms |",
+		"| < 10ms | 1 | ██████████ |",
+		"<summary><code>day1_part1_2015</code> pass 5ms</summary>\n\n```python\nprint(1)\n```\n\nOutput:\n\n```\n1\n```",
+		"Error: error running command: exit status 1",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Markdown report missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Index(out.String(), "day2_part1_2015</code>") > strings.Index(out.String(), "day1_part1_2016</code>") {
+		t.Errorf("Expected challenges in year and day order:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runBenchmarkReportCommand("run1", Flags{}, &out); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "<strong>2/4 passed (50.0%)</strong>", `style="width: 100.0%"`, "print(&#39;&lt;2&gt;&#39;)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("HTML report missing %q", want)
+		}
+	}
+
+	// The solution file in the working directory is what perf benchmarked
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print(2 - 1)\n"), 0644)
+	report, err := buildRunReport(&BenchmarkRun{ID: "run1", Lang: "python", Results: []RunResult{{Challenge: "day1_part1_2015"}}})
+	if err != nil {
+		t.Fatalf("buildRunReport failed: %v", err)
+	}
+	if report.Results[0].Code != "print(2 - 1)\n" {
+		t.Errorf("Expected the code of the working directory, got %q", report.Results[0].Code)
+	}
+}
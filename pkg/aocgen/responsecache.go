@@ -0,0 +1,62 @@
+package aocgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const responsesDir = "responses"
+
+// cachedResponse is a model response kept by --cache-responses.
+type cachedResponse struct {
+	Model    string    `json:"model"`
+	Time     time.Time `json:"time"`
+	Response string    `json:"response"`
+	// Usage is what the original request cost
+	Usage Usage `json:"usage"`
+}
+
+// responseCacheKey identifies a request to a model: the model, its endpoint,
+// the system prompt, the prompt and the sampling parameters.
+func responseCacheKey(flags Flags, system, prompt string) string {
+	sampling, _ := json.Marshal(flags.Sampling)
+	h := sha256.New()
+	for _, part := range []string{flags.Model, flags.ModelAPI, system, prompt, string(sampling)} {
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func responseCachePath(key string) string {
+	return filepath.Join(getCacheDir(), responsesDir, key+".json")
+}
+
+// loadCachedResponse returns the cached response for key. Unreadable entries
+// count as missing, so the request is just sent again.
+func loadCachedResponse(key string) (cachedResponse, bool) {
+	var cached cachedResponse
+	data, err := os.ReadFile(responseCachePath(key))
+	if err != nil {
+		return cached, false
+	}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return cached, false
+	}
+	return cached, true
+}
+
+func saveCachedResponse(key string, cached cachedResponse) error {
+	if err := os.MkdirAll(filepath.Join(getCacheDir(), responsesDir), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(responseCachePath(key), data, 0644)
+}
@@ -0,0 +1,59 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheResponses(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(42)\n```"}},
+			},
+			"usage": map[string]int{"prompt_tokens": 10, "completion_tokens": 4},
+		})
+	}))
+	defer server.Close()
+
+	flags := Flags{Model: "gpt-4o-mini", ModelAPI: server.URL, Lang: "python", CacheResponses: true}
+	first, err := completeCode(context.Background(), "Solve it.", flags)
+	if err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	second, err := completeCode(context.Background(), "Solve it.", flags)
+	if err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second request to be answered from the cache, got %d requests", requests)
+	}
+	if first.Cached || !second.Cached || second.Code != "print(42)" || second.Usage.CompletionTokens != 4 {
+		t.Errorf("Unexpected transcripts: %+v, %+v", first, second)
+	}
+
+	temperature := 0.5
+	changed := flags
+	changed.Sampling.Temperature = &temperature
+	if _, err := completeCode(context.Background(), "Solve it.", changed); err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	if _, err := completeCode(context.Background(), "Solve it differently.", flags); err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	flags.CacheResponses = false
+	if _, err := completeCode(context.Background(), "Solve it.", flags); err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("Expected other parameters, another prompt and a disabled cache to reach the API, got %d requests", requests)
+	}
+}
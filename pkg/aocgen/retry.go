@@ -0,0 +1,174 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryAttempts    = 4
+	defaultRetryBaseDelayMs = 1000
+	defaultRetryMaxDelayMs  = 30000
+)
+
+// RetryPolicy sets how model API requests that fail with a transient error
+// are retried, under "retry" in the config file. Zero fields keep their
+// defaults: 4 attempts, with delays doubling from 1s up to 30s.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent, including the
+	// first; 1 turns retries off
+	MaxAttempts int   `json:"max_attempts,omitempty"`
+	BaseDelayMs int64 `json:"base_delay_ms,omitempty"`
+	MaxDelayMs  int64 `json:"max_delay_ms,omitempty"`
+}
+
+// retrySleep waits between attempts and is replaced in tests.
+var retrySleep = sleepContext
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryPolicy returns the retry policy of a model request: the one of its
+// --profile or config file, with defaults filled in.
+func retryPolicy(ctx context.Context) RetryPolicy {
+	policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy)
+	if !ok {
+		if cfg, err := loadConfig(); err == nil {
+			policy = cfg.Retry
+		}
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultRetryAttempts
+	}
+	if policy.BaseDelayMs <= 0 {
+		policy.BaseDelayMs = defaultRetryBaseDelayMs
+	}
+	if policy.MaxDelayMs <= 0 {
+		policy.MaxDelayMs = defaultRetryMaxDelayMs
+	}
+	return policy
+}
+
+// backoff returns the delay before the retry after attempt: the base delay
+// doubled for every earlier attempt, capped at the maximum, of which a random
+// half is taken off so that parallel requests do not retry in lockstep.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := time.Duration(p.BaseDelayMs) * time.Millisecond
+	limit := time.Duration(p.MaxDelayMs) * time.Millisecond
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	delay = min(delay, limit)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isRetryableStatus reports whether a provider response with status code is
+// worth retrying: rate limits, timeouts and server errors, including the
+// "overloaded" 529 of Anthropic's API. Other errors, such as a bad request
+// or an invalid key, fail the same way every time.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns how long the provider asked to wait in the
+// Retry-After header, in seconds or as a date, or in the retry-after-ms
+// header OpenAI and Anthropic send.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// retryProviderRequests resends requests that failed with a network error or
+// a retryable status, following the retry policy and the provider's
+// Retry-After. Every attempt is recorded in the transcript, which also counts
+// the retries.
+func retryProviderRequests(next ProviderHandler) ProviderHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		// The body is sent again on every attempt
+		if req.Body != nil && req.GetBody == nil {
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		}
+
+		ctx := req.Context()
+		policy := retryPolicy(ctx)
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+
+			var reason string
+			var wait time.Duration
+			switch {
+			case err != nil && ctx.Err() == nil:
+				reason = err.Error()
+			case err == nil && isRetryableStatus(resp.StatusCode):
+				reason = resp.Status
+				wait, _ = retryAfter(resp.Header)
+			}
+			if reason == "" {
+				return resp, err
+			}
+			if attempt >= policy.MaxAttempts {
+				if policy.MaxAttempts > 1 {
+					logger.Warn(fmt.Sprintf("model API request failed after %d attempts", attempt), "err", reason)
+				}
+				return resp, err
+			}
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+
+			if wait == 0 {
+				wait = policy.backoff(attempt)
+			}
+			logger.Warn(fmt.Sprintf("model API request failed, retrying in %v (attempt %d of %d)", wait.Round(time.Millisecond), attempt+1, policy.MaxAttempts), "err", reason)
+			if recorder, ok := ctx.Value(exchangeRecorderKey{}).(*exchangeRecorder); ok {
+				recorder.retried()
+			}
+			if err := retrySleep(ctx, wait); err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(ctx)
+			if req.GetBody != nil {
+				if req.Body, err = req.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryProviderRequests(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	var waits []time.Duration
+	retrySleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	var bodies []string
+	statuses := []int{http.StatusTooManyRequests, 529, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		status := statuses[len(bodies)-1]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "7")
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"message":"slow down"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```python\nprint(1)\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	transcript, err := completeCode(context.Background(), "prompt", Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL})
+	if err != nil {
+		t.Fatalf("Expected the request to succeed after retries: %v", err)
+	}
+	if transcript.Code != "print(1)" || transcript.Retries != 2 || len(transcript.Exchanges) != 3 {
+		t.Errorf("Unexpected transcript: retries %d, %d exchanges, code %q", transcript.Retries, len(transcript.Exchanges), transcript.Code)
+	}
+	if transcript.Exchanges[0].Status != "429 Too Many Requests" {
+		t.Errorf("Expected the rate limited attempt in the transcript, got %q", transcript.Exchanges[0].Status)
+	}
+	if len(bodies) != 3 || bodies[2] != bodies[0] || !strings.Contains(bodies[2], "prompt") {
+		t.Errorf("Expected the same body on every attempt, got %q", bodies)
+	}
+	// Retry-After is honored; without it the backoff is 1-2s after the
+	// second attempt
+	if len(waits) != 2 || waits[0] != 7*time.Second || waits[1] < time.Second || waits[1] > 2*time.Second {
+		t.Errorf("Unexpected waits: %v", waits)
+	}
+
+	// Client errors are not retried, and max_attempts bounds the retries
+	bodies, waits = nil, nil
+	statuses = []int{http.StatusUnauthorized}
+	if _, err := completeCode(context.Background(), "prompt", Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL}); err == nil {
+		t.Errorf("Expected an error for 401")
+	}
+	if len(bodies) != 1 {
+		t.Errorf("Expected a 401 not to be retried, got %d requests", len(bodies))
+	}
+
+	saveConfig(Config{Retry: RetryPolicy{MaxAttempts: 2}})
+	bodies = nil
+	statuses = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+	if _, err := completeCode(context.Background(), "prompt", Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL}); err == nil || !strings.Contains(err.Error(), "slow down") {
+		t.Errorf("Expected the 503 after the last attempt, got %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Errorf("Expected 2 attempts, got %d", len(bodies))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	if _, ok := retryAfter(header); ok {
+		t.Errorf("Expected no delay without headers")
+	}
+	header.Set("Retry-After", "2")
+	if d, _ := retryAfter(header); d != 2*time.Second {
+		t.Errorf("Expected 2s, got %v", d)
+	}
+	header.Set("Retry-After-Ms", "150")
+	if d, _ := retryAfter(header); d != 150*time.Millisecond {
+		t.Errorf("Expected retry-after-ms to take precedence, got %v", d)
+	}
+	header = http.Header{}
+	header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if d, ok := retryAfter(header); !ok || d != 0 {
+		t.Errorf("Expected a past date to mean no wait, got %v %v", d, ok)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelayMs: 100, MaxDelayMs: 1000}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := policy.backoff(attempt); d < want/2 || d > want {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, d, want/2, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+func runRunCommand(flags Flags) error {
+	if flags.Lang == "" {
+		return fmt.Errorf("language is required")
+	}
+
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return err
+	}
+
+	ext, err := getFileExtension(flags.Lang)
+	if err != nil {
+		return fmt.Errorf("error getting file extension: %v", err)
+	}
+
+	dir, err := openChallengeDir(flags, false)
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, solutionFileName(challenge.Name, ext))
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return fmt.Errorf("solution file not found: %s", filename)
+	}
+
+	if err := writeInputFile(dir, challenge); err != nil {
+		return fmt.Errorf("error creating input file: %v", err)
+	}
+
+	var args []string
+	if flags.InputArg {
+		args = append(args, "input.txt")
+	}
+
+	duration, err := runSolution(commandContext, filename, flags.Lang, time.Duration(flags.Timeout)*time.Millisecond, os.Stdout, os.Stderr, args...)
+	fmt.Fprintf(os.Stderr, "\n%s finished in %v\n", filename, duration.Round(time.Millisecond))
+	return err
+}
+
+// runSolution executes a solution file with args in its directory, streaming
+// its output to stdout and stderr as it runs, and returns the wall-clock time
+// it took. A zero timeout means no limit. Cancelling ctx kills the solution.
+func runSolution(ctx context.Context, filename, lang string, timeout time.Duration, stdout, stderr io.Writer, args ...string) (time.Duration, error) {
+	cmd, cleanup, err := getCommand(lang, filename)
+	defer cleanup()
+	if err != nil {
+		return 0, err
+	}
+
+	parent := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd = exec.CommandContext(ctx, cmd.Path, append(cmd.Args[1:], args...)...)
+	cmd.Dir = filepath.Dir(filename)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	killGroupOnCancel(cmd)
+
+	start := time.Now()
+	err = runCommandTree(cmd)
+	duration := time.Since(start)
+
+	if parent.Err() != nil {
+		return duration, errInterrupted
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return duration, fmt.Errorf("process killed as timeout reached")
+	}
+	if err != nil {
+		return duration, fmt.Errorf("process finished with error: %v", err)
+	}
+	return duration, nil
+}
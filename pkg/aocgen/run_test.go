@@ -0,0 +1,57 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunSolution(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	filename := filepath.Join(tmpDir, "solution.py")
+	code := "import sys\nprint('to stdout')\nprint('to stderr', file=sys.stderr)\n"
+	if err := os.WriteFile(filename, []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write solution file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	duration, err := runSolution(context.Background(), filename, "python", 5*time.Second, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("Failed to run solution: %v", err)
+	}
+
+	if stdout.String() != "to stdout\n" {
+		t.Errorf("Unexpected stdout: %q", stdout.String())
+	}
+	if stderr.String() != "to stderr\n" {
+		t.Errorf("Unexpected stderr: %q", stderr.String())
+	}
+	if duration <= 0 {
+		t.Errorf("Expected a positive duration, got %v", duration)
+	}
+}
+
+func TestRunSolutionTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	filename := filepath.Join(tmpDir, "solution.py")
+	if err := os.WriteFile(filename, []byte("import time\ntime.sleep(10)\n"), 0644); err != nil {
+		t.Fatalf("Failed to write solution file: %v", err)
+	}
+
+	_, err := runSolution(context.Background(), filename, "python", 200*time.Millisecond, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Expected timeout error, got: %v", err)
+	}
+}
+
+func TestRunSolutionUnsupportedLanguage(t *testing.T) {
+	if _, err := runSolution(context.Background(), "solution.xyz", "unsupported", 0, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected error for unsupported language")
+	}
+}
@@ -0,0 +1,82 @@
+package aocgen
+
+import (
+	"bytes"
+	"os/exec"
+	"time"
+)
+
+// Runner adapts toolchain commands to the operating system. It finds the
+// program that provides an interpreter, names executables, and starts
+// solutions so that they can be killed together with every process they
+// spawn, such as the binary built by `go run`.
+type Runner interface {
+	// LookPath returns the path of the program that provides name, trying
+	// the names it goes by on this system in turn.
+	LookPath(name string) (string, error)
+	// Executable returns the file name a compiler should write the
+	// executable at path to.
+	Executable(path string) string
+	// Start starts cmd as the root of a process tree that Kill can end.
+	Start(cmd *exec.Cmd) error
+	// Wait waits for cmd and releases what Start set up for it.
+	Wait(cmd *exec.Cmd) error
+	// Kill kills cmd and every process it started.
+	Kill(cmd *exec.Cmd) error
+}
+
+// osRunner is the Runner of the operating system aocgen was built for.
+var osRunner Runner = newOSRunner()
+
+// lookPathAlternatives returns the path of the first name of program in
+// programAlternatives that is on the PATH, or the error for the first name
+// if none is. programAlternatives lists the names a program goes by on this
+// system, in the order they are tried; a program without an entry is only
+// looked up by its own name.
+func lookPathAlternatives(program string) (string, error) {
+	alternatives, ok := programAlternatives[program]
+	if !ok {
+		return exec.LookPath(program)
+	}
+	var first error
+	for _, name := range alternatives {
+		path, err := exec.LookPath(name)
+		if err == nil {
+			return path, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return "", first
+}
+
+// processWaitDelay bounds how long Wait keeps copying the output of a killed
+// command, in case a process that escaped its group still holds the pipes.
+const processWaitDelay = time.Second
+
+// killGroupOnCancel makes a command created with exec.CommandContext kill its
+// whole process tree, not just itself, when the context is done. The command
+// must be started with runCommandTree or osRunner.Start.
+func killGroupOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error { return osRunner.Kill(cmd) }
+	cmd.WaitDelay = processWaitDelay
+}
+
+// runCommandTree starts cmd with osRunner and waits for it.
+func runCommandTree(cmd *exec.Cmd) error {
+	if err := osRunner.Start(cmd); err != nil {
+		return err
+	}
+	return osRunner.Wait(cmd)
+}
+
+// combinedOutputTree is cmd.CombinedOutput for a command started with
+// osRunner.
+func combinedOutputTree(cmd *exec.Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := runCommandTree(cmd)
+	return out.Bytes(), err
+}
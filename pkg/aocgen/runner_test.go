@@ -0,0 +1,41 @@
+package aocgen
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookPathAlternatives(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test programs are shell scripts")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fakelang-2"), []byte("#!/bin/sh\necho 42\n"), 0755); err != nil {
+		t.Fatalf("Failed to write program: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	programAlternatives["fakelang"] = []string{"fakelang", "fakelang-2"}
+	defer delete(programAlternatives, "fakelang")
+
+	path, err := osRunner.LookPath("fakelang")
+	if err != nil || path != filepath.Join(dir, "fakelang-2") {
+		t.Errorf("Expected the second alternative, got %q, %v", path, err)
+	}
+	if _, err := osRunner.LookPath("missing"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected a program without alternatives to be looked up by name, got %v", err)
+	}
+
+	cmd := expandCommand([]string{"fakelang", "{file}"}, map[string]string{"{file}": "solution.fake"})
+	if cmd.Path != filepath.Join(dir, "fakelang-2") || cmd.Args[1] != "solution.fake" {
+		t.Errorf("Expected the command to use the resolved program, got %s %v", cmd.Path, cmd.Args)
+	}
+	output, err := combinedOutputTree(cmd)
+	if err != nil || string(output) != "42\n" {
+		t.Errorf("Expected the program to run, got %q, %v", output, err)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package aocgen
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// unixRunner runs each solution in a process group of its own and kills the
+// group.
+type unixRunner struct{}
+
+func newOSRunner() Runner { return unixRunner{} }
+
+var programAlternatives = map[string][]string{
+	"python": {"python", "python3"},
+}
+
+func (unixRunner) LookPath(name string) (string, error) { return lookPathAlternatives(name) }
+
+func (unixRunner) Executable(path string) string { return path }
+
+// Start starts cmd as the leader of a new process group.
+func (unixRunner) Start(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return cmd.Start()
+}
+
+func (unixRunner) Wait(cmd *exec.Cmd) error { return cmd.Wait() }
+
+// Kill kills cmd and every process in its group.
+func (unixRunner) Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package aocgen
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// The py launcher comes first because "python" may be the Microsoft Store
+// stub, which opens the store instead of running the script.
+var programAlternatives = map[string][]string{
+	"python": {"py", "python", "python3"},
+	"awk":    {"awk", "gawk"},
+}
+
+// windowsRunner puts each solution in a job object. Windows has no process
+// groups to signal, and walking the process tree misses processes whose
+// parent has already exited, but every process a job member starts joins the
+// job and is ended with it.
+type windowsRunner struct {
+	mu   sync.Mutex
+	jobs map[*exec.Cmd]windows.Handle
+}
+
+func newOSRunner() Runner { return &windowsRunner{jobs: make(map[*exec.Cmd]windows.Handle)} }
+
+func (r *windowsRunner) LookPath(name string) (string, error) { return lookPathAlternatives(name) }
+
+func (r *windowsRunner) Executable(path string) string {
+	if strings.HasSuffix(strings.ToLower(path), ".exe") {
+		return path
+	}
+	return path + ".exe"
+}
+
+// Start starts cmd in a new process group, so it does not receive the
+// console's Ctrl-C and is only stopped by aocgen, and assigns it to a job
+// that kills its members once the job is closed.
+func (r *windowsRunner) Start(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Without a job Kill falls back to taskkill
+	job, err := newKillOnCloseJob()
+	if err != nil {
+		return nil
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return nil
+	}
+	defer windows.CloseHandle(process)
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		windows.CloseHandle(job)
+		return nil
+	}
+
+	r.mu.Lock()
+	r.jobs[cmd] = job
+	r.mu.Unlock()
+	return nil
+}
+
+// Wait waits for cmd and closes its job, which ends the processes it left
+// running.
+func (r *windowsRunner) Wait(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+	r.mu.Lock()
+	job, ok := r.jobs[cmd]
+	delete(r.jobs, cmd)
+	r.mu.Unlock()
+	if ok {
+		windows.CloseHandle(job)
+	}
+	return err
+}
+
+// Kill ends every process in cmd's job, or its process tree if it has no
+// job.
+func (r *windowsRunner) Kill(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	r.mu.Lock()
+	job, ok := r.jobs[cmd]
+	r.mu.Unlock()
+	if ok {
+		if err := windows.TerminateJobObject(job, 1); err == nil {
+			return nil
+		}
+	}
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// newKillOnCloseJob creates a job object whose processes are killed when its
+// last handle is closed.
+func newKillOnCloseJob() (windows.Handle, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation, uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(job)
+		return 0, err
+	}
+	return job, nil
+}
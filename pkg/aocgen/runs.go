@@ -0,0 +1,141 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	runsDir = "runs"
+	// workDir holds the working directories of generate, eval and benchmark
+	// runs
+	workDir = "work"
+	// runOutputLines bounds the output of a solution kept in a run
+	runOutputLines = 20
+)
+
+// BenchmarkRun is the checkpoint of a perf or benchmark run. It is written
+// after every benchmarked challenge so an interrupted run can be resumed.
+type BenchmarkRun struct {
+	ID   string `json:"id"`
+	Lang string `json:"lang"`
+	// Model is the model a benchmark run generated the solutions with; perf
+	// runs time the existing solutions and have none
+	Model string `json:"model,omitempty"`
+	// Workdir is where a benchmark run writes and runs the solutions; it,
+	// Out and Workspace are where a perf run finds them, see challengeDir
+	Workdir   string      `json:"workdir,omitempty"`
+	Out       string      `json:"out,omitempty"`
+	Workspace bool        `json:"workspace,omitempty"`
+	TimeoutMs int64       `json:"timeout_ms"`
+	StartedAt time.Time   `json:"started_at"`
+	Results   []RunResult `json:"results"`
+
+	mu sync.Mutex
+}
+
+// RunResult is the outcome of benchmarking a single challenge.
+type RunResult struct {
+	Challenge string        `json:"challenge"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	// Verdict is the result of evaluating the solution in a benchmark run
+	Verdict Verdict `json:"verdict,omitempty"`
+	// Output is the end of what the solution printed
+	Output string `json:"output,omitempty"`
+	// Failure is why a benchmark run got no solution to evaluate: an API
+	// error or an extraction failure
+	Failure string `json:"failure,omitempty"`
+}
+
+func newBenchmarkRun(lang string, timeoutMs int64) *BenchmarkRun {
+	now := time.Now()
+	return &BenchmarkRun{
+		ID:        now.Format("20060102-150405"),
+		Lang:      lang,
+		TimeoutMs: timeoutMs,
+		StartedAt: now,
+	}
+}
+
+// solutionPath returns the file of the solution of challenge in the
+// language with extension ext that the run evaluated.
+func (r *BenchmarkRun) solutionPath(challenge, ext string) string {
+	day, part, year, _ := parseChallengeName(challenge)
+	flags := Flags{Day: day, Part: part, Year: year, Workdir: r.Workdir, Out: r.Out, Workspace: r.Workspace}
+	return filepath.Join(challengeDir(flags), solutionFileName(challenge, ext))
+}
+
+// absPath returns path as an absolute path, so a run finds its files from
+// any directory. Empty stays empty.
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func runPath(id string) string {
+	return filepath.Join(getCacheDir(), runsDir, id+".json")
+}
+
+// loadBenchmarkRun reads the checkpoint of a previous run.
+func loadBenchmarkRun(id string) (*BenchmarkRun, error) {
+	data, err := os.ReadFile(runPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run %s not found", id)
+		}
+		return nil, err
+	}
+	var run BenchmarkRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("error parsing run %s: %v", id, err)
+	}
+	return &run, nil
+}
+
+// completed returns the results recorded so far, keyed by challenge name.
+func (r *BenchmarkRun) completed() map[string]RunResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	done := make(map[string]RunResult, len(r.Results))
+	for _, result := range r.Results {
+		done[result.Challenge] = result
+	}
+	return done
+}
+
+// record adds a result and checkpoints the run to disk. It is safe to call
+// from concurrent workers.
+func (r *BenchmarkRun) record(result RunResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Results = append(r.Results, result)
+	return r.save()
+}
+
+// save writes the run atomically so an interruption mid-write never leaves a
+// corrupt checkpoint behind. The caller must hold r.mu.
+func (r *BenchmarkRun) save() error {
+	path := runPath(r.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
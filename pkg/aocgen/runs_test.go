@@ -0,0 +1,65 @@
+package aocgen
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRunPerformanceBenchmarkResume(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "python", Input: "1"},
+		{Name: "day2_part1_2015", SolutionLang: "python", Input: "2"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+	// The first solution fails, so it must not be run again on resume
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("raise SystemExit(1)\n"), 0644)
+	os.WriteFile(testSolutionPath(t, "day2_part1_2015", "py"), []byte("print(open('input.txt').read())\n"), 0644)
+
+	run := newBenchmarkRun("python", 5000)
+	run.ID = "interrupted"
+	if err := run.record(RunResult{Challenge: "day1_part1_2015", Duration: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	if err := runPerformanceBenchmark(Flags{Resume: "interrupted", Workers: 1}); err != nil {
+		t.Fatalf("Failed to resume benchmark: %v", err)
+	}
+
+	resumed, err := loadBenchmarkRun("interrupted")
+	if err != nil {
+		t.Fatalf("Failed to load run: %v", err)
+	}
+	if len(resumed.Results) != 2 {
+		t.Fatalf("Expected 2 results after resuming, got %+v", resumed.Results)
+	}
+	first, second := resumed.Results[0], resumed.Results[1]
+	if first.Challenge != "day1_part1_2015" || first.Error != "" || first.Duration != 5*time.Millisecond {
+		t.Errorf("Expected the completed challenge to be kept as is, got %+v", first)
+	}
+	if second.Challenge != "day2_part1_2015" || second.Error != "" {
+		t.Errorf("Expected the remaining challenge to be benchmarked, got %+v", second)
+	}
+
+	if err := runPerformanceBenchmark(Flags{Resume: "interrupted", Lang: "go"}); err == nil {
+		t.Errorf("Expected an error when resuming with a different language")
+	}
+	if err := runPerformanceBenchmark(Flags{Resume: "missing"}); err == nil {
+		t.Errorf("Expected an error for an unknown run")
+	}
+}
@@ -0,0 +1,72 @@
+package aocgen
+
+import (
+	"flag"
+	"strconv"
+)
+
+// Sampling holds the sampling parameters sent to the model. Unset parameters
+// are left out of the request, so the provider's defaults apply.
+type Sampling struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	// MaxTokens bounds the length of the completion; zero means the
+	// provider's default.
+	MaxTokens int    `json:"max_tokens,omitempty"`
+	Seed      *int64 `json:"seed,omitempty"`
+}
+
+// registerSamplingFlags adds the sampling flags to flagSet. Temperature, top-p
+// and seed are only set when given, since zero is a meaningful value for them.
+func registerSamplingFlags(flagSet *flag.FlagSet, s *Sampling) {
+	flagSet.Func("temperature", "Sampling temperature (default: the provider's)", func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		s.Temperature = &v
+		return err
+	})
+	flagSet.Func("top-p", "Nucleus sampling probability mass (default: the provider's)", func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		s.TopP = &v
+		return err
+	})
+	flagSet.Func("seed", "Sampling seed for reproducible generations, where the provider supports it", func(value string) error {
+		v, err := strconv.ParseInt(value, 10, 64)
+		s.Seed = &v
+		return err
+	})
+	flagSet.IntVar(&s.MaxTokens, "max-tokens", 0, "Maximum number of tokens to generate (default: the provider's)")
+}
+
+// withDefaults fills the parameters that are not set in s from defaults.
+func (s Sampling) withDefaults(defaults Sampling) Sampling {
+	if s.Temperature == nil {
+		s.Temperature = defaults.Temperature
+	}
+	if s.TopP == nil {
+		s.TopP = defaults.TopP
+	}
+	if s.MaxTokens == 0 {
+		s.MaxTokens = defaults.MaxTokens
+	}
+	if s.Seed == nil {
+		s.Seed = defaults.Seed
+	}
+	return s
+}
+
+// setSamplingParams adds the parameters that are set in s to an OpenAI-style
+// chat completion payload. seedKey is the provider's name for the seed.
+func setSamplingParams(payload map[string]interface{}, s Sampling, seedKey string) {
+	if s.Temperature != nil {
+		payload["temperature"] = *s.Temperature
+	}
+	if s.TopP != nil {
+		payload["top_p"] = *s.TopP
+	}
+	if s.MaxTokens > 0 {
+		payload["max_tokens"] = s.MaxTokens
+	}
+	if s.Seed != nil {
+		payload[seedKey] = *s.Seed
+	}
+}
@@ -0,0 +1,104 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSamplingFlags(t *testing.T) {
+	flags, err := parseFlags([]string{"--temperature", "0", "--seed", "42", "--max-tokens", "8192"})
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	s := flags.Sampling
+	if s.Temperature == nil || *s.Temperature != 0 || s.Seed == nil || *s.Seed != 42 || s.MaxTokens != 8192 || s.TopP != nil {
+		t.Errorf("Unexpected sampling: %+v", s)
+	}
+
+	if _, err := parseFlags([]string{"--temperature", "warm"}); err == nil {
+		t.Errorf("Expected an error for an invalid temperature")
+	}
+
+	topP, temperature := 0.9, 0.7
+	flags = applyConfig(flags, Config{Sampling: Sampling{Temperature: &temperature, TopP: &topP, MaxTokens: 1024}})
+	s = flags.Sampling
+	if *s.Temperature != 0 || *s.TopP != 0.9 || s.MaxTokens != 8192 {
+		t.Errorf("Expected flags to win over the config and the config to fill the rest, got %+v", s)
+	}
+}
+
+func TestSamplingParamsSentToProviders(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	t.Setenv("MISTRAL_API_KEY", "mistral-key")
+
+	var requestBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody = nil
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "```go\npackage main\n```"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	temperature, seed := 0.0, int64(7)
+	sampling := Sampling{Temperature: &temperature, Seed: &seed, MaxTokens: 2048}
+
+	tests := []struct {
+		model   string
+		seedKey string
+	}{
+		{"gpt-4o-mini", "seed"},
+		{"ollama/llama3", "seed"},
+		{"mistral-large-latest", "random_seed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			_, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: tt.model, ModelAPI: server.URL, Sampling: sampling})
+			if err != nil {
+				t.Fatalf("Failed to generate code: %v", err)
+			}
+			if requestBody["temperature"] != 0.0 || requestBody[tt.seedKey] != 7.0 || requestBody["max_tokens"] != 2048.0 {
+				t.Errorf("Expected the sampling parameters in the request, got %v", requestBody)
+			}
+			if _, ok := requestBody["top_p"]; ok {
+				t.Errorf("Expected an unset top_p to be left out, got %v", requestBody)
+			}
+		})
+	}
+
+	if _, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{Lang: "go", Model: "gpt-4o-mini", ModelAPI: server.URL}); err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	for _, key := range []string{"temperature", "top_p", "max_tokens", "seed"} {
+		if _, ok := requestBody[key]; ok {
+			t.Errorf("Expected no %s without sampling flags, got %v", key, requestBody)
+		}
+	}
+}
+
+func TestBedrockRequestBodySampling(t *testing.T) {
+	topP := 0.5
+	body, err := bedrockRequestBody("meta.llama3-8b-instruct-v1:0", "", "prompt", Sampling{TopP: &topP, MaxTokens: 512})
+	if err != nil {
+		t.Fatalf("Failed to build request body: %v", err)
+	}
+	var request map[string]interface{}
+	json.Unmarshal(body, &request)
+	if request["top_p"] != 0.5 || request["max_gen_len"] != 512.0 {
+		t.Errorf("Unexpected request body: %s", body)
+	}
+
+	body, _ = bedrockRequestBody("anthropic.claude-3-haiku-20240307-v1:0", "", "prompt", Sampling{})
+	request = nil
+	json.Unmarshal(body, &request)
+	if request["max_tokens"] != float64(bedrockMaxTokens) {
+		t.Errorf("Expected the default max_tokens, got %s", body)
+	}
+}
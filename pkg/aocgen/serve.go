@@ -0,0 +1,321 @@
+package aocgen
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultServeAddr = "127.0.0.1:8080"
+	// serveTokenEnv holds the API token of `aocgen serve` if --token is not
+	// given.
+	serveTokenEnv = "AOCGEN_TOKEN"
+)
+
+// server is the REST API of `aocgen serve`.
+type server struct {
+	// flags are the defaults of every request, e.g. the configured model
+	flags Flags
+	token string
+	// mu runs one generation or evaluation at a time, since they write the
+	// input and solution files of a challenge and the stored challenges
+	mu sync.Mutex
+}
+
+// ServeRequest selects the challenge and language of a generate or eval
+// request. Empty fields fall back to the flags of `aocgen serve`.
+type ServeRequest struct {
+	Year  int    `json:"year"`
+	Day   int    `json:"day"`
+	Part  int    `json:"part"`
+	Lang  string `json:"lang,omitempty"`
+	Model string `json:"model,omitempty"`
+}
+
+func (req ServeRequest) flags(base Flags) (Flags, error) {
+	if req.Year == 0 || req.Day == 0 || req.Part == 0 {
+		return base, fmt.Errorf("year, day and part are required")
+	}
+	flags := base
+	flags.Year, flags.Day, flags.Part = req.Year, req.Day, req.Part
+	if req.Lang != "" {
+		flags.Lang = req.Lang
+	}
+	if req.Model != "" {
+		flags.Model = req.Model
+	}
+	if flags.Lang == "" {
+		return flags, fmt.Errorf("lang is required")
+	}
+	return flags, nil
+}
+
+func newServer(flags Flags, token string) *server {
+	return &server{flags: flags, token: token}
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/challenges", s.handleList)
+	mux.HandleFunc("GET /api/challenges/{name}", s.handleChallenge)
+	mux.HandleFunc("POST /api/generate", s.handleGenerate)
+	mux.HandleFunc("GET /api/eval", s.handleEval)
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	return s.authenticate(mux)
+}
+
+// authenticate rejects requests without the token, given as a bearer token or,
+// for EventSource clients which cannot set headers, as the token query
+// parameter.
+func (s *server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleList serves the challenges like `aocgen list --json`, filtered by the
+// year, day, lang and solved query parameters.
+func (s *server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var flags Flags
+	var err error
+	if flags.Year, err = queryInt(query.Get("year")); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid year: %v", err))
+		return
+	}
+	if flags.Day, err = queryInt(query.Get("day")); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid day: %v", err))
+		return
+	}
+	flags.Lang = query.Get("lang")
+	switch query.Get("solved") {
+	case "true":
+		flags.Solved = true
+	case "false":
+		flags.Unsolved = true
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("error loading challenges: %v", err))
+		return
+	}
+	entries := filterListEntries(listEntries(challenges), flags)
+	if entries == nil {
+		entries = []ListEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleChallenge serves every stored record of a challenge.
+func (s *server) handleChallenge(w http.ResponseWriter, r *http.Request) {
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Errorf("error loading challenges: %v", err))
+		return
+	}
+	records := []Challenge{}
+	for _, c := range challenges {
+		if c.Name == r.PathValue("name") {
+			records = append(records, c)
+		}
+	}
+	if len(records) == 0 {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("challenge not found: %s", r.PathValue("name")))
+		return
+	}
+	writeJSON(w, http.StatusOK, records)
+}
+
+// handleGenerate generates a solution like `aocgen generate --json` for the
+// ServeRequest in the body.
+func (s *server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var req ServeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	flags, err := req.flags(s.flags)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report, err := generateChallengeSolution(r.Context(), flags)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleEval evaluates the solution of the challenge in the year, day, part
+// and lang query parameters and streams it as server-sent events: "output"
+// events with the output of the solution as it runs, then a "result" event
+// with the EvalReport, or an "error" event.
+func (s *server) handleEval(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var req ServeRequest
+	var err error
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{{"year", &req.Year}, {"day", &req.Day}, {"part", &req.Part}} {
+		if *field.value, err = queryInt(query.Get(field.name)); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid %s: %v", field.name, err))
+			return
+		}
+	}
+	req.Lang = query.Get("lang")
+	flags, err := req.flags(s.flags)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events := newEventWriter(w)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	challenge, result, err := evaluateChallengeSolution(r.Context(), flags, events.writer("output"))
+	var missing *MissingToolchainError
+	switch {
+	case errors.As(err, &missing):
+		events.send("result", EvalReport{Challenge: challenge.Name, Lang: flags.Lang, Verdict: VerdictMissingToolchain, ExitCode: VerdictMissingToolchain.ExitCode(), Error: err.Error()})
+	case err != nil:
+		events.send("error", map[string]string{"error": err.Error()})
+	default:
+		events.send("result", newEvalReport(challenge.Name, flags.Lang, result))
+	}
+}
+
+// eventWriter writes server-sent events. It is safe for concurrent use, so
+// the stdout and stderr of a solution can both be streamed.
+type eventWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+}
+
+func newEventWriter(w http.ResponseWriter) *eventWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &eventWriter{w: w}
+}
+
+// send writes an event whose data is v as JSON and flushes it to the client.
+func (e *eventWriter) send(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// writer returns an io.Writer that sends everything written to it as events
+// of the given name with the data {"text": "..."}. A client that went away
+// must not fail the solution writing to it, so errors are dropped.
+func (e *eventWriter) writer(event string) io.Writer {
+	return eventFunc(func(p []byte) {
+		e.send(event, map[string]string{"text": string(p)})
+	})
+}
+
+type eventFunc func([]byte)
+
+func (f eventFunc) Write(p []byte) (int, error) {
+	f(p)
+	return len(p), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError reports err in the same {"error": "..."} form as --json.
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// queryInt parses an optional integer query parameter.
+func queryInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// newServeToken returns a random token for a server started without one.
+func newServeToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// runServeCommand serves the REST API on --addr until ctx is cancelled.
+func runServeCommand(ctx context.Context, flags Flags, w io.Writer) error {
+	token := flags.Token
+	if token == "" {
+		token = os.Getenv(serveTokenEnv)
+	}
+	if token == "" {
+		var err error
+		if token, err = newServeToken(); err != nil {
+			return fmt.Errorf("error creating token: %v", err)
+		}
+		fmt.Fprintf(w, "Token: %s\n", token)
+	}
+
+	listener, err := net.Listen("tcp", flags.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", flags.Addr, err)
+	}
+	srv := &http.Server{
+		Handler: newServer(flags, token).handler(),
+		// Interrupting the server stops the evaluations it is running
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintf(w, "Serving the aocgen API on http://%s\n", listener.Addr())
+	if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
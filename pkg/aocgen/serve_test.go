@@ -0,0 +1,137 @@
+package aocgen
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServeChallenges(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", Task: "Floors", SolutionLang: "go"},
+		{Name: "day1_part1_2015", Task: "Floors", SolutionLang: "python"},
+		{Name: "day2_part1_2015", Task: "Wrapping paper"},
+	})
+
+	srv := httptest.NewServer(newServer(Flags{}, "secret").handler())
+	defer srv.Close()
+
+	get := func(path, token string) *http.Response {
+		req, _ := http.NewRequest("GET", srv.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		resp := get("/api/challenges", token)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 with token %q, got %d", token, resp.StatusCode)
+		}
+	}
+
+	resp := get("/api/challenges?solved=true", "secret")
+	var entries []ListEntry
+	json.NewDecoder(resp.Body).Decode(&entries)
+	resp.Body.Close()
+	if len(entries) != 1 || entries[0].Name != "day1_part1_2015" || strings.Join(entries[0].Languages, ",") != "go,python" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	resp = get("/api/challenges/day2_part1_2015?token=secret", "")
+	var records []Challenge
+	json.NewDecoder(resp.Body).Decode(&records)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(records) != 1 || records[0].Task != "Wrapping paper" {
+		t.Errorf("Unexpected challenge response %d: %+v", resp.StatusCode, records)
+	}
+
+	resp = get("/api/challenges/day9_part1_2015", "secret")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing challenge, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest("POST", srv.URL+"/api/generate", strings.NewReader(`{"year": 2015}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without day and part, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeEvalEvents(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Input: "(()", Answer: "42"}})
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print('thinking')\nprint(42)"), 0644)
+
+	srv := httptest.NewServer(newServer(Flags{Lang: "python", Timeout: 5000}, "secret").handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/eval?year=2015&day=1&part=1&token=secret")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %s", resp.Header.Get("Content-Type"))
+	}
+
+	var output strings.Builder
+	var report EvalReport
+	var event string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			switch event {
+			case "output":
+				var chunk map[string]string
+				json.Unmarshal(data, &chunk)
+				output.WriteString(chunk["text"])
+			case "result":
+				json.Unmarshal(data, &report)
+			default:
+				t.Errorf("Unexpected %s event: %s", event, data)
+			}
+		}
+	}
+
+	if output.String() != "thinking\n42\n" {
+		t.Errorf("Expected the output to be streamed, got %q", output.String())
+	}
+	if report.Verdict != VerdictCorrect || report.Challenge != "day1_part1_2015" || report.Lang != "python" {
+		t.Errorf("Unexpected result: %+v", report)
+	}
+}
@@ -0,0 +1,71 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// sessionHelp tells how to get a fresh session token.
+const sessionHelp = `log in to adventofcode.com, copy the value of the "session" cookie from your browser's developer tools (Application or Storage > Cookies) and store it with 'aocgen init' or --session`
+
+// errSessionExpired is returned for session tokens Advent of Code no longer
+// accepts.
+var errSessionExpired = errors.New("session expired, refresh it from your browser: " + sessionHelp)
+
+// requestSettings loads the Advent of Code settings page with session
+// without following redirects. The page is small and needs a login, so it
+// tells whether the session is still valid.
+func requestSettings(ctx context.Context, client *http.Client, session string) (*http.Response, error) {
+	req, err := newAoCRequest(ctx, "GET", aocBaseURL+"/settings", session, nil)
+	if err != nil {
+		return nil, err
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return doAoCRequest(client, req)
+}
+
+// sessionRejected reports whether a response to the settings page means the
+// session is not accepted: Advent of Code redirects to the login page, or
+// answers 400 for tokens it cannot read.
+func sessionRejected(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		return true
+	case resp.StatusCode == http.StatusBadRequest, resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return true
+	}
+	return false
+}
+
+// verifySession checks session and returns errSessionExpired if Advent of
+// Code rejects it, instead of a bare 400 or a login page stored as puzzle
+// input. Commands call it once, before their first authenticated request,
+// however many requests follow. When the site cannot be reached the check is
+// skipped and the request that follows reports it.
+func verifySession(ctx context.Context, session string) error {
+	if session == "" {
+		return nil
+	}
+	resp, err := requestSettings(ctx, &http.Client{}, session)
+	if err != nil {
+		logger.Debug("cannot check the session token", "err", err)
+		return nil
+	}
+	resp.Body.Close()
+
+	if sessionRejected(resp) {
+		return errSessionExpired
+	}
+	return nil
+}
+
+// verifyCommandSession is verifySession for the session of flags within
+// --http-timeout.
+func verifyCommandSession(ctx context.Context, flags Flags) error {
+	ctx, cancel := withHTTPTimeout(ctx, flags.HTTPTimeout)
+	defer cancel()
+	return verifySession(ctx, flags.Session)
+}
@@ -0,0 +1,89 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifySession(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "valid" {
+			http.Redirect(w, r, "/auth/login", http.StatusFound)
+			return
+		}
+		w.Write([]byte("settings"))
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	if err := verifySession(context.Background(), "valid"); err != nil {
+		t.Errorf("Expected a valid session to pass, got %v", err)
+	}
+
+	// An expired session stops the download before the login page is
+	// stored as input
+	paths = nil
+	_, err := Client{Session: "expired"}.Download(context.Background(), 2023, 1, 1)
+	if !errors.Is(err, errSessionExpired) || !strings.Contains(err.Error(), "refresh it from your browser") {
+		t.Errorf("Expected the session to be reported as expired, got %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/settings" {
+		t.Errorf("Expected only the settings page to be requested, got %v", paths)
+	}
+}
+
+func TestDownloadYearChecksSessionOnce(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	checks := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/settings":
+			checks++
+		case strings.HasSuffix(r.URL.Path, "/input"):
+			w.Write([]byte("1"))
+		default:
+			w.Write([]byte("<article><h2>--- Day ---</h2><p>Task.</p></article>"))
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	// Every day of 2015 is unlocked, so all 25 are fetched
+	reports, err := downloadYear(context.Background(), Flags{Year: 2015, Session: "valid"})
+	if err != nil {
+		t.Fatalf("Failed to download the year: %v", err)
+	}
+	if len(reports) != 25 || checks != 1 {
+		t.Errorf("Expected 25 days downloaded after a single session check, got %d days and %d checks", len(reports), checks)
+	}
+}
+
+func TestVerifySessionUnreachable(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+	server.Close()
+
+	if err := verifySession(context.Background(), "token"); err != nil {
+		t.Errorf("Expected the check to be skipped when the site cannot be reached, got %v", err)
+	}
+}
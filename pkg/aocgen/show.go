@@ -0,0 +1,175 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Terminal styles of a rendered task.
+const (
+	styleReset   = "\x1b[0m"
+	styleHeading = "\x1b[1;33m"
+	styleStrong  = "\x1b[1m"
+	styleEm      = "\x1b[1;97m"
+	styleCode    = "\x1b[36m"
+	styleBlock   = "\x1b[32m"
+	styleLink    = "\x1b[4m"
+)
+
+// ansiEscape matches the escape sequences of the styles.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// inlineMarkup matches the inline Markdown htmlToMarkdown writes: code,
+// strong and emphasized text, and links.
+var inlineMarkup = regexp.MustCompile("``(.+?)``|`([^`]+)`|\\*\\*(.+?)\\*\\*|\\*([^*\\s](?:[^*]*[^*\\s])?)\\*|\\[([^\\]]+)\\]\\((https?://[^)\\s]+)\\)")
+
+// listItem matches the marker of a list item.
+var listItem = regexp.MustCompile(`^(-|\d+\.) `)
+
+// ShowReport is the --json form of `aocgen show`.
+type ShowReport struct {
+	Name string `json:"name"`
+	Task string `json:"task"`
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth returns the width to wrap text at: $COLUMNS, or 80.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 80
+}
+
+// renderInline styles the inline Markdown of a line of text.
+func renderInline(line string) string {
+	return inlineMarkup.ReplaceAllStringFunc(line, func(match string) string {
+		m := inlineMarkup.FindStringSubmatch(match)
+		switch {
+		case m[1] != "":
+			return styleCode + strings.TrimSpace(m[1]) + styleReset
+		case m[2] != "":
+			return styleCode + m[2] + styleReset
+		case m[3] != "":
+			return styleStrong + m[3] + styleReset
+		case m[4] != "":
+			return styleEm + m[4] + styleReset
+		default:
+			return styleLink + m[5] + styleReset + " (" + m[6] + ")"
+		}
+	})
+}
+
+// wrapStyled wraps styled text at width visible columns, starting every line
+// after the first with indent.
+func wrapStyled(text string, width int, indent string) string {
+	var sb strings.Builder
+	column := 0
+	for _, word := range strings.Fields(text) {
+		n := len([]rune(ansiEscape.ReplaceAllString(word, "")))
+		if column > 0 && column+1+n > width {
+			sb.WriteString("\n" + indent)
+			column = len(indent)
+		} else if column > 0 {
+			sb.WriteString(" ")
+			column++
+		}
+		sb.WriteString(word)
+		column += n
+	}
+	return sb.String()
+}
+
+// renderTask renders a task in Markdown for a terminal: headings and
+// emphasis in bold, code in color, example blocks indented and highlighted,
+// and paragraphs wrapped at width.
+func renderTask(markdown string, width int) string {
+	var sb strings.Builder
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inFence = !inFence
+			continue
+		case inFence:
+			sb.WriteString("    " + styleBlock + line + styleReset)
+		case strings.HasPrefix(trimmed, "#"):
+			sb.WriteString(styleHeading + strings.TrimSpace(strings.TrimLeft(trimmed, "#")) + styleReset)
+		case strings.HasPrefix(trimmed, "--- ") && strings.HasSuffix(trimmed, " ---"):
+			sb.WriteString(styleHeading + trimmed + styleReset)
+		case listItem.MatchString(trimmed):
+			marker := listItem.FindString(trimmed)
+			sb.WriteString("  " + marker + wrapStyled(renderInline(trimmed[len(marker):]), width-2-len(marker), strings.Repeat(" ", 2+len(marker))))
+		default:
+			sb.WriteString(wrapStyled(renderInline(trimmed), width, ""))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// pageOutput writes text through $PAGER when it is set, or else to w. Like
+// git, less is told to keep the colors and to quit when the text fits on one
+// screen.
+func pageOutput(text string, w io.Writer) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		_, err := io.WriteString(w, text)
+		return err
+	}
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout, cmd.Stderr = w, os.Stderr
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running pager %s: %v", pager[0], err)
+	}
+	return nil
+}
+
+// runShowCommand prints the stored task of a challenge. On a terminal it is
+// paged through $PAGER and its Markdown rendered unless NO_COLOR is set;
+// otherwise it is printed as it is stored.
+func runShowCommand(flags Flags, w io.Writer) error {
+	if flags.Day == 0 || flags.Year == 0 {
+		return fmt.Errorf("--day and --year are required")
+	}
+	if flags.Part == 0 {
+		flags.Part = 1
+	}
+	challenge, err := findStoredChallenge(flags)
+	if err != nil {
+		return err
+	}
+	if flags.JSON {
+		return emitJSON(ShowReport{Name: challenge.Name, Task: challenge.Task})
+	}
+
+	task := taskMarkdown(challenge)
+	if !isTerminal(w) {
+		_, err := io.WriteString(w, task)
+		return err
+	}
+	if os.Getenv("NO_COLOR") == "" {
+		task = renderTask(task, terminalWidth())
+	}
+	return pageOutput(task, w)
+}
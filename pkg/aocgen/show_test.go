@@ -0,0 +1,59 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRenderTask(t *testing.T) {
+	markdown := "--- Day 7: Some Assembly Required ---\n\nEach wire has an *identifier* and `123 -> x` means **something**.\n\n```\n123 -> x\nx AND y -> d\n```\n\n- a [link](https://example.com) to follow\n"
+	got := renderTask(markdown, 30)
+
+	for _, want := range []string{
+		styleHeading + "--- Day 7: Some Assembly Required ---" + styleReset,
+		styleEm + "identifier" + styleReset,
+		styleCode + "123 -> x" + styleReset,
+		styleStrong + "something" + styleReset,
+		"    " + styleBlock + "x AND y -> d" + styleReset,
+		"  - a " + styleLink + "link" + styleReset,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "```") {
+		t.Errorf("Expected the fences to be dropped:\n%s", got)
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if n := len(ansiEscape.ReplaceAllString(line, "")); n > 30 && !strings.Contains(line, "---") {
+			t.Errorf("Expected lines of at most 30 columns, got %d: %q", n, line)
+		}
+	}
+}
+
+func TestShowCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{{Name: "day7_part1_2021", Task: "Align the *crabs*.", Input: "16,1,2"}})
+
+	var out bytes.Buffer
+	if err := runShowCommand(Flags{Day: 7, Year: 2021}, &out); err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	if out.String() != "# Advent of Code 2021, Day 7, Part 1\n\nAlign the *crabs*.\n" {
+		t.Errorf("Expected the stored markdown when not on a terminal, got %q", out.String())
+	}
+
+	if err := runShowCommand(Flags{Day: 7, Part: 2, Year: 2021}, &out); err == nil {
+		t.Errorf("Expected an error for a challenge that is not stored")
+	}
+
+	var report ShowReport
+	data := captureJSON(t, func() error { return runShowCommand(Flags{Day: 7, Year: 2021, JSON: true}, &out) })
+	if err := json.Unmarshal(data, &report); err != nil || report.Name != "day7_part1_2021" || report.Task != "Align the *crabs*." {
+		t.Errorf("Unexpected JSON report %s: %v", data, err)
+	}
+}
@@ -0,0 +1,45 @@
+package aocgen
+
+import (
+	"os"
+	"strings"
+)
+
+// recordSolution stores code as the solution of challenge in lang once it has
+// passed eval, so list shows the challenge as solved and export includes it.
+func recordSolution(challenge Challenge, lang, code string) error {
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return saveChallenges(upsertSolution(challenges, challenge, lang, code))
+}
+
+// upsertSolution replaces the solution of an existing record of challenge in
+// lang, or fills in an unsolved record of it. Otherwise a copy of challenge
+// with the solution is appended.
+func upsertSolution(challenges []Challenge, challenge Challenge, lang, code string) []Challenge {
+	unsolved := -1
+	for i := range challenges {
+		if challenges[i].Name != challenge.Name {
+			continue
+		}
+		if strings.EqualFold(challenges[i].SolutionLang, lang) {
+			challenges[i].Solution = code
+			return challenges
+		}
+		if challenges[i].SolutionLang == "" && unsolved < 0 {
+			unsolved = i
+		}
+	}
+
+	if unsolved >= 0 {
+		challenges[unsolved].Solution = code
+		challenges[unsolved].SolutionLang = lang
+		return challenges
+	}
+
+	challenge.Solution = code
+	challenge.SolutionLang = lang
+	return append(challenges, challenge)
+}
@@ -0,0 +1,84 @@
+package aocgen
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUpsertSolution(t *testing.T) {
+	challenge := Challenge{Name: "day1_part1_2015", Input: "(()", Task: "Find the floor.", Answer: "1", Year: 2015}
+
+	tests := []struct {
+		name       string
+		challenges []Challenge
+		expected   []Challenge
+	}{
+		{
+			"fills in an unsolved record",
+			[]Challenge{{Name: "day1_part1_2015", Input: "(()"}},
+			[]Challenge{{Name: "day1_part1_2015", Input: "(()", Solution: "new", SolutionLang: "go"}},
+		},
+		{
+			"replaces the solution in the same language",
+			[]Challenge{{Name: "day1_part1_2015", Solution: "old", SolutionLang: "go"}, {Name: "day1_part1_2015"}},
+			[]Challenge{{Name: "day1_part1_2015", Solution: "new", SolutionLang: "go"}, {Name: "day1_part1_2015"}},
+		},
+		{
+			"adds a record for a new language",
+			[]Challenge{{Name: "day1_part1_2015", Solution: "print(1)", SolutionLang: "python"}},
+			[]Challenge{
+				{Name: "day1_part1_2015", Solution: "print(1)", SolutionLang: "python"},
+				{Name: "day1_part1_2015", Input: "(()", Task: "Find the floor.", Answer: "1", Year: 2015, Solution: "new", SolutionLang: "go"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := upsertSolution(tt.challenges, challenge, "go", "new")
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %d challenges, got %+v", len(tt.expected), got)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.expected[i]) {
+					t.Errorf("Challenge %d:\nExpected: %+v\nGot: %+v", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEvalRecordsPassingSolution(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Answer: "42"}})
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python"}
+
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print(41)\n"), 0644)
+	if err := runEvaluationCommand(flags); verdictExitCode(err) != VerdictWrongAnswer.ExitCode() {
+		t.Fatalf("Expected a wrong answer, got: %v", err)
+	}
+	challenges, _ := loadChallenges(tempDir, challengesFile)
+	if len(challenges) != 1 || challenges[0].SolutionLang != "" {
+		t.Errorf("Expected a wrong solution not to be recorded, got %+v", challenges)
+	}
+
+	os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte("print(42)\n"), 0644)
+	if err := runEvaluationCommand(flags); err != nil {
+		t.Fatalf("Failed to evaluate: %v", err)
+	}
+	challenges, _ = loadChallenges(tempDir, challengesFile)
+	if len(challenges) != 1 || challenges[0].SolutionLang != "python" || challenges[0].Solution != "print(42)\n" {
+		t.Errorf("Expected the passing solution to be recorded, got %+v", challenges)
+	}
+}
@@ -0,0 +1,227 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Stats summarizes the local dataset and eval history.
+type Stats struct {
+	Years     []YearStats     `json:"years"`
+	Languages []LanguageStats `json:"languages"`
+	// PassAtK is computed from the evaluated attempts of each model.
+	PassAtK []PassAtKStats `json:"pass_at_k,omitempty"`
+	// Challenges lists the challenges of --year, if given.
+	Challenges []ChallengeStats `json:"challenges,omitempty"`
+}
+
+// YearStats counts the challenges stored for one event year.
+type YearStats struct {
+	Year       int `json:"year"`
+	Challenges int `json:"challenges"`
+	// Solved counts challenges with a solution in at least one language.
+	Solved      int   `json:"solved"`
+	MissingDays []int `json:"missing_days"`
+}
+
+// LanguageStats counts the solutions and eval results for one language.
+type LanguageStats struct {
+	Lang      string `json:"lang"`
+	Solved    int    `json:"solved"`
+	Evaluated int    `json:"evaluated"`
+	Passed    int    `json:"passed"`
+	// PassRate is the share of evaluated challenges whose latest eval was
+	// correct.
+	PassRate float64 `json:"pass_rate"`
+}
+
+// computeStats aggregates challenges and eval records. Only the latest eval of
+// each challenge counts towards a language's pass rate.
+func computeStats(challenges []Challenge, evals []EvalRecord) Stats {
+	type yearData struct {
+		names  map[string]bool
+		solved map[string]bool
+		days   map[int]bool
+	}
+	years := make(map[int]*yearData)
+	solvedByLang := make(map[string]map[string]bool)
+
+	for _, c := range challenges {
+		day, _, year, ok := parseChallengeName(c.Name)
+		if !ok {
+			continue
+		}
+		y, exists := years[year]
+		if !exists {
+			y = &yearData{names: map[string]bool{}, solved: map[string]bool{}, days: map[int]bool{}}
+			years[year] = y
+		}
+		y.names[c.Name] = true
+		y.days[day] = true
+
+		if strings.TrimSpace(c.Solution) != "" && c.SolutionLang != "" {
+			y.solved[c.Name] = true
+			lang := strings.ToLower(c.SolutionLang)
+			if solvedByLang[lang] == nil {
+				solvedByLang[lang] = map[string]bool{}
+			}
+			solvedByLang[lang][c.Name] = true
+		}
+	}
+
+	var stats Stats
+	for year, y := range years {
+		ys := YearStats{Year: year, Challenges: len(y.names), Solved: len(y.solved), MissingDays: []int{}}
+		for day := 1; day <= 25; day++ {
+			if !y.days[day] {
+				ys.MissingDays = append(ys.MissingDays, day)
+			}
+		}
+		stats.Years = append(stats.Years, ys)
+	}
+	sort.Slice(stats.Years, func(i, j int) bool { return stats.Years[i].Year < stats.Years[j].Year })
+
+	latest := make(map[string]map[string]EvalRecord)
+	for _, e := range evals {
+		lang := strings.ToLower(e.Lang)
+		if latest[lang] == nil {
+			latest[lang] = map[string]EvalRecord{}
+		}
+		if prev, ok := latest[lang][e.Challenge]; !ok || !e.Time.Before(prev.Time) {
+			latest[lang][e.Challenge] = e
+		}
+	}
+
+	langs := make(map[string]bool)
+	for lang := range solvedByLang {
+		langs[lang] = true
+	}
+	for lang := range latest {
+		langs[lang] = true
+	}
+	for lang := range langs {
+		ls := LanguageStats{Lang: lang, Solved: len(solvedByLang[lang]), Evaluated: len(latest[lang])}
+		for _, e := range latest[lang] {
+			if e.Verdict == VerdictCorrect {
+				ls.Passed++
+			}
+		}
+		if ls.Evaluated > 0 {
+			ls.PassRate = float64(ls.Passed) / float64(ls.Evaluated)
+		}
+		stats.Languages = append(stats.Languages, ls)
+	}
+	sort.Slice(stats.Languages, func(i, j int) bool {
+		if stats.Languages[i].Solved != stats.Languages[j].Solved {
+			return stats.Languages[i].Solved > stats.Languages[j].Solved
+		}
+		return stats.Languages[i].Lang < stats.Languages[j].Lang
+	})
+
+	return stats
+}
+
+// formatDayRanges renders days compactly, e.g. "1, 3, 5-7".
+func formatDayRanges(days []int) string {
+	if len(days) == 0 {
+		return "-"
+	}
+	var parts []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprint(days[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", days[i], days[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printStats(w io.Writer, stats Stats, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+
+	if len(stats.Years) == 0 {
+		fmt.Fprintln(w, "No challenges found. Run 'aocgen setup' or 'aocgen download' first.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-6s  %10s  %8s  %s\n", "Year", "Challenges", "Solved", "Missing days")
+	for _, y := range stats.Years {
+		fmt.Fprintf(w, "%-6d  %10d  %8d  %s\n", y.Year, y.Challenges, y.Solved, formatDayRanges(y.MissingDays))
+	}
+
+	if len(stats.Languages) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%-14s  %8s  %10s  %8s  %10s\n", "Language", "Solved", "Evaluated", "Passed", "Pass rate")
+		for _, l := range stats.Languages {
+			rate := "-"
+			if l.Evaluated > 0 {
+				rate = fmt.Sprintf("%.1f%%", l.PassRate*100)
+			}
+			fmt.Fprintf(w, "%-14s  %8d  %10d  %8d  %10s\n", l.Lang, l.Solved, l.Evaluated, l.Passed, rate)
+		}
+	}
+
+	if len(stats.PassAtK) > 0 {
+		fmt.Fprintln(w)
+		printPassAtK(w, stats.PassAtK)
+	}
+
+	if len(stats.Challenges) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%-18s  %-30s  %-20s  %s\n", "Challenge", "Title", "Solved in", "Latest eval")
+		for _, c := range stats.Challenges {
+			languages, verdict := strings.Join(c.Languages, ", "), string(c.Verdict)
+			if languages == "" {
+				languages = "-"
+			}
+			if verdict == "" {
+				verdict = "-"
+			}
+			fmt.Fprintf(w, "%-18s  %-30s  %-20s  %s\n", c.Name, c.Title, languages, verdict)
+		}
+	}
+	return nil
+}
+
+func runStatsCommand(flags Flags, w io.Writer) error {
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+	evals, err := loadEvalLog()
+	if err != nil {
+		return err
+	}
+	attempts, err := loadAttempts()
+	if err != nil {
+		return err
+	}
+
+	stats := computeStats(challenges, evals)
+	stats.PassAtK = computePassAtK(attempts)
+	if flags.Year != 0 {
+		stats.Challenges = yearChallengeStats(challenges, evals, flags.Year)
+	}
+	return printStats(w, stats, flags.Format)
+}
@@ -0,0 +1,81 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	challenges := []Challenge{
+		{Name: "day1_part1_2015", SolutionLang: "go", Solution: "package main"},
+		{Name: "day1_part1_2015", SolutionLang: "python", Solution: "print(1)"},
+		{Name: "day1_part2_2015", SolutionLang: "go", Solution: "package main"},
+		{Name: "day3_part1_2015"},
+		{Name: "day2_part1_2016", SolutionLang: "python", Solution: "print(2)"},
+	}
+	now := time.Now()
+	evals := []EvalRecord{
+		{Challenge: "day1_part1_2015", Lang: "go", Verdict: VerdictWrongAnswer, Time: now.Add(-time.Hour)},
+		{Challenge: "day1_part1_2015", Lang: "go", Verdict: VerdictCorrect, Time: now},
+		{Challenge: "day1_part2_2015", Lang: "go", Verdict: VerdictTimeout, Time: now},
+	}
+
+	stats := computeStats(challenges, evals)
+
+	if len(stats.Years) != 2 {
+		t.Fatalf("Expected 2 years, got %+v", stats.Years)
+	}
+	y2015 := stats.Years[0]
+	if y2015.Year != 2015 || y2015.Challenges != 3 || y2015.Solved != 2 {
+		t.Errorf("Unexpected 2015 stats: %+v", y2015)
+	}
+	if got := formatDayRanges(y2015.MissingDays); got != "2, 4-25" {
+		t.Errorf("Expected missing days 2, 4-25, got %s", got)
+	}
+
+	expectedLangs := []LanguageStats{
+		{Lang: "go", Solved: 2, Evaluated: 2, Passed: 1, PassRate: 0.5},
+		{Lang: "python", Solved: 2},
+	}
+	if !reflect.DeepEqual(stats.Languages, expectedLangs) {
+		t.Errorf("Unexpected language stats.\nExpected: %+v\nGot: %+v", expectedLangs, stats.Languages)
+	}
+}
+
+func TestRunStatsCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2020", SolutionLang: "rust", Solution: "fn main() {}"}})
+	recordEval(Challenge{Name: "day1_part1_2020"}, "rust", "", EvalResult{Verdict: VerdictCorrect})
+
+	var out bytes.Buffer
+	if err := runStatsCommand(Flags{Format: "table"}, &out); err != nil {
+		t.Fatalf("Failed to print stats: %v", err)
+	}
+	for _, expected := range []string{"2020", "2-25", "rust", "100.0%"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected table to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runStatsCommand(Flags{Format: "json"}, &out); err != nil {
+		t.Fatalf("Failed to print stats: %v", err)
+	}
+	var stats Stats
+	if err := json.Unmarshal(out.Bytes(), &stats); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, out.String())
+	}
+	if len(stats.Languages) != 1 || stats.Languages[0].Passed != 1 {
+		t.Errorf("Unexpected JSON stats: %+v", stats)
+	}
+
+	if err := runStatsCommand(Flags{Format: "xml"}, &out); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
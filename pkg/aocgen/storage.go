@@ -0,0 +1,113 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ChallengeStore persists the challenges in a cache directory. Load returns an
+// error for which os.IsNotExist is true when nothing has been stored yet.
+type ChallengeStore interface {
+	Load() ([]Challenge, error)
+	Save(challenges []Challenge) error
+}
+
+// storeBackends opens the store of a cache directory by the backend name used
+// for "storage" in the config file.
+var storeBackends = map[string]func(dir string) ChallengeStore{
+	"json":   func(dir string) ChallengeStore { return jsonStore{dir: dir, file: challengesFile} },
+	"sqlite": func(dir string) ChallengeStore { return sqliteStore{dir: dir} },
+}
+
+// RegisterStoreBackend makes a custom ChallengeStore available under name, so
+// it can be selected with "storage" in the config file or Store.Backend.
+func RegisterStoreBackend(name string, open func(dir string) ChallengeStore) {
+	storeBackends[name] = open
+}
+
+// openStore returns the store of dir for backend.
+func openStore(dir, backend string) (ChallengeStore, error) {
+	open, ok := storeBackends[backend]
+	if !ok {
+		var names []string
+		for name := range storeBackends {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown storage backend %q, expected one of %v", backend, names)
+	}
+	return open(dir), nil
+}
+
+// jsonStore keeps all challenges in a single JSON file.
+type jsonStore struct {
+	dir  string
+	file string
+}
+
+func (s jsonStore) Load() ([]Challenge, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, s.file))
+	if err != nil {
+		return nil, err
+	}
+
+	var challenges []Challenge
+	err = json.Unmarshal(data, &challenges)
+	return challenges, err
+}
+
+// Save backs up the current file and replaces it atomically, so a crash
+// never leaves a truncated file behind.
+func (s jsonStore) Save(challenges []Challenge) error {
+	data, err := json.Marshal(challenges)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, s.file)
+	if err := backupFile(s.dir, path, backupCount()); err != nil {
+		return fmt.Errorf("error backing up %s: %v", s.file, err)
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// sqliteStore keeps challenges in an SQLite database, see storage_sqlite.go.
+type sqliteStore struct {
+	dir string
+}
+
+func (s sqliteStore) Load() ([]Challenge, error) {
+	return loadChallengesSQLite(s.dir)
+}
+
+// Save writes only the records that changed.
+func (s sqliteStore) Save(challenges []Challenge) error {
+	return saveChallengesSQLite(s.dir, challenges)
+}
+
+// Replace rewrites every record.
+func (s sqliteStore) Replace(challenges []Challenge) error {
+	return resetChallengesSQLite(s.dir, challenges)
+}
+
+// replacingStore is a ChallengeStore that can also rewrite all records,
+// when its Save only writes those that changed.
+type replacingStore interface {
+	ChallengeStore
+	Replace(challenges []Challenge) error
+}
+
+// replaceChallenges rewrites the stored challenges with challenges, for
+// `aocgen import`, which may change most of them.
+func replaceChallenges(challenges []Challenge) error {
+	store, err := openStore(getCacheDir(), storageBackend())
+	if err != nil {
+		return err
+	}
+	if replacing, ok := store.(replacingStore); ok {
+		return replacing.Replace(challenges)
+	}
+	return saveChallenges(challenges)
+}
@@ -0,0 +1,366 @@
+package aocgen
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const challengesDB = "challenges.db"
+
+// sqliteMigrations are applied in order; PRAGMA user_version records how many
+// have already run. Never edit an existing entry, append a new one instead.
+//
+// The full challenge is stored as JSON in the data column so new Challenge
+// fields don't need a schema change; the other columns exist for indexed lookup.
+var sqliteMigrations = []string{
+	`CREATE TABLE challenges (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		year INTEGER NOT NULL,
+		day INTEGER NOT NULL,
+		part INTEGER NOT NULL,
+		solution_lang TEXT NOT NULL,
+		data TEXT NOT NULL
+	);
+	CREATE INDEX idx_challenges_lookup ON challenges (year, day, part, solution_lang);
+	CREATE INDEX idx_challenges_name ON challenges (name);`,
+}
+
+var challengeNameRe = regexp.MustCompile(`^day(\d+)_part(\d+)_(\d+)$`)
+
+// parseChallengeName splits a name like day3_part2_2023 into its day, part and year.
+func parseChallengeName(name string) (day, part, year int, ok bool) {
+	m := challengeNameRe.FindStringSubmatch(name)
+	if m == nil {
+		return 0, 0, 0, false
+	}
+	day, _ = strconv.Atoi(m[1])
+	part, _ = strconv.Atoi(m[2])
+	year, _ = strconv.Atoi(m[3])
+	return day, part, year, true
+}
+
+// storageBackend returns the configured storage backend, "json" or "sqlite".
+func storageBackend() string {
+	cfg, err := loadConfig()
+	if err != nil || cfg.Storage == "" {
+		return "json"
+	}
+	return cfg.Storage
+}
+
+// openChallengesDB opens the SQLite store in cacheDir, creating and migrating
+// it as needed. A brand new database is seeded from challenges.json if present.
+func openChallengesDB(cacheDir string) (*sql.DB, error) {
+	dbPath := filepath.Join(cacheDir, challengesDB)
+	_, statErr := os.Stat(dbPath)
+	isNew := os.IsNotExist(statErr)
+
+	if isNew {
+		// Report a missing store the same way the JSON backend does
+		if _, err := os.Stat(filepath.Join(cacheDir, challengesFile)); os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateChallengesDB(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating database: %v", err)
+	}
+
+	if isNew {
+		if err := importJSONChallenges(db, cacheDir); err != nil {
+			db.Close()
+			os.Remove(dbPath)
+			return nil, fmt.Errorf("error importing %s: %v", challengesFile, err)
+		}
+	}
+
+	return db, nil
+}
+
+func migrateChallengesDB(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %v", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importJSONChallenges copies an existing challenges.json into a new database.
+func importJSONChallenges(db *sql.DB, cacheDir string) error {
+	data, err := os.ReadFile(filepath.Join(cacheDir, challengesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var challenges []Challenge
+	if err := json.Unmarshal(data, &challenges); err != nil {
+		return err
+	}
+
+	if err := replaceChallengesSQLite(db, challenges); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Migrated %d challenges from %s to SQLite", len(challenges), challengesFile))
+	return nil
+}
+
+func loadChallengesSQLite(cacheDir string) ([]Challenge, error) {
+	db, err := openChallengesDB(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryChallengesSQLite(db, "SELECT data FROM challenges ORDER BY id")
+}
+
+// saveChallengesSQLite stores challenges, writing only the records that
+// changed, see upsertChallengesSQLite.
+func saveChallengesSQLite(cacheDir string, challenges []Challenge) error {
+	db, err := openChallengesDBForWrite(cacheDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return upsertChallengesSQLite(db, challenges)
+}
+
+// resetChallengesSQLite rewrites every record, for `aocgen import`.
+func resetChallengesSQLite(cacheDir string, challenges []Challenge) error {
+	db, err := openChallengesDBForWrite(cacheDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return replaceChallengesSQLite(db, challenges)
+}
+
+func openChallengesDBForWrite(cacheDir string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", filepath.Join(cacheDir, challengesDB))
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateChallengesDB(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating database: %v", err)
+	}
+	return db, nil
+}
+
+// storedRow is a row of the challenges table.
+type storedRow struct {
+	id   int64
+	name string
+	lang string
+	data string
+}
+
+// upsertChallengesSQLite makes the table hold challenges, touching only
+// the rows that differ. Records are matched to rows by name and solution
+// language, in order; a record whose solution language changed keeps the
+// first remaining row of its name, so its place in the order does not
+// change. Rows without a record are deleted, records without a row
+// inserted.
+func upsertChallengesSQLite(db *sql.DB, challenges []Challenge) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, name, solution_lang, data FROM challenges ORDER BY id")
+	if err != nil {
+		return err
+	}
+	var stored []*storedRow
+	for rows.Next() {
+		var row storedRow
+		if err := rows.Scan(&row.id, &row.name, &row.lang, &row.data); err != nil {
+			rows.Close()
+			return err
+		}
+		stored = append(stored, &row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	byKey := make(map[string][]*storedRow)
+	for _, row := range stored {
+		byKey[row.name+"\x00"+row.lang] = append(byKey[row.name+"\x00"+row.lang], row)
+	}
+	matched := make([]*storedRow, len(challenges))
+	used := make(map[int64]bool)
+	for i, c := range challenges {
+		key := c.Name + "\x00" + c.SolutionLang
+		if queue := byKey[key]; len(queue) > 0 {
+			matched[i], byKey[key] = queue[0], queue[1:]
+			used[matched[i].id] = true
+		}
+	}
+	for i, c := range challenges {
+		if matched[i] != nil {
+			continue
+		}
+		for _, row := range stored {
+			if !used[row.id] && row.name == c.Name {
+				matched[i] = row
+				used[row.id] = true
+				break
+			}
+		}
+	}
+
+	for _, row := range stored {
+		if !used[row.id] {
+			if _, err := tx.Exec("DELETE FROM challenges WHERE id = ?", row.id); err != nil {
+				return err
+			}
+		}
+	}
+	for i, c := range challenges {
+		year, day, part, data, err := challengeColumns(c)
+		if err != nil {
+			return err
+		}
+		row := matched[i]
+		switch {
+		case row == nil:
+			_, err = tx.Exec("INSERT INTO challenges (name, year, day, part, solution_lang, data) VALUES (?, ?, ?, ?, ?, ?)", c.Name, year, day, part, c.SolutionLang, data)
+		case row.lang != c.SolutionLang || row.data != data:
+			_, err = tx.Exec("UPDATE challenges SET year = ?, day = ?, part = ?, solution_lang = ?, data = ? WHERE id = ?", year, day, part, c.SolutionLang, data, row.id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// challengeColumns returns the values of the columns of c other than its
+// name and solution language.
+func challengeColumns(c Challenge) (year, day, part int, data string, err error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return 0, 0, 0, "", err
+	}
+	day, part, year, _ = parseChallengeName(c.Name)
+	if year == 0 {
+		year = int(c.Year)
+	}
+	return year, day, part, string(encoded), nil
+}
+
+// replaceChallengesSQLite deletes every row and inserts challenges again.
+func replaceChallengesSQLite(db *sql.DB, challenges []Challenge) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM challenges"); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO challenges (name, year, day, part, solution_lang, data) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range challenges {
+		year, day, part, data, err := challengeColumns(c)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(c.Name, year, day, part, c.SolutionLang, data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// lookupChallengeSQLite finds the first challenge for year/day/part using the
+// lookup index. An empty lang matches any solution language.
+func lookupChallengeSQLite(cacheDir string, year, day, part int, lang string) (Challenge, bool, error) {
+	db, err := openChallengesDB(cacheDir)
+	if err != nil {
+		return Challenge{}, false, err
+	}
+	defer db.Close()
+
+	query := "SELECT data FROM challenges WHERE year = ? AND day = ? AND part = ?"
+	args := []interface{}{year, day, part}
+	if lang != "" {
+		query += " AND solution_lang = ?"
+		args = append(args, lang)
+	}
+	query += " ORDER BY id LIMIT 1"
+
+	challenges, err := queryChallengesSQLite(db, query, args...)
+	if err != nil || len(challenges) == 0 {
+		return Challenge{}, false, err
+	}
+	return challenges[0], true, nil
+}
+
+func queryChallengesSQLite(db *sql.DB, query string, args ...interface{}) ([]Challenge, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var challenges []Challenge
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var c Challenge
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, c)
+	}
+	return challenges, rows.Err()
+}
@@ -0,0 +1,196 @@
+package aocgen
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSQLiteStorageMigratesFromJSON(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	saveChallenges = defaultSaveChallenges
+
+	if err := saveConfig(Config{Storage: "sqlite"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	testData := []Challenge{
+		{Name: "day1_part1_2015", Input: "(()", Answer: "280", Task: "task 1", Year: 2015, SolutionLang: "go"},
+		{Name: "day1_part1_2015", Input: "(()", Answer: "280", Task: "task 1", Year: 2015, SolutionLang: "python"},
+		{Name: "day2_part2_2016", Input: "2x3x4", Answer: "34", Task: "task 2", Year: 2016},
+	}
+	data, _ := json.Marshal(testData)
+	if err := os.WriteFile(filepath.Join(tempDir, challengesFile), data, 0644); err != nil {
+		t.Fatalf("Failed to write test data: %v", err)
+	}
+
+	challenges, err := loadChallenges(tempDir, challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	if len(challenges) != len(testData) {
+		t.Fatalf("Expected %d challenges after migration, got %d", len(testData), len(challenges))
+	}
+	for i := range testData {
+		if !reflect.DeepEqual(challenges[i], testData[i]) {
+			t.Errorf("Challenge %d does not match.\nExpected: %+v\nGot: %+v", i, testData[i], challenges[i])
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, challengesDB)); err != nil {
+		t.Errorf("Expected %s to be created: %v", challengesDB, err)
+	}
+
+	// Saving goes to the database, not the JSON file
+	challenges = append(challenges, Challenge{Name: "day3_part1_2017", Answer: "3", Year: 2017})
+	if err := saveChallenges(challenges); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	reloaded, err := loadChallenges(tempDir, challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to reload challenges: %v", err)
+	}
+	if len(reloaded) != 4 || reloaded[3].Name != "day3_part1_2017" {
+		t.Errorf("Saved challenges were not reloaded from SQLite: %+v", reloaded)
+	}
+
+	jsonData, _ := os.ReadFile(filepath.Join(tempDir, challengesFile))
+	if string(jsonData) != string(data) {
+		t.Errorf("Expected challenges.json to be left untouched")
+	}
+}
+
+func TestSQLiteIndexedLookup(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	saveChallenges = defaultSaveChallenges
+
+	if err := saveConfig(Config{Storage: "sqlite"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	err := saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", Answer: "280", SolutionLang: "go"},
+		{Name: "day1_part2_2015", Answer: "1797", SolutionLang: "go"},
+		{Name: "day1_part2_2015", Answer: "1797", SolutionLang: "rust"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	challenge, err := findStoredChallenge(Flags{Day: 1, Part: 2, Year: 2015})
+	if err != nil {
+		t.Fatalf("Failed to find challenge: %v", err)
+	}
+	if challenge.Name != "day1_part2_2015" || challenge.Answer != "1797" {
+		t.Errorf("Found wrong challenge: %+v", challenge)
+	}
+
+	challenge, found, err := lookupChallengeSQLite(tempDir, 2015, 1, 2, "rust")
+	if err != nil || !found {
+		t.Fatalf("Failed to look up rust challenge: found=%v err=%v", found, err)
+	}
+	if challenge.SolutionLang != "rust" {
+		t.Errorf("Expected rust solution, got %q", challenge.SolutionLang)
+	}
+
+	if _, err := findStoredChallenge(Flags{Day: 9, Part: 1, Year: 2015}); err == nil {
+		t.Errorf("Expected error for missing challenge")
+	}
+}
+
+func TestSQLiteSaveWritesOnlyChanges(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	saveChallenges = defaultSaveChallenges
+
+	if err := saveConfig(Config{Storage: "sqlite"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	challenges := []Challenge{
+		{Name: "day1_part1_2015", Answer: "280", SolutionLang: "go", Solution: "package main"},
+		{Name: "day1_part2_2015", Answer: "1797"},
+		{Name: "day2_part1_2015", Answer: "1606483"},
+		{Name: "day3_part1_2015", Answer: "2565"},
+	}
+	if err := saveChallenges(challenges); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	// Log every write to the table
+	db, err := sql.Open("sqlite3", filepath.Join(tempDir, challengesDB))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE writes (op TEXT, name TEXT)",
+		"CREATE TRIGGER log_insert AFTER INSERT ON challenges BEGIN INSERT INTO writes VALUES ('insert', new.name); END",
+		"CREATE TRIGGER log_update AFTER UPDATE ON challenges BEGIN INSERT INTO writes VALUES ('update', new.name); END",
+		"CREATE TRIGGER log_delete AFTER DELETE ON challenges BEGIN INSERT INTO writes VALUES ('delete', old.name); END",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up logging: %v", err)
+		}
+	}
+
+	challenges[1].SolutionLang, challenges[1].Solution = "python", "print(1797)"
+	challenges[2].Answer = "1606484"
+	challenges = append(challenges[:3], Challenge{Name: "day1_part1_2015", Answer: "280", SolutionLang: "rust", Solution: "fn main() {}"})
+	if err := saveChallenges(challenges); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	var writes []string
+	rows, err := db.Query("SELECT op || ' ' || name FROM writes ORDER BY rowid")
+	if err != nil {
+		t.Fatalf("Failed to read writes: %v", err)
+	}
+	for rows.Next() {
+		var write string
+		rows.Scan(&write)
+		writes = append(writes, write)
+	}
+	rows.Close()
+	want := []string{"delete day3_part1_2015", "update day1_part2_2015", "update day2_part1_2015", "insert day1_part1_2015"}
+	if !reflect.DeepEqual(writes, want) {
+		t.Errorf("Expected only the changed records to be written, got %v", writes)
+	}
+
+	loaded, err := loadChallenges(tempDir, challengesFile)
+	if err != nil {
+		t.Fatalf("Failed to load challenges: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, challenges) {
+		t.Errorf("Expected the saved challenges in order, got %+v", loaded)
+	}
+}
+
+func TestSQLiteMissingStoreIsNotExist(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := saveConfig(Config{Storage: "sqlite"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	_, err := loadChallenges(tempDir, challengesFile)
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error for empty cache, got: %v", err)
+	}
+}
+
+func TestParseChallengeName(t *testing.T) {
+	day, part, year, ok := parseChallengeName("day13_part2_2019")
+	if !ok || day != 13 || part != 2 || year != 2019 {
+		t.Errorf("Unexpected parse result: %d %d %d %v", day, part, year, ok)
+	}
+	if _, _, _, ok := parseChallengeName("not_a_challenge"); ok {
+		t.Errorf("Expected invalid name to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package aocgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// memoryStore is a ChallengeStore that keeps challenges in memory.
+type memoryStore struct {
+	challenges *[]Challenge
+}
+
+func (s memoryStore) Load() ([]Challenge, error) {
+	if *s.challenges == nil {
+		return nil, os.ErrNotExist
+	}
+	return append([]Challenge(nil), *s.challenges...), nil
+}
+
+func (s memoryStore) Save(challenges []Challenge) error {
+	*s.challenges = append([]Challenge{}, challenges...)
+	return nil
+}
+
+func TestRegisterStoreBackend(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	saveChallenges = defaultSaveChallenges
+
+	var stored []Challenge
+	RegisterStoreBackend("memory", func(dir string) ChallengeStore { return memoryStore{&stored} })
+	defer delete(storeBackends, "memory")
+
+	if err := saveConfig(Config{Storage: "memory"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	if _, err := loadChallenges(tempDir, challengesFile); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error from an empty store, got %v", err)
+	}
+	if challenges, err := (Store{}).Load(); err != nil || len(challenges) != 0 {
+		t.Errorf("Expected an empty Store, got %v, %v", challenges, err)
+	}
+
+	if err := saveChallenges([]Challenge{{Name: "day1_part1_2015", Answer: "1"}}); err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Name != "day1_part1_2015" {
+		t.Errorf("Expected the challenge to be saved to the registered store, got %+v", stored)
+	}
+
+	challenges, err := loadChallenges(tempDir, challengesFile)
+	if err != nil || len(challenges) != 1 || challenges[0].Answer != "1" {
+		t.Errorf("Expected to load the saved challenge, got %+v, %v", challenges, err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, challengesFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no %s to be written", challengesFile)
+	}
+
+	if _, err := (Store{Backend: "missing"}).Load(); err == nil {
+		t.Errorf("Expected an error for an unknown backend")
+	}
+}
@@ -0,0 +1,96 @@
+package aocgen
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// streamOutput receives tokens as they arrive from a streaming provider.
+var streamOutput io.Writer = os.Stderr
+
+// readStreamedCompletion assembles a streamed completion from body, echoing
+// each chunk to w. It understands both OpenAI-style server-sent events
+// ("data: {...}" lines terminated by "data: [DONE]") and Ollama's native
+// newline-delimited JSON chunks. Token usage is taken from whichever chunk
+// reports it, normally the last one.
+func readStreamedCompletion(body io.Reader, w io.Writer) (string, Usage, error) {
+	var content strings.Builder
+	var usage Usage
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if line == "[DONE]" {
+			break
+		}
+
+		var chunk map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return "", Usage{}, fmt.Errorf("error unmarshaling stream chunk: %v", err)
+		}
+
+		if errObj, ok := chunk["error"]; ok {
+			return "", Usage{}, fmt.Errorf("API error: %v", errObj)
+		}
+
+		if chunkUsage := parseUsage(chunk); chunkUsage != (Usage{}) {
+			usage = chunkUsage
+		}
+
+		token := streamChunkContent(chunk)
+		if token != "" {
+			content.WriteString(token)
+			fmt.Fprint(w, token)
+		}
+
+		if done, ok := chunk["done"].(bool); ok && done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", Usage{}, err
+	}
+
+	fmt.Fprintln(w)
+	return content.String(), usage, nil
+}
+
+// streamChunkContent extracts the text carried by a single stream chunk.
+func streamChunkContent(chunk map[string]interface{}) string {
+	// Ollama /api/generate
+	if response, ok := chunk["response"].(string); ok {
+		return response
+	}
+
+	// Ollama /api/chat
+	if message, ok := chunk["message"].(map[string]interface{}); ok {
+		if content, ok := message["content"].(string); ok {
+			return content
+		}
+	}
+
+	// OpenAI-compatible chat completions
+	choices, ok := chunk["choices"].([]interface{})
+	if !ok || len(choices) == 0 {
+		return ""
+	}
+	firstChoice, ok := choices[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	delta, ok := firstChoice["delta"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	content, _ := delta["content"].(string)
+	return content
+}
@@ -0,0 +1,97 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadStreamedCompletionSSE(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+		``,
+		`data: {"choices":[{"delta":{"content":"` + "```" + `python\n"}}]}`,
+		`data: {"choices":[{"delta":{"content":"print(42)\n"}}]}`,
+		`data: {"choices":[{"delta":{"content":"` + "```" + `"}}]}`,
+		`data: [DONE]`,
+	}, "\n")
+
+	var progress bytes.Buffer
+	content, _, err := readStreamedCompletion(strings.NewReader(body), &progress)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	expected := "```python\nprint(42)\n```"
+	if content != expected {
+		t.Errorf("Unexpected content.\nExpected: %q\nGot: %q", expected, content)
+	}
+	if !strings.Contains(progress.String(), "print(42)") {
+		t.Errorf("Expected progress output to contain streamed tokens, got: %q", progress.String())
+	}
+}
+
+func TestReadStreamedCompletionOllamaNDJSON(t *testing.T) {
+	body := `{"message":{"role":"assistant","content":"Hello"},"done":false}
+{"message":{"role":"assistant","content":", world"},"done":false}
+{"message":{"role":"assistant","content":""},"done":true}
+{"message":{"role":"assistant","content":"ignored"},"done":false}
+`
+	content, _, err := readStreamedCompletion(strings.NewReader(body), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	if content != "Hello, world" {
+		t.Errorf("Expected %q, got %q", "Hello, world", content)
+	}
+}
+
+func TestGenerateCodeWithAIStreaming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requestBody map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if requestBody["stream"] != true {
+			t.Errorf("Expected stream to be true, got: %v", requestBody["stream"])
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"```go\\n", "package main\\n", "```"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":\"%s\"}}]}\n\n", token)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var progress bytes.Buffer
+	oldStreamOutput := streamOutput
+	streamOutput = &progress
+	defer func() { streamOutput = oldStreamOutput }()
+
+	for _, model := range []string{"gpt-4o-mini", "ollama/llama3"} {
+		t.Run(model, func(t *testing.T) {
+			progress.Reset()
+			code, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{
+				Lang:     "go",
+				Model:    model,
+				ModelAPI: server.URL,
+				Stream:   true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to generate code with AI: %v", err)
+			}
+			if code != "package main" {
+				t.Errorf("Unexpected code: %q", code)
+			}
+			if !strings.Contains(progress.String(), "package main") {
+				t.Errorf("Expected streamed tokens on progress output, got: %q", progress.String())
+			}
+		})
+	}
+}
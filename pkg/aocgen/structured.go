@@ -0,0 +1,96 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// structuredInstruction is added to the system prompt with --structured.
+// Providers that only guarantee valid JSON, not a schema, rely on it for the
+// shape of the reply.
+const structuredInstruction = `Respond with a JSON object with two fields: "language", the programming language of the solution, and "code", the complete program. Do not wrap the code in a markdown code block.`
+
+// StructuredSolution is the reply the model gives with --structured.
+type StructuredSolution struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// solutionSchema is the JSON schema of StructuredSolution.
+var solutionSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"language": map[string]interface{}{"type": "string"},
+		"code":     map[string]interface{}{"type": "string"},
+	},
+	"required":             []string{"language", "code"},
+	"additionalProperties": false,
+}
+
+// setResponseFormat asks an OpenAI-style chat completion for a reply that
+// follows solutionSchema, or with schema false for any JSON object, which is
+// all some providers support.
+func setResponseFormat(payload map[string]interface{}, schema bool) {
+	if !schema {
+		payload["response_format"] = map[string]interface{}{"type": "json_object"}
+		return
+	}
+	payload["response_format"] = map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   "solution",
+			"strict": true,
+			"schema": solutionSchema,
+		},
+	}
+}
+
+// structuredSystemPrompt returns the system prompt of a structured request.
+func structuredSystemPrompt(system string) string {
+	if system == "" {
+		return structuredInstruction
+	}
+	return system + "\n\n" + structuredInstruction
+}
+
+// extractStructuredCode returns the code of a structured reply. Models that
+// wrap the JSON in a code block anyway are tolerated.
+func extractStructuredCode(response, lang string) (string, error) {
+	text := strings.TrimSpace(response)
+	if i := strings.Index(text, "\n"); i >= 0 && strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		text = strings.TrimSpace(strings.TrimSuffix(text[i+1:], "```"))
+	}
+
+	var solution StructuredSolution
+	if err := json.Unmarshal([]byte(text), &solution); err != nil {
+		return "", fmt.Errorf("error parsing structured response: %v", err)
+	}
+	code := strings.TrimSpace(solution.Code)
+	if code == "" {
+		return "", fmt.Errorf("extracted code is empty")
+	}
+	if lang != "" && solution.Language != "" && !strings.EqualFold(solution.Language, lang) {
+		logger.Warn(fmt.Sprintf("model says the solution is in %s, not %s", solution.Language, lang))
+	}
+	return code, nil
+}
+
+// extractSolution returns the code in the model's reply: the "code" field of
+// a structured reply, or the first code block. A structured reply that is
+// not valid JSON falls back to the code block, since some models ignore the
+// response format.
+func extractSolution(response string, flags Flags) (string, error) {
+	if !flags.Structured {
+		return extractCode(response, flags.Lang)
+	}
+	code, err := extractStructuredCode(response, flags.Lang)
+	if err != nil {
+		if fenced, fencedErr := extractCode(response, flags.Lang); fencedErr == nil {
+			logger.Warn("model did not reply with the structured format, using its code block", "err", err)
+			return fenced, nil
+		}
+		return "", err
+	}
+	return code, nil
+}
@@ -0,0 +1,94 @@
+package aocgen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractStructuredCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantErr  bool
+	}{
+		{"json", `{"language": "python", "code": "print(1)\n"}`, "print(1)", false},
+		{"fenced json", "```json\n{\"language\": \"python\", \"code\": \"print(1)\"}\n```", "print(1)", false},
+		{"code with fences", `{"language": "python", "code": "s = '''\n` + "```" + `\n'''\nprint(s)"}`, "s = '''\n```\n'''\nprint(s)", false},
+		{"empty code", `{"language": "python", "code": " "}`, "", true},
+		{"not json", "Here is the code:\n```python\nprint(1)\n```", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractStructuredCode(tt.response, "python")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("extractStructuredCode() = %q, %v, want %q (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	// Models ignoring the response format still get their code block used
+	code, err := extractSolution("Here is the code:\n```python\nprint(1)\n```", Flags{Lang: "python", Structured: true})
+	if err != nil || code != "print(1)" {
+		t.Errorf("Expected the code block as a fallback, got %q, %v", code, err)
+	}
+}
+
+func TestCompleteCodeStructured(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	t.Setenv("GROQ_API_KEY", "test-key")
+
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload = nil
+		json.NewDecoder(r.Body).Decode(&payload)
+		reply, _ := json.Marshal(StructuredSolution{Language: "python", Code: "print('```')"})
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": string(reply)}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	flags := Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL, Structured: true}
+	transcript, err := completeCode(context.Background(), "prompt", flags)
+	if err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	if transcript.Code != "print('```')" || !transcript.Structured || !strings.Contains(transcript.System, structuredInstruction) {
+		t.Errorf("Unexpected transcript: %+v", transcript)
+	}
+	format, _ := payload["response_format"].(map[string]interface{})
+	if format["type"] != "json_schema" {
+		t.Errorf("Expected a JSON schema response format, got %v", payload["response_format"])
+	}
+
+	// Groq only gets JSON mode
+	flags.Model = "groq/llama3-70b-8192"
+	if _, err := completeCode(context.Background(), "prompt", flags); err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	format, _ = payload["response_format"].(map[string]interface{})
+	if format["type"] != "json_object" {
+		t.Errorf("Expected JSON mode for Groq, got %v", payload["response_format"])
+	}
+
+	flags.Model = "ollama/llama3"
+	if _, err := completeCode(context.Background(), "prompt", flags); err != nil {
+		t.Fatalf("completeCode failed: %v", err)
+	}
+	if _, ok := payload["format"].(map[string]interface{}); !ok {
+		t.Errorf("Expected the schema as the native Ollama format, got %v", payload["format"])
+	}
+
+	flags.Model = "bedrock/anthropic.claude-3-haiku"
+	if _, err := completeCode(context.Background(), "prompt", flags); err == nil {
+		t.Errorf("Expected an error for Bedrock")
+	}
+}
@@ -0,0 +1,218 @@
+package aocgen
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SubmitOutcome classifies the Advent of Code response to an answer.
+type SubmitOutcome string
+
+const (
+	SubmitCorrect       SubmitOutcome = "correct"
+	SubmitWrong         SubmitOutcome = "wrong"
+	SubmitCooldown      SubmitOutcome = "cooldown"
+	SubmitAlreadySolved SubmitOutcome = "already solved"
+	SubmitUnknown       SubmitOutcome = "unknown"
+)
+
+// SubmitResult is the parsed response to a submitted answer.
+type SubmitResult struct {
+	Outcome SubmitOutcome
+	// Wait is how long Advent of Code asks us to wait before the next
+	// submission, if it says so.
+	Wait    time.Duration
+	Message string
+}
+
+// maxSubmitRetries bounds how many cooldowns --retry will sit through.
+const maxSubmitRetries = 3
+
+// minSubmitCooldown is how long --retry waits when Advent of Code does not
+// say how long the cooldown is, so the answer is never resubmitted at once.
+const minSubmitCooldown = time.Minute
+
+var (
+	submitArticleRe = regexp.MustCompile(`(?s)<article>(.*?)</article>`)
+	submitWaitRe    = regexp.MustCompile(`(?:(\d+)h\s*)?(?:(\d+)m\s*)?(?:(\d+)s\s*)?left to wait`)
+	submitMinutesRe = regexp.MustCompile(`wait (one|two|five|ten|\d+) minutes? before trying again`)
+)
+
+var minuteWords = map[string]int{"one": 1, "two": 2, "five": 5, "ten": 10}
+
+// cooldownSleep is replaced in tests to avoid real waiting.
+var cooldownSleep = time.Sleep
+
+// parseSubmitResponse extracts the outcome and any requested wait time from
+// the answer page returned by Advent of Code.
+func parseSubmitResponse(body string) SubmitResult {
+	message := body
+	if m := submitArticleRe.FindStringSubmatch(body); m != nil {
+		message = m[1]
+	}
+	message = strings.TrimSpace(html.UnescapeString(stripTags(message)))
+	message = strings.Join(strings.Fields(message), " ")
+
+	result := SubmitResult{Outcome: SubmitUnknown, Message: message}
+	switch {
+	case strings.Contains(message, "That's the right answer"):
+		result.Outcome = SubmitCorrect
+	case strings.Contains(message, "That's not the right answer"):
+		result.Outcome = SubmitWrong
+	case strings.Contains(message, "You gave an answer too recently"):
+		result.Outcome = SubmitCooldown
+	case strings.Contains(message, "You don't seem to be solving the right level"):
+		result.Outcome = SubmitAlreadySolved
+	}
+
+	if m := submitWaitRe.FindStringSubmatch(message); m != nil && m[0] != "left to wait" {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		seconds, _ := strconv.Atoi(m[3])
+		result.Wait = time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	} else if m := submitMinutesRe.FindStringSubmatch(message); m != nil {
+		minutes, ok := minuteWords[m[1]]
+		if !ok {
+			minutes, _ = strconv.Atoi(m[1])
+		}
+		result.Wait = time.Duration(minutes) * time.Minute
+	}
+
+	return result
+}
+
+// submitAnswer posts an answer for the challenge selected by flags.
+func submitAnswer(flags Flags, answer string) (SubmitResult, error) {
+	form := url.Values{}
+	form.Set("level", strconv.Itoa(flags.Part))
+	form.Set("answer", answer)
+
+	ctx, cancel := withHTTPTimeout(commandContext, flags.HTTPTimeout)
+	defer cancel()
+
+	submitURL := fmt.Sprintf("%s/%d/day/%d/answer", aocBaseURL, flags.Year, flags.Day)
+	req, err := newAoCRequest(ctx, "POST", submitURL, flags.Session, strings.NewReader(form.Encode()))
+	if err != nil {
+		return SubmitResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := doAoCRequest(http.DefaultClient, req)
+	if err != nil {
+		return SubmitResult{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return SubmitResult{}, fmt.Errorf("failed to submit answer: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SubmitResult{}, httpError(ctx, flags.HTTPTimeout, err)
+	}
+
+	return parseSubmitResponse(string(body)), nil
+}
+
+// recordSubmittedAnswer stores an answer Advent of Code accepted in the
+// stored records of the challenge that have your input, which is downloaded
+// again to tell them from records with other inputs.
+func recordSubmittedAnswer(flags Flags, answer string) {
+	name := fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if os.IsNotExist(err) || (err == nil && lookupChallenge(challenges, name) == nil) {
+		return
+	}
+	if err != nil {
+		logger.Warn("failed to record answer", "err", err)
+		return
+	}
+
+	ctx, cancel := withHTTPTimeout(commandContext, flags.HTTPTimeout)
+	defer cancel()
+	input, err := fetchPuzzleInput(ctx, http.DefaultClient, flags)
+	if err != nil {
+		logger.Warn("failed to download the input of the answer", "err", err)
+		return
+	}
+	if n, err := recordAnswer(name, input, answer, true); err != nil {
+		logger.Warn("failed to record answer", "err", err)
+	} else if n > 0 {
+		fmt.Printf("Stored the answer, eval can now check solutions of %s with your input in any language.\n", name)
+	}
+}
+
+// waitForCooldown prints a countdown to w and returns once d has passed.
+func waitForCooldown(w io.Writer, d time.Duration) {
+	for remaining := d.Round(time.Second); remaining > 0; remaining -= time.Second {
+		fmt.Fprintf(w, "\rCooldown: %v left  ", remaining)
+		cooldownSleep(time.Second)
+	}
+	fmt.Fprintln(w, "\rCooldown finished, retrying.   ")
+}
+
+func runSubmitCommand(flags Flags) error {
+	if flags.Session == "" {
+		return fmt.Errorf("session token is required")
+	}
+	if flags.Day == 0 || flags.Year == 0 {
+		return fmt.Errorf("day and year are required")
+	}
+	if flags.Part == 0 {
+		flags.Part = 1
+	}
+
+	answer := strings.TrimSpace(flags.Answer)
+	if answer == "" {
+		return fmt.Errorf("answer is required")
+	}
+
+	if err := verifyCommandSession(commandContext, flags); err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		result, err := submitAnswer(flags, answer)
+		if err != nil {
+			return err
+		}
+
+		switch result.Outcome {
+		case SubmitCorrect:
+			fmt.Printf("That's the right answer for day %d part %d of %d!\n", flags.Day, flags.Part, flags.Year)
+			recordSubmittedAnswer(flags, answer)
+			return nil
+		case SubmitWrong:
+			fmt.Printf("That's not the right answer.\n%s\n", result.Message)
+			// The message is already printed, so like a wrong answer of
+			// eval it only sets the exit status
+			return &exitError{code: VerdictWrongAnswer.ExitCode()}
+		case SubmitAlreadySolved:
+			fmt.Printf("Day %d part %d of %d is already solved or not unlocked yet.\n", flags.Day, flags.Part, flags.Year)
+			return nil
+		case SubmitCooldown:
+			if !flags.Retry {
+				return fmt.Errorf("answer submitted too recently, %v left to wait (use --retry to wait and resubmit automatically)", result.Wait)
+			}
+			if attempt >= maxSubmitRetries {
+				return fmt.Errorf("still in cooldown after %d retries", maxSubmitRetries)
+			}
+			wait := result.Wait
+			if wait == 0 {
+				wait = minSubmitCooldown
+			}
+			fmt.Printf("You gave an answer too recently, waiting %v before retrying.\n", wait)
+			waitForCooldown(os.Stderr, wait)
+		default:
+			return fmt.Errorf("unexpected response from Advent of Code: %s", result.Message)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package aocgen
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSubmitResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		outcome SubmitOutcome
+		wait    time.Duration
+	}{
+		{
+			name:    "correct",
+			body:    `<main><article><p>That's the right answer!  You are <em>one gold star</em> closer.</p></article></main>`,
+			outcome: SubmitCorrect,
+		},
+		{
+			name:    "wrong",
+			body:    `<article><p>That's not the right answer; your answer is too low.  Please wait one minute before trying again.</p></article>`,
+			outcome: SubmitWrong,
+			wait:    time.Minute,
+		},
+		{
+			name:    "cooldown seconds",
+			body:    `<article><p>You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 37s left to wait.</p></article>`,
+			outcome: SubmitCooldown,
+			wait:    37 * time.Second,
+		},
+		{
+			name:    "cooldown minutes",
+			body:    `<article><p>You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 4m 2s left to wait.</p></article>`,
+			outcome: SubmitCooldown,
+			wait:    4*time.Minute + 2*time.Second,
+		},
+		{
+			name:    "already solved",
+			body:    `<article><p>You don't seem to be solving the right level.  Did you already complete it?</p></article>`,
+			outcome: SubmitAlreadySolved,
+		},
+		{
+			name:    "unknown",
+			body:    `<html>Something else</html>`,
+			outcome: SubmitUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseSubmitResponse(tt.body)
+			if result.Outcome != tt.outcome {
+				t.Errorf("Expected outcome %q, got %q (message %q)", tt.outcome, result.Outcome, result.Message)
+			}
+			if result.Wait != tt.wait {
+				t.Errorf("Expected wait %v, got %v", tt.wait, result.Wait)
+			}
+		})
+	}
+}
+
+func TestRunSubmitCommandRetriesAfterCooldown(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	requests := 0
+	unparsed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/settings" {
+			return
+		}
+		if r.Method != "POST" || r.URL.Path != "/2023/day/3/answer" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		if r.Form.Get("level") != "2" || r.Form.Get("answer") != "4361" {
+			t.Errorf("Unexpected form values: %v", r.Form)
+		}
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "test_session" {
+			t.Errorf("Expected session cookie, got %v", cookie)
+		}
+
+		requests++
+		if requests == 1 && unparsed {
+			fmt.Fprint(w, `<article><p>You gave an answer too recently; you have to wait after submitting an answer before trying again.</p></article>`)
+			return
+		}
+		if requests == 1 {
+			fmt.Fprint(w, `<article><p>You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 3s left to wait.</p></article>`)
+			return
+		}
+		fmt.Fprint(w, `<article><p>That's the right answer!</p></article>`)
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	var slept time.Duration
+	originalSleep := cooldownSleep
+	cooldownSleep = func(d time.Duration) { slept += d }
+	defer func() { cooldownSleep = originalSleep }()
+
+	flags := Flags{Day: 3, Part: 2, Year: 2023, Session: "test_session", Answer: "4361"}
+
+	if err := runSubmitCommand(flags); err == nil {
+		t.Fatalf("Expected cooldown error without --retry")
+	}
+
+	requests = 0
+	flags.Retry = true
+	if err := runSubmitCommand(flags); err != nil {
+		t.Fatalf("Expected retry to succeed, got: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 submissions, got %d", requests)
+	}
+	if slept != 3*time.Second {
+		t.Errorf("Expected to wait out the 3s cooldown, waited %v", slept)
+	}
+
+	// A cooldown without a wait time is not retried at once
+	requests, slept = 0, 0
+	unparsed = true
+	if err := runSubmitCommand(flags); err != nil {
+		t.Fatalf("Expected retry to succeed, got: %v", err)
+	}
+	if slept != minSubmitCooldown {
+		t.Errorf("Expected to wait %v for a cooldown of unknown length, waited %v", minSubmitCooldown, slept)
+	}
+}
+
+func TestRunSubmitCommandWrongAnswer(t *testing.T) {
+	withoutAoCThrottle(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/settings" {
+			return
+		}
+		fmt.Fprint(w, `<article><p>That's not the right answer; your answer is too low.</p></article>`)
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	var err error
+	output := captureStdout(t, func() {
+		err = runSubmitCommand(Flags{Day: 3, Part: 1, Year: 2023, Session: "test_session", Answer: "42"})
+	})
+	if code := verdictExitCode(err); code != VerdictWrongAnswer.ExitCode() {
+		t.Errorf("Expected the exit code of a wrong answer, got %d (%v)", code, err)
+	}
+	if !strings.Contains(output, "your answer is too low") {
+		t.Errorf("Expected the reply of Advent of Code, got:\n%s", output)
+	}
+}
+
+func TestRunSubmitCommandRecordsAnswerForYourInput(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	withoutAoCThrottle(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/settings":
+		case "/2023/day/3/input":
+			fmt.Fprint(w, "mine\n")
+		default:
+			fmt.Fprint(w, `<article><p>That's the right answer!</p></article>`)
+		}
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	saveChallenges([]Challenge{
+		{Name: "day3_part2_2023", SolutionLang: "go", Input: "mine\n"},
+		{Name: "day3_part2_2023", SolutionLang: "python", Input: "theirs", Answer: "467835"},
+	})
+
+	if err := runSubmitCommand(Flags{Day: 3, Part: 2, Year: 2023, Session: "test_session", Answer: "4361"}); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	challenges, _ := loadChallenges(getCacheDir(), challengesFile)
+	if len(challenges) != 2 || challenges[0].Answer != "4361" || challenges[1].Answer != "467835" {
+		t.Errorf("Expected only the record with your input to get the answer, got %+v", challenges)
+	}
+}
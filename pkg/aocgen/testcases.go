@@ -0,0 +1,103 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readTestInput returns the input given with --input. A value starting with
+// @ names a file to read it from.
+func readTestInput(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+	if err != nil {
+		return "", fmt.Errorf("error reading test input: %v", err)
+	}
+	return string(data), nil
+}
+
+// testCaseChallenge returns the name of the challenge `aocgen test` works on.
+// The part defaults to 1.
+func testCaseChallenge(flags Flags) (string, error) {
+	if flags.Day == 0 || flags.Year == 0 {
+		return "", fmt.Errorf("--day and --year are required")
+	}
+	part := flags.Part
+	if part == 0 {
+		part = 1
+	}
+	return fmt.Sprintf("day%d_part%d_%d", flags.Day, part, flags.Year), nil
+}
+
+// runTestAddCommand attaches a test case to every stored record of a
+// challenge. eval runs it along with the real input.
+func runTestAddCommand(flags Flags, w io.Writer) error {
+	name, err := testCaseChallenge(flags)
+	if err != nil {
+		return err
+	}
+	if flags.Input == "" || flags.Expect == "" {
+		return fmt.Errorf("--input and --expect are required")
+	}
+	input, err := readTestInput(flags.Input)
+	if err != nil {
+		return err
+	}
+
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+	var count int
+	for i := range challenges {
+		if challenges[i].Name == name {
+			challenges[i].Tests = append(challenges[i].Tests, Example{Input: input, Answer: strings.TrimSpace(flags.Expect)})
+			count = len(challenges[i].Tests)
+		}
+	}
+	if count == 0 {
+		return fmt.Errorf("challenge not found: %s", name)
+	}
+	if err := saveChallenges(challenges); err != nil {
+		return fmt.Errorf("error saving challenges: %v", err)
+	}
+
+	fmt.Fprintf(w, "Added test case %d to %s.\n", count, name)
+	return nil
+}
+
+// runTestListCommand prints the test cases attached to a challenge.
+func runTestListCommand(flags Flags, w io.Writer) error {
+	name, err := testCaseChallenge(flags)
+	if err != nil {
+		return err
+	}
+	challenges, err := loadChallenges(getCacheDir(), challengesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error loading challenges: %v", err)
+	}
+	challenge := lookupChallenge(challenges, name)
+	if challenge == nil {
+		return fmt.Errorf("challenge not found: %s", name)
+	}
+
+	if flags.JSON {
+		tests := challenge.Tests
+		if tests == nil {
+			tests = []Example{}
+		}
+		return emitJSON(tests)
+	}
+	if len(challenge.Tests) == 0 {
+		fmt.Fprintf(w, "No test cases for %s. Add one with 'aocgen test add'.\n", name)
+		return nil
+	}
+	for i, test := range challenge.Tests {
+		fmt.Fprintf(w, "Test %d, expect %s:\n%s\n", i+1, test.Answer, strings.TrimRight(test.Input, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTestCases(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", Input: "10\n20\n", Answer: "30"},
+		{Name: "day1_part1_2015", Input: "10\n20\n", Answer: "30", SolutionLang: "go", Solution: "package main"},
+	})
+
+	inputFile := filepath.Join(tempDir, "case.txt")
+	os.WriteFile(inputFile, []byte("-5\n5\n"), 0644)
+
+	var out bytes.Buffer
+	if err := runTestAddCommand(Flags{Day: 1, Year: 2015, Input: "@" + inputFile, Expect: "0"}, &out); err != nil {
+		t.Fatalf("Failed to add test case: %v", err)
+	}
+	if err := runTestAddCommand(Flags{Day: 1, Year: 2015, Input: "1\n", Expect: " 1 "}, &out); err != nil {
+		t.Fatalf("Failed to add test case: %v", err)
+	}
+	if !strings.Contains(out.String(), "Added test case 2 to day1_part1_2015.") {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+
+	challenges, _ := loadChallenges(tempDir, challengesFile)
+	for _, c := range challenges {
+		if len(c.Tests) != 2 || c.Tests[0] != (Example{Input: "-5\n5\n", Answer: "0"}) || c.Tests[1].Answer != "1" {
+			t.Errorf("Expected the test cases on every record, got %+v", c.Tests)
+		}
+	}
+
+	out.Reset()
+	if err := runTestListCommand(Flags{Day: 1, Year: 2015}, &out); err != nil {
+		t.Fatalf("Failed to list test cases: %v", err)
+	}
+	if !strings.Contains(out.String(), "Test 1, expect 0:\n-5\n5\n") {
+		t.Errorf("Unexpected test case list:\n%s", out.String())
+	}
+
+	if err := runTestAddCommand(Flags{Day: 2, Year: 2015, Input: "x", Expect: "1"}, &out); err == nil {
+		t.Errorf("Expected an error for a missing challenge")
+	}
+	if err := runTestAddCommand(Flags{Day: 1, Year: 2015, Input: "x"}, &out); err == nil {
+		t.Errorf("Expected an error without --expect")
+	}
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Timeout: 5000}
+	for code, expected := range map[string]Verdict{
+		"print(sum(int(l) for l in open('input.txt')))":      VerdictCorrect,
+		"print(sum(abs(int(l)) for l in open('input.txt')))": VerdictWrongAnswer,
+	} {
+		os.WriteFile(testSolutionPath(t, "day1_part1_2015", "py"), []byte(code), 0644)
+		_, result, err := evaluateChallengeSolution(context.Background(), flags, nil)
+		if err != nil {
+			t.Fatalf("Failed to evaluate: %v", err)
+		}
+		if result.Verdict != expected {
+			t.Errorf("%s: expected %s, got %s: %s", code, expected, result.Verdict, result.Output)
+		}
+		if expected == VerdictWrongAnswer && (result.Case != "Test 1 of 2" || result.Expected != "0") {
+			t.Errorf("Expected the failing test case to be reported, got %+v", result)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package aocgen
+
+import (
+	"regexp"
+	"strings"
+)
+
+// puzzleTitleRe matches the "--- Day 13: Care Package ---" header of a task.
+var puzzleTitleRe = regexp.MustCompile(`--- Day \d+: (.+?) ---`)
+
+// puzzleTitle returns the title in the header of task, or "" if it has none.
+func puzzleTitle(task string) string {
+	m := puzzleTitleRe.FindStringSubmatch(task)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(m[1])
+}
+
+// challengeTitle returns the title of c. Records downloaded before titles
+// were stored, and those from the dataset, get it from their task.
+func challengeTitle(c Challenge) string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return puzzleTitle(c.Task)
+}
+
+// ChallengeStats is one challenge of the year `stats --year` reports on.
+type ChallengeStats struct {
+	Name      string   `json:"name"`
+	Title     string   `json:"title,omitempty"`
+	Languages []string `json:"languages"`
+	// Verdict is the verdict of the latest eval in any language, or empty
+	// if the challenge was never evaluated.
+	Verdict Verdict `json:"verdict,omitempty"`
+}
+
+// yearChallengeStats lists the challenges of year in name order with their
+// titles, the languages they are solved in and their latest eval verdict.
+func yearChallengeStats(challenges []Challenge, evals []EvalRecord, year int) []ChallengeStats {
+	latest := make(map[string]EvalRecord)
+	for _, e := range evals {
+		if prev, ok := latest[e.Challenge]; !ok || !e.Time.Before(prev.Time) {
+			latest[e.Challenge] = e
+		}
+	}
+
+	var rows []ChallengeStats
+	for _, entry := range listEntries(challenges) {
+		if _, _, y, ok := parseChallengeName(entry.Name); !ok || y != year {
+			continue
+		}
+		row := ChallengeStats{Name: entry.Name, Title: entry.Title, Languages: []string{}}
+		for _, lang := range entry.Languages {
+			if lang != "unsolved" {
+				row.Languages = append(row.Languages, lang)
+			}
+		}
+		if e, ok := latest[entry.Name]; ok {
+			row.Verdict = e.Verdict
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
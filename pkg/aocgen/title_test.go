@@ -0,0 +1,89 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPuzzleTitle(t *testing.T) {
+	tests := map[string]string{
+		"## --- Day 13: Care Package ---\n\nAs you ponder...": "Care Package",
+		"--- Day 1: Not Quite Lisp ---":                       "Not Quite Lisp",
+		"--- Part Two ---\n\nNo header here.":                 "",
+	}
+	for task, want := range tests {
+		if got := puzzleTitle(task); got != want {
+			t.Errorf("puzzleTitle(%q) = %q, want %q", task, got, want)
+		}
+	}
+	if got := challengeTitle(Challenge{Title: "Stored", Task: "--- Day 1: Parsed ---"}); got != "Stored" {
+		t.Errorf("Expected the stored title first, got %q", got)
+	}
+}
+
+func TestDownloadStoresTitle(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/input") {
+			fmt.Fprint(w, "1\n")
+			return
+		}
+		fmt.Fprint(w, `<article class="day-desc"><h2>--- Day 13: Care Package ---</h2><p>Part one.</p></article>`)
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	if err := downloadChallenge(context.Background(), Flags{Day: 13, Part: 1, Year: 2019, Session: "test_session"}); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	challenges, _ := loadChallenges(getCacheDir(), challengesFile)
+	if c := lookupChallenge(challenges, "day13_part1_2019"); c == nil || c.Title != "Care Package" {
+		t.Errorf("Expected the title to be stored, got %+v", c)
+	}
+}
+
+func TestTitlesInListAndStats(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	saveChallenges([]Challenge{
+		{Name: "day13_part2_2019", Title: "Care Package", SolutionLang: "go", Solution: "package main"},
+		// Records from the dataset only have the header in their task
+		{Name: "day1_part1_2019", Task: "--- Day 1: The Tyranny of the Rocket Equation ---"},
+		{Name: "day1_part1_2020", Title: "Report Repair"},
+	})
+	recordEval(Challenge{Name: "day13_part2_2019"}, "go", "", EvalResult{Verdict: VerdictCorrect})
+
+	var entries []ListEntry
+	data := captureJSON(t, func() error { return runListCommand(Flags{JSON: true, Year: 2019}) })
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if len(entries) != 2 || entries[0].Title != "Care Package" || entries[1].Title != "The Tyranny of the Rocket Equation" {
+		t.Errorf("Expected titles in the list, got %+v", entries)
+	}
+
+	var out bytes.Buffer
+	if err := runStatsCommand(Flags{Format: "table", Year: 2019}, &out); err != nil {
+		t.Fatalf("Failed to print stats: %v", err)
+	}
+	for _, expected := range []string{"Care Package", "correct", "The Tyranny of the Rocket Equation"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected stats to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Report Repair") {
+		t.Errorf("Expected only the challenges of --year, got:\n%s", out.String())
+	}
+}
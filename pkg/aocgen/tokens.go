@@ -0,0 +1,47 @@
+package aocgen
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenPieceRe splits text the way tiktoken's cl100k_base encoding does
+// before applying byte pair merges: contractions, words with one leading
+// space or symbol, numbers of up to three digits, runs of symbols and runs of
+// whitespace. RE2 has no lookahead, so whitespace before a word is kept as a
+// separate piece.
+var tokenPieceRe = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// countTokens approximates the number of tokens text takes in the cl100k_base
+// encoding of OpenAI models without its 100k-entry vocabulary: every piece
+// of the pre-tokenization is one token, except that long words and symbol
+// runs, which the vocabulary rarely holds whole, take one token per few
+// characters. The byte pair merges are not applied, so the count is not
+// tiktoken's, only close enough for a cost estimate, also for the models of
+// other providers, whose tokenizers are of a similar size.
+func countTokens(text string) int {
+	tokens := 0
+	for _, piece := range tokenPieceRe.FindAllString(text, -1) {
+		first, _ := utf8.DecodeRuneInString(piece)
+		last, _ := utf8.DecodeLastRuneInString(piece)
+		n := utf8.RuneCountInString(piece)
+		switch {
+		case len(piece) > n:
+			// Non-ASCII text is merged from its UTF-8 bytes
+			tokens += (len(piece) + 2) / 3
+		case unicode.IsLetter(last) && n <= 8:
+			tokens++
+		case unicode.IsLetter(last):
+			tokens += 1 + (n-5)/4
+		case unicode.IsDigit(first), strings.TrimSpace(piece) == "":
+			// Numbers are split into up to three digits, and indentation
+			// and blank lines are single tokens
+			tokens++
+		default:
+			tokens += (n + 2) / 3
+		}
+	}
+	return tokens
+}
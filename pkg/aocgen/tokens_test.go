@@ -0,0 +1,23 @@
+package aocgen
+
+import "testing"
+
+func TestCountTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Hello, world!", 4},
+		{"The answer is 12345.", 7},
+		{"def solve():\n    return 42\n", 9},
+		{"    if x:\n        pass", 6},
+		{"supercalifragilistic", 4},
+		{"naïve", 2},
+	}
+	for _, tt := range tests {
+		if got := countTokens(tt.text); got != tt.want {
+			t.Errorf("countTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// languageToolchain describes how to build and run solutions in one language.
+//
+// Commands are templates; each argument may contain the placeholders
+//
+//	{file}  path of the solution source
+//	{dir}   temporary build directory
+//	{bin}   path of the executable produced by Build
+//	{entry} entry class or module name (see Entry), by default the base
+//	        name of the solution file
+type languageToolchain struct {
+	// Build compiles the solution inside the build directory. It is empty for
+	// languages that run straight from source.
+	Build []string
+	// Run executes the solution from the current working directory.
+	Run []string
+	// Source, if set, is the file name the solution must have for the compiler
+	// (e.g. Java's public class rule). The source is copied into the build
+	// directory under this name and {file} refers to the copy.
+	Source string
+	// Entry extracts {entry} from the source code; the first submatch wins.
+	Entry []*regexp.Regexp
+	// Version, if set, prints the version of the toolchain.
+	Version []string
+}
+
+var toolchains = map[string]languageToolchain{
+	"go":           {Build: []string{"go", "build", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"python":       {Run: []string{"python", "{file}"}},
+	"javascript":   {Run: []string{"node", "{file}"}},
+	"typescript":   {Run: []string{"npx", "--yes", "tsx", "{file}"}},
+	"java":         {Build: []string{"javac", "-d", "{dir}", "{file}"}, Run: []string{"java", "-cp", "{dir}", "{entry}"}, Source: "{entry}.java", Entry: []*regexp.Regexp{regexp.MustCompile(`public\s+(?:final\s+)?class\s+(\w+)`), regexp.MustCompile(`class\s+(\w+)`)}},
+	"scala":        {Run: []string{"scala", "{file}"}},
+	"kotlin":       {Build: []string{"kotlinc", "{file}", "-include-runtime", "-d", "{dir}/solution.jar"}, Run: []string{"java", "-jar", "{dir}/solution.jar"}},
+	"groovy":       {Run: []string{"groovy", "{file}"}},
+	"clojure":      {Run: []string{"clojure", "-M", "{file}"}},
+	"csharp":       {Run: []string{"dotnet", "script", "{file}"}},
+	"fsharp":       {Run: []string{"dotnet", "fsi", "--quiet", "--exec", "{file}"}},
+	"swift":        {Build: []string{"swiftc", "-O", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"objectivec":   {Build: []string{"clang", "-fobjc-arc", "-framework", "Foundation", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"r":            {Run: []string{"Rscript", "{file}"}},
+	"haskell":      {Build: []string{"ghc", "-O2", "-outputdir", "{dir}", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"ocaml":        {Build: []string{"ocamlfind", "ocamlopt", "-package", "str,unix", "-linkpkg", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}, Source: "solution.ml"},
+	"racket":       {Run: []string{"racket", "{file}"}},
+	"scheme":       {Run: []string{"scheme", "--script", "{file}"}},
+	"ruby":         {Run: []string{"ruby", "{file}"}},
+	"erlang":       {Build: []string{"erlc", "-o", "{dir}", "{file}"}, Run: []string{"erl", "-noshell", "-pa", "{dir}", "-s", "{entry}", "main", "-s", "init", "stop"}, Source: "{entry}.erl", Entry: []*regexp.Regexp{regexp.MustCompile(`-module\((\w+)\)`)}},
+	"elixir":       {Run: []string{"elixir", "{file}"}},
+	"rust":         {Build: []string{"rustc", "--edition", "2021", "-O", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"c":            {Build: []string{"gcc", "-O2", "-o", "{bin}", "{file}", "-lm"}, Run: []string{"{bin}"}},
+	"cpp":          {Build: []string{"g++", "-std=c++17", "-O2", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"zig":          {Build: []string{"zig", "build-exe", "-O", "ReleaseSafe", "-femit-bin={bin}", "{file}"}, Run: []string{"{bin}"}},
+	"fortran90":    {Build: []string{"gfortran", "-O2", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"perl":         {Run: []string{"perl", "{file}"}},
+	"pascal":       {Build: []string{"fpc", "-O2", "-o{bin}", "{file}"}, Run: []string{"{bin}"}, Source: "solution.pas"},
+	"crystal":      {Build: []string{"crystal", "build", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"julia":        {Run: []string{"julia", "{file}"}},
+	"lua":          {Run: []string{"lua", "{file}"}},
+	"php":          {Run: []string{"php", "{file}"}},
+	"dart":         {Run: []string{"dart", "run", "{file}"}},
+	"bash":         {Run: []string{"bash", "{file}"}},
+	"awk":          {Run: []string{"awk", "-f", "{file}", "input.txt"}},
+	"nim":          {Build: []string{"nim", "compile", "--hints:off", "-d:release", "--nimcache:{dir}/cache", "--out:{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"d":            {Build: []string{"dmd", "-O", "-of{bin}", "-od{dir}", "{file}"}, Run: []string{"{bin}"}},
+	"v":            {Build: []string{"v", "-o", "{bin}", "{file}"}, Run: []string{"{bin}"}},
+	"prolog":       {Run: []string{"swipl", "-q", "-t", "halt", "{file}"}},
+	"tcl":          {Run: []string{"tclsh", "{file}"}},
+	"coffeescript": {Run: []string{"coffee", "{file}"}},
+}
+
+// MissingToolchainError reports that the program needed to build or run a
+// language is not installed.
+type MissingToolchainError struct {
+	Lang    string
+	Program string
+}
+
+func (e *MissingToolchainError) Error() string {
+	return fmt.Sprintf("%s toolchain not found: %q is not installed or not in PATH", e.Lang, e.Program)
+}
+
+// CompileError reports that a solution failed to build.
+type CompileError struct {
+	Output string
+	Err    error
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("compile error: %v", e.Err)
+}
+
+// checkToolchain verifies that every program lang needs is on the PATH.
+func checkToolchain(lang string) error {
+	tc, ok := toolchains[lang]
+	if !ok {
+		return fmt.Errorf("unsupported language: %s", lang)
+	}
+	for _, args := range [][]string{tc.Build, tc.Run} {
+		if len(args) == 0 || strings.HasPrefix(args[0], "{") {
+			continue
+		}
+		if _, err := osRunner.LookPath(args[0]); err != nil {
+			return &MissingToolchainError{Lang: lang, Program: args[0]}
+		}
+	}
+	return nil
+}
+
+// getCommand prepares filename for execution and returns the command that
+// runs it. Compiled languages are built into a temporary directory first;
+// the returned cleanup function removes it and must always be called.
+// Build failures are reported as *CompileError and missing compilers or
+// interpreters as *MissingToolchainError.
+func getCommand(lang, filename string) (*exec.Cmd, func(), error) {
+	noop := func() {}
+
+	tc, ok := toolchains[lang]
+	if !ok {
+		return nil, noop, fmt.Errorf("unsupported language: %s", lang)
+	}
+	if err := checkToolchain(lang); err != nil {
+		return nil, noop, err
+	}
+
+	file, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	vars := map[string]string{"{file}": file, "{entry}": findEntry(nil, "", file)}
+	if len(tc.Entry) > 0 {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return nil, noop, err
+		}
+		vars["{entry}"] = findEntry(tc.Entry, string(source), file)
+	}
+
+	if len(tc.Build) == 0 && tc.Source == "" {
+		return expandCommand(tc.Run, vars), noop, nil
+	}
+
+	dir, err := os.MkdirTemp("", "aocgen_build_")
+	if err != nil {
+		return nil, noop, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	vars["{dir}"] = dir
+	vars["{bin}"] = osRunner.Executable(filepath.Join(dir, "solution"))
+
+	if tc.Source != "" {
+		copyPath := filepath.Join(dir, expandArg(tc.Source, vars))
+		source, err := os.ReadFile(file)
+		if err == nil {
+			err = os.WriteFile(copyPath, source, 0644)
+		}
+		if err != nil {
+			cleanup()
+			return nil, noop, err
+		}
+		vars["{file}"] = copyPath
+	}
+
+	if len(tc.Build) > 0 {
+		if output, err := runBuild(expandCommand(tc.Build, vars), dir); err != nil {
+			cleanup()
+			return nil, noop, &CompileError{Output: output, Err: err}
+		}
+	}
+
+	return expandCommand(tc.Run, vars), cleanup, nil
+}
+
+// runBuild runs a build command inside dir so compiler artifacts stay there.
+func runBuild(cmd *exec.Cmd, dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(commandContext, compileTimeout)
+	defer cancel()
+
+	build := exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
+	build.Dir = dir
+	killGroupOnCancel(build)
+	output, err := combinedOutputTree(build)
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(output), fmt.Errorf("compilation timed out after %v", compileTimeout)
+	}
+	return string(output), err
+}
+
+func findEntry(patterns []*regexp.Regexp, source, file string) string {
+	for _, re := range patterns {
+		if m := re.FindStringSubmatch(source); m != nil {
+			return m[1]
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+func expandArg(arg string, vars map[string]string) string {
+	for k, v := range vars {
+		arg = strings.ReplaceAll(arg, k, v)
+	}
+	return arg
+}
+
+// expandCommand fills in the placeholders of template. The program is
+// resolved with osRunner, so it may differ from the one in the template.
+func expandCommand(template []string, vars map[string]string) *exec.Cmd {
+	args := make([]string, len(template))
+	for i, arg := range template {
+		args[i] = expandArg(arg, vars)
+	}
+	if !strings.HasPrefix(template[0], "{") {
+		if path, err := osRunner.LookPath(args[0]); err == nil {
+			args[0] = path
+		}
+	}
+	return exec.Command(args[0], args[1:]...)
+}
+
+// compileTimeout bounds the build step separately from the run timeout so
+// slow compilers don't eat into the solution's time budget.
+const compileTimeout = 2 * time.Minute
@@ -0,0 +1,149 @@
+package aocgen
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEveryLanguageHasToolchain(t *testing.T) {
+	for lang := range languageExtensions {
+		tc, ok := toolchains[lang]
+		if !ok {
+			t.Errorf("No toolchain defined for supported language %s", lang)
+			continue
+		}
+		if len(tc.Run) == 0 {
+			t.Errorf("Toolchain for %s has no run command", lang)
+		}
+	}
+	for lang := range toolchains {
+		if _, ok := languageExtensions[lang]; !ok {
+			t.Errorf("Toolchain defined for unknown language %s", lang)
+		}
+	}
+}
+
+func TestCompiledLanguages(t *testing.T) {
+	tests := []struct {
+		lang string
+		code string
+	}{
+		{lang: "c", code: "#include <stdio.h>\nint main() { printf(\"%d\\n\", 40 + 2); return 0; }\n"},
+		{lang: "cpp", code: "#include <iostream>\nint main() { std::cout << 40 + 2 << std::endl; }\n"},
+		{lang: "rust", code: "fn main() { println!(\"{}\", 40 + 2); }\n"},
+		{lang: "java", code: "public class Solution {\n  public static void main(String[] args) { System.out.println(40 + 2); }\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if err := checkToolchain(tt.lang); err != nil {
+				t.Skipf("Skipping: %v", err)
+			}
+
+			tmpDir := t.TempDir()
+			ext, _ := getFileExtension(tt.lang)
+			filename := filepath.Join(tmpDir, "day1_part1_2015."+ext)
+			if err := os.WriteFile(filename, []byte(tt.code), 0644); err != nil {
+				t.Fatalf("Failed to write solution file: %v", err)
+			}
+
+			result, err := judgeSolution(context.Background(), Challenge{Answer: "42"}, filename, tt.lang, Limits{Timeout: 10 * time.Second}, false)
+			if err != nil {
+				t.Fatalf("Failed to judge solution: %v", err)
+			}
+			if result.Verdict != VerdictCorrect {
+				t.Errorf("Expected correct verdict, got %q. Output: %s", result.Verdict, result.Output)
+			}
+
+			// Build artifacts must not be left next to the source
+			entries, _ := os.ReadDir(tmpDir)
+			if len(entries) != 1 {
+				t.Errorf("Expected only the source file in %s, found %d entries", tmpDir, len(entries))
+			}
+		})
+	}
+}
+
+func TestGetCommandCompileError(t *testing.T) {
+	if err := checkToolchain("c"); err != nil {
+		t.Skipf("Skipping: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "broken.c")
+	if err := os.WriteFile(filename, []byte("int main() { return missing; }\n"), 0644); err != nil {
+		t.Fatalf("Failed to write solution file: %v", err)
+	}
+
+	_, cleanup, err := getCommand("c", filename)
+	defer cleanup()
+
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("Expected *CompileError, got: %v", err)
+	}
+	if !strings.Contains(compileErr.Output, "missing") {
+		t.Errorf("Expected compiler diagnostics, got: %s", compileErr.Output)
+	}
+}
+
+func TestGetCommandMissingToolchain(t *testing.T) {
+	toolchains["fake"] = languageToolchain{Run: []string{"aocgen-no-such-interpreter", "{file}"}}
+	defer delete(toolchains, "fake")
+
+	_, cleanup, err := getCommand("fake", "solution.fake")
+	defer cleanup()
+
+	var missing *MissingToolchainError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected *MissingToolchainError, got: %v", err)
+	}
+	if missing.Program != "aocgen-no-such-interpreter" {
+		t.Errorf("Unexpected missing program: %s", missing.Program)
+	}
+	if !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("Expected a helpful error message, got: %v", err)
+	}
+}
+
+func TestGetCommandEntryPlaceholder(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("Skipping: sh not available")
+	}
+
+	toolchains["fake"] = languageToolchain{
+		Run:    []string{sh, "-c", "echo {entry}; cat {file}"},
+		Source: "{entry}.src",
+		Entry:  []*regexp.Regexp{regexp.MustCompile(`module (\w+)`)},
+	}
+	defer delete(toolchains, "fake")
+
+	filename := filepath.Join(t.TempDir(), "solution.fake")
+	if err := os.WriteFile(filename, []byte("module Answer"), 0644); err != nil {
+		t.Fatalf("Failed to write solution file: %v", err)
+	}
+
+	cmd, cleanup, err := getCommand("fake", filename)
+	if err != nil {
+		t.Fatalf("Failed to get command: %v", err)
+	}
+	output, err := cmd.Output()
+	cleanup()
+	if err != nil {
+		t.Fatalf("Failed to run command: %v", err)
+	}
+
+	if string(output) != "Answer\nmodule Answer" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if !strings.Contains(cmd.String(), "Answer.src") {
+		t.Errorf("Expected the source to be copied to Answer.src, got command: %s", cmd.String())
+	}
+}
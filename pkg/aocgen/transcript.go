@@ -0,0 +1,289 @@
+package aocgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const transcriptsDir = "transcripts"
+
+// Transcript is the full record of one generation: what was sent to the
+// model, the raw HTTP exchanges with the provider and what came back. It is
+// stored under transcripts/<challenge>/<lang>_<attempt>.json in the cache
+// directory, so benchmark results can be traced back to the model's output.
+type Transcript struct {
+	Challenge string        `json:"challenge"`
+	Lang      string        `json:"lang"`
+	Attempt   int           `json:"attempt"`
+	Model     string        `json:"model"`
+	Provider  string        `json:"provider"`
+	Time      time.Time     `json:"time"`
+	Latency   time.Duration `json:"latency"`
+	System    string        `json:"system,omitempty"`
+	Prompt    string        `json:"prompt"`
+	Sampling  Sampling      `json:"sampling"`
+	Stream    bool          `json:"stream,omitempty"`
+	// Structured is set when the model was asked for a JSON reply
+	Structured bool `json:"structured,omitempty"`
+	// Cached is set when the response came from the response cache instead
+	// of the provider
+	Cached bool `json:"cached,omitempty"`
+	// Exchanges are the HTTP requests sent to the provider, with their raw
+	// bodies, and Retries the number of them that were retries after a
+	// transient error
+	Exchanges []TranscriptExchange `json:"exchanges"`
+	Retries   int                  `json:"retries,omitempty"`
+	// Response is the text of the model's reply and Code the solution
+	// extracted from it, before formatting
+	Response string `json:"response"`
+	Code     string `json:"code"`
+	Usage    Usage  `json:"usage"`
+}
+
+// TranscriptExchange is one HTTP request to a model provider and its raw
+// response. Credentials in the URL are redacted.
+type TranscriptExchange struct {
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+	Request  string `json:"request"`
+	Status   string `json:"status,omitempty"`
+	Response string `json:"response,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// modelProvider names the provider completeCode sends requests for model to.
+func modelProvider(model string) string {
+	switch {
+	case model == "test":
+		return "test"
+	case strings.HasPrefix(model, "gpt-"):
+		return "openai"
+	case strings.HasPrefix(model, "ollama/"):
+		return "ollama"
+	case strings.HasPrefix(model, "groq/"):
+		return "groq"
+	case isMistralModel(model):
+		return "mistral"
+	case strings.HasPrefix(model, "bedrock/"):
+		return "bedrock"
+	}
+	return ""
+}
+
+// exchangeRecorderKey is the context key of the exchangeRecorder a generation
+// collects its HTTP exchanges in.
+type exchangeRecorderKey struct{}
+
+type exchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []TranscriptExchange
+	retries   int
+}
+
+func (r *exchangeRecorder) add(exchange TranscriptExchange) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+	return len(r.exchanges) - 1
+}
+
+func (r *exchangeRecorder) update(i int, f func(*TranscriptExchange)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f(&r.exchanges[i])
+}
+
+// retried counts a request that is sent again after a transient error.
+func (r *exchangeRecorder) retried() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retries++
+}
+
+func (r *exchangeRecorder) retryCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.retries
+}
+
+func (r *exchangeRecorder) all() []TranscriptExchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]TranscriptExchange{}, r.exchanges...)
+}
+
+// recordExchanges keeps the raw requests and responses of a generation in
+// the exchangeRecorder of the request's context, if there is one.
+func recordExchanges(next ProviderHandler) ProviderHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		recorder, ok := req.Context().Value(exchangeRecorderKey{}).(*exchangeRecorder)
+		if !ok {
+			return next(req)
+		}
+
+		var body []byte
+		if req.Body != nil {
+			var err error
+			body, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		i := recorder.add(TranscriptExchange{Method: req.Method, URL: redactURL(req.URL), Request: string(body)})
+
+		resp, err := next(req)
+		if err != nil {
+			recorder.update(i, func(e *TranscriptExchange) { e.Error = err.Error() })
+			return nil, err
+		}
+		recorder.update(i, func(e *TranscriptExchange) { e.Status = resp.Status })
+		resp.Body = &recordedBody{ReadCloser: resp.Body, recorder: recorder, index: i}
+		return resp, nil
+	}
+}
+
+// recordedBody copies a response body into its exchange as it is read, so
+// streamed responses still arrive as they are generated. The exchange gets
+// the body when it is closed.
+type recordedBody struct {
+	io.ReadCloser
+	recorder *exchangeRecorder
+	index    int
+	body     bytes.Buffer
+	closed   bool
+}
+
+func (b *recordedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.body.Write(p[:n])
+	return n, err
+}
+
+func (b *recordedBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		b.recorder.update(b.index, func(e *TranscriptExchange) { e.Response = b.body.String() })
+	}
+	return b.ReadCloser.Close()
+}
+
+func transcriptPath(challenge, lang string, attempt int) string {
+	return filepath.Join(getCacheDir(), transcriptsDir, challenge, fmt.Sprintf("%s_%d.json", strings.ToLower(lang), attempt))
+}
+
+func saveTranscript(transcript Transcript) error {
+	path := transcriptPath(transcript.Challenge, transcript.Lang, transcript.Attempt)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(transcript, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func loadTranscript(challenge, lang string, attempt int) (Transcript, error) {
+	var transcript Transcript
+	data, err := os.ReadFile(transcriptPath(challenge, lang, attempt))
+	if err != nil {
+		return transcript, err
+	}
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		return transcript, fmt.Errorf("error parsing transcript: %v", err)
+	}
+	return transcript, nil
+}
+
+// runTranscriptShowCommand prints the transcript of an attempt, by default
+// the latest one of the challenge in --lang.
+func runTranscriptShowCommand(flags Flags, w io.Writer) error {
+	if flags.Day == 0 || flags.Year == 0 || flags.Lang == "" {
+		return fmt.Errorf("--day, --year and --lang are required")
+	}
+	if flags.Part == 0 {
+		flags.Part = 1
+	}
+	name := fmt.Sprintf("day%d_part%d_%d", flags.Day, flags.Part, flags.Year)
+
+	number := flags.Attempt
+	if number == 0 {
+		attempts, err := loadAttempts()
+		if err != nil {
+			return err
+		}
+		for _, a := range filterAttempts(attempts, flags) {
+			number = max(number, a.Number)
+		}
+		if number == 0 {
+			return fmt.Errorf("no attempts found for %s in %s", name, flags.Lang)
+		}
+	}
+
+	transcript, err := loadTranscript(name, flags.Lang, number)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no transcript of attempt %d of %s in %s", number, name, flags.Lang)
+	}
+	if err != nil {
+		return err
+	}
+
+	if flags.JSON {
+		return emitJSON(transcript)
+	}
+	writeTranscript(w, transcript)
+	return nil
+}
+
+func writeTranscript(w io.Writer, t Transcript) {
+	fmt.Fprintf(w, "Challenge: %s (%s, attempt #%d)\n", t.Challenge, t.Lang, t.Attempt)
+	model := t.Model
+	if t.Provider != "" {
+		model += " via " + t.Provider
+	}
+	if t.Cached {
+		model += " (cached response)"
+	}
+	if t.Structured {
+		model += " (structured output)"
+	}
+	fmt.Fprintf(w, "Model:     %s\n", model)
+	fmt.Fprintf(w, "Time:      %s\n", t.Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Latency:   %v\n", t.Latency.Round(time.Millisecond))
+	if t.Retries > 0 {
+		fmt.Fprintf(w, "Retries:   %d\n", t.Retries)
+	}
+	fmt.Fprintf(w, "Tokens:    %d prompt, %d completion\n", t.Usage.PromptTokens, t.Usage.CompletionTokens)
+	sampling, _ := json.Marshal(t.Sampling)
+	fmt.Fprintf(w, "Sampling:  %s\n", sampling)
+	for _, e := range t.Exchanges {
+		status := e.Status
+		if e.Error != "" {
+			status = "error: " + e.Error
+		}
+		fmt.Fprintf(w, "Request:   %s %s -> %s (%d bytes sent, %d received)\n", e.Method, e.URL, status, len(e.Request), len(e.Response))
+	}
+
+	sections := []struct{ title, text string }{
+		{"System prompt", t.System},
+		{"Prompt", t.Prompt},
+		{"Response", t.Response},
+		{"Code", t.Code},
+	}
+	for _, section := range sections {
+		if section.text == "" {
+			continue
+		}
+		fmt.Fprintf(w, "\n=== %s ===\n%s\n", section.title, strings.TrimRight(section.text, "\n"))
+	}
+}
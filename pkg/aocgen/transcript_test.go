@@ -0,0 +1,88 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTranscripts(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "Here you go:\n```python\nprint(42)\n```"}},
+			},
+			"usage": map[string]int{"prompt_tokens": 12, "completion_tokens": 5},
+		})
+	}))
+	defer server.Close()
+
+	temperature := 0.2
+	challenge := Challenge{Name: "day2_part1_2023", Task: "Print the answer."}
+	flags := Flags{Lang: "python", Model: "gpt-4o-mini", ModelAPI: server.URL + "?key=secret-key", NoFormat: true, Sampling: Sampling{Temperature: &temperature}}
+	for i := 0; i < 2; i++ {
+		if err := generateSolutionFile(context.Background(), challenge, flags); err != nil {
+			t.Fatalf("Failed to generate solution file: %v", err)
+		}
+	}
+
+	transcript, err := loadTranscript("day2_part1_2023", "python", 2)
+	if err != nil {
+		t.Fatalf("Failed to load transcript: %v", err)
+	}
+	if transcript.Attempt != 2 || transcript.Provider != "openai" || transcript.Code != "print(42)" || transcript.Usage.CompletionTokens != 5 {
+		t.Errorf("Unexpected transcript: %+v", transcript)
+	}
+	if !strings.Contains(transcript.Prompt, "Print the answer.") || !strings.Contains(transcript.Response, "Here you go:") {
+		t.Errorf("Expected the prompt and the model's reply, got %+v", transcript)
+	}
+	if len(transcript.Exchanges) != 1 {
+		t.Fatalf("Expected 1 exchange, got %+v", transcript.Exchanges)
+	}
+	exchange := transcript.Exchanges[0]
+	if exchange.Status != "200 OK" || !strings.Contains(exchange.Request, `"temperature":0.2`) || !strings.Contains(exchange.Response, `"prompt_tokens":12`) {
+		t.Errorf("Unexpected exchange: %+v", exchange)
+	}
+	if strings.Contains(exchange.URL, "secret-key") {
+		t.Errorf("Expected the API key in the URL to be redacted, got %s", exchange.URL)
+	}
+
+	var out bytes.Buffer
+	if err := runTranscriptShowCommand(Flags{Day: 2, Year: 2023, Lang: "python"}, &out); err != nil {
+		t.Fatalf("transcript show failed: %v", err)
+	}
+	for _, want := range []string{"attempt #2", "gpt-4o-mini via openai", "12 prompt, 5 completion", "=== Prompt ===", "=== Code ===\nprint(42)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	data := captureJSON(t, func() error {
+		return runTranscriptShowCommand(Flags{Day: 2, Year: 2023, Lang: "python", Attempt: 1, JSON: true}, &out)
+	})
+	var shown Transcript
+	if err := json.Unmarshal(data, &shown); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if shown.Attempt != 1 || shown.Challenge != "day2_part1_2023" {
+		t.Errorf("Unexpected transcript: %+v", shown)
+	}
+
+	if err := runTranscriptShowCommand(Flags{Day: 2, Year: 2023, Lang: "python", Attempt: 3}, &out); err == nil {
+		t.Error("Expected an error for a missing transcript")
+	}
+}
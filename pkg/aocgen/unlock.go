@@ -0,0 +1,60 @@
+package aocgen
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// aocTimeZone is the time zone of the Advent of Code calendar: a new puzzle
+// unlocks every day of December at midnight EST (UTC-5).
+var aocTimeZone = time.FixedZone("EST", -5*60*60)
+
+// unlockRetries is how often a download is retried when the puzzle is still
+// not served right after its unlock time, one second apart.
+const unlockRetries = 10
+
+// unlockNow and unlockSleep are replaced in tests with a fake clock.
+var (
+	unlockNow   = time.Now
+	unlockSleep = time.Sleep
+)
+
+// unlockTime returns when the puzzle of day in year unlocks.
+func unlockTime(year, day int) time.Time {
+	return time.Date(year, time.December, day, 0, 0, 0, 0, aocTimeZone)
+}
+
+// checkUnlocked returns an error telling how long to wait if the puzzle of day
+// in year is not unlocked yet.
+func checkUnlocked(year, day int) error {
+	unlock := unlockTime(year, day)
+	if remaining := unlock.Sub(unlockNow()); remaining > 0 {
+		return fmt.Errorf("day %d of %d unlocks in %v (%s), use --wait to wait for it",
+			day, year, remaining.Round(time.Second), unlock.Local().Format("Jan 2 15:04 MST"))
+	}
+	return nil
+}
+
+// waitForUnlock prints a countdown to w and returns once the puzzle of day in
+// year is unlocked.
+func waitForUnlock(w io.Writer, year, day int) {
+	unlock := unlockTime(year, day)
+	waited := false
+	for {
+		remaining := unlock.Sub(unlockNow())
+		if remaining <= 0 {
+			break
+		}
+		waited = true
+		fmt.Fprintf(w, "\rDay %d unlocks in %v  ", day, remaining.Round(time.Second))
+		step := time.Second
+		if remaining < step {
+			step = remaining
+		}
+		unlockSleep(step)
+	}
+	if waited {
+		fmt.Fprintf(w, "\rDay %d is unlocked!              \n", day)
+	}
+}
@@ -0,0 +1,95 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useFakeClock makes the unlock logic see now, advanced by every sleep.
+func useFakeClock(t *testing.T, now time.Time) {
+	t.Helper()
+	originalNow, originalSleep := unlockNow, unlockSleep
+	unlockNow = func() time.Time { return now }
+	unlockSleep = func(d time.Duration) { now = now.Add(d) }
+	t.Cleanup(func() { unlockNow, unlockSleep = originalNow, originalSleep })
+}
+
+func TestUnlockTime(t *testing.T) {
+	unlock := unlockTime(2023, 5)
+	if expected := time.Date(2023, time.December, 5, 5, 0, 0, 0, time.UTC); !unlock.Equal(expected) {
+		t.Errorf("Expected day 5 to unlock at %v, got %v", expected, unlock)
+	}
+
+	useFakeClock(t, unlock.Add(-90*time.Second))
+	err := checkUnlocked(2023, 5)
+	if err == nil || !strings.Contains(err.Error(), "1m30s") {
+		t.Errorf("Expected the remaining time in the error, got: %v", err)
+	}
+	if err := checkUnlocked(2023, 4); err != nil {
+		t.Errorf("Expected day 4 to be unlocked, got: %v", err)
+	}
+
+	var out bytes.Buffer
+	waitForUnlock(&out, 2023, 5)
+	if unlockNow().Before(unlock) {
+		t.Errorf("Expected to wait until %v, only waited until %v", unlock, unlockNow())
+	}
+	if !strings.Contains(out.String(), "Day 5 unlocks in 1m30s") || !strings.Contains(out.String(), "Day 5 is unlocked!") {
+		t.Errorf("Unexpected countdown output: %q", out.String())
+	}
+}
+
+func TestDownloadWaitsForUnlock(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	unlock := unlockTime(2023, 7)
+	useFakeClock(t, unlock.Add(-5*time.Second))
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unlockNow().Before(unlock) {
+			t.Errorf("Request sent before the puzzle unlocked: %s", r.URL.Path)
+		}
+		requests++
+		if requests == 1 {
+			// The first request right after the unlock can still miss
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/input") {
+			w.Write([]byte("32T3K 765"))
+			return
+		}
+		w.Write([]byte(`<article class="day-desc"><h2>--- Day 7: Camel Cards ---</h2><p>Play cards.</p></article>`))
+	}))
+	defer server.Close()
+
+	originalAocBaseURL := aocBaseURL
+	aocBaseURL = server.URL
+	defer func() { aocBaseURL = originalAocBaseURL }()
+
+	flags := Flags{Year: 2023, Day: 7, Part: 1, Session: "test_session"}
+	if _, err := downloadChallengeIfMissing(context.Background(), flags); err == nil || !strings.Contains(err.Error(), "--wait") {
+		t.Fatalf("Expected a locked puzzle to be refused without --wait, got: %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("Expected no request for a locked puzzle, got %d", requests)
+	}
+
+	flags.Wait = true
+	downloaded, err := downloadChallengeIfMissing(context.Background(), flags)
+	if err != nil || !downloaded {
+		t.Fatalf("Expected the puzzle to be downloaded after it unlocked, got %v, %v", downloaded, err)
+	}
+
+	challenges, _ := loadChallenges(getCacheDir(), challengesFile)
+	if c := lookupChallenge(challenges, "day7_part1_2023"); c == nil || c.Input != "32T3K 765" {
+		t.Errorf("Expected the downloaded challenge to be stored, got %+v", c)
+	}
+}
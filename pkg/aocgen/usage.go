@@ -0,0 +1,219 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const usageFile = "usage.json"
+
+// Usage is the token count reported by a provider for a single completion.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// UsageEntry accumulates usage for one model on one day.
+type UsageEntry struct {
+	Date             string `json:"date"`
+	Model            string `json:"model"`
+	Requests         int    `json:"requests"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// ModelPrice is the cost in USD per million tokens.
+type ModelPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// defaultPrices maps model name prefixes to their list price. The longest
+// matching prefix wins; models without a match have no known cost and are
+// reported as n/a.
+var defaultPrices = map[string]ModelPrice{
+	"gpt-3.5-turbo":      {Prompt: 0.50, Completion: 1.50},
+	"gpt-4":              {Prompt: 30.00, Completion: 60.00},
+	"gpt-4-turbo":        {Prompt: 10.00, Completion: 30.00},
+	"gpt-4o":             {Prompt: 2.50, Completion: 10.00},
+	"gpt-4o-mini":        {Prompt: 0.15, Completion: 0.60},
+	"groq/llama3-8b":     {Prompt: 0.05, Completion: 0.08},
+	"groq/llama3-70b":    {Prompt: 0.59, Completion: 0.79},
+	"groq/llama-3.1-8b":  {Prompt: 0.05, Completion: 0.08},
+	"groq/llama-3.1-70b": {Prompt: 0.59, Completion: 0.79},
+	"groq/mixtral-8x7b":  {Prompt: 0.24, Completion: 0.24},
+	"groq/gemma2-9b":     {Prompt: 0.20, Completion: 0.20},
+	"mistral-large":      {Prompt: 2.00, Completion: 6.00},
+	"mistral-small":      {Prompt: 0.20, Completion: 0.60},
+	"codestral-":         {Prompt: 0.30, Completion: 0.90},
+	"ollama/":            {},
+
+	"bedrock/anthropic.claude-3-5-sonnet": {Prompt: 3.00, Completion: 15.00},
+	"bedrock/anthropic.claude-3-haiku":    {Prompt: 0.25, Completion: 1.25},
+	"bedrock/meta.llama3-8b":              {Prompt: 0.30, Completion: 0.60},
+	"bedrock/meta.llama3-70b":             {Prompt: 2.65, Completion: 3.50},
+}
+
+// parseUsage reads token counts from a provider response. It understands the
+// OpenAI-compatible "usage" object and Ollama's eval counters.
+func parseUsage(response map[string]interface{}) Usage {
+	var usage Usage
+	if u, ok := response["usage"].(map[string]interface{}); ok {
+		if n, ok := u["prompt_tokens"].(float64); ok {
+			usage.PromptTokens = int(n)
+		}
+		if n, ok := u["completion_tokens"].(float64); ok {
+			usage.CompletionTokens = int(n)
+		}
+		return usage
+	}
+	if n, ok := response["prompt_eval_count"].(float64); ok {
+		usage.PromptTokens = int(n)
+	}
+	if n, ok := response["eval_count"].(float64); ok {
+		usage.CompletionTokens = int(n)
+	}
+	return usage
+}
+
+// usageMu serializes updates of the usage ledger by concurrent generations.
+var usageMu sync.Mutex
+
+// recordUsage adds usage for model to today's entry in the usage ledger.
+// Completions that report no usage are not recorded.
+func recordUsage(model string, usage Usage) error {
+	if usage == (Usage{}) {
+		return nil
+	}
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	entries, err := loadUsage()
+	if err != nil {
+		return err
+	}
+
+	date := time.Now().Format("2006-01-02")
+	found := false
+	for i := range entries {
+		if entries[i].Date == date && entries[i].Model == model {
+			entries[i].Requests++
+			entries[i].PromptTokens += usage.PromptTokens
+			entries[i].CompletionTokens += usage.CompletionTokens
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, UsageEntry{
+			Date:             date,
+			Model:            model,
+			Requests:         1,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+		})
+	}
+
+	return saveUsage(entries)
+}
+
+// loadUsage reads the usage ledger. A missing ledger yields no entries.
+func loadUsage() ([]UsageEntry, error) {
+	var entries []UsageEntry
+	data, err := os.ReadFile(filepath.Join(getCacheDir(), usageFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing usage ledger: %v", err)
+	}
+	return entries, nil
+}
+
+func saveUsage(entries []UsageEntry) error {
+	if err := os.MkdirAll(getCacheDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getCacheDir(), usageFile), data, 0644)
+}
+
+// priceForModel returns the price of model, preferring overrides over the
+// built-in table and the longest matching prefix within each.
+func priceForModel(model string, overrides map[string]ModelPrice) (ModelPrice, bool) {
+	for _, table := range []map[string]ModelPrice{overrides, defaultPrices} {
+		best := ""
+		for prefix := range table {
+			if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+				best = prefix
+			}
+		}
+		if best != "" {
+			return table[best], true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// estimateCost returns the cost in USD of usage at price.
+func estimateCost(price ModelPrice, promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6
+}
+
+// printUsage writes the ledger as a table of tokens and estimated cost per
+// model per day, followed by a total.
+func printUsage(w io.Writer, entries []UsageEntry, prices map[string]ModelPrice) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No token usage recorded yet.")
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		return entries[i].Model < entries[j].Model
+	})
+
+	fmt.Fprintf(w, "%-10s  %-30s  %8s  %12s  %12s  %10s\n", "Date", "Model", "Requests", "Prompt", "Completion", "Cost (USD)")
+	var total float64
+	var totalPrompt, totalCompletion int
+	for _, e := range entries {
+		cost := "n/a"
+		if price, ok := priceForModel(e.Model, prices); ok {
+			c := estimateCost(price, e.PromptTokens, e.CompletionTokens)
+			total += c
+			cost = fmt.Sprintf("%.4f", c)
+		}
+		totalPrompt += e.PromptTokens
+		totalCompletion += e.CompletionTokens
+		fmt.Fprintf(w, "%-10s  %-30s  %8d  %12d  %12d  %10s\n", e.Date, e.Model, e.Requests, e.PromptTokens, e.CompletionTokens, cost)
+	}
+	fmt.Fprintf(w, "%-10s  %-30s  %8s  %12d  %12d  %10.4f\n", "Total", "", "", totalPrompt, totalCompletion, total)
+}
+
+func runUsageCommand(w io.Writer) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+	entries, err := loadUsage()
+	if err != nil {
+		return err
+	}
+	printUsage(w, entries, cfg.Prices)
+	return nil
+}
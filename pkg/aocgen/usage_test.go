@@ -0,0 +1,116 @@
+package aocgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseUsage(t *testing.T) {
+	var openAI map[string]interface{}
+	json.Unmarshal([]byte(`{"usage":{"prompt_tokens":12,"completion_tokens":34,"total_tokens":46}}`), &openAI)
+	if got := parseUsage(openAI); got != (Usage{PromptTokens: 12, CompletionTokens: 34}) {
+		t.Errorf("Unexpected OpenAI usage: %+v", got)
+	}
+
+	var ollama map[string]interface{}
+	json.Unmarshal([]byte(`{"done":true,"prompt_eval_count":5,"eval_count":7}`), &ollama)
+	if got := parseUsage(ollama); got != (Usage{PromptTokens: 5, CompletionTokens: 7}) {
+		t.Errorf("Unexpected Ollama usage: %+v", got)
+	}
+
+	if got := parseUsage(map[string]interface{}{}); got != (Usage{}) {
+		t.Errorf("Expected no usage, got: %+v", got)
+	}
+}
+
+func TestRecordUsageAccumulates(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	for _, u := range []Usage{{100, 50}, {200, 25}, {}} {
+		if err := recordUsage("gpt-4o-mini", u); err != nil {
+			t.Fatalf("Failed to record usage: %v", err)
+		}
+	}
+	if err := recordUsage("ollama/llama3", Usage{10, 20}); err != nil {
+		t.Fatalf("Failed to record usage: %v", err)
+	}
+
+	entries, err := loadUsage()
+	if err != nil {
+		t.Fatalf("Failed to load usage: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 ledger entries, got %d: %+v", len(entries), entries)
+	}
+	e := entries[0]
+	if e.Model != "gpt-4o-mini" || e.Requests != 2 || e.PromptTokens != 300 || e.CompletionTokens != 75 {
+		t.Errorf("Unexpected accumulated entry: %+v", e)
+	}
+}
+
+func TestPrintUsage(t *testing.T) {
+	entries := []UsageEntry{
+		{Date: "2024-12-02", Model: "ollama/llama3", Requests: 1, PromptTokens: 1000, CompletionTokens: 1000},
+		{Date: "2024-12-01", Model: "gpt-4o", Requests: 3, PromptTokens: 1000000, CompletionTokens: 100000},
+		{Date: "2024-12-01", Model: "custom-model", Requests: 1, PromptTokens: 10, CompletionTokens: 10},
+	}
+
+	var out bytes.Buffer
+	printUsage(&out, entries, map[string]ModelPrice{"gpt-4o": {Prompt: 1, Completion: 10}})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected header, 3 rows and a total, got:\n%s", out.String())
+	}
+	if !strings.Contains(lines[2], "gpt-4o") || !strings.Contains(lines[2], "2.0000") {
+		t.Errorf("Expected overridden gpt-4o price in row, got: %q", lines[2])
+	}
+	if !strings.Contains(lines[1], "custom-model") || !strings.Contains(lines[1], "n/a") {
+		t.Errorf("Expected unknown model to have no cost, got: %q", lines[1])
+	}
+	if !strings.Contains(lines[3], "0.0000") {
+		t.Errorf("Expected Ollama to be free, got: %q", lines[3])
+	}
+	if !strings.HasPrefix(lines[4], "Total") || !strings.Contains(lines[4], "2.0000") {
+		t.Errorf("Unexpected total row: %q", lines[4])
+	}
+}
+
+func TestGenerateCodeWithAIRecordsUsage(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": "```go\npackage main\n```"}},
+			},
+			"usage": map[string]int{"prompt_tokens": 42, "completion_tokens": 8},
+		})
+	}))
+	defer server.Close()
+
+	_, err := generateCodeWithAI(context.Background(), Challenge{Task: "test task"}, Flags{
+		Lang:     "go",
+		Model:    "gpt-4o-mini",
+		ModelAPI: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate code with AI: %v", err)
+	}
+
+	entries, err := loadUsage()
+	if err != nil {
+		t.Fatalf("Failed to load usage: %v", err)
+	}
+	if len(entries) != 1 || entries[0].PromptTokens != 42 || entries[0].CompletionTokens != 8 {
+		t.Errorf("Expected usage to be recorded, got: %+v", entries)
+	}
+}
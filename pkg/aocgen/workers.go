@@ -0,0 +1,59 @@
+package aocgen
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// runWorkers calls fn once for every index in [0, n) from up to workers
+// goroutines. Each worker gets its own temporary working directory, passed to
+// fn, so solutions reading input.txt never see another challenge's input.
+// Callers collect results by index, which keeps them in job order no matter
+// which worker finishes first. Once ctx is cancelled no new jobs are started;
+// jobs already running are left to finish or notice the cancellation.
+func runWorkers(ctx context.Context, n, workers int, fn func(i int, dir string)) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	dirs := make([]string, 0, workers)
+	defer func() {
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		dir, err := os.MkdirTemp("", "aocgen_worker_")
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i, dir)
+			}
+		}(dir)
+	}
+
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return nil
+}
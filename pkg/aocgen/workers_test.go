@@ -0,0 +1,139 @@
+package aocgen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunWorkersIsolatesInput(t *testing.T) {
+	const n = 12
+
+	var mu sync.Mutex
+	dirs := make(map[string]bool)
+	got := make([]string, n)
+
+	err := runWorkers(context.Background(), n, 4, func(i int, dir string) {
+		mu.Lock()
+		dirs[dir] = true
+		mu.Unlock()
+
+		challenge := Challenge{Input: fmt.Sprintf("input %d", i)}
+		if err := writeInputFile(dir, challenge); err != nil {
+			t.Errorf("Failed to write input file: %v", err)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		data, err := os.ReadFile(filepath.Join(dir, "input.txt"))
+		if err != nil {
+			t.Errorf("Failed to read input file: %v", err)
+			return
+		}
+		got[i] = string(data)
+	})
+	if err != nil {
+		t.Fatalf("runWorkers failed: %v", err)
+	}
+
+	for i, input := range got {
+		if expected := fmt.Sprintf("input %d", i); input != expected {
+			t.Errorf("Job %d read %q, expected %q", i, input, expected)
+		}
+	}
+	if len(dirs) != 4 {
+		t.Errorf("Expected 4 worker directories, got %d", len(dirs))
+	}
+	for dir := range dirs {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("Expected worker directory %s to be removed", dir)
+		}
+	}
+}
+
+func TestBenchmarkSolutionRunsInWorkerDir(t *testing.T) {
+	workDir, err := os.MkdirTemp("", "aocgen_bench_")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	solution := filepath.Join(workDir, "solution.py")
+	os.WriteFile(solution, []byte("import time\nif open('input.txt').read() == 'slow':\n    time.sleep(5)\n"), 0644)
+
+	inputs := []string{"fast", "slow", "fast"}
+	durations := make([]time.Duration, len(inputs))
+	errs := make([]error, len(inputs))
+	timeout := 500 * time.Millisecond
+
+	err = runWorkers(context.Background(), len(inputs), len(inputs), func(i int, dir string) {
+		challenge := Challenge{Input: inputs[i]}
+		if err := writeInputFile(dir, challenge); err != nil {
+			errs[i] = err
+			return
+		}
+		durations[i], _, errs[i] = benchmarkSolution(context.Background(), challenge, solution, "python", timeout, dir)
+	})
+	if err != nil {
+		t.Fatalf("runWorkers failed: %v", err)
+	}
+
+	for i, input := range inputs {
+		if errs[i] != nil {
+			if strings.Contains(errs[i].Error(), "not installed") {
+				t.Skip("python is not installed")
+			}
+			t.Fatalf("Benchmark %d failed: %v", i, errs[i])
+		}
+		if input == "slow" && durations[i] != timeout {
+			t.Errorf("Expected slow solution to hit the %v timeout, got %v", timeout, durations[i])
+		}
+		if input == "fast" && durations[i] >= timeout {
+			t.Errorf("Expected fast solution to finish before the timeout, got %v", durations[i])
+		}
+	}
+}
+
+func TestRunWorkersStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	started := 0
+	err := runWorkers(ctx, 10, 1, func(i int, dir string) {
+		mu.Lock()
+		started++
+		mu.Unlock()
+		if i == 2 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("runWorkers failed: %v", err)
+	}
+	if started != 3 {
+		t.Errorf("Expected no jobs to start after cancellation, %d started", started)
+	}
+}
+
+func TestBenchmarkSolutionInterrupted(t *testing.T) {
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	dir := t.TempDir()
+	solution := filepath.Join(dir, "solution.py")
+	if err := os.WriteFile(solution, []byte("import time\ntime.sleep(10)"), 0644); err != nil {
+		t.Fatalf("Failed to write solution: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	if _, _, err := benchmarkSolution(ctx, Challenge{}, solution, "python", 10*time.Second, dir); err != errInterrupted {
+		t.Errorf("Expected errInterrupted, got %v", err)
+	}
+}
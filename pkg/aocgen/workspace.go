@@ -0,0 +1,129 @@
+package aocgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceDir returns the directory of a challenge in the --workspace
+// layout, e.g. 2023/day03/part1.
+func workspaceDir(year, day, part int) string {
+	return filepath.Join(fmt.Sprint(year), fmt.Sprintf("day%02d", day), fmt.Sprintf("part%d", part))
+}
+
+// defaultWorkdir is the --workdir of generate, eval and the commands that
+// read their files when neither --workdir nor --out is given.
+func defaultWorkdir() string {
+	return filepath.Join(getCacheDir(), workDir)
+}
+
+// challengeDir returns the directory of the challenge of flags: its
+// --workspace directory, or its own directory in the working directory,
+// e.g. <workdir>/day3_part1_2023, or --out itself. Without --workspace,
+// --workdir and --out the working directory is defaultWorkdir; a workspace
+// is a project to work on, so it is made in the current directory instead.
+func challengeDir(flags Flags) string {
+	part := flags.Part
+	if part == 0 {
+		part = 1
+	}
+	workdir := flags.Workdir
+	if workdir == "" && flags.Out == "" && !flags.Workspace {
+		workdir = defaultWorkdir()
+	}
+	var dir string
+	if flags.Workspace {
+		dir = workspaceDir(flags.Year, flags.Day, part)
+	} else if workdir != "" {
+		dir = fmt.Sprintf("day%d_part%d_%d", flags.Day, part, flags.Year)
+	}
+	if workdir != "" {
+		dir = filepath.Join(workdir, dir)
+	}
+	if flags.Out != "" {
+		dir = filepath.Join(flags.Out, dir)
+	}
+	return dir
+}
+
+// openChallengeDir returns the directory of the challenge of flags, where
+// its solution and input.txt are read and written and the solution runs,
+// creating it if create is true.
+func openChallengeDir(flags Flags, create bool) (string, error) {
+	if flags.Out != "" && flags.Workdir != "" {
+		return "", fmt.Errorf("--out and --workdir cannot be used together")
+	}
+	dir := challengeDir(flags)
+
+	if create {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("error creating workspace: %v", err)
+		}
+	} else if _, err := os.Stat(dir); err != nil {
+		if !flags.Workspace && flags.Out != "" {
+			return "", fmt.Errorf("output directory %s not found, run generate with --out first", dir)
+		}
+		if !flags.Workspace && flags.Workdir != "" {
+			return "", fmt.Errorf("working directory %s not found, run generate with --workdir first", dir)
+		}
+		if !flags.Workspace {
+			return "", fmt.Errorf("working directory %s not found, run generate first", dir)
+		}
+		return "", fmt.Errorf("workspace %s not found, run generate with --workspace first", dir)
+	}
+	return dir, nil
+}
+
+// scaffoldWorkspace writes the task as README.md and the boilerplate lang
+// needs to build the solution as a standalone project into dir. Existing
+// boilerplate files are left alone.
+func scaffoldWorkspace(dir string, challenge Challenge, lang string) error {
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(taskMarkdown(challenge)), 0644); err != nil {
+		return fmt.Errorf("error writing README.md: %v", err)
+	}
+
+	for name, content := range workspaceBoilerplate(challenge.Name, lang) {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// taskMarkdown returns the task of challenge as a markdown document.
+func taskMarkdown(challenge Challenge) string {
+	day, part, year, _ := parseChallengeName(challenge.Name)
+	return fmt.Sprintf("# Advent of Code %d, Day %d, Part %d\n\n%s\n", year, day, part, strings.TrimSpace(challenge.Task))
+}
+
+// workspaceBoilerplate returns the project files lang needs next to the
+// solution, keyed by file name.
+func workspaceBoilerplate(name, lang string) map[string]string {
+	ext, _ := getFileExtension(lang)
+	solution := solutionFileName(name, ext)
+
+	switch strings.ToLower(lang) {
+	case "go":
+		return map[string]string{"go.mod": "module " + name + "\n\ngo 1.22\n"}
+	case "javascript", "typescript":
+		start := "node " + solution
+		if lang == "typescript" {
+			start = "npx --yes tsx " + solution
+		}
+		pkg, _ := json.MarshalIndent(map[string]interface{}{
+			"name":    strings.ReplaceAll(name, "_", "-"),
+			"private": true,
+			"scripts": map[string]string{"start": start},
+		}, "", "  ")
+		return map[string]string{"package.json": string(pkg) + "\n"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+package aocgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSolutionWorkspace(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	err := saveChallenges([]Challenge{{Name: "day3_part1_2023", Task: "Sum the gears.", Input: "467..114..", Answer: "4361"}})
+	if err != nil {
+		t.Fatalf("Failed to save challenges: %v", err)
+	}
+
+	flags := Flags{Day: 3, Part: 1, Year: 2023, Lang: "go", Model: "test", Workspace: true}
+	if err := generateSolution(flags); err != nil {
+		t.Fatalf("Failed to generate solution: %v", err)
+	}
+
+	if cwd, _ := os.Getwd(); cwd != tempDir {
+		if resolved, _ := filepath.EvalSymlinks(tempDir); cwd != resolved {
+			t.Errorf("Expected to be back in %s, got %s", tempDir, cwd)
+		}
+	}
+
+	dir := filepath.Join("2023", "day03", "part1")
+	for name, expected := range map[string]string{
+		"input.txt":          "467..114..",
+		"README.md":          "Sum the gears.",
+		"go.mod":             "module day3_part1_2023",
+		"day3_part1_2023.go": "Test model response",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Expected %s in the workspace: %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", name, expected, data)
+		}
+	}
+	if _, err := os.Stat("input.txt"); !os.IsNotExist(err) {
+		t.Errorf("Expected no input.txt in the working directory")
+	}
+
+	workspace, err := openChallengeDir(flags, false)
+	if err != nil {
+		t.Fatalf("Failed to open workspace: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "day3_part1_2023.go")); err != nil {
+		t.Errorf("Expected to find the solution inside the workspace: %v", err)
+	}
+
+	if _, err := openChallengeDir(Flags{Day: 4, Part: 1, Year: 2023, Workspace: true}, false); err == nil {
+		t.Errorf("Expected an error for a workspace that was never generated")
+	}
+}
+
+func TestWorkspaceBoilerplate(t *testing.T) {
+	files := workspaceBoilerplate("day1_part2_2022", "javascript")
+	if !strings.Contains(files["package.json"], `"start": "node day1_part2_2022.js"`) {
+		t.Errorf("Unexpected package.json: %s", files["package.json"])
+	}
+	if files := workspaceBoilerplate("day1_part2_2022", "python"); len(files) != 0 {
+		t.Errorf("Expected no boilerplate for python, got %v", files)
+	}
+}
+
+func TestGenerateAndEvaluateWorkdir(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{
+		{Name: "day1_part1_2015", Task: "Print the input.", Input: "42\n", Answer: "42"},
+		{Name: "day2_part1_2015", Task: "Print the input.", Input: "7\n", Answer: "7"},
+	})
+	os.WriteFile("input.txt", []byte("mine"), 0644)
+
+	work := filepath.Join(tempDir, "work")
+	for day := 1; day <= 2; day++ {
+		flags := Flags{Day: day, Part: 1, Year: 2015, Lang: "python", Model: "test", Workdir: work, NoFormat: true}
+		report, err := generateChallengeSolution(commandContext, flags)
+		if err != nil {
+			t.Fatalf("Failed to generate solution: %v", err)
+		}
+		if want := filepath.Join(work, report.Challenge, report.Challenge+".py"); report.File != want {
+			t.Errorf("Expected the solution in %s, got %s", want, report.File)
+		}
+		os.WriteFile(report.File, []byte("print(open('input.txt').read().strip())\n"), 0644)
+
+		_, result, err := evaluateChallengeSolution(commandContext, flags, nil)
+		if err != nil || result.Verdict != VerdictCorrect {
+			t.Errorf("Expected day %d to run in its own directory with its input, got %v, %v", day, result.Verdict, err)
+		}
+	}
+
+	if data, _ := os.ReadFile("input.txt"); string(data) != "mine" {
+		t.Errorf("Expected input.txt in the current directory to be left alone, got %q", data)
+	}
+	if _, err := openChallengeDir(Flags{Day: 3, Part: 1, Year: 2015, Workdir: work}, false); err == nil || !strings.Contains(err.Error(), "--workdir") {
+		t.Errorf("Expected an error for a challenge that was never generated, got %v", err)
+	}
+}
+
+func TestGenerateAndEvaluateDefaultWorkdir(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := checkToolchain("python"); err != nil {
+		t.Skip(err)
+	}
+
+	wd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(wd)
+
+	saveChallenges([]Challenge{{Name: "day1_part1_2015", Task: "Print the input.", Input: "42\n", Answer: "42"}})
+
+	flags := Flags{Day: 1, Part: 1, Year: 2015, Lang: "python", Model: "test", NoFormat: true}
+	report, err := generateChallengeSolution(commandContext, flags)
+	if err != nil {
+		t.Fatalf("Failed to generate solution: %v", err)
+	}
+	if want := filepath.Join(tempDir, workDir, "day1_part1_2015", "day1_part1_2015.py"); report.File != want {
+		t.Errorf("Expected the solution in %s, got %s", want, report.File)
+	}
+	os.WriteFile(report.File, []byte("print(open('input.txt').read().strip())\n"), 0644)
+
+	_, result, err := evaluateChallengeSolution(commandContext, flags, nil)
+	if err != nil || result.Verdict != VerdictCorrect {
+		t.Errorf("Expected the solution to run in its working directory, got %v, %v", result.Verdict, err)
+	}
+	for _, name := range []string{"input.txt", "day1_part1_2015.py"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("Expected no %s in the current directory", name)
+		}
+	}
+
+	// generate tells where the files went
+	flags.Force = true
+	output := captureStdout(t, func() {
+		err = generateSolution(flags)
+	})
+	if err != nil || !strings.Contains(output, "Solution: "+report.File) {
+		t.Errorf("Expected generate to print the solution path, got %v:\n%s", err, output)
+	}
+}